@@ -1,69 +1,475 @@
 package main
 
 import (
+	"bytes"
 	"context"
+	"crypto/tls"
+	"crypto/x509"
 	"flag"
+	"fmt"
 	"log"
 	"net"
 	"net/http"
 	"os"
 	"os/signal"
+	"path/filepath"
+	"strings"
 	"syscall"
 	"time"
 
 	mcp "github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/ovn-kubernetes/ovn-kubernetes-mcp/pkg/artifacts"
+	artifactsmcp "github.com/ovn-kubernetes/ovn-kubernetes-mcp/pkg/artifacts/mcp"
+	"github.com/ovn-kubernetes/ovn-kubernetes-mcp/pkg/audit"
+	"github.com/ovn-kubernetes/ovn-kubernetes-mcp/pkg/auth"
+	clustersmcp "github.com/ovn-kubernetes/ovn-kubernetes-mcp/pkg/clusters/mcp"
+	"github.com/ovn-kubernetes/ovn-kubernetes-mcp/pkg/completion"
+	"github.com/ovn-kubernetes/ovn-kubernetes-mcp/pkg/config"
+	"github.com/ovn-kubernetes/ovn-kubernetes-mcp/pkg/executor"
+	"github.com/ovn-kubernetes/ovn-kubernetes-mcp/pkg/jobs"
+	jobsmcp "github.com/ovn-kubernetes/ovn-kubernetes-mcp/pkg/jobs/mcp"
 	kernelmcp "github.com/ovn-kubernetes/ovn-kubernetes-mcp/pkg/kernel/mcp"
 	kubernetesmcp "github.com/ovn-kubernetes/ovn-kubernetes-mcp/pkg/kubernetes/mcp"
+	"github.com/ovn-kubernetes/ovn-kubernetes-mcp/pkg/metrics"
 	"github.com/ovn-kubernetes/ovn-kubernetes-mcp/pkg/middleware"
 	mustgathermcp "github.com/ovn-kubernetes/ovn-kubernetes-mcp/pkg/must-gather/mcp"
 	nettoolsmcp "github.com/ovn-kubernetes/ovn-kubernetes-mcp/pkg/network-tools/mcp"
 	ovnmcp "github.com/ovn-kubernetes/ovn-kubernetes-mcp/pkg/ovn/mcp"
 	ovsmcp "github.com/ovn-kubernetes/ovn-kubernetes-mcp/pkg/ovs/mcp"
+	runbooksmcp "github.com/ovn-kubernetes/ovn-kubernetes-mcp/pkg/runbooks/mcp"
 	sosreportmcp "github.com/ovn-kubernetes/ovn-kubernetes-mcp/pkg/sosreport/mcp"
+	"github.com/ovn-kubernetes/ovn-kubernetes-mcp/pkg/topology"
+	topologymcp "github.com/ovn-kubernetes/ovn-kubernetes-mcp/pkg/topology/mcp"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"k8s.io/client-go/kubernetes"
 )
 
 const defaultNetshootImage = "nicolaka/netshoot:v0.15"
 
+// debugPodValidationTimeout bounds the connection to the default cluster at startup,
+// including the validation of the debug pod template.
+const debugPodValidationTimeout = 30 * time.Second
+
 type MCPServerConfig struct {
 	Mode         string
 	Transport    string
 	Host         string
 	Port         string
+	MetricsAddr  string
 	PwruImage    string
 	TcpdumpImage string
 	Kernel       kernelmcp.Config
 	Kubernetes   kubernetesmcp.Config
+	Executor     string
+	Local        executor.LocalConfig
 	ToolTimeout  time.Duration
+	Auth         AuthConfig
+	Audit        AuditConfig
+	Jobs         jobs.Config
+	Artifacts    artifacts.Config
+	BundleDir    string
+	ConfigFile   string
+	Tools        config.Tools
+
+	// setFlags contains the names of the flags set on the command line, which
+	// take precedence over the configuration file.
+	setFlags map[string]bool
+}
+
+// applyConfigFile sets the values of the configuration file, except for the ones
+// overridden by a flag set on the command line.
+func (c *MCPServerConfig) applyConfigFile(fileCfg *config.Config) {
+	set := func(flagName string, target *string, value string) {
+		if value != "" && !c.setFlags[flagName] {
+			*target = value
+		}
+	}
+	set("mode", &c.Mode, fileCfg.Mode)
+	set("transport", &c.Transport, fileCfg.Transport)
+	set("host", &c.Host, fileCfg.Host)
+	set("port", &c.Port, fileCfg.Port)
+	set("kubeconfig", &c.Kubernetes.Clusters.Kubeconfig, fileCfg.Kubeconfig)
+	set("pwru-image", &c.PwruImage, fileCfg.Images.Pwru)
+	set("tcpdump-image", &c.TcpdumpImage, fileCfg.Images.Tcpdump)
+	set("kernel-image", &c.Kernel.Image, fileCfg.Images.Kernel)
+	if fileCfg.ToolTimeout != nil && !c.setFlags["tool-timeout"] {
+		c.ToolTimeout = fileCfg.ToolTimeout.Duration
+	}
+	c.Tools = fileCfg.Tools
+	c.Kubernetes.DebugPodTemplate = fileCfg.DebugPod
+}
+
+// AuditConfig contains the configuration of the audit log.
+type AuditConfig struct {
+	File       string
+	KeyFile    string
+	MaxSizeMB  int
+	MaxBackups int
+	Events     bool
+}
+
+// AuthConfig contains the authentication and authorization configuration of the
+// HTTP transport.
+type AuthConfig struct {
+	TLSCertFile    string
+	TLSKeyFile     string
+	ClientCAFile   string
+	TokenReview    bool
+	TokenAudiences string
+	PolicyFile     string
+}
+
+// enabled returns true if any authentication method is configured.
+func (c *AuthConfig) enabled() bool {
+	return c.TokenReview || c.ClientCAFile != ""
+}
+
+// validateImpersonation checks that the cluster calls can be made as the callers
+// of the tool calls: they must be authenticated, and make their calls to a live
+// cluster through the Kubernetes API.
+func validateImpersonation(serverCfg *MCPServerConfig) error {
+	if serverCfg.Transport != "http" || !serverCfg.Auth.enabled() {
+		return fmt.Errorf("--impersonate requires --transport http with --auth-token-review or --client-ca-file")
+	}
+	if serverCfg.Mode != "live-cluster" && serverCfg.Mode != "dual" {
+		return fmt.Errorf("--impersonate requires --mode live-cluster or dual")
+	}
+	if serverCfg.Executor != "kubernetes" {
+		return fmt.Errorf("--impersonate requires --executor=kubernetes")
+	}
+	return nil
+}
+
+// setupHTTPAuth builds the authentication middleware and TLS configuration of the
+// HTTP transport and registers the authorization middleware on the MCP server.
+// It returns a nil middleware if authentication is not enabled.
+func setupHTTPAuth(serverCfg *MCPServerConfig, server *mcp.Server, completer *completion.Completer) (func(http.Handler) http.Handler, *tls.Config, error) {
+	authCfg := &serverCfg.Auth
+	if (authCfg.TLSCertFile == "") != (authCfg.TLSKeyFile == "") {
+		return nil, nil, fmt.Errorf("--tls-cert-file and --tls-key-file must be set together")
+	}
+	if authCfg.ClientCAFile != "" && authCfg.TLSCertFile == "" {
+		return nil, nil, fmt.Errorf("--client-ca-file requires --tls-cert-file and --tls-key-file")
+	}
+	if authCfg.PolicyFile != "" && !authCfg.enabled() {
+		return nil, nil, fmt.Errorf("--auth-policy-file requires --auth-token-review or --client-ca-file")
+	}
+
+	var tlsConfig *tls.Config
+	if authCfg.TLSCertFile != "" {
+		tlsConfig = &tls.Config{MinVersion: tls.VersionTLS12}
+	}
+
+	var authenticators []auth.Authenticator
+	if authCfg.ClientCAFile != "" {
+		caData, err := os.ReadFile(authCfg.ClientCAFile)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to read client CA file: %w", err)
+		}
+		clientCAs := x509.NewCertPool()
+		if !clientCAs.AppendCertsFromPEM(caData) {
+			return nil, nil, fmt.Errorf("no certificates found in client CA file %s", authCfg.ClientCAFile)
+		}
+		// Client certificates are optional at the TLS layer so that bearer token
+		// clients can connect too; unauthenticated requests are rejected later.
+		tlsConfig.ClientCAs = clientCAs
+		tlsConfig.ClientAuth = tls.VerifyClientCertIfGiven
+		authenticators = append(authenticators, auth.ClientCertAuthenticator{})
+		log.Println("Client certificate authentication enabled")
+	}
+	if authCfg.TokenReview {
+		clientSet, err := newKubernetesClient(serverCfg)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to create kubernetes client for token review: %w", err)
+		}
+		var audiences []string
+		for _, audience := range strings.Split(authCfg.TokenAudiences, ",") {
+			if audience = strings.TrimSpace(audience); audience != "" {
+				audiences = append(audiences, audience)
+			}
+		}
+		authenticators = append(authenticators, auth.NewTokenReviewAuthenticator(clientSet, audiences, 0))
+		log.Println("Bearer token authentication enabled")
+	}
+	if len(authenticators) == 0 {
+		return nil, tlsConfig, nil
+	}
+
+	if authCfg.PolicyFile != "" {
+		policy, err := auth.LoadPolicy(authCfg.PolicyFile)
+		if err != nil {
+			return nil, nil, err
+		}
+		server.AddReceivingMiddleware(middleware.Authorization(policy, completer.Family))
+		log.Printf("Authorization policy loaded from %s", authCfg.PolicyFile)
+	} else {
+		log.Println("No authorization policy configured, authenticated callers may use all tools")
+	}
+	return auth.Middleware(authenticators...), tlsConfig, nil
+}
+
+// newKubernetesClient creates a Kubernetes client from the server configuration.
+func newKubernetesClient(serverCfg *MCPServerConfig) (kubernetes.Interface, error) {
+	restConfig, err := kubernetesmcp.NewRESTConfig(serverCfg.Kubernetes)
+	if err != nil {
+		return nil, err
+	}
+	return kubernetes.NewForConfig(restConfig)
+}
+
+// setupAudit opens the audit log and registers the audit middleware on the MCP
+// server. It returns a nil logger if the audit log is not enabled.
+func setupAudit(serverCfg *MCPServerConfig, server *mcp.Server) (*audit.Logger, error) {
+	auditCfg := &serverCfg.Audit
+	if auditCfg.File == "" {
+		if auditCfg.Events {
+			return nil, fmt.Errorf("--audit-events requires --audit-log-file")
+		}
+		return nil, nil
+	}
+	key, err := readAuditKey(auditCfg)
+	if err != nil {
+		return nil, err
+	}
+
+	var sinks []audit.Sink
+	if auditCfg.Events {
+		clientSet, err := newKubernetesClient(serverCfg)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create kubernetes client for audit events: %w", err)
+		}
+		sinks = append(sinks, audit.NewEventSink(clientSet))
+	}
+	logger, err := audit.NewLogger(audit.Config{
+		Path:       auditCfg.File,
+		MaxSize:    int64(auditCfg.MaxSizeMB) * 1024 * 1024,
+		MaxBackups: auditCfg.MaxBackups,
+		Key:        key,
+	}, sinks...)
+	if err != nil {
+		return nil, err
+	}
+	server.AddReceivingMiddleware(middleware.Audit(logger))
+	log.Printf("Audit log enabled: %s", auditCfg.File)
+	return logger, nil
+}
+
+// readAuditKey reads the key of the audit log hash chain from the key file.
+func readAuditKey(auditCfg *AuditConfig) ([]byte, error) {
+	if auditCfg.KeyFile == "" {
+		return nil, fmt.Errorf("--audit-log-file requires --audit-log-key-file")
+	}
+	data, err := os.ReadFile(auditCfg.KeyFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read audit log key: %w", err)
+	}
+	key := bytes.TrimSpace(data)
+	if len(key) == 0 {
+		return nil, fmt.Errorf("audit log key file %s is empty", auditCfg.KeyFile)
+	}
+	if filepath.Dir(auditCfg.KeyFile) == filepath.Dir(auditCfg.File) {
+		log.Printf("Warning: the audit log key is in the directory of the audit log, whose records can be rewritten by anyone able to read the key")
+	}
+	return key, nil
+}
+
+// listTools returns the tools registered on the MCP server.
+func listTools(ctx context.Context, server *mcp.Server) ([]*mcp.Tool, error) {
+	clientTransport, serverTransport := mcp.NewInMemoryTransports()
+	serverSession, err := server.Connect(ctx, serverTransport, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer serverSession.Close()
+	client := mcp.NewClient(&mcp.Implementation{Name: "ovnk-mcp-server"}, nil)
+	clientSession, err := client.Connect(ctx, clientTransport, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer clientSession.Close()
+
+	var tools []*mcp.Tool
+	for tool, err := range clientSession.Tools(ctx, nil) {
+		if err != nil {
+			return nil, err
+		}
+		tools = append(tools, tool)
+	}
+	return tools, nil
+}
+
+// allToolNames returns the names of the tools of all the modes and executors. The
+// tool servers are created without their dependencies, only to register their
+// tools on a server that is never run.
+func allToolNames(ctx context.Context) ([]string, error) {
+	server := mcp.NewServer(&mcp.Implementation{Name: "ovn-kubernetes"}, nil)
+	k8sMcpServer := &kubernetesmcp.MCPServer{}
+	clustersmcp.NewMCPServer(nil).AddTools(server)
+	k8sMcpServer.AddTools(server)
+	topologymcp.NewMCPServer(nil).AddTools(server)
+	ovnmcp.NewMCPServer(nil, nil, k8sMcpServer).AddTools(server)
+	ovsmcp.NewMCPServer(nil, nil).AddTools(server)
+	kernelmcp.NewMCPServer(nil, kernelmcp.Config{}).AddTools(server)
+	nettoolsmcp.NewMCPServer(nil, "", "", jobs.NewManager(jobs.Config{})).AddTools(server)
+	jobsmcp.NewMCPServer(nil).AddTools(server)
+	sosreportmcp.NewMCPServer().AddTools(server)
+	mustgathermcp.NewToolListMCPServer().AddTools(server)
+	tools, err := listTools(ctx, server)
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(tools))
+	for _, tool := range tools {
+		names = append(names, tool.Name)
+	}
+	return names, nil
+}
+
+// reloadConfigFile reloads the tool configuration and tool timeout from the
+// configuration file. The other settings only take effect on restart.
+func reloadConfigFile(flagCfg MCPServerConfig, toolStore *config.ToolStore, tools []*mcp.Tool, allTools []string) error {
+	fileCfg, err := config.Load(flagCfg.ConfigFile)
+	if err != nil {
+		return err
+	}
+	warnings, err := fileCfg.Tools.ValidateTools(tools, allTools)
+	if err != nil {
+		return fmt.Errorf("invalid config file %s: %w", flagCfg.ConfigFile, err)
+	}
+	for _, warning := range warnings {
+		log.Printf("Warning: config file %s: %s", flagCfg.ConfigFile, warning)
+	}
+	flagCfg.applyConfigFile(fileCfg)
+	toolStore.Set(flagCfg.Tools, flagCfg.ToolTimeout)
+	return nil
 }
 
-// setupLiveCluster sets up the live cluster mode.
-func setupLiveCluster(serverCfg *MCPServerConfig, server *mcp.Server) {
+// setupLiveCluster sets up the live cluster mode. The returned function must be
+// called on shutdown to stop the background jobs and delete the debug pods.
+func setupLiveCluster(serverCfg *MCPServerConfig, server *mcp.Server, completer *completion.Completer) func() {
+	switch serverCfg.Executor {
+	case "kubernetes":
+	case "local":
+		return setupLocalExecutor(serverCfg, server, completer)
+	default:
+		log.Fatalf("Invalid executor: %s. Valid executors are: kubernetes, local", serverCfg.Executor)
+	}
+	serverCfg.Kubernetes.DebugPodImage = serverCfg.Kernel.Image
 	k8sMcpServer, err := kubernetesmcp.NewMCPServer(serverCfg.Kubernetes)
 	if err != nil {
 		log.Fatalf("Failed to create OVN-K MCP server: %v", err)
 	}
+	// The default cluster is connected at startup, the other clusters on their
+	// first tool call.
+	ctx, cancel := context.WithTimeout(context.Background(), debugPodValidationTimeout)
+	defer cancel()
+	if err := k8sMcpServer.ConnectDefaultCluster(ctx); err != nil {
+		log.Fatalf("Failed to connect to the default cluster: %v", err)
+	}
+	if serverCfg.Kubernetes.RecordDir != "" {
+		log.Printf("Recording the cluster calls to bundle %s", serverCfg.Kubernetes.RecordDir)
+	}
+	return addLiveClusterTools(serverCfg, server, completer, k8sMcpServer)
+}
+
+// setupReplay sets up the replay mode, serving the live cluster tools from the
+// bundle recorded with --record. The returned function must be called on shutdown
+// to stop the background jobs.
+func setupReplay(serverCfg *MCPServerConfig, server *mcp.Server, completer *completion.Completer) func() {
+	if serverCfg.BundleDir == "" {
+		log.Fatalf("--bundle is required in replay mode")
+	}
+	if serverCfg.Kubernetes.RecordDir != "" {
+		log.Fatalf("--record cannot be used in replay mode")
+	}
+	k8sMcpServer, err := kubernetesmcp.NewReplayMCPServer(serverCfg.BundleDir, serverCfg.Kubernetes.Clusters.DefaultCluster)
+	if err != nil {
+		log.Fatalf("Failed to load bundle: %v", err)
+	}
+	log.Printf("Replaying the cluster calls of bundle %s", serverCfg.BundleDir)
+	return addLiveClusterTools(serverCfg, server, completer, k8sMcpServer)
+}
+
+// addLiveClusterTools adds the live cluster tools using the Kubernetes server.
+func addLiveClusterTools(serverCfg *MCPServerConfig, server *mcp.Server, completer *completion.Completer,
+	k8sMcpServer *kubernetesmcp.MCPServer) func() {
+	registry := k8sMcpServer.Clusters()
+	log.Printf("Clusters: %s (default: %s)", strings.Join(registry.Names(), ", "), registry.DefaultName())
+	log.Println("Adding cluster tools to OVN-K MCP server")
+	clustersServer := clustersmcp.NewMCPServer(registry)
+	clustersServer.AddTools(server)
+	clustersServer.AddCompletions(completer)
+
 	log.Println("Adding Kubernetes tools to OVN-K MCP server")
 	k8sMcpServer.AddTools(server)
+	k8sMcpServer.AddCompletions(completer)
+
+	log.Println("Adding topology tools to OVN-K MCP server")
+	discoverer := topology.NewDiscoverer(k8sMcpServer, topology.DefaultNamespaces, topology.DefaultTTL)
+	topologymcp.NewMCPServer(discoverer).AddTools(server)
+
+	closeDataPlaneTools := addDataPlaneTools(serverCfg, server, completer, k8sMcpServer, discoverer, k8sMcpServer)
+	return func() {
+		closeDataPlaneTools()
+		k8sMcpServer.Close()
+	}
+}
+
+// setupLocalExecutor sets up the live cluster mode with the local executor: the
+// OVN, OVS, kernel and network tools run their commands on the local host, and the
+// Kubernetes tools are not available. The returned function must be called on
+// shutdown to stop the background jobs.
+func setupLocalExecutor(serverCfg *MCPServerConfig, server *mcp.Server, completer *completion.Completer) func() {
+	if serverCfg.Kubernetes.RecordDir != "" {
+		log.Fatalf("--record requires --executor=kubernetes")
+	}
+	if serverCfg.Kubernetes.Clusters.AllContexts || serverCfg.Kubernetes.Clusters.KubeconfigDir != "" {
+		log.Fatalf("--all-contexts and --kubeconfig-dir require --executor=kubernetes")
+	}
+	log.Println("Running the commands of the tools on the local host")
+	localExecutor, err := executor.NewLocal(serverCfg.Local)
+	if err != nil {
+		log.Fatalf("Failed to set up the local executor: %v", err)
+	}
+	return addDataPlaneTools(serverCfg, server, completer, localExecutor, localExecutor, nil)
+}
 
-	ovnServer := ovnmcp.NewMCPServer(k8sMcpServer)
+// addDataPlaneTools adds the OVN, OVS, kernel, network and job tools, running their
+// commands with the executor in the pods resolved by pods. The OVN tools reading
+// the pods use resources, nil with the local executor. The returned function
+// stops the background jobs.
+func addDataPlaneTools(serverCfg *MCPServerConfig, server *mcp.Server, completer *completion.Completer,
+	commandExecutor executor.Executor, pods topology.PodResolver, resources ovnmcp.Resources) func() {
+	ovnServer := ovnmcp.NewMCPServer(commandExecutor, pods, resources)
 	log.Println("Adding OVN tools to OVN-K MCP server")
 	ovnServer.AddTools(server)
+	ovnServer.AddCompletions(completer)
 
-	ovsServer := ovsmcp.NewMCPServer(k8sMcpServer)
+	ovsServer := ovsmcp.NewMCPServer(commandExecutor, pods)
 	log.Println("Adding OVS tools to OVN-K MCP server")
 	ovsServer.AddTools(server)
+	ovsServer.AddCompletions(completer)
 
-	kernelMcpServer := kernelmcp.NewMCPServer(k8sMcpServer, serverCfg.Kernel)
+	kernelMcpServer := kernelmcp.NewMCPServer(commandExecutor, serverCfg.Kernel)
 	log.Println("Adding Kernel tools to OVN-K MCP server")
 	kernelMcpServer.AddTools(server)
 
-	netToolsServer := nettoolsmcp.NewMCPServer(k8sMcpServer, serverCfg.PwruImage, serverCfg.TcpdumpImage)
+	jobManager := jobs.NewManager(serverCfg.Jobs)
+	netToolsServer := nettoolsmcp.NewMCPServer(commandExecutor, serverCfg.PwruImage, serverCfg.TcpdumpImage, jobManager)
 	log.Println("Adding network tools to OVN-K MCP server")
 	netToolsServer.AddTools(server)
+
+	jobsServer := jobsmcp.NewMCPServer(jobManager)
+	log.Println("Adding job tools to OVN-K MCP server")
+	jobsServer.AddTools(server)
+
+	return jobManager.Close
 }
 
 // setupOffline sets up the offline mode.
-func setupOffline(server *mcp.Server) {
+func setupOffline(server *mcp.Server, completer *completion.Completer) {
 	sosreportServer := sosreportmcp.NewMCPServer()
 	log.Println("Adding sosreport tools to OVN-K MCP server")
 	sosreportServer.AddTools(server)
@@ -75,32 +481,157 @@ func setupOffline(server *mcp.Server) {
 	}
 	log.Println("Adding Must Gather tools to OVN-K MCP server")
 	mustGatherServer.AddTools(server)
+	mustGatherServer.AddCompletions(completer)
 }
 
 func main() {
-	serverCfg := parseFlags()
+	flagCfg := parseFlags()
+
+	// Apply the configuration file, keeping the flag values for the reloads.
+	serverCfg := flagCfg
+	if flagCfg.ConfigFile != "" {
+		fileCfg, err := config.Load(flagCfg.ConfigFile)
+		if err != nil {
+			log.Fatalf("Failed to load configuration: %v", err)
+		}
+		cfg := *flagCfg
+		cfg.applyConfigFile(fileCfg)
+		serverCfg = &cfg
+		log.Printf("Configuration loaded from %s", flagCfg.ConfigFile)
+	}
+
+	if serverCfg.ToolTimeout == 0 {
+		log.Println("Tool timeout enforcement disabled")
+	} else {
+		log.Printf("Tool timeout: %v", serverCfg.ToolTimeout)
+	}
 
+	// Complete the arguments with the values looked up by the tool servers.
+	completer := completion.NewCompleter(completion.DefaultTTL)
 	ovnkMcpServer := mcp.NewServer(
 		&mcp.Implementation{Name: "ovn-kubernetes"},
-		&mcp.ServerOptions{HasTools: true},
+		&mcp.ServerOptions{HasTools: true, CompletionHandler: completer.Complete},
 	)
 
-	// Apply timeout middleware to all tool calls if configured.
-	if serverCfg.ToolTimeout > 0 {
-		ovnkMcpServer.AddReceivingMiddleware(middleware.ToolTimeout(serverCfg.ToolTimeout))
+	// Record metrics for all tool calls. This is added before the timeout middleware
+	// so that it runs within the tool deadline and can detect timeouts.
+	if err := metrics.Register(prometheus.DefaultRegisterer); err != nil {
+		log.Fatalf("Failed to register metrics: %v", err)
+	}
+	ovnkMcpServer.AddReceivingMiddleware(middleware.ToolMetrics())
+	ovnkMcpServer.AddReceivingMiddleware(middleware.Progress())
+	// Target the live-cluster tool calls at the cluster of their cluster argument.
+	ovnkMcpServer.AddReceivingMiddleware(middleware.Cluster())
+	// Make the cluster calls of the tool calls as their authenticated caller.
+	if serverCfg.Kubernetes.Impersonate {
+		if err := validateImpersonation(serverCfg); err != nil {
+			log.Fatalf("Invalid impersonation configuration: %v", err)
+		}
+		ovnkMcpServer.AddReceivingMiddleware(middleware.Impersonation())
+		log.Println("Impersonating the authenticated callers for the cluster calls")
 	}
 
+	// Keep the complete output of the tools truncating it, and expose it as resources.
+	artifactStore, err := artifacts.NewStore(serverCfg.Artifacts)
+	if err != nil {
+		log.Fatalf("Failed to create artifact store: %v", err)
+	}
+	defer func() {
+		if err := artifactStore.Close(); err != nil {
+			log.Printf("Failed to remove artifacts: %v", err)
+		}
+	}()
+	log.Printf("Artifacts stored in %s", artifactStore.Dir())
+	ovnkMcpServer.AddReceivingMiddleware(middleware.Artifacts(artifactStore))
+	artifactsmcp.NewMCPServer(artifactStore).AddResources(ovnkMcpServer)
+
+	// Apply the default or per-tool timeout to all tool calls.
+	toolStore := config.NewToolStore(serverCfg.Tools, serverCfg.ToolTimeout)
+	ovnkMcpServer.AddReceivingMiddleware(middleware.ToolTimeouts(toolStore.Timeout))
+
 	// Setup the MCP server based on the mode.
+	var closeLiveCluster func()
 	switch serverCfg.Mode {
 	case "live-cluster":
-		setupLiveCluster(serverCfg, ovnkMcpServer)
+		closeLiveCluster = setupLiveCluster(serverCfg, ovnkMcpServer, completer)
 	case "offline":
-		setupOffline(ovnkMcpServer)
+		setupOffline(ovnkMcpServer, completer)
 	case "dual":
-		setupLiveCluster(serverCfg, ovnkMcpServer)
-		setupOffline(ovnkMcpServer)
+		closeLiveCluster = setupLiveCluster(serverCfg, ovnkMcpServer, completer)
+		setupOffline(ovnkMcpServer, completer)
+	case "replay":
+		closeLiveCluster = setupReplay(serverCfg, ovnkMcpServer, completer)
 	default:
-		log.Fatalf("Invalid mode: %s. Valid modes are: live-cluster, offline, dual", serverCfg.Mode)
+		log.Fatalf("Invalid mode: %s. Valid modes are: live-cluster, offline, dual, replay", serverCfg.Mode)
+	}
+	if closeLiveCluster != nil {
+		// Stop the background jobs and delete the debug pods on shutdown.
+		defer closeLiveCluster()
+	}
+
+	// Validate the per-tool settings against the registered tools, and disable
+	// the tools that are not enabled by the configuration.
+	tools, err := listTools(context.Background(), ovnkMcpServer)
+	if err != nil {
+		log.Fatalf("Failed to list tools: %v", err)
+	}
+	allTools, err := allToolNames(context.Background())
+	if err != nil {
+		log.Fatalf("Failed to list tools: %v", err)
+	}
+	warnings, err := serverCfg.Tools.ValidateTools(tools, allTools)
+	if err != nil {
+		log.Fatalf("Invalid tool configuration: %v", err)
+	}
+	for _, warning := range warnings {
+		log.Printf("Warning: tool configuration: %s", warning)
+	}
+	toolStore.Set(serverCfg.Tools, serverCfg.ToolTimeout)
+
+	// The troubleshooting prompts plan the calls of the registered tools.
+	var toolNames []string
+	for _, tool := range tools {
+		toolNames = append(toolNames, tool.Name)
+	}
+	log.Println("Adding troubleshooting prompts to OVN-K MCP server")
+	live := serverCfg.Mode != "offline"
+	offline := serverCfg.Mode == "offline" || serverCfg.Mode == "dual"
+	runbooksmcp.NewMCPServer(live, offline, toolNames).AddPrompts(ovnkMcpServer)
+	ovnkMcpServer.AddReceivingMiddleware(middleware.ToolConfig(toolStore))
+
+	// Setup authentication and authorization of the HTTP transport.
+	var authMiddleware func(http.Handler) http.Handler
+	var tlsConfig *tls.Config
+	if serverCfg.Transport == "http" {
+		authMiddleware, tlsConfig, err = setupHTTPAuth(serverCfg, ovnkMcpServer, completer)
+		if err != nil {
+			log.Fatalf("Failed to setup HTTP authentication: %v", err)
+		}
+	}
+
+	// The audit middleware is added last so that it also records the tool calls
+	// rejected by the authorization middleware.
+	auditLogger, err := setupAudit(serverCfg, ovnkMcpServer)
+	if err != nil {
+		log.Fatalf("Failed to setup audit log: %v", err)
+	}
+	if auditLogger != nil {
+		defer auditLogger.Close()
+	}
+
+	// Reload the tool configuration on SIGHUP.
+	if flagCfg.ConfigFile != "" {
+		reloadChan := make(chan os.Signal, 1)
+		signal.Notify(reloadChan, syscall.SIGHUP)
+		go func() {
+			for range reloadChan {
+				if err := reloadConfigFile(*flagCfg, toolStore, tools, allTools); err != nil {
+					log.Printf("Failed to reload configuration, keeping the previous one: %v", err)
+					continue
+				}
+				log.Printf("Tool configuration reloaded from %s", flagCfg.ConfigFile)
+			}
+		}()
 	}
 
 	// Create a context that can be cancelled to shutdown the server.
@@ -110,6 +641,25 @@ func main() {
 	signalChan := make(chan os.Signal, 1)
 	signal.Notify(signalChan, os.Interrupt, syscall.SIGTERM, syscall.SIGINT)
 
+	// Serve the metrics on their own listener if requested.
+	var metricsServer *http.Server
+	if serverCfg.MetricsAddr != "" {
+		metricsMux := http.NewServeMux()
+		metricsMux.Handle("/metrics", promhttp.Handler())
+		metricsServer = &http.Server{
+			Addr:              serverCfg.MetricsAddr,
+			Handler:           metricsMux,
+			ReadHeaderTimeout: 10 * time.Second,
+			IdleTimeout:       60 * time.Second,
+		}
+		log.Printf("Serving metrics on %s", serverCfg.MetricsAddr)
+		go func() {
+			if err := metricsServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+				log.Printf("Metrics server failed: %v", err)
+			}
+		}()
+	}
+
 	// Start a goroutine to handle signals to shutdown the server.
 	var server *http.Server
 	go func() {
@@ -121,7 +671,12 @@ func main() {
 		// Cancel the context to shutdown the server.
 		defer cancel()
 
-		// Shutdown the http server if it is running.
+		// Shutdown the http servers if they are running.
+		if metricsServer != nil {
+			if err := metricsServer.Shutdown(ctx); err != nil {
+				log.Printf("Failed to shutdown metrics server: %v", err)
+			}
+		}
 		if server != nil {
 			// Shutdown the http server.
 			if err := server.Shutdown(ctx); err != nil {
@@ -137,18 +692,35 @@ func main() {
 			log.Printf("Server failed: %v", err)
 		}
 	case "http":
-		handler := mcp.NewStreamableHTTPHandler(func(*http.Request) *mcp.Server {
+		var handler http.Handler = mcp.NewStreamableHTTPHandler(func(*http.Request) *mcp.Server {
 			return ovnkMcpServer
 		}, nil)
+		// Serve the metrics next to the MCP endpoint, with the same authentication,
+		// unless they have their own listener.
+		mux := http.NewServeMux()
+		if serverCfg.MetricsAddr == "" {
+			mux.Handle("/metrics", promhttp.Handler())
+		}
+		mux.Handle("/", handler)
+		var rootHandler http.Handler = mux
+		if authMiddleware != nil {
+			rootHandler = authMiddleware(mux)
+		}
 		addr := net.JoinHostPort(serverCfg.Host, serverCfg.Port)
 		log.Printf("Listening on %s", addr)
 		server = &http.Server{
 			Addr:              addr,
-			Handler:           handler,
+			Handler:           rootHandler,
+			TLSConfig:         tlsConfig,
 			ReadHeaderTimeout: 10 * time.Second,
 			IdleTimeout:       60 * time.Second,
 		}
-		if err := server.ListenAndServe(); err != nil {
+		if tlsConfig != nil {
+			err = server.ListenAndServeTLS(serverCfg.Auth.TLSCertFile, serverCfg.Auth.TLSKeyFile)
+		} else {
+			err = server.ListenAndServe()
+		}
+		if err != nil {
 			log.Printf("HTTP server failed: %v", err)
 		}
 	default:
@@ -159,17 +731,51 @@ func main() {
 func parseFlags() *MCPServerConfig {
 	cfg := &MCPServerConfig{}
 	var timeoutSeconds int
+	var jobOutputMB int
+	var artifactMB int
 
-	flag.StringVar(&cfg.Mode, "mode", "live-cluster", "Mode of debugging: live-cluster or offline or dual")
+	flag.StringVar(&cfg.Mode, "mode", "live-cluster", "Mode of debugging: live-cluster or offline or dual, or replay to serve the live-cluster tools from a recorded bundle")
 	flag.StringVar(&cfg.Transport, "transport", "stdio", "Transport to use: stdio or http")
 	flag.StringVar(&cfg.Host, "host", "localhost", "Host to bind to (use 0.0.0.0 for container/cluster)")
 	flag.StringVar(&cfg.Port, "port", "8080", "Port to use")
-	flag.StringVar(&cfg.Kubernetes.Kubeconfig, "kubeconfig", "", "Path to the kubeconfig file")
+	flag.StringVar(&cfg.MetricsAddr, "metrics-addr", "", "Address of a separate plain HTTP listener serving the metrics without authentication, instead of /metrics on the HTTP transport listener (for example 127.0.0.1:9090)")
+	flag.StringVar(&cfg.Kubernetes.Clusters.Kubeconfig, "kubeconfig", "", "Path to the kubeconfig file")
+	flag.BoolVar(&cfg.Kubernetes.Clusters.AllContexts, "all-contexts", false, "Load every context of the kubeconfig file as a cluster the live-cluster tools can target, instead of its current context only")
+	flag.StringVar(&cfg.Kubernetes.Clusters.KubeconfigDir, "kubeconfig-dir", "", "Directory of kubeconfig files whose current contexts are loaded as clusters named after the files")
+	flag.StringVar(&cfg.Kubernetes.Clusters.DefaultCluster, "default-cluster", "", "Cluster targeted by the tool calls without cluster (defaults to the current context, or to the only cluster)")
+	flag.StringVar(&cfg.Executor, "executor", "kubernetes", "How the live-cluster tools run their commands: kubernetes, in pods and node debug pods, or local, on the local host")
+	flag.StringVar(&cfg.Local.NodeName, "node-name", os.Getenv("NODE_NAME"), "Name of the node the server runs on with --executor=local; commands targeting other nodes are rejected (defaults to the hostname)")
 	flag.StringVar(&cfg.PwruImage, "pwru-image", "docker.io/cilium/pwru:v1.0.10", "Container image for pwru operations")
 
 	flag.StringVar(&cfg.TcpdumpImage, "tcpdump-image", defaultNetshootImage, "Container image for tcpdump operations")
 	flag.StringVar(&cfg.Kernel.Image, "kernel-image", defaultNetshootImage, "Container image for kernel operations")
 	flag.IntVar(&timeoutSeconds, "tool-timeout", 120, "Timeout in seconds for tool operations (0 to disable)")
+	flag.StringVar(&cfg.Auth.TLSCertFile, "tls-cert-file", "", "TLS certificate file for the HTTP transport")
+	flag.StringVar(&cfg.Auth.TLSKeyFile, "tls-key-file", "", "TLS private key file for the HTTP transport")
+	flag.StringVar(&cfg.Auth.ClientCAFile, "client-ca-file", "", "CA bundle used to authenticate HTTP clients by TLS client certificate")
+	flag.BoolVar(&cfg.Auth.TokenReview, "auth-token-review", false, "Authenticate HTTP bearer tokens with the Kubernetes TokenReview API")
+	flag.StringVar(&cfg.Auth.TokenAudiences, "auth-token-audiences", "", "Comma-separated audiences a bearer token must be valid for")
+	flag.StringVar(&cfg.Auth.PolicyFile, "auth-policy-file", "", "YAML file mapping users and groups to the tool families they may call")
+	flag.BoolVar(&cfg.Kubernetes.Impersonate, "impersonate", false, "Make the cluster calls of the live-cluster tools as the authenticated HTTP caller, so that its Kubernetes RBAC permissions apply")
+	flag.StringVar(&cfg.Audit.File, "audit-log-file", "", "File to write the audit log of tool calls to (disabled if empty)")
+	flag.StringVar(&cfg.Audit.KeyFile, "audit-log-key-file", "", "File containing the HMAC key of the audit log hash chain, kept away from the audit log (required with --audit-log-file)")
+	flag.IntVar(&cfg.Audit.MaxSizeMB, "audit-log-max-size", audit.DefaultMaxSize/(1024*1024), "Size in megabytes after which the audit log is rotated (0 to disable rotation)")
+	flag.IntVar(&cfg.Audit.MaxBackups, "audit-log-max-backups", audit.DefaultMaxBackups, "Number of rotated audit log files to keep")
+	flag.BoolVar(&cfg.Audit.Events, "audit-events", false, "Also emit audit records as Kubernetes Events on the target pods and nodes")
+	flag.IntVar(&cfg.Kubernetes.DebugPodLimits.MaxConcurrent, "max-debug-pods", 10, "Maximum number of privileged debug pods running at the same time (0 for no limit)")
+	flag.IntVar(&cfg.Kubernetes.DebugPodLimits.MaxConcurrentPerNode, "max-debug-pods-per-node", 2, "Maximum number of privileged debug pods running at the same time on a node (0 for no limit)")
+	flag.Float64Var(&cfg.Kubernetes.DebugPodLimits.Rate, "debug-pod-rate", 1, "Number of privileged debug pods that can be created per second (0 for no limit)")
+	flag.IntVar(&cfg.Kubernetes.DebugPodLimits.Burst, "debug-pod-burst", 5, "Number of privileged debug pods that can be created at once above --debug-pod-rate")
+	flag.DurationVar(&cfg.Kubernetes.DebugPodIdleTTL, "debug-pod-idle-ttl", 5*time.Minute, "How long a node debug pod is kept for reuse after its last command (0 to create a debug pod per command)")
+	flag.DurationVar(&cfg.Jobs.MaxDuration, "job-max-duration", jobs.DefaultMaxDuration, "Maximum duration of a background capture or trace job")
+	flag.IntVar(&jobOutputMB, "job-max-output-size", jobs.DefaultMaxOutputBytes/(1024*1024), "Size in megabytes of the output after which a background job is stopped")
+	flag.IntVar(&cfg.Jobs.MaxRunning, "max-jobs", jobs.DefaultMaxRunning, "Maximum number of background jobs running at the same time")
+	flag.StringVar(&cfg.Artifacts.Dir, "artifact-dir", "", "Directory to store the complete output of truncated tool results in (a temporary directory if empty)")
+	flag.IntVar(&artifactMB, "artifact-max-size", artifacts.DefaultMaxBytes/(1024*1024), "Size in megabytes of the stored tool outputs after which the oldest ones are removed")
+	flag.DurationVar(&cfg.Artifacts.TTL, "artifact-ttl", artifacts.DefaultTTL, "How long the complete output of a truncated tool result is kept")
+	flag.StringVar(&cfg.Kubernetes.RecordDir, "record", "", "Directory of a bundle to record the cluster calls of the live-cluster tools to, for --mode=replay")
+	flag.StringVar(&cfg.BundleDir, "bundle", "", "Directory of the bundle recorded with --record to serve in replay mode")
+	flag.StringVar(&cfg.ConfigFile, "config", "", "YAML configuration file; flags set on the command line take precedence")
 	flag.Parse()
 
 	// Convert timeout to duration and apply limits
@@ -178,12 +784,13 @@ func parseFlags() *MCPServerConfig {
 	}
 
 	cfg.ToolTimeout = time.Duration(timeoutSeconds) * time.Second
+	cfg.Jobs.MaxOutputBytes = jobOutputMB * 1024 * 1024
+	cfg.Artifacts.MaxBytes = int64(artifactMB) * 1024 * 1024
 
-	if cfg.ToolTimeout == 0 {
-		log.Println("Tool timeout enforcement disabled")
-	} else {
-		log.Printf("Tool timeout: %v", cfg.ToolTimeout)
-	}
+	cfg.setFlags = map[string]bool{}
+	flag.Visit(func(f *flag.Flag) {
+		cfg.setFlags[f.Name] = true
+	})
 
 	return cfg
 }
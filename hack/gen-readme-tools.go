@@ -139,7 +139,8 @@ func findRepoRoot() (string, error) {
 	}
 }
 
-// inferModesFromMain parses main.go and returns package names in order of first use in setupLiveCluster and setupOffline.
+// inferModesFromMain parses main.go and returns package names in order of first use in setupLiveCluster and setupOffline,
+// including the functions of main.go they call.
 func inferModesFromMain(mainPath string) (liveOrder, offlineOrder []string, err error) {
 	fset := token.NewFileSet()
 	node, err := parser.ParseFile(fset, mainPath, nil, 0)
@@ -176,48 +177,55 @@ func inferModesFromMain(mainPath string) (liveOrder, offlineOrder []string, err
 		importAliasToPkg[alias] = pkgName
 	}
 
-	var setupLiveClusterBody *ast.BlockStmt
-	var setupOfflineBody *ast.BlockStmt
+	// Map the functions of main.go to their bodies, to follow the calls of the
+	// setup functions to helpers like addDataPlaneTools.
+	funcBodies := make(map[string]*ast.BlockStmt)
 	for _, decl := range node.Decls {
 		fn, ok := decl.(*ast.FuncDecl)
-		if !ok || fn.Body == nil {
+		if !ok || fn.Body == nil || fn.Recv != nil {
 			continue
 		}
-		switch fn.Name.Name {
-		case "setupLiveCluster":
-			setupLiveClusterBody = fn.Body
-		case "setupOffline":
-			setupOfflineBody = fn.Body
-		}
+		funcBodies[fn.Name.Name] = fn.Body
 	}
-	if setupLiveClusterBody == nil || setupOfflineBody == nil {
+	if funcBodies["setupLiveCluster"] == nil || funcBodies["setupOffline"] == nil {
 		return nil, nil, fmt.Errorf("setupLiveCluster or setupOffline not found in %s", mainPath)
 	}
 
-	liveOrder = collectPackagesInOrder(setupLiveClusterBody, importAliasToPkg)
-	offlineOrder = collectPackagesInOrder(setupOfflineBody, importAliasToPkg)
+	liveOrder = collectPackagesInOrder("setupLiveCluster", funcBodies, importAliasToPkg)
+	offlineOrder = collectPackagesInOrder("setupOffline", funcBodies, importAliasToPkg)
 	return liveOrder, offlineOrder, nil
 }
 
-// collectPackagesInOrder walks the block and returns pkg names in order of first occurrence.
-func collectPackagesInOrder(block *ast.BlockStmt, aliasToPkg map[string]string) []string {
+// collectPackagesInOrder walks the body of the function, then the bodies of the
+// functions of main.go it calls, and returns pkg names in order of first occurrence.
+func collectPackagesInOrder(funcName string, funcBodies map[string]*ast.BlockStmt, aliasToPkg map[string]string) []string {
 	seen := make(map[string]bool)
+	queued := map[string]bool{funcName: true}
+	queue := []string{funcName}
 	var order []string
-	ast.Inspect(block, func(n ast.Node) bool {
-		sel, ok := n.(*ast.SelectorExpr)
-		if !ok {
-			return true
-		}
-		ident, ok := sel.X.(*ast.Ident)
-		if !ok {
+	for len(queue) > 0 {
+		body := funcBodies[queue[0]]
+		queue = queue[1:]
+		ast.Inspect(body, func(n ast.Node) bool {
+			switch v := n.(type) {
+			case *ast.CallExpr:
+				if ident, ok := v.Fun.(*ast.Ident); ok && funcBodies[ident.Name] != nil && !queued[ident.Name] {
+					queued[ident.Name] = true
+					queue = append(queue, ident.Name)
+				}
+			case *ast.SelectorExpr:
+				ident, ok := v.X.(*ast.Ident)
+				if !ok {
+					return true
+				}
+				if pkg, ok := aliasToPkg[ident.Name]; ok && !seen[pkg] {
+					seen[pkg] = true
+					order = append(order, pkg)
+				}
+			}
 			return true
-		}
-		if pkg, ok := aliasToPkg[ident.Name]; ok && !seen[pkg] {
-			seen[pkg] = true
-			order = append(order, pkg)
-		}
-		return true
-	})
+		})
+	}
 	return order
 }
 
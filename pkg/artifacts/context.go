@@ -0,0 +1,87 @@
+package artifacts
+
+import (
+	"context"
+	"log"
+	"sync"
+)
+
+// collector collects the artifacts saved during a tool call.
+type collector struct {
+	store *Store
+	tool  string
+	owner string
+
+	mu        sync.Mutex
+	artifacts []*Artifact
+}
+
+func (c *collector) add(a *Artifact) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.artifacts = append(c.artifacts, a)
+}
+
+type collectorKey struct{}
+
+// WithCollector returns a context in which the tool can save its complete output
+// with Save and Create, and a function returning the artifacts saved so far.
+func WithCollector(ctx context.Context, store *Store, tool, owner string) (context.Context, func() []*Artifact) {
+	c := &collector{store: store, tool: tool, owner: owner}
+	return context.WithValue(ctx, collectorKey{}, c), func() []*Artifact {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		return append([]*Artifact(nil), c.artifacts...)
+	}
+}
+
+// Enabled returns true if the complete output of the tool call of the context can
+// be saved, so that callers can skip reading output only kept for an artifact.
+func Enabled(ctx context.Context) bool {
+	_, ok := ctx.Value(collectorKey{}).(*collector)
+	return ok
+}
+
+// Save saves the complete output of the tool call of the context. It returns nil
+// if the context has no artifact store or the output could not be saved, in which
+// case the tool returns its truncated output only.
+func Save(ctx context.Context, content string) *Artifact {
+	w := Create(ctx)
+	if w == nil {
+		return nil
+	}
+	if _, err := w.Write([]byte(content)); err != nil {
+		log.Printf("Failed to save artifact of tool %s: %v", w.artifact.Tool, err)
+		w.Discard()
+		return nil
+	}
+	return Commit(w)
+}
+
+// Create returns a writer for the complete output of the tool call of the context,
+// to stream outputs too large to be kept in memory. It returns nil if the context
+// has no artifact store or the artifact could not be created.
+func Create(ctx context.Context) *Writer {
+	c, ok := ctx.Value(collectorKey{}).(*collector)
+	if !ok {
+		return nil
+	}
+	w, err := c.store.Create(c.tool, c.owner)
+	if err != nil {
+		log.Printf("Failed to create artifact of tool %s: %v", c.tool, err)
+		return nil
+	}
+	w.collector = c
+	return w
+}
+
+// Commit commits a writer returned by Create, logging the errors. It returns nil
+// if the artifact could not be saved.
+func Commit(w *Writer) *Artifact {
+	a, err := w.Commit()
+	if err != nil {
+		log.Printf("Failed to save artifact of tool %s: %v", w.artifact.Tool, err)
+		return nil
+	}
+	return a
+}
@@ -0,0 +1,90 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/ovn-kubernetes/ovn-kubernetes-mcp/pkg/artifacts"
+	"github.com/ovn-kubernetes/ovn-kubernetes-mcp/pkg/auth"
+)
+
+// MCPServer exposes the artifacts of the store as MCP resources.
+type MCPServer struct {
+	store *artifacts.Store
+}
+
+// NewMCPServer creates a new MCP server exposing the artifacts of the store.
+func NewMCPServer(store *artifacts.Store) *MCPServer {
+	return &MCPServer{store: store}
+}
+
+// AddResources registers the artifact resource template with the MCP server.
+func (s *MCPServer) AddResources(server *mcp.Server) {
+	server.AddResourceTemplate(&mcp.ResourceTemplate{
+		Name:  "artifact",
+		Title: "Complete tool output",
+		// The reserved expansion also matches the query of the URI, which is parsed
+		// by the handler.
+		URITemplate: artifacts.URIScheme + "://{+ref}",
+		MIMEType:    "text/plain",
+		Description: fmt.Sprintf(`Complete output of a tool call whose output was truncated. The tools return a
+resource link to it next to their truncated output.
+
+Query parameters (values must be percent-encoded):
+- lines=<start>-<end>: Lines to read, numbered from 1 (end is optional)
+- bytes=<start>-<end>: Bytes to read, numbered from 0 (end is optional)
+- grep=<regex>: Only return the lines matching the RE2 regex, prefixed with their line
+  number, within lines if set
+- max_matches=<n>: Maximum number of grep matches (default: %d, max: %d)
+
+Without a range, the first %d lines are returned. Pages are at most %d bytes, and the _meta
+of the contents contains the URI of the next page, and the total lines and bytes.
+
+Examples: artifact://<id>?lines=1001-2000, artifact://<id>?grep=drop%%7Creject&max_matches=20`,
+			artifacts.DefaultMaxMatches, artifacts.MaxMatches, artifacts.DefaultPageLines, artifacts.MaxPageBytes),
+	}, s.Read)
+}
+
+// Read reads a page of an artifact. Only the user who called the tool can read
+// its artifacts.
+func (s *MCPServer) Read(ctx context.Context, req *mcp.ReadResourceRequest) (*mcp.ReadResourceResult, error) {
+	uri := req.Params.URI
+	id, query, err := artifacts.ParseURI(uri)
+	if err != nil {
+		return nil, err
+	}
+	owner := ""
+	if identity, ok := auth.IdentityFromRequest(req); ok {
+		owner = identity.Username
+	}
+	if _, err := s.store.Get(id, owner); err != nil {
+		return nil, mcp.ResourceNotFoundError(uri)
+	}
+	page, err := s.store.Read(id, owner, query)
+	if err != nil {
+		return nil, err
+	}
+	meta := mcp.Meta{
+		"tool":        page.Artifact.Tool,
+		"created":     page.Artifact.Created,
+		"total_lines": page.Artifact.Lines,
+		"total_bytes": page.Artifact.Size,
+	}
+	if page.Artifact.Truncated {
+		meta["truncated"] = true
+	}
+	if page.Next != "" {
+		meta["next"] = page.Next
+	}
+	return &mcp.ReadResourceResult{
+		Contents: []*mcp.ResourceContents{
+			{
+				URI:      uri,
+				MIMEType: "text/plain",
+				Text:     page.Text,
+				Meta:     meta,
+			},
+		},
+	}, nil
+}
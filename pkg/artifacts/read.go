@@ -0,0 +1,303 @@
+package artifacts
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+const (
+	// DefaultPageLines is the number of lines returned when no range is requested.
+	DefaultPageLines = 1000
+	// MaxPageBytes is the maximum size of a page, ranges are shortened to it.
+	MaxPageBytes = 1024 * 1024
+	// DefaultMaxMatches and MaxMatches are the default and maximum number of regex
+	// search matches returned in a page.
+	DefaultMaxMatches = 100
+	MaxMatches        = 1000
+)
+
+// Range is an inclusive range of lines, numbered from 1, or bytes, numbered from
+// 0. An End of -1 means up to the end of the artifact.
+type Range struct {
+	Start int64
+	End   int64
+}
+
+// Query selects the part of an artifact to read.
+type Query struct {
+	// Lines or Bytes is the range to read. Both cannot be set.
+	Lines *Range
+	Bytes *Range
+	// Grep only returns the lines matching the regex, prefixed with their line
+	// number, within Lines if set.
+	Grep *regexp.Regexp
+	// MaxMatches is the maximum number of matches returned by Grep.
+	MaxMatches int
+}
+
+// Page is a part of an artifact.
+type Page struct {
+	Artifact *Artifact
+	Text     string
+	// Next is the URI of the next page, empty if the page is the last one.
+	Next string
+}
+
+// ParseURI returns the ID of the artifact and the query of an artifact resource
+// URI, for example artifact://<id>?lines=200-300, artifact://<id>?bytes=0-4095 or
+// artifact://<id>?grep=drop&max_matches=20. Query values must be percent-encoded.
+func ParseURI(uri string) (string, Query, error) {
+	var q Query
+	u, err := url.Parse(uri)
+	if err != nil {
+		return "", q, fmt.Errorf("invalid artifact URI: %w", err)
+	}
+	if u.Scheme != URIScheme || u.Host == "" || (u.Path != "" && u.Path != "/") {
+		return "", q, fmt.Errorf("invalid artifact URI %q: must be %s://<id>", uri, URIScheme)
+	}
+	values, err := url.ParseQuery(u.RawQuery)
+	if err != nil {
+		return "", q, fmt.Errorf("invalid artifact URI query: %w", err)
+	}
+	for key := range values {
+		switch key {
+		case "lines", "bytes", "grep", "max_matches":
+		default:
+			return "", q, fmt.Errorf("unknown artifact URI parameter %q", key)
+		}
+	}
+	if v := values.Get("lines"); v != "" {
+		if q.Lines, err = parseRange(v, 1); err != nil {
+			return "", q, fmt.Errorf("invalid lines: %w", err)
+		}
+	}
+	if v := values.Get("bytes"); v != "" {
+		if q.Lines != nil {
+			return "", q, fmt.Errorf("lines and bytes cannot be used together")
+		}
+		if q.Bytes, err = parseRange(v, 0); err != nil {
+			return "", q, fmt.Errorf("invalid bytes: %w", err)
+		}
+	}
+	if v := values.Get("grep"); v != "" {
+		if q.Bytes != nil {
+			return "", q, fmt.Errorf("grep cannot be used with bytes, use lines to restrict the search")
+		}
+		if q.Grep, err = regexp.Compile(v); err != nil {
+			return "", q, fmt.Errorf("invalid grep pattern: %w", err)
+		}
+	}
+	if v := values.Get("max_matches"); v != "" {
+		if q.MaxMatches, err = strconv.Atoi(v); err != nil || q.MaxMatches <= 0 {
+			return "", q, fmt.Errorf("invalid max_matches %q: must be a positive number", v)
+		}
+	}
+	return u.Host, q, nil
+}
+
+// parseRange parses a "<start>-<end>" or "<start>-" range whose numbers are not
+// lower than first.
+func parseRange(s string, first int64) (*Range, error) {
+	startStr, endStr, ok := strings.Cut(s, "-")
+	if !ok {
+		return nil, fmt.Errorf("%q must be <start>-<end> or <start>-", s)
+	}
+	start, err := strconv.ParseInt(startStr, 10, 64)
+	if err != nil || start < first {
+		return nil, fmt.Errorf("invalid start %q: must be a number not lower than %d", startStr, first)
+	}
+	r := &Range{Start: start, End: -1}
+	if endStr != "" {
+		r.End, err = strconv.ParseInt(endStr, 10, 64)
+		if err != nil || r.End < start {
+			return nil, fmt.Errorf("invalid end %q: must be a number not lower than the start", endStr)
+		}
+	}
+	return r, nil
+}
+
+// String returns the range in the URI format.
+func (r *Range) String() string {
+	if r.End < 0 {
+		return fmt.Sprintf("%d-", r.Start)
+	}
+	return fmt.Sprintf("%d-%d", r.Start, r.End)
+}
+
+// uri returns the URI of a query on the artifact.
+func (a *Artifact) uri(q Query) string {
+	values := url.Values{}
+	if q.Lines != nil {
+		values.Set("lines", q.Lines.String())
+	}
+	if q.Bytes != nil {
+		values.Set("bytes", q.Bytes.String())
+	}
+	if q.Grep != nil {
+		values.Set("grep", q.Grep.String())
+	}
+	if q.MaxMatches > 0 {
+		values.Set("max_matches", strconv.Itoa(q.MaxMatches))
+	}
+	if len(values) == 0 {
+		return a.URI()
+	}
+	return a.URI() + "?" + values.Encode()
+}
+
+// Read returns the part of an artifact selected by the query, if the owner can
+// read it. Pages are at most MaxPageBytes long. Without a range, the first
+// DefaultPageLines lines are returned.
+func (s *Store) Read(id, owner string, q Query) (*Page, error) {
+	a, err := s.Get(id, owner)
+	if err != nil {
+		return nil, err
+	}
+	file, err := os.Open(s.path(id))
+	if err != nil {
+		if os.IsNotExist(err) {
+			// The artifact was removed since it was looked up.
+			return nil, fmt.Errorf("artifact %s not found", id)
+		}
+		return nil, fmt.Errorf("failed to open artifact %s: %w", id, err)
+	}
+	defer file.Close()
+
+	switch {
+	case q.Bytes != nil:
+		return readBytes(file, a, *q.Bytes)
+	case q.Grep != nil:
+		r := Range{Start: 1, End: -1}
+		if q.Lines != nil {
+			r = *q.Lines
+		}
+		maxMatches := q.MaxMatches
+		if maxMatches <= 0 {
+			maxMatches = DefaultMaxMatches
+		}
+		return grepLines(file, a, r, q.Grep, min(maxMatches, MaxMatches))
+	case q.Lines != nil:
+		return readLines(file, a, *q.Lines)
+	default:
+		return readLines(file, a, Range{Start: 1, End: DefaultPageLines})
+	}
+}
+
+// readBytes returns a byte range of an artifact.
+func readBytes(file *os.File, a *Artifact, r Range) (*Page, error) {
+	page := &Page{Artifact: a}
+	if r.Start >= a.Size {
+		return page, nil
+	}
+	end := a.Size - 1
+	if r.End >= 0 {
+		end = min(end, r.End)
+	}
+	end = min(end, r.Start+MaxPageBytes-1)
+	buf := make([]byte, end-r.Start+1)
+	if _, err := file.ReadAt(buf, r.Start); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("failed to read artifact %s: %w", a.ID, err)
+	}
+	page.Text = string(buf)
+	switch {
+	case end == a.Size-1:
+	case r.End < 0 || end < r.End:
+		page.Next = a.uri(Query{Bytes: &Range{Start: end + 1, End: r.End}})
+	default:
+		// Suggest a page of the same length after the range.
+		length := r.End - r.Start + 1
+		page.Next = a.uri(Query{Bytes: &Range{Start: end + 1, End: min(end+length, a.Size-1)}})
+	}
+	return page, nil
+}
+
+// lineReader reads the lines of an artifact with their number.
+type lineReader struct {
+	reader *bufio.Reader
+	number int64
+}
+
+// next returns the next line, without its newline, or io.EOF.
+func (lr *lineReader) next() ([]byte, error) {
+	line, err := lr.reader.ReadBytes('\n')
+	if len(line) == 0 && err != nil {
+		return nil, err
+	}
+	if err != nil && !errors.Is(err, io.EOF) {
+		return nil, err
+	}
+	lr.number++
+	return bytes.TrimSuffix(line, []byte{'\n'}), nil
+}
+
+// readLines returns a line range of an artifact.
+func readLines(file *os.File, a *Artifact, r Range) (*Page, error) {
+	page := &Page{Artifact: a}
+	lr := &lineReader{reader: bufio.NewReader(file)}
+	var text strings.Builder
+	for r.End < 0 || lr.number < r.End {
+		line, err := lr.next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read artifact %s: %w", a.ID, err)
+		}
+		if lr.number < r.Start {
+			continue
+		}
+		if text.Len() > 0 && text.Len()+len(line)+1 > MaxPageBytes {
+			// The page is full, the next one starts at this line.
+			page.Next = a.uri(Query{Lines: &Range{Start: lr.number, End: r.End}})
+			break
+		}
+		text.Write(line[:min(len(line), MaxPageBytes)])
+		text.WriteByte('\n')
+	}
+	if page.Next == "" && r.End >= 0 && r.End < a.Lines {
+		// Suggest a page of the same length after the range.
+		length := r.End - r.Start + 1
+		page.Next = a.uri(Query{Lines: &Range{Start: r.End + 1, End: min(r.End+length, a.Lines)}})
+	}
+	page.Text = text.String()
+	return page, nil
+}
+
+// grepLines returns the lines of a line range of an artifact matching a regex,
+// prefixed with their line number.
+func grepLines(file *os.File, a *Artifact, r Range, pattern *regexp.Regexp, maxMatches int) (*Page, error) {
+	page := &Page{Artifact: a}
+	lr := &lineReader{reader: bufio.NewReader(file)}
+	var text strings.Builder
+	matches := 0
+	for r.End < 0 || lr.number < r.End {
+		line, err := lr.next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read artifact %s: %w", a.ID, err)
+		}
+		if lr.number < r.Start || !pattern.Match(line) {
+			continue
+		}
+		entry := fmt.Sprintf("%d:%s\n", lr.number, line[:min(len(line), MaxPageBytes)])
+		if matches == maxMatches || (text.Len() > 0 && text.Len()+len(entry) > MaxPageBytes) {
+			// The next page resumes the search at this line.
+			page.Next = a.uri(Query{Lines: &Range{Start: lr.number, End: r.End}, Grep: pattern, MaxMatches: maxMatches})
+			break
+		}
+		text.WriteString(entry)
+		matches++
+	}
+	page.Text = text.String()
+	return page, nil
+}
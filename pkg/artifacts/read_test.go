@@ -0,0 +1,119 @@
+package artifacts
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseURI(t *testing.T) {
+	tests := []struct {
+		name    string
+		uri     string
+		want    Query
+		wantErr bool
+	}{
+		{name: "no query", uri: "artifact://abc"},
+		{name: "line range", uri: "artifact://abc?lines=10-20", want: Query{Lines: &Range{Start: 10, End: 20}}},
+		{name: "open byte range", uri: "artifact://abc?bytes=100-", want: Query{Bytes: &Range{Start: 100, End: -1}}},
+		{name: "grep", uri: "artifact://abc?grep=a%7Cb&max_matches=5", want: Query{MaxMatches: 5}},
+		{name: "wrong scheme", uri: "file://abc", wantErr: true},
+		{name: "path", uri: "artifact://abc/def", wantErr: true},
+		{name: "unknown parameter", uri: "artifact://abc?offset=1", wantErr: true},
+		{name: "lines from zero", uri: "artifact://abc?lines=0-1", wantErr: true},
+		{name: "reversed range", uri: "artifact://abc?lines=5-1", wantErr: true},
+		{name: "lines and bytes", uri: "artifact://abc?lines=1-2&bytes=0-1", wantErr: true},
+		{name: "grep bytes", uri: "artifact://abc?bytes=0-1&grep=a", wantErr: true},
+		{name: "invalid regex", uri: "artifact://abc?grep=%28", wantErr: true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			id, q, err := ParseURI(test.uri)
+			if test.wantErr {
+				if err == nil {
+					t.Fatal("Expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if id != "abc" {
+				t.Fatalf("Unexpected ID %q", id)
+			}
+			if (q.Lines == nil) != (test.want.Lines == nil) || q.Lines != nil && *q.Lines != *test.want.Lines {
+				t.Fatalf("Unexpected lines %v", q.Lines)
+			}
+			if (q.Bytes == nil) != (test.want.Bytes == nil) || q.Bytes != nil && *q.Bytes != *test.want.Bytes {
+				t.Fatalf("Unexpected bytes %v", q.Bytes)
+			}
+			if q.MaxMatches != test.want.MaxMatches {
+				t.Fatalf("Unexpected max matches %d", q.MaxMatches)
+			}
+		})
+	}
+}
+
+func TestRead(t *testing.T) {
+	store, err := NewStore(Config{Dir: t.TempDir()})
+	if err != nil {
+		t.Fatalf("Failed to create store: %v", err)
+	}
+	defer store.Close()
+	artifact, err := store.Save("ovs-ofctl-dump-flows", "", "table=0 drop\ntable=1 output:1\ntable=2 drop\n")
+	if err != nil {
+		t.Fatalf("Failed to save artifact: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		query    string
+		wantText string
+		wantNext string
+	}{
+		{"whole artifact", "", "table=0 drop\ntable=1 output:1\ntable=2 drop\n", ""},
+		{"line range", "?lines=2-2", "table=1 output:1\n", "?lines=3-3"},
+		{"lines beyond the end", "?lines=10-", "", ""},
+		{"byte range", "?bytes=6-11", "0 drop", "?bytes=12-17"},
+		{"bytes beyond the end", "?bytes=100-", "", ""},
+		{"grep", "?grep=drop", "1:table=0 drop\n3:table=2 drop\n", ""},
+		{"grep within lines", "?grep=drop&lines=2-", "3:table=2 drop\n", ""},
+		{"grep with max matches", "?grep=drop&max_matches=1", "1:table=0 drop\n", "?grep=drop&lines=3-&max_matches=1"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			id, q, err := ParseURI(artifact.URI() + test.query)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			page, err := store.Read(id, "", q)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if page.Text != test.wantText {
+				t.Fatalf("Unexpected text %q, want %q", page.Text, test.wantText)
+			}
+			wantNext := ""
+			if test.wantNext != "" {
+				wantNext = artifact.URI() + test.wantNext
+			}
+			if page.Next != wantNext {
+				t.Fatalf("Unexpected next page %q, want %q", page.Next, wantNext)
+			}
+		})
+	}
+
+	t.Run("long pages are split", func(t *testing.T) {
+		line := strings.Repeat("x", 1023) + "\n"
+		long, err := store.Save("ovn-lflow-list", "", strings.Repeat(line, 2048))
+		if err != nil {
+			t.Fatalf("Failed to save artifact: %v", err)
+		}
+		page, err := store.Read(long.ID, "", Query{Lines: &Range{Start: 1, End: -1}})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if len(page.Text) != MaxPageBytes || page.Next != long.URI()+"?lines=1025-" {
+			t.Fatalf("Unexpected page of %d bytes, next %q", len(page.Text), page.Next)
+		}
+	})
+}
@@ -0,0 +1,300 @@
+// Package artifacts keeps the complete output of the tool calls whose output was
+// truncated on disk, so that clients can page through and search it with MCP
+// resources/read instead of running the command again.
+package artifacts
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/rand"
+)
+
+const (
+	// DefaultMaxBytes is the default maximum size of all the artifacts.
+	DefaultMaxBytes = 1024 * 1024 * 1024
+	// DefaultTTL is how long the artifacts are kept by default.
+	DefaultTTL = 24 * time.Hour
+
+	// URIScheme is the scheme of the artifact resource URIs.
+	URIScheme = "artifact"
+
+	filePrefix = "artifact-"
+	fileSuffix = ".txt"
+)
+
+// Config contains the location and limits of the artifact store.
+type Config struct {
+	// Dir is the directory the artifacts are written to. A temporary directory,
+	// removed on Close, is used if empty.
+	Dir string
+	// MaxBytes is the maximum size of all the artifacts. The oldest artifacts are
+	// removed once it is reached, and a single artifact is truncated to it.
+	MaxBytes int64
+	// TTL is how long an artifact is kept.
+	TTL time.Duration
+}
+
+// Artifact is the complete output of a tool call.
+type Artifact struct {
+	ID      string    `json:"id"`
+	Tool    string    `json:"tool"`
+	Created time.Time `json:"created"`
+	// Size and Lines are the size in bytes and number of lines of the artifact.
+	Size  int64 `json:"size"`
+	Lines int64 `json:"lines"`
+	// Truncated is true if the output was larger than the store.
+	Truncated bool `json:"truncated,omitempty"`
+
+	// owner is the user who called the tool, empty without authentication. Only
+	// the owner can read the artifact.
+	owner string
+}
+
+// URI returns the resource URI of the artifact.
+func (a *Artifact) URI() string {
+	return URIScheme + "://" + a.ID
+}
+
+// Store keeps the artifacts in a directory until they expire or the store is full.
+type Store struct {
+	cfg       Config
+	removeDir bool
+
+	mu        sync.Mutex
+	artifacts map[string]*Artifact
+	size      int64
+	now       func() time.Time
+}
+
+// NewStore returns an artifact store writing to the configured directory. The
+// artifacts left in the directory by a previous run are removed, since they can no
+// longer be read. Zero limits take their default value.
+func NewStore(cfg Config) (*Store, error) {
+	if cfg.MaxBytes <= 0 {
+		cfg.MaxBytes = DefaultMaxBytes
+	}
+	if cfg.TTL <= 0 {
+		cfg.TTL = DefaultTTL
+	}
+	s := &Store{cfg: cfg, artifacts: map[string]*Artifact{}, now: time.Now}
+	if cfg.Dir == "" {
+		dir, err := os.MkdirTemp("", "ovnk-mcp-artifacts-")
+		if err != nil {
+			return nil, fmt.Errorf("failed to create artifact directory: %w", err)
+		}
+		s.cfg.Dir = dir
+		s.removeDir = true
+		return s, nil
+	}
+	if err := os.MkdirAll(cfg.Dir, 0o700); err != nil {
+		return nil, fmt.Errorf("failed to create artifact directory: %w", err)
+	}
+	if err := s.removeFiles(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Dir returns the directory of the artifacts.
+func (s *Store) Dir() string {
+	return s.cfg.Dir
+}
+
+// Close removes all the artifacts.
+func (s *Store) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.artifacts = map[string]*Artifact{}
+	s.size = 0
+	if s.removeDir {
+		return os.RemoveAll(s.cfg.Dir)
+	}
+	return s.removeFiles()
+}
+
+// removeFiles removes the artifact files of the directory, leaving the other files.
+func (s *Store) removeFiles() error {
+	entries, err := os.ReadDir(s.cfg.Dir)
+	if err != nil {
+		return fmt.Errorf("failed to read artifact directory: %w", err)
+	}
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.Type().IsRegular() && strings.HasPrefix(name, filePrefix) && strings.HasSuffix(name, fileSuffix) {
+			if err := os.Remove(filepath.Join(s.cfg.Dir, name)); err != nil {
+				return fmt.Errorf("failed to remove stale artifact: %w", err)
+			}
+		}
+	}
+	return nil
+}
+
+// path returns the path of the file of an artifact.
+func (s *Store) path(id string) string {
+	return filepath.Join(s.cfg.Dir, filePrefix+id+fileSuffix)
+}
+
+// Create returns a writer for a new artifact produced by the tool for the owner.
+// The artifact can only be read once the writer is committed.
+func (s *Store) Create(tool, owner string) (*Writer, error) {
+	s.mu.Lock()
+	s.pruneLocked()
+	id := s.newIDLocked()
+	s.mu.Unlock()
+	file, err := os.OpenFile(s.path(id), os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create artifact: %w", err)
+	}
+	return &Writer{
+		store:    s,
+		file:     file,
+		artifact: &Artifact{ID: id, Tool: tool, Created: s.now(), owner: owner},
+	}, nil
+}
+
+// Save saves content as a new artifact produced by the tool for the owner.
+func (s *Store) Save(tool, owner, content string) (*Artifact, error) {
+	w, err := s.Create(tool, owner)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := w.Write([]byte(content)); err != nil {
+		w.Discard()
+		return nil, err
+	}
+	return w.Commit()
+}
+
+// newIDLocked returns an unused artifact ID.
+func (s *Store) newIDLocked() string {
+	for {
+		id := rand.String(12)
+		if _, ok := s.artifacts[id]; !ok {
+			return id
+		}
+	}
+}
+
+// Get returns the artifact with the ID if the owner can read it.
+func (s *Store) Get(id, owner string) (*Artifact, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.pruneLocked()
+	a, ok := s.artifacts[id]
+	// Do not tell the other users that the artifact exists.
+	if !ok || a.owner != owner {
+		return nil, fmt.Errorf("artifact %s not found, artifacts are kept for %s", id, s.cfg.TTL)
+	}
+	return a, nil
+}
+
+// add indexes a committed artifact and removes the oldest artifacts if the store
+// is full.
+func (s *Store) add(a *Artifact) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.artifacts[a.ID] = a
+	s.size += a.Size
+	if s.size <= s.cfg.MaxBytes {
+		return
+	}
+	artifacts := make([]*Artifact, 0, len(s.artifacts))
+	for _, other := range s.artifacts {
+		if other != a {
+			artifacts = append(artifacts, other)
+		}
+	}
+	slices.SortFunc(artifacts, func(x, y *Artifact) int {
+		return x.Created.Compare(y.Created)
+	})
+	for _, oldest := range artifacts {
+		if s.size <= s.cfg.MaxBytes {
+			break
+		}
+		s.removeLocked(oldest)
+	}
+}
+
+// pruneLocked removes the expired artifacts.
+func (s *Store) pruneLocked() {
+	now := s.now()
+	for _, a := range s.artifacts {
+		if now.Sub(a.Created) > s.cfg.TTL {
+			s.removeLocked(a)
+		}
+	}
+}
+
+// removeLocked removes an artifact and its file.
+func (s *Store) removeLocked(a *Artifact) {
+	delete(s.artifacts, a.ID)
+	s.size -= a.Size
+	if err := os.Remove(s.path(a.ID)); err != nil && !os.IsNotExist(err) {
+		log.Printf("Failed to remove artifact %s: %v", a.ID, err)
+	}
+}
+
+// Writer writes the content of a new artifact. Writes beyond the maximum size of
+// the store are dropped and the artifact is marked as truncated, so that the
+// command producing the output is not interrupted.
+type Writer struct {
+	store     *Store
+	file      *os.File
+	artifact  *Artifact
+	lastByte  byte
+	collector *collector
+}
+
+func (w *Writer) Write(p []byte) (int, error) {
+	a := w.artifact
+	room := w.store.cfg.MaxBytes - a.Size
+	data := p
+	if int64(len(data)) > room {
+		data = data[:max(room, 0)]
+		a.Truncated = true
+	}
+	if len(data) == 0 {
+		return len(p), nil
+	}
+	n, err := w.file.Write(data)
+	a.Size += int64(n)
+	a.Lines += int64(bytes.Count(data[:n], []byte{'\n'}))
+	if n > 0 {
+		w.lastByte = data[n-1]
+	}
+	if err != nil {
+		return n, err
+	}
+	return len(p), nil
+}
+
+// Commit makes the artifact readable and returns it.
+func (w *Writer) Commit() (*Artifact, error) {
+	if err := w.file.Close(); err != nil {
+		_ = os.Remove(w.file.Name())
+		return nil, fmt.Errorf("failed to write artifact: %w", err)
+	}
+	a := w.artifact
+	if a.Size > 0 && w.lastByte != '\n' {
+		a.Lines++
+	}
+	w.store.add(a)
+	if w.collector != nil {
+		w.collector.add(a)
+	}
+	return a, nil
+}
+
+// Discard removes the artifact.
+func (w *Writer) Discard() {
+	_ = w.file.Close()
+	_ = os.Remove(w.file.Name())
+}
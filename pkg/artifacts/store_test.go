@@ -0,0 +1,95 @@
+package artifacts
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestStore(t *testing.T) {
+	dir := t.TempDir()
+	stale := filepath.Join(dir, "artifact-stale.txt")
+	other := filepath.Join(dir, "notes.txt")
+	for _, path := range []string{stale, other} {
+		if err := os.WriteFile(path, []byte("x"), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	store, err := NewStore(Config{Dir: dir, MaxBytes: 10})
+	if err != nil {
+		t.Fatalf("Failed to create store: %v", err)
+	}
+	if _, err := os.Stat(stale); !os.IsNotExist(err) {
+		t.Fatal("Expected the stale artifact to be removed")
+	}
+	if _, err := os.Stat(other); err != nil {
+		t.Fatal("Expected the other files to be kept")
+	}
+
+	first, err := store.Save("ovn-show", "alice", "a\nb\nc")
+	if err != nil {
+		t.Fatalf("Failed to save artifact: %v", err)
+	}
+	if first.Size != 5 || first.Lines != 3 || first.Truncated {
+		t.Fatalf("Unexpected artifact: %+v", first)
+	}
+	if _, err := store.Get(first.ID, "bob"); err == nil {
+		t.Fatal("Expected another user not to find the artifact")
+	}
+	if _, err := store.Get(first.ID, "alice"); err != nil {
+		t.Fatalf("Expected the owner to find the artifact: %v", err)
+	}
+
+	// The second artifact fills the store, which removes the first one.
+	second, err := store.Save("ovn-show", "alice", "0123456789abc\n")
+	if err != nil {
+		t.Fatalf("Failed to save artifact: %v", err)
+	}
+	if second.Size != 10 || !second.Truncated {
+		t.Fatalf("Expected the artifact to be truncated to the store size: %+v", second)
+	}
+	if _, err := store.Get(first.ID, "alice"); err == nil {
+		t.Fatal("Expected the oldest artifact to be removed")
+	}
+
+	store.now = func() time.Time { return time.Now().Add(DefaultTTL + time.Minute) }
+	if _, err := store.Get(second.ID, "alice"); err == nil {
+		t.Fatal("Expected the expired artifact to be removed")
+	}
+	if _, err := os.Stat(store.path(second.ID)); !os.IsNotExist(err) {
+		t.Fatal("Expected the expired artifact file to be removed")
+	}
+
+	if err := store.Close(); err != nil {
+		t.Fatalf("Failed to close store: %v", err)
+	}
+	if _, err := os.Stat(other); err != nil {
+		t.Fatal("Expected the other files to be kept on close")
+	}
+}
+
+func TestTemporaryStore(t *testing.T) {
+	store, err := NewStore(Config{})
+	if err != nil {
+		t.Fatalf("Failed to create store: %v", err)
+	}
+	ctx, saved := WithCollector(context.Background(), store, "nft-list-ruleset", "")
+	if !Enabled(ctx) || Enabled(context.Background()) {
+		t.Fatal("Expected artifacts to be enabled with a collector only")
+	}
+	if Save(context.Background(), "ignored") != nil {
+		t.Fatal("Expected no artifact without a collector")
+	}
+	artifact := Save(ctx, "table inet filter\n")
+	if artifact == nil || len(saved()) != 1 || saved()[0] != artifact || artifact.Tool != "nft-list-ruleset" {
+		t.Fatalf("Expected the artifact to be collected, got %v", saved())
+	}
+	if err := store.Close(); err != nil {
+		t.Fatalf("Failed to close store: %v", err)
+	}
+	if _, err := os.Stat(store.Dir()); !os.IsNotExist(err) {
+		t.Fatal("Expected the temporary directory to be removed")
+	}
+}
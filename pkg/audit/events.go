@@ -0,0 +1,106 @@
+package audit
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	eventReason     = "MCPToolCall"
+	eventComponent  = "ovnk-mcp-server"
+	eventTimeout    = 5 * time.Second
+	maxEventMessage = 1024
+)
+
+// EventSink emits every record as a Kubernetes Event on the pods and nodes the
+// tool call executed commands on. Records without commands are not emitted, nor
+// are the commands executed in another cluster than the default one.
+type EventSink struct {
+	client kubernetes.Interface
+}
+
+// NewEventSink returns a sink creating Events with the given client.
+func NewEventSink(client kubernetes.Interface) *EventSink {
+	return &EventSink{client: client}
+}
+
+// Emit creates one Event per distinct target of the record. Failures are logged
+// and do not affect the tool call.
+func (s *EventSink) Emit(record *Record) {
+	// The tool call context may already be cancelled, so use a fresh bounded one.
+	ctx, cancel := context.WithTimeout(context.Background(), eventTimeout)
+	defer cancel()
+
+	eventType := corev1.EventTypeNormal
+	if record.Outcome != OutcomeSuccess {
+		eventType = corev1.EventTypeWarning
+	}
+	seen := map[corev1.ObjectReference]bool{}
+	for _, command := range record.Commands {
+		if command.Cluster != "" {
+			continue
+		}
+		target := eventTarget(command)
+		if seen[target] {
+			continue
+		}
+		seen[target] = true
+
+		namespace := target.Namespace
+		if namespace == "" {
+			namespace = metav1.NamespaceDefault
+		}
+		now := metav1.NewTime(record.Time)
+		event := &corev1.Event{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      fmt.Sprintf("%s.%x", target.Name, time.Now().UnixNano()),
+				Namespace: namespace,
+			},
+			InvolvedObject: target,
+			Reason:         eventReason,
+			Message:        eventMessage(record),
+			Source:         corev1.EventSource{Component: eventComponent},
+			FirstTimestamp: now,
+			LastTimestamp:  now,
+			Count:          1,
+			Type:           eventType,
+		}
+		if _, err := s.client.CoreV1().Events(namespace).Create(ctx, event, metav1.CreateOptions{}); err != nil {
+			log.Printf("Failed to create audit event for %s %s: %v", target.Kind, target.Name, err)
+		}
+	}
+}
+
+// eventTarget returns the object a command was executed on.
+func eventTarget(command Command) corev1.ObjectReference {
+	if command.Node != "" {
+		return corev1.ObjectReference{APIVersion: "v1", Kind: "Node", Name: command.Node}
+	}
+	return corev1.ObjectReference{APIVersion: "v1", Kind: "Pod", Namespace: command.Namespace, Name: command.Pod}
+}
+
+// eventMessage describes the record in at most maxEventMessage bytes.
+func eventMessage(record *Record) string {
+	user := record.User
+	if user == "" {
+		user = "unknown user"
+	}
+	message := fmt.Sprintf("Tool %s called by %s (%s)", record.Tool, user, record.Outcome)
+	if record.Completion != "" {
+		message = fmt.Sprintf("Argument %s completed for %s (%s)", record.Completion, user, record.Outcome)
+	}
+	for _, command := range record.Commands {
+		message += ": " + strings.Join(command.Command, " ")
+	}
+	if len(message) > maxEventMessage {
+		message = message[:maxEventMessage-3] + "..."
+	}
+	return message
+}
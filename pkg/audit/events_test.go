@@ -0,0 +1,52 @@
+package audit
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestEventSink(t *testing.T) {
+	client := fake.NewClientset()
+	sink := NewEventSink(client)
+
+	record := newTestRecord("ovn-show")
+	record.Outcome = OutcomeError
+	record.Commands = append(record.Commands,
+		record.Commands[0],
+		Command{Node: "worker-0", Image: "netshoot", Command: []string{"nft", "list", "ruleset"}},
+		// Not emitted: the events are created in the default cluster only.
+		Command{Cluster: "east", Node: "worker-1", Image: "netshoot", Command: []string{"ip", "route"}},
+	)
+	sink.Emit(record)
+
+	podEvents, err := client.CoreV1().Events("ovn-kubernetes").List(context.Background(), metav1.ListOptions{})
+	if err != nil {
+		t.Fatalf("Failed to list events: %v", err)
+	}
+	if len(podEvents.Items) != 1 {
+		t.Fatalf("Expected 1 pod event, got %d", len(podEvents.Items))
+	}
+	event := podEvents.Items[0]
+	if event.InvolvedObject.Kind != "Pod" || event.InvolvedObject.Name != "ovnkube-node-abc" {
+		t.Fatalf("Unexpected involved object: %+v", event.InvolvedObject)
+	}
+	if event.Type != corev1.EventTypeWarning {
+		t.Fatalf("Expected a warning event, got %s", event.Type)
+	}
+	if !strings.Contains(event.Message, "ovn-nbctl show") || !strings.Contains(event.Message, "alice") {
+		t.Fatalf("Unexpected event message: %s", event.Message)
+	}
+
+	nodeEvents, err := client.CoreV1().Events(metav1.NamespaceDefault).List(context.Background(), metav1.ListOptions{})
+	if err != nil {
+		t.Fatalf("Failed to list events: %v", err)
+	}
+	if len(nodeEvents.Items) != 1 || nodeEvents.Items[0].InvolvedObject.Kind != "Node" {
+		t.Fatalf("Expected 1 node event, got %+v", nodeEvents.Items)
+	}
+}
@@ -0,0 +1,198 @@
+package audit
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+)
+
+const (
+	// DefaultMaxSize is the default size in bytes after which the audit log is rotated.
+	DefaultMaxSize = 100 * 1024 * 1024
+	// DefaultMaxBackups is the default number of rotated audit logs that are kept.
+	DefaultMaxBackups = 5
+)
+
+// Config contains the configuration of the audit log.
+type Config struct {
+	// Path is the file the records are written to.
+	Path string
+	// MaxSize is the size in bytes after which the file is rotated. Zero disables
+	// rotation.
+	MaxSize int64
+	// MaxBackups is the number of rotated files that are kept.
+	MaxBackups int
+	// Key is the HMAC key of the hash chain. It must not be readable by those who
+	// can write the file, so that they cannot rewrite the records with valid hashes.
+	Key []byte
+}
+
+// Sink receives every record after it has been written to the audit log.
+type Sink interface {
+	Emit(record *Record)
+}
+
+// Logger writes audit records as JSON lines. Each record is chained to the
+// previous one by its keyed hash, including across rotated files and restarts.
+type Logger struct {
+	mu       sync.Mutex
+	file     *rotatingFile
+	key      []byte
+	lastHash string
+	sinks    []Sink
+}
+
+// NewLogger opens the audit log described by cfg. If the file already contains
+// records, the hash chain continues from the last one.
+func NewLogger(cfg Config, sinks ...Sink) (*Logger, error) {
+	if cfg.Path == "" {
+		return nil, fmt.Errorf("audit log path is required")
+	}
+	if len(cfg.Key) == 0 {
+		return nil, fmt.Errorf("audit log key is required")
+	}
+	lastHash, err := readLastHash(cfg.Path)
+	if err != nil {
+		return nil, err
+	}
+	if lastHash == "" {
+		// The current file may be empty right after a rotation.
+		if lastHash, err = readLastHash(cfg.Path + ".1"); err != nil {
+			return nil, err
+		}
+	}
+	file, err := openRotatingFile(cfg.Path, cfg.MaxSize, cfg.MaxBackups)
+	if err != nil {
+		return nil, err
+	}
+	return &Logger{file: file, key: cfg.Key, lastHash: lastHash, sinks: sinks}, nil
+}
+
+// Log chains the record to the previous one, writes it and passes it to the sinks.
+func (l *Logger) Log(record *Record) error {
+	l.mu.Lock()
+	record.PrevHash = l.lastHash
+	hash, err := hashRecord(l.key, record)
+	if err != nil {
+		l.mu.Unlock()
+		return err
+	}
+	record.Hash = hash
+	data, err := json.Marshal(record)
+	if err != nil {
+		l.mu.Unlock()
+		return fmt.Errorf("failed to marshal audit record: %w", err)
+	}
+	if _, err := l.file.Write(append(data, '\n')); err != nil {
+		l.mu.Unlock()
+		return fmt.Errorf("failed to write audit record: %w", err)
+	}
+	l.lastHash = hash
+	l.mu.Unlock()
+
+	for _, sink := range l.sinks {
+		sink.Emit(record)
+	}
+	return nil
+}
+
+// Close closes the audit log.
+func (l *Logger) Close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.file.Close()
+}
+
+// hashRecord returns the hex encoded HMAC-SHA256 of the record with an empty Hash
+// field, keyed with key.
+func hashRecord(key []byte, record *Record) (string, error) {
+	unhashed := *record
+	unhashed.Hash = ""
+	data, err := json.Marshal(&unhashed)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal audit record: %w", err)
+	}
+	mac := hmac.New(sha256.New, key)
+	mac.Write(data)
+	return hex.EncodeToString(mac.Sum(nil)), nil
+}
+
+// Verify checks the hash chain of the records read from r with the key of the
+// audit log. prevHash is the hash of the record preceding the first one, or empty
+// for the first file of the chain. It returns the hash of the last record so that
+// rotated files can be verified in order. Removing the last records keeps the chain
+// valid: compare the returned hash with a copy kept elsewhere to detect it.
+func Verify(r io.Reader, key []byte, prevHash string) (string, error) {
+	if len(key) == 0 {
+		return "", fmt.Errorf("audit log key is required")
+	}
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	line := 0
+	for scanner.Scan() {
+		line++
+		data := bytes.TrimSpace(scanner.Bytes())
+		if len(data) == 0 {
+			continue
+		}
+		record := &Record{}
+		if err := json.Unmarshal(data, record); err != nil {
+			return "", fmt.Errorf("line %d: invalid audit record: %w", line, err)
+		}
+		if record.PrevHash != prevHash {
+			return "", fmt.Errorf("line %d: previous hash %q does not match %q", line, record.PrevHash, prevHash)
+		}
+		hash, err := hashRecord(key, record)
+		if err != nil {
+			return "", fmt.Errorf("line %d: %w", line, err)
+		}
+		if !hmac.Equal([]byte(record.Hash), []byte(hash)) {
+			return "", fmt.Errorf("line %d: record hash %q does not match content hash %q", line, record.Hash, hash)
+		}
+		prevHash = hash
+	}
+	if err := scanner.Err(); err != nil {
+		return "", fmt.Errorf("failed to read audit log: %w", err)
+	}
+	return prevHash, nil
+}
+
+// readLastHash returns the hash of the last record in the file at path, or an
+// empty string if the file does not exist or has no records.
+func readLastHash(path string) (string, error) {
+	file, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to open audit log %s: %w", path, err)
+	}
+	defer file.Close()
+
+	var last []byte
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		if data := bytes.TrimSpace(scanner.Bytes()); len(data) > 0 {
+			last = append(last[:0], data...)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return "", fmt.Errorf("failed to read audit log %s: %w", path, err)
+	}
+	if last == nil {
+		return "", nil
+	}
+	record := &Record{}
+	if err := json.Unmarshal(last, record); err != nil {
+		return "", fmt.Errorf("failed to parse last record of audit log %s: %w", path, err)
+	}
+	return record.Hash, nil
+}
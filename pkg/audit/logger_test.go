@@ -0,0 +1,177 @@
+package audit
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+var testKey = []byte("audit-log-key")
+
+func newTestRecord(tool string) *Record {
+	return &Record{
+		Time:     time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+		Tool:     tool,
+		User:     "alice",
+		Commands: []Command{{Namespace: "ovn-kubernetes", Pod: "ovnkube-node-abc", Command: []string{"ovn-nbctl", "show"}}},
+		Outcome:  OutcomeSuccess,
+	}
+}
+
+func TestLoggerHashChain(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.log")
+	logger, err := NewLogger(Config{Path: path, Key: testKey})
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	for _, tool := range []string{"ovn-show", "ovs-list-br"} {
+		if err := logger.Log(newTestRecord(tool)); err != nil {
+			t.Fatalf("Failed to log record: %v", err)
+		}
+	}
+	if err := logger.Close(); err != nil {
+		t.Fatalf("Failed to close logger: %v", err)
+	}
+
+	// Reopening the log continues the chain.
+	logger, err = NewLogger(Config{Path: path, Key: testKey})
+	if err != nil {
+		t.Fatalf("Failed to reopen logger: %v", err)
+	}
+	if err := logger.Log(newTestRecord("get-nft")); err != nil {
+		t.Fatalf("Failed to log record: %v", err)
+	}
+	logger.Close()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read audit log: %v", err)
+	}
+	if lines := strings.Count(string(data), "\n"); lines != 3 {
+		t.Fatalf("Expected 3 records, got %d", lines)
+	}
+	if _, err := Verify(bytes.NewReader(data), testKey, ""); err != nil {
+		t.Fatalf("Expected a valid hash chain, got: %v", err)
+	}
+
+	t.Run("detects modified records", func(t *testing.T) {
+		tampered := bytes.Replace(data, []byte(`"user":"alice"`), []byte(`"user":"mallory"`), 1)
+		if _, err := Verify(bytes.NewReader(tampered), testKey, ""); err == nil {
+			t.Fatal("Expected the modified record to be detected")
+		}
+	})
+
+	t.Run("detects removed records", func(t *testing.T) {
+		lines := strings.SplitAfter(string(data), "\n")
+		removed := lines[0] + lines[2]
+		if _, err := Verify(strings.NewReader(removed), testKey, ""); err == nil {
+			t.Fatal("Expected the removed record to be detected")
+		}
+	})
+
+	t.Run("detects records rewritten without the key", func(t *testing.T) {
+		rewritten := &bytes.Buffer{}
+		prevHash := ""
+		for _, tool := range []string{"ovn-show", "ovs-list-br", "get-nft"} {
+			record := newTestRecord(tool)
+			record.User = "mallory"
+			record.PrevHash = prevHash
+			// The hash of the previous, keyless, version of the chain.
+			unhashed, _ := json.Marshal(record)
+			sum := sha256.Sum256(unhashed)
+			record.Hash = hex.EncodeToString(sum[:])
+			prevHash = record.Hash
+			line, _ := json.Marshal(record)
+			rewritten.Write(append(line, '\n'))
+		}
+		if _, err := Verify(rewritten, testKey, ""); err == nil {
+			t.Fatal("Expected the rewritten records to be detected")
+		}
+		if _, err := Verify(bytes.NewReader(data), []byte("other-key"), ""); err == nil {
+			t.Fatal("Expected the chain to be invalid with another key")
+		}
+	})
+
+	t.Run("requires the key", func(t *testing.T) {
+		if _, err := Verify(bytes.NewReader(data), nil, ""); err == nil {
+			t.Fatal("Expected an error without a key")
+		}
+		if _, err := NewLogger(Config{Path: path}); err == nil {
+			t.Fatal("Expected an error without a key")
+		}
+	})
+}
+
+func TestLoggerRotation(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "audit.log")
+	// Every record is larger than the maximum size, so each one rotates the file.
+	logger, err := NewLogger(Config{Path: path, MaxSize: 100, MaxBackups: 2, Key: testKey})
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	for _, tool := range []string{"ovn-show", "ovs-list-br", "get-nft", "get-ip"} {
+		if err := logger.Log(newTestRecord(tool)); err != nil {
+			t.Fatalf("Failed to log record: %v", err)
+		}
+	}
+	logger.Close()
+
+	if _, err := os.Stat(path + ".3"); !os.IsNotExist(err) {
+		t.Fatalf("Expected at most 2 backups, got error %v", err)
+	}
+	// The chain continues across the rotated files, oldest first.
+	prevHash := ""
+	first := true
+	for _, name := range []string{path + ".2", path + ".1", path} {
+		data, err := os.ReadFile(name)
+		if err != nil {
+			t.Fatalf("Failed to read %s: %v", name, err)
+		}
+		if first {
+			// The oldest kept file follows a removed one, so start from its own chain.
+			record := strings.SplitN(string(data), "\n", 2)[0]
+			prevHash = extractPrevHash(t, record)
+			first = false
+		}
+		if prevHash, err = Verify(bytes.NewReader(data), testKey, prevHash); err != nil {
+			t.Fatalf("Invalid hash chain in %s: %v", name, err)
+		}
+	}
+}
+
+func extractPrevHash(t *testing.T, line string) string {
+	t.Helper()
+	_, after, found := strings.Cut(line, `"prev_hash":"`)
+	if !found {
+		t.Fatalf("No prev_hash in record %s", line)
+	}
+	hash, _, _ := strings.Cut(after, `"`)
+	return hash
+}
+
+func TestRecordCommand(t *testing.T) {
+	// Recording without a recorder is a no-op.
+	RecordCommand(context.Background(), Command{Pod: "pod", Command: []string{"true"}})
+
+	ctx, commands := WithRecorder(context.Background())
+	command := []string{"ovs-vsctl", "show"}
+	RecordCommand(ctx, Command{Pod: "pod", Command: command})
+	RecordCommand(ctx, Command{Node: "node", Command: []string{"ip", "route"}})
+	command[0] = "modified"
+
+	got := commands()
+	if len(got) != 2 {
+		t.Fatalf("Expected 2 commands, got %d", len(got))
+	}
+	if got[0].Command[0] != "ovs-vsctl" || got[1].Node != "node" {
+		t.Fatalf("Unexpected commands: %+v", got)
+	}
+}
@@ -0,0 +1,86 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+)
+
+const (
+	// OutcomeSuccess is the outcome of a tool call that completed without error.
+	OutcomeSuccess = "success"
+	// OutcomeError is the outcome of a tool call that failed or was rejected.
+	OutcomeError = "error"
+)
+
+// Command is a command sent to a pod or a node on behalf of a tool call.
+type Command struct {
+	// Cluster is the cluster the command was executed in, empty for the default
+	// cluster.
+	Cluster string `json:"cluster,omitempty"`
+	// Namespace, Pod and Container identify the target of a pod exec.
+	Namespace string `json:"namespace,omitempty"`
+	Pod       string `json:"pod,omitempty"`
+	Container string `json:"container,omitempty"`
+	// Node and Image identify the target of a node debug pod.
+	Node  string `json:"node,omitempty"`
+	Image string `json:"image,omitempty"`
+	// Command is the exact command vector that was executed.
+	Command []string `json:"command"`
+}
+
+// Record is a single audit log entry describing a tools/call request, or a
+// completion/complete request that executed commands. Completion is the completed
+// argument of the latter, which has no Tool.
+type Record struct {
+	Time       time.Time       `json:"time"`
+	Tool       string          `json:"tool,omitempty"`
+	Completion string          `json:"completion,omitempty"`
+	Arguments  json.RawMessage `json:"arguments,omitempty"`
+	User       string          `json:"user,omitempty"`
+	Groups     []string        `json:"groups,omitempty"`
+	SessionID  string          `json:"session_id,omitempty"`
+	Commands   []Command       `json:"commands,omitempty"`
+	Duration   float64         `json:"duration_seconds"`
+	Outcome    string          `json:"outcome"`
+	Error      string          `json:"error,omitempty"`
+	// PrevHash is the hash of the previous record and Hash is the HMAC-SHA256 of
+	// this record with an empty Hash field, keyed with the audit log key. Together
+	// they chain the records so that, without the key, any modification, insertion
+	// or removal of a record can be detected, except the removal of the last ones.
+	PrevHash string `json:"prev_hash"`
+	Hash     string `json:"hash"`
+}
+
+// recorder collects the commands executed during a tool call.
+type recorder struct {
+	mu       sync.Mutex
+	commands []Command
+}
+
+type recorderKey struct{}
+
+// WithRecorder returns a context that collects the commands recorded with
+// RecordCommand, and a function returning the collected commands.
+func WithRecorder(ctx context.Context) (context.Context, func() []Command) {
+	r := &recorder{}
+	return context.WithValue(ctx, recorderKey{}, r), func() []Command {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		return append([]Command(nil), r.commands...)
+	}
+}
+
+// RecordCommand records a command executed on behalf of the tool call of the
+// context. It does nothing if the context has no recorder.
+func RecordCommand(ctx context.Context, command Command) {
+	r, ok := ctx.Value(recorderKey{}).(*recorder)
+	if !ok {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	command.Command = append([]string(nil), command.Command...)
+	r.commands = append(r.commands, command)
+}
@@ -0,0 +1,163 @@
+package auth
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	authenticationv1 "k8s.io/api/authentication/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/cache"
+	"k8s.io/client-go/kubernetes"
+)
+
+// errNoCredentials is returned by an authenticator when the request does not carry
+// the kind of credentials it handles, so that the next authenticator can be tried.
+var errNoCredentials = errors.New("no credentials")
+
+const (
+	// tokenCacheSize is the maximum number of token review results kept in memory.
+	tokenCacheSize = 1024
+	// defaultTokenCacheTTL is the default time a successful token review is cached.
+	defaultTokenCacheTTL = 10 * time.Second
+)
+
+// Authenticator authenticates an HTTP request.
+type Authenticator interface {
+	// Authenticate returns the identity of the caller. It returns errNoCredentials
+	// if the request does not contain credentials handled by the authenticator.
+	Authenticate(r *http.Request) (*Identity, error)
+}
+
+// TokenReviewAuthenticator authenticates bearer tokens by submitting a TokenReview
+// to the Kubernetes API server.
+type TokenReviewAuthenticator struct {
+	client    kubernetes.Interface
+	audiences []string
+	cacheTTL  time.Duration
+	cache     *cache.LRUExpireCache
+}
+
+// NewTokenReviewAuthenticator creates a new TokenReviewAuthenticator. If audiences is
+// not empty, the token must be valid for at least one of them. Successful reviews
+// are cached for cacheTTL; a zero cacheTTL uses the default.
+func NewTokenReviewAuthenticator(client kubernetes.Interface, audiences []string, cacheTTL time.Duration) *TokenReviewAuthenticator {
+	if cacheTTL <= 0 {
+		cacheTTL = defaultTokenCacheTTL
+	}
+	return &TokenReviewAuthenticator{
+		client:    client,
+		audiences: audiences,
+		cacheTTL:  cacheTTL,
+		cache:     cache.NewLRUExpireCache(tokenCacheSize),
+	}
+}
+
+// Authenticate authenticates the bearer token of the request.
+func (a *TokenReviewAuthenticator) Authenticate(r *http.Request) (*Identity, error) {
+	fields := strings.Fields(r.Header.Get("Authorization"))
+	if len(fields) != 2 || !strings.EqualFold(fields[0], "bearer") {
+		return nil, errNoCredentials
+	}
+	token := fields[1]
+
+	// Only the hash of the token is kept in memory.
+	sum := sha256.Sum256([]byte(token))
+	key := hex.EncodeToString(sum[:])
+	if cached, ok := a.cache.Get(key); ok {
+		return cached.(*Identity), nil
+	}
+
+	identity, err := a.review(r.Context(), token)
+	if err != nil {
+		return nil, err
+	}
+	a.cache.Add(key, identity, a.cacheTTL)
+	return identity, nil
+}
+
+// review submits a TokenReview for the token.
+func (a *TokenReviewAuthenticator) review(ctx context.Context, token string) (*Identity, error) {
+	review := &authenticationv1.TokenReview{
+		Spec: authenticationv1.TokenReviewSpec{
+			Token:     token,
+			Audiences: a.audiences,
+		},
+	}
+	result, err := a.client.AuthenticationV1().TokenReviews().Create(ctx, review, metav1.CreateOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to review token: %w", err)
+	}
+	if !result.Status.Authenticated {
+		if result.Status.Error != "" {
+			return nil, fmt.Errorf("token is not authenticated: %s", result.Status.Error)
+		}
+		return nil, errors.New("token is not authenticated")
+	}
+	if result.Status.User.Username == "" {
+		return nil, errors.New("token review returned an empty user name")
+	}
+	return &Identity{
+		Username: result.Status.User.Username,
+		Groups:   result.Status.User.Groups,
+	}, nil
+}
+
+// ClientCertAuthenticator authenticates a request by its TLS client certificate.
+// The certificate must already have been verified against the client CA by the
+// TLS server. The common name is used as user name and the organizations as groups,
+// following the Kubernetes convention.
+type ClientCertAuthenticator struct{}
+
+// Authenticate authenticates the verified client certificate of the request.
+func (ClientCertAuthenticator) Authenticate(r *http.Request) (*Identity, error) {
+	if r.TLS == nil || len(r.TLS.VerifiedChains) == 0 || len(r.TLS.VerifiedChains[0]) == 0 {
+		return nil, errNoCredentials
+	}
+	cert := r.TLS.VerifiedChains[0][0]
+	if cert.Subject.CommonName == "" {
+		return nil, errors.New("client certificate has an empty common name")
+	}
+	return &Identity{
+		Username: cert.Subject.CommonName,
+		Groups:   cert.Subject.Organization,
+	}, nil
+}
+
+// Middleware returns an HTTP middleware that authenticates every request with the
+// given authenticators, in order. The first authenticator that finds credentials
+// decides the outcome. Unauthenticated requests are rejected with 401. The identity
+// of authenticated requests is passed to the MCP handlers in the identity headers.
+func Middleware(authenticators ...Authenticator) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			// Never trust identity headers sent by the client.
+			clearHeaders(r.Header)
+
+			for _, authenticator := range authenticators {
+				identity, err := authenticator.Authenticate(r)
+				if errors.Is(err, errNoCredentials) {
+					continue
+				}
+				if err != nil {
+					log.Printf("Authentication failed for %s: %v", r.RemoteAddr, err)
+					w.Header().Set("WWW-Authenticate", "Bearer")
+					http.Error(w, "authentication failed", http.StatusUnauthorized)
+					return
+				}
+				identity.setHeaders(r.Header)
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			http.Error(w, "authentication required", http.StatusUnauthorized)
+		})
+	}
+}
@@ -0,0 +1,144 @@
+package auth
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+
+	authenticationv1 "k8s.io/api/authentication/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+// newFakeTokenReviewClient returns a fake client that authenticates only validToken.
+func newFakeTokenReviewClient(validToken string, reviews *int) *fake.Clientset {
+	client := fake.NewClientset()
+	client.PrependReactor("create", "tokenreviews", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		*reviews++
+		review := action.(k8stesting.CreateAction).GetObject().(*authenticationv1.TokenReview)
+		if review.Spec.Token == validToken {
+			review.Status = authenticationv1.TokenReviewStatus{
+				Authenticated: true,
+				User: authenticationv1.UserInfo{
+					Username: "alice",
+					Groups:   []string{"sre", "system:authenticated"},
+				},
+			}
+		} else {
+			review.Status = authenticationv1.TokenReviewStatus{Error: "invalid token"}
+		}
+		return true, review, nil
+	})
+	return client
+}
+
+func TestMiddleware(t *testing.T) {
+	reviews := 0
+	tokenAuthenticator := NewTokenReviewAuthenticator(newFakeTokenReviewClient("valid", &reviews), nil, 0)
+
+	tests := []struct {
+		name           string
+		header         http.Header
+		tls            *tls.ConnectionState
+		expectedStatus int
+		expectedUser   string
+		expectedGroups []string
+	}{
+		{
+			name:           "valid bearer token",
+			header:         http.Header{"Authorization": []string{"Bearer valid"}},
+			expectedStatus: http.StatusOK,
+			expectedUser:   "alice",
+			expectedGroups: []string{"sre", "system:authenticated"},
+		},
+		{
+			name:           "invalid bearer token",
+			header:         http.Header{"Authorization": []string{"Bearer invalid"}},
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name:           "no credentials",
+			header:         http.Header{},
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name:           "spoofed identity headers are rejected",
+			header:         http.Header{UserHeader: []string{"admin"}},
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name: "spoofed identity headers are replaced",
+			header: http.Header{
+				"Authorization": []string{"Bearer valid"},
+				UserHeader:      []string{"admin"},
+				GroupHeader:     []string{"system:masters"},
+			},
+			expectedStatus: http.StatusOK,
+			expectedUser:   "alice",
+			expectedGroups: []string{"sre", "system:authenticated"},
+		},
+		{
+			name:   "verified client certificate",
+			header: http.Header{},
+			tls: &tls.ConnectionState{
+				VerifiedChains: [][]*x509.Certificate{{
+					{Subject: pkix.Name{CommonName: "bob", Organization: []string{"network-admins"}}},
+				}},
+			},
+			expectedStatus: http.StatusOK,
+			expectedUser:   "bob",
+			expectedGroups: []string{"network-admins"},
+		},
+		{
+			name:           "unverified client certificate",
+			header:         http.Header{},
+			tls:            &tls.ConnectionState{},
+			expectedStatus: http.StatusUnauthorized,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var gotUser string
+			var gotGroups []string
+			handler := Middleware(ClientCertAuthenticator{}, tokenAuthenticator)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				gotUser = r.Header.Get(UserHeader)
+				gotGroups = r.Header.Values(GroupHeader)
+			}))
+			req := httptest.NewRequest(http.MethodPost, "/", nil)
+			req.Header = test.header
+			req.TLS = test.tls
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			if rec.Code != test.expectedStatus {
+				t.Fatalf("Expected status %d, got %d", test.expectedStatus, rec.Code)
+			}
+			if gotUser != test.expectedUser {
+				t.Fatalf("Expected user %q, got %q", test.expectedUser, gotUser)
+			}
+			if !slices.Equal(gotGroups, test.expectedGroups) {
+				t.Fatalf("Expected groups %v, got %v", test.expectedGroups, gotGroups)
+			}
+		})
+	}
+}
+
+func TestTokenReviewAuthenticatorCache(t *testing.T) {
+	reviews := 0
+	authenticator := NewTokenReviewAuthenticator(newFakeTokenReviewClient("valid", &reviews), nil, 0)
+	for range 3 {
+		req := httptest.NewRequest(http.MethodPost, "/", nil)
+		req.Header.Set("Authorization", "Bearer valid")
+		if _, err := authenticator.Authenticate(req); err != nil {
+			t.Fatalf("Unexpected authentication error: %v", err)
+		}
+	}
+	if reviews != 1 {
+		t.Fatalf("Expected 1 token review, got %d", reviews)
+	}
+}
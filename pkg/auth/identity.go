@@ -0,0 +1,68 @@
+package auth
+
+import (
+	"net/http"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+const (
+	// UserHeader is the request header carrying the authenticated user name from the
+	// HTTP authentication layer to the MCP handlers. Any value sent by the client is
+	// discarded before authentication.
+	UserHeader = "X-Remote-User"
+	// GroupHeader is the request header carrying the groups of the authenticated user.
+	// The header is repeated once per group.
+	GroupHeader = "X-Remote-Group"
+)
+
+// Identity is the authenticated caller of the MCP server.
+type Identity struct {
+	Username string
+	Groups   []string
+}
+
+// setHeaders stores the identity in the request headers.
+func (i *Identity) setHeaders(header http.Header) {
+	header.Set(UserHeader, i.Username)
+	for _, group := range i.Groups {
+		header.Add(GroupHeader, group)
+	}
+}
+
+// clearHeaders removes any identity headers from the request headers.
+func clearHeaders(header http.Header) {
+	header.Del(UserHeader)
+	header.Del(GroupHeader)
+}
+
+// IdentityFromRequest returns the identity of the caller of an MCP request. It returns
+// false if the request did not go through the authentication middleware, for example
+// when the server is running with the stdio transport.
+func IdentityFromRequest(req mcp.Request) (*Identity, bool) {
+	if req == nil {
+		return nil, false
+	}
+	extra := req.GetExtra()
+	if extra == nil || extra.Header == nil {
+		return nil, false
+	}
+	username := extra.Header.Get(UserHeader)
+	if username == "" {
+		return nil, false
+	}
+	return &Identity{
+		Username: username,
+		Groups:   extra.Header.Values(GroupHeader),
+	}, true
+}
+
+// Username returns the name of the authenticated caller of an MCP request, empty
+// without authentication. The resources created for a caller, like artifacts and
+// jobs, are owned by this name.
+func Username(req mcp.Request) string {
+	if identity, ok := IdentityFromRequest(req); ok {
+		return identity.Username
+	}
+	return ""
+}
@@ -0,0 +1,169 @@
+package auth
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	yaml "sigs.k8s.io/yaml"
+)
+
+// Tool families that can be granted in a policy.
+const (
+	FamilyKubernetes   = "kubernetes"
+	FamilyOVN          = "ovn"
+	FamilyOVS          = "ovs"
+	FamilyKernel       = "kernel"
+	FamilyNetworkTools = "network-tools"
+	FamilySosreport    = "sosreport"
+	FamilyMustGather   = "must-gather"
+
+	// wildcard matches any user, group or family in a policy rule.
+	wildcard = "*"
+)
+
+// familyPrefixes maps tool name prefixes to the family of the tools.
+var familyPrefixes = map[string]string{
+	"ovn-":         FamilyOVN,
+	"ovs-":         FamilyOVS,
+	"sos-":         FamilySosreport,
+	"must-gather-": FamilyMustGather,
+	// Background jobs run the captures and traces of the network tools.
+	"job-": FamilyNetworkTools,
+}
+
+// familyTools maps tool names that do not share a prefix to the family of the tool.
+var familyTools = map[string]string{
+	"pod-logs":      FamilyKubernetes,
+	"resource-get":  FamilyKubernetes,
+	"resource-list": FamilyKubernetes,
+	"cluster-list":  FamilyKubernetes,
+	"ovnk-topology": FamilyOVN,
+	"get-conntrack": FamilyKernel,
+	"get-iptables":  FamilyKernel,
+	"get-nft":       FamilyKernel,
+	"get-ip":        FamilyKernel,
+	"tcpdump":       FamilyNetworkTools,
+	"pwru":          FamilyNetworkTools,
+	"tcpdump-start": FamilyNetworkTools,
+	"pwru-start":    FamilyNetworkTools,
+}
+
+// ToolFamily returns the family of a tool, or an empty string if the tool does not
+// belong to a known family.
+func ToolFamily(tool string) string {
+	if family, ok := familyTools[tool]; ok {
+		return family
+	}
+	for prefix, family := range familyPrefixes {
+		if strings.HasPrefix(tool, prefix) {
+			return family
+		}
+	}
+	return ""
+}
+
+// isKnownFamily returns true if the family is a known tool family.
+func isKnownFamily(family string) bool {
+	for _, f := range familyTools {
+		if f == family {
+			return true
+		}
+	}
+	for _, f := range familyPrefixes {
+		if f == family {
+			return true
+		}
+	}
+	return false
+}
+
+// PolicyRule grants access to tool families to a set of users and groups.
+type PolicyRule struct {
+	// Users are the user names the rule applies to. "*" matches any user.
+	Users []string `json:"users,omitempty"`
+	// Groups are the groups the rule applies to. "*" matches any group.
+	Groups []string `json:"groups,omitempty"`
+	// Families are the tool families granted by the rule. "*" grants all tools,
+	// including tools that do not belong to a known family.
+	Families []string `json:"families"`
+}
+
+// Policy is the authorization policy of the MCP server. Rules are additive: a caller
+// may invoke a tool if any rule matching the caller grants the family of the tool.
+type Policy struct {
+	Rules []PolicyRule `json:"rules"`
+}
+
+// LoadPolicy reads and validates a policy file.
+func LoadPolicy(path string) (*Policy, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read policy file %s: %w", path, err)
+	}
+	policy := &Policy{}
+	if err := yaml.UnmarshalStrict(data, policy); err != nil {
+		return nil, fmt.Errorf("failed to parse policy file %s: %w", path, err)
+	}
+	if err := policy.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid policy file %s: %w", path, err)
+	}
+	return policy, nil
+}
+
+// Validate validates the policy rules.
+func (p *Policy) Validate() error {
+	for i, rule := range p.Rules {
+		if len(rule.Users) == 0 && len(rule.Groups) == 0 {
+			return fmt.Errorf("rule %d must have at least one user or group", i)
+		}
+		if len(rule.Families) == 0 {
+			return fmt.Errorf("rule %d must have at least one family", i)
+		}
+		for _, family := range rule.Families {
+			if family != wildcard && !isKnownFamily(family) {
+				return fmt.Errorf("rule %d has unknown family %q", i, family)
+			}
+		}
+	}
+	return nil
+}
+
+// Allowed returns true if the identity may invoke the tool.
+func (p *Policy) Allowed(identity *Identity, tool string) bool {
+	return p.AllowedFamily(identity, ToolFamily(tool))
+}
+
+// AllowedFamily returns true if the identity may invoke the tools of the family.
+// An empty family is only granted by the "*" family.
+func (p *Policy) AllowedFamily(identity *Identity, family string) bool {
+	if identity == nil {
+		return false
+	}
+	for _, rule := range p.Rules {
+		if !rule.matches(identity) {
+			continue
+		}
+		if slices.Contains(rule.Families, wildcard) {
+			return true
+		}
+		if family != "" && slices.Contains(rule.Families, family) {
+			return true
+		}
+	}
+	return false
+}
+
+// matches returns true if the rule applies to the identity.
+func (r *PolicyRule) matches(identity *Identity) bool {
+	if slices.Contains(r.Users, wildcard) || slices.Contains(r.Users, identity.Username) {
+		return true
+	}
+	for _, group := range identity.Groups {
+		if slices.Contains(r.Groups, group) {
+			return true
+		}
+	}
+	return slices.Contains(r.Groups, wildcard)
+}
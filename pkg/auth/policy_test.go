@@ -0,0 +1,125 @@
+package auth
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestToolFamily(t *testing.T) {
+	tests := []struct {
+		tool   string
+		family string
+	}{
+		{"ovn-show", FamilyOVN},
+		{"ovs-ofctl-dump-flows", FamilyOVS},
+		{"get-nft", FamilyKernel},
+		{"tcpdump", FamilyNetworkTools},
+		{"pwru", FamilyNetworkTools},
+		{"resource-list", FamilyKubernetes},
+		{"sos-list-plugins", FamilySosreport},
+		{"must-gather-pod-logs", FamilyMustGather},
+		{"unknown-tool", ""},
+	}
+	for _, test := range tests {
+		t.Run(test.tool, func(t *testing.T) {
+			if got := ToolFamily(test.tool); got != test.family {
+				t.Fatalf("ToolFamily(%q) = %q, want %q", test.tool, got, test.family)
+			}
+		})
+	}
+}
+
+func TestPolicyAllowed(t *testing.T) {
+	policy := &Policy{
+		Rules: []PolicyRule{
+			{Users: []string{"alice"}, Families: []string{FamilyOVN, FamilyOVS}},
+			{Groups: []string{"sre"}, Families: []string{FamilyKernel}},
+			{Users: []string{"admin"}, Families: []string{wildcard}},
+			{Groups: []string{wildcard}, Families: []string{FamilyKubernetes}},
+		},
+	}
+	tests := []struct {
+		name     string
+		identity *Identity
+		tool     string
+		allowed  bool
+	}{
+		{"user granted family", &Identity{Username: "alice"}, "ovn-show", true},
+		{"user not granted family", &Identity{Username: "alice"}, "get-nft", false},
+		{"group granted family", &Identity{Username: "bob", Groups: []string{"sre"}}, "get-nft", true},
+		{"group not granted family", &Identity{Username: "bob", Groups: []string{"sre"}}, "tcpdump", false},
+		{"wildcard family grants unknown tool", &Identity{Username: "admin"}, "unknown-tool", true},
+		{"wildcard group grants family", &Identity{Username: "carol"}, "pod-logs", true},
+		{"unknown tool not granted by family", &Identity{Username: "alice"}, "unknown-tool", false},
+		{"nil identity", nil, "ovn-show", false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := policy.Allowed(test.identity, test.tool); got != test.allowed {
+				t.Fatalf("Allowed(%v, %q) = %v, want %v", test.identity, test.tool, got, test.allowed)
+			}
+		})
+	}
+}
+
+func TestLoadPolicy(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		wantErr bool
+	}{
+		{
+			name: "valid policy",
+			content: `rules:
+- users: ["alice"]
+  groups: ["sre"]
+  families: ["ovn", "ovs", "kernel", "network-tools"]
+- groups: ["system:authenticated"]
+  families: ["kubernetes"]
+`,
+		},
+		{
+			name: "unknown family",
+			content: `rules:
+- users: ["alice"]
+  families: ["routing"]
+`,
+			wantErr: true,
+		},
+		{
+			name: "rule without subjects",
+			content: `rules:
+- families: ["ovn"]
+`,
+			wantErr: true,
+		},
+		{
+			name: "rule without families",
+			content: `rules:
+- users: ["alice"]
+`,
+			wantErr: true,
+		},
+		{
+			name: "unknown field",
+			content: `rules:
+- user: ["alice"]
+  families: ["ovn"]
+`,
+			wantErr: true,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "policy.yaml")
+			if err := os.WriteFile(path, []byte(test.content), 0o600); err != nil {
+				t.Fatalf("Failed to write policy file: %v", err)
+			}
+			_, err := LoadPolicy(path)
+			if (err != nil) != test.wantErr {
+				t.Fatalf("LoadPolicy() error = %v, wantErr %v", err, test.wantErr)
+			}
+		})
+	}
+}
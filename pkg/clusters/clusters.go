@@ -0,0 +1,389 @@
+// Package clusters manages the Kubernetes clusters the live-cluster tools can
+// target: the current context of a kubeconfig, every context of a kubeconfig, or
+// a directory of kubeconfigs. The client of a cluster is created on the first tool
+// call targeting it and kept for the next ones.
+package clusters
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+
+	"github.com/ovn-kubernetes/ovn-kubernetes-mcp/pkg/kubernetes/client"
+)
+
+const (
+	// InClusterName is the name of the cluster the server runs in, when it uses its
+	// ServiceAccount credentials.
+	InClusterName = "in-cluster"
+	// healthCheckTimeout bounds the health check of a cluster.
+	healthCheckTimeout = 5 * time.Second
+)
+
+// Config is the configuration of the clusters.
+type Config struct {
+	// Kubeconfig is the path to the kubeconfig file. The ServiceAccount credentials
+	// of the server are used if empty.
+	Kubeconfig string
+	// AllContexts loads every context of Kubeconfig as a cluster named after the
+	// context, instead of its current context only.
+	AllContexts bool
+	// KubeconfigDir is a directory of kubeconfig files, whose current context are
+	// loaded as clusters named after the files.
+	KubeconfigDir string
+	// DefaultCluster is the cluster of the tool calls without cluster. It defaults
+	// to the current context of Kubeconfig, or to the only cluster.
+	DefaultCluster string
+}
+
+// NewClientFunc creates the client of a cluster from its REST configuration.
+type NewClientFunc func(ctx context.Context, name string, config *rest.Config) (client.Interface, error)
+
+// Status is the status of a cluster.
+type Status struct {
+	Name string `json:"name"`
+	// Default is true for the cluster of the tool calls without cluster.
+	Default bool `json:"default,omitempty"`
+	// Source is the kubeconfig file and context the cluster is loaded from.
+	Source string `json:"source"`
+	// Server is the URL of the API server.
+	Server string `json:"server,omitempty"`
+	// Connected is true once the client of the cluster is created.
+	Connected bool `json:"connected"`
+	// Healthy is true if the API server answered the last health check.
+	Healthy bool `json:"healthy"`
+	// Version is the Kubernetes version of the API server.
+	Version string `json:"version,omitempty"`
+	// Error is the error of the last health check or client creation.
+	Error string `json:"error,omitempty"`
+	// CheckedAt is the time of the last health check or client creation.
+	CheckedAt *time.Time `json:"checked_at,omitempty"`
+}
+
+// cluster is a cluster of the registry.
+type cluster struct {
+	name   string
+	source string
+	config *rest.Config
+
+	// mu serializes the creation of the client.
+	mu     sync.Mutex
+	client client.Interface
+
+	statusMu sync.Mutex
+	status   Status
+}
+
+// Registry is the set of clusters the tools can target.
+type Registry struct {
+	clusters    map[string]*cluster
+	names       []string
+	defaultName string
+	newClient   NewClientFunc
+	// checkHealth returns the version of the API server of a cluster.
+	checkHealth func(ctx context.Context, config *rest.Config) (string, error)
+}
+
+// NewRegistry loads the clusters of the configuration. The clients of the clusters
+// are created with newClient when they are first used.
+func NewRegistry(cfg Config, newClient NewClientFunc) (*Registry, error) {
+	var clusters []*cluster
+	var defaultName string
+	var err error
+	switch {
+	case cfg.KubeconfigDir != "":
+		if cfg.Kubeconfig != "" || cfg.AllContexts {
+			return nil, errors.New("a kubeconfig directory cannot be used with a kubeconfig file or all its contexts")
+		}
+		clusters, err = loadDir(cfg.KubeconfigDir)
+	case cfg.AllContexts:
+		if cfg.Kubeconfig == "" {
+			return nil, errors.New("loading all the contexts requires a kubeconfig file")
+		}
+		clusters, defaultName, err = loadContexts(cfg.Kubeconfig)
+	case cfg.Kubeconfig != "":
+		var c *cluster
+		c, err = loadCurrentContext(cfg.Kubeconfig, "")
+		clusters = []*cluster{c}
+	default:
+		var config *rest.Config
+		config, err = rest.InClusterConfig()
+		clusters = []*cluster{{name: InClusterName, source: InClusterName, config: config}}
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(clusters) == 1 {
+		defaultName = clusters[0].name
+	}
+	if cfg.DefaultCluster != "" {
+		defaultName = cfg.DefaultCluster
+	}
+
+	r := &Registry{clusters: map[string]*cluster{}, newClient: newClient, checkHealth: serverVersion}
+	for _, c := range clusters {
+		if _, found := r.clusters[c.name]; found {
+			return nil, fmt.Errorf("duplicate cluster %s", c.name)
+		}
+		c.status = Status{Name: c.name, Source: c.source, Server: c.config.Host}
+		r.clusters[c.name] = c
+		r.names = append(r.names, c.name)
+	}
+	if err := r.setDefault(defaultName); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// NewStaticRegistry returns a registry of clusters whose clients are already
+// created, like the clusters replayed from a bundle.
+func NewStaticRegistry(clients map[string]client.Interface, defaultName, source string) (*Registry, error) {
+	r := &Registry{clusters: map[string]*cluster{}}
+	for name, c := range clients {
+		now := time.Now()
+		r.clusters[name] = &cluster{name: name, source: source, client: c,
+			status: Status{Name: name, Source: source, Connected: true, Healthy: true, CheckedAt: &now}}
+		r.names = append(r.names, name)
+	}
+	if defaultName == "" && len(clients) == 1 {
+		defaultName = r.names[0]
+	}
+	if err := r.setDefault(defaultName); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// setDefault sets the default cluster and sorts the clusters by name.
+func (r *Registry) setDefault(name string) error {
+	slices.Sort(r.names)
+	if name == "" {
+		return nil
+	}
+	c, found := r.clusters[name]
+	if !found {
+		return fmt.Errorf("unknown default cluster %s, the clusters are: %s", name, strings.Join(r.names, ", "))
+	}
+	r.defaultName = name
+	c.status.Default = true
+	return nil
+}
+
+// loadCurrentContext loads the current context of a kubeconfig file as a cluster,
+// named after the context if name is empty.
+func loadCurrentContext(path, name string) (*cluster, error) {
+	kubeconfig, err := clientcmd.LoadFromFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load kubeconfig %s: %w", path, err)
+	}
+	contextName := kubeconfig.CurrentContext
+	if contextName == "" && len(kubeconfig.Contexts) == 1 {
+		contextName = slices.Collect(maps.Keys(kubeconfig.Contexts))[0]
+	}
+	if contextName == "" {
+		return nil, fmt.Errorf("kubeconfig %s has no current context", path)
+	}
+	if name == "" {
+		name = contextName
+	}
+	return newCluster(kubeconfig, path, contextName, name)
+}
+
+// loadContexts loads every context of a kubeconfig file as a cluster, and returns
+// the name of the current context.
+func loadContexts(path string) ([]*cluster, string, error) {
+	kubeconfig, err := clientcmd.LoadFromFile(path)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to load kubeconfig %s: %w", path, err)
+	}
+	if len(kubeconfig.Contexts) == 0 {
+		return nil, "", fmt.Errorf("kubeconfig %s has no context", path)
+	}
+	var clusters []*cluster
+	for contextName := range kubeconfig.Contexts {
+		c, err := newCluster(kubeconfig, path, contextName, contextName)
+		if err != nil {
+			return nil, "", err
+		}
+		clusters = append(clusters, c)
+	}
+	return clusters, kubeconfig.CurrentContext, nil
+}
+
+// loadDir loads the current context of the kubeconfig files of a directory as
+// clusters named after the files, without their extension.
+func loadDir(dir string) ([]*cluster, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read kubeconfig directory %s: %w", dir, err)
+	}
+	var clusters []*cluster
+	for _, entry := range entries {
+		if entry.IsDir() || strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+		name := strings.TrimSuffix(entry.Name(), filepath.Ext(entry.Name()))
+		c, err := loadCurrentContext(filepath.Join(dir, entry.Name()), name)
+		if err != nil {
+			return nil, err
+		}
+		clusters = append(clusters, c)
+	}
+	if len(clusters) == 0 {
+		return nil, fmt.Errorf("no kubeconfig in directory %s", dir)
+	}
+	return clusters, nil
+}
+
+// newCluster returns the cluster of a context of a kubeconfig.
+func newCluster(kubeconfig *clientcmdapi.Config, path, contextName, name string) (*cluster, error) {
+	config, err := clientcmd.NewNonInteractiveClientConfig(*kubeconfig, contextName, &clientcmd.ConfigOverrides{}, nil).ClientConfig()
+	if err != nil {
+		return nil, fmt.Errorf("invalid context %s of kubeconfig %s: %w", contextName, path, err)
+	}
+	return &cluster{name: name, source: fmt.Sprintf("%s (context %s)", path, contextName), config: config}, nil
+}
+
+// serverVersion returns the version of the API server of a cluster.
+func serverVersion(ctx context.Context, config *rest.Config) (string, error) {
+	config = rest.CopyConfig(config)
+	config.Timeout = healthCheckTimeout
+	discoveryClient, err := discovery.NewDiscoveryClientForConfig(config)
+	if err != nil {
+		return "", err
+	}
+	version, err := discoveryClient.ServerVersion()
+	if err != nil {
+		return "", err
+	}
+	return version.GitVersion, nil
+}
+
+// Names returns the names of the clusters, sorted.
+func (r *Registry) Names() []string {
+	return slices.Clone(r.names)
+}
+
+// DefaultName returns the name of the default cluster, empty if there is none.
+func (r *Registry) DefaultName() string {
+	return r.defaultName
+}
+
+// lookup returns the cluster of the tool call, see WithName.
+func (r *Registry) lookup(ctx context.Context) (*cluster, error) {
+	name := Name(ctx)
+	if name == "" {
+		name = r.defaultName
+	}
+	if name == "" {
+		return nil, fmt.Errorf("cluster is required: %d clusters are configured (%s), list them with cluster-list",
+			len(r.names), strings.Join(r.names, ", "))
+	}
+	c, found := r.clusters[name]
+	if !found {
+		return nil, fmt.Errorf("unknown cluster %q, the clusters are: %s", name, strings.Join(r.names, ", "))
+	}
+	return c, nil
+}
+
+// DefaultConfig returns the REST configuration of the default cluster.
+func (r *Registry) DefaultConfig() (*rest.Config, error) {
+	c, err := r.lookup(context.Background())
+	if err != nil {
+		return nil, err
+	}
+	if c.config == nil {
+		return nil, fmt.Errorf("cluster %s has no REST configuration", c.name)
+	}
+	return c.config, nil
+}
+
+// Client returns the client of the cluster of the tool call, see WithName, or of
+// the default cluster. The client is created on the first call.
+func (r *Registry) Client(ctx context.Context) (client.Interface, error) {
+	c, err := r.lookup(ctx)
+	if err != nil {
+		return nil, err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.client != nil {
+		return c.client, nil
+	}
+	clusterClient, err := r.newClient(ctx, c.name, c.config)
+	now := time.Now()
+	c.statusMu.Lock()
+	defer c.statusMu.Unlock()
+	c.status.CheckedAt = &now
+	if err != nil {
+		c.status.Healthy = false
+		c.status.Error = err.Error()
+		return nil, fmt.Errorf("cluster %s is not available: %w", c.name, err)
+	}
+	c.client = clusterClient
+	c.status.Connected = true
+	c.status.Healthy = true
+	c.status.Error = ""
+	return clusterClient, nil
+}
+
+// Status checks the health of the clusters concurrently and returns their status.
+func (r *Registry) Status(ctx context.Context) []Status {
+	statuses := make([]Status, len(r.names))
+	var wg sync.WaitGroup
+	for i, name := range r.names {
+		c := r.clusters[name]
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			statuses[i] = r.check(ctx, c)
+		}()
+	}
+	wg.Wait()
+	return statuses
+}
+
+// check checks the health of a cluster and returns its status.
+func (r *Registry) check(ctx context.Context, c *cluster) Status {
+	if c.config != nil && r.checkHealth != nil {
+		ctx, cancel := context.WithTimeout(ctx, healthCheckTimeout)
+		defer cancel()
+		version, err := r.checkHealth(ctx, c.config)
+		now := time.Now()
+		c.statusMu.Lock()
+		c.status.CheckedAt = &now
+		c.status.Healthy = err == nil
+		c.status.Version = version
+		c.status.Error = ""
+		if err != nil {
+			c.status.Error = err.Error()
+		}
+		c.statusMu.Unlock()
+	}
+	c.statusMu.Lock()
+	defer c.statusMu.Unlock()
+	return c.status
+}
+
+// Close closes the clients of the clusters.
+func (r *Registry) Close() {
+	for _, c := range r.clusters {
+		c.mu.Lock()
+		if c.client != nil {
+			c.client.Close()
+		}
+		c.mu.Unlock()
+	}
+}
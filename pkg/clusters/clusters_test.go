@@ -0,0 +1,211 @@
+package clusters
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"k8s.io/client-go/rest"
+
+	"github.com/ovn-kubernetes/ovn-kubernetes-mcp/pkg/kubernetes/client"
+)
+
+// kubeconfig returns a kubeconfig with a context per cluster name, the current
+// context being the first one.
+func kubeconfig(names ...string) string {
+	var clusters, contexts, users strings.Builder
+	for _, name := range names {
+		clusters.WriteString("- name: " + name + "\n  cluster:\n    server: https://" + name + ".example.com:6443\n")
+		contexts.WriteString("- name: " + name + "\n  context:\n    cluster: " + name + "\n    user: " + name + "\n")
+		users.WriteString("- name: " + name + "\n  user:\n    token: " + name + "-token\n")
+	}
+	return "apiVersion: v1\nkind: Config\ncurrent-context: " + names[0] +
+		"\nclusters:\n" + clusters.String() + "contexts:\n" + contexts.String() + "users:\n" + users.String()
+}
+
+func writeFile(t *testing.T, path, data string) string {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+		t.Fatalf("Failed to write %s: %v", path, err)
+	}
+	return path
+}
+
+func TestNewRegistry(t *testing.T) {
+	dir := t.TempDir()
+	kubeconfigFile := writeFile(t, filepath.Join(dir, "kubeconfig"), kubeconfig("west", "east"))
+	kubeconfigDir := filepath.Join(dir, "clusters")
+	if err := os.Mkdir(kubeconfigDir, 0o700); err != nil {
+		t.Fatalf("Failed to create directory: %v", err)
+	}
+	writeFile(t, filepath.Join(kubeconfigDir, "prod.yaml"), kubeconfig("admin"))
+	writeFile(t, filepath.Join(kubeconfigDir, "staging.kubeconfig"), kubeconfig("admin"))
+	writeFile(t, filepath.Join(kubeconfigDir, ".hidden"), "not a kubeconfig")
+
+	tests := []struct {
+		name        string
+		cfg         Config
+		wantNames   []string
+		wantDefault string
+		wantServer  string
+		wantErr     string
+	}{
+		{
+			name:        "current context",
+			cfg:         Config{Kubeconfig: kubeconfigFile},
+			wantNames:   []string{"west"},
+			wantDefault: "west",
+			wantServer:  "https://west.example.com:6443",
+		},
+		{
+			name:        "all contexts",
+			cfg:         Config{Kubeconfig: kubeconfigFile, AllContexts: true},
+			wantNames:   []string{"east", "west"},
+			wantDefault: "west",
+			wantServer:  "https://west.example.com:6443",
+		},
+		{
+			name:        "default cluster",
+			cfg:         Config{Kubeconfig: kubeconfigFile, AllContexts: true, DefaultCluster: "east"},
+			wantNames:   []string{"east", "west"},
+			wantDefault: "east",
+			wantServer:  "https://east.example.com:6443",
+		},
+		{
+			name:      "kubeconfig directory",
+			cfg:       Config{KubeconfigDir: kubeconfigDir},
+			wantNames: []string{"prod", "staging"},
+		},
+		{
+			name:    "unknown default cluster",
+			cfg:     Config{Kubeconfig: kubeconfigFile, DefaultCluster: "north"},
+			wantErr: "unknown default cluster north",
+		},
+		{
+			name:    "all contexts without kubeconfig",
+			cfg:     Config{AllContexts: true},
+			wantErr: "requires a kubeconfig file",
+		},
+		{
+			name:    "directory and kubeconfig",
+			cfg:     Config{Kubeconfig: kubeconfigFile, KubeconfigDir: kubeconfigDir},
+			wantErr: "cannot be used with a kubeconfig file",
+		},
+		{
+			name:    "missing kubeconfig",
+			cfg:     Config{Kubeconfig: filepath.Join(dir, "missing")},
+			wantErr: "failed to load kubeconfig",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			registry, err := NewRegistry(test.cfg, nil)
+			if test.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), test.wantErr) {
+					t.Fatalf("Expected error %q, got %v", test.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(registry.Names(), test.wantNames) || registry.DefaultName() != test.wantDefault {
+				t.Fatalf("Unexpected clusters %v, default %q", registry.Names(), registry.DefaultName())
+			}
+			config, err := registry.DefaultConfig()
+			if test.wantDefault == "" {
+				if err == nil || !strings.Contains(err.Error(), "cluster is required") {
+					t.Fatalf("Expected a cluster required error, got %v", err)
+				}
+				return
+			}
+			if err != nil || config.Host != test.wantServer {
+				t.Fatalf("Unexpected default config %v, %v", config, err)
+			}
+		})
+	}
+}
+
+func TestRegistryClient(t *testing.T) {
+	dir := t.TempDir()
+	kubeconfigFile := writeFile(t, filepath.Join(dir, "kubeconfig"), kubeconfig("west", "east"))
+
+	created := map[string]int{}
+	newClient := func(ctx context.Context, name string, config *rest.Config) (client.Interface, error) {
+		created[name]++
+		if name == "east" {
+			return nil, errors.New("connection refused")
+		}
+		return client.NewFakeClient(), nil
+	}
+	registry, err := NewRegistry(Config{Kubeconfig: kubeconfigFile, AllContexts: true}, newClient)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	registry.checkHealth = func(ctx context.Context, config *rest.Config) (string, error) {
+		if strings.Contains(config.Host, "east") {
+			return "", errors.New("connection refused")
+		}
+		return "v1.34.0", nil
+	}
+	ctx := context.Background()
+
+	// The clients are created on their first use only.
+	for range 2 {
+		if _, err := registry.Client(ctx); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if _, err := registry.Client(WithName(ctx, "west")); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+	if created["west"] != 1 || created["east"] != 0 {
+		t.Fatalf("Unexpected client creations %v", created)
+	}
+	if _, err := registry.Client(WithName(ctx, "east")); err == nil || !strings.Contains(err.Error(), "cluster east is not available") {
+		t.Fatalf("Expected an unavailable cluster error, got %v", err)
+	}
+	if _, err := registry.Client(WithName(ctx, "north")); err == nil || !strings.Contains(err.Error(), "unknown cluster") {
+		t.Fatalf("Expected an unknown cluster error, got %v", err)
+	}
+
+	statuses := registry.Status(ctx)
+	if len(statuses) != 2 {
+		t.Fatalf("Expected 2 statuses, got %v", statuses)
+	}
+	east, west := statuses[0], statuses[1]
+	if east.Name != "east" || east.Connected || east.Healthy || east.Default || east.Error != "connection refused" {
+		t.Fatalf("Unexpected east status %+v", east)
+	}
+	if west.Name != "west" || !west.Connected || !west.Healthy || !west.Default || west.Version != "v1.34.0" || west.CheckedAt == nil {
+		t.Fatalf("Unexpected west status %+v", west)
+	}
+}
+
+func TestStaticRegistry(t *testing.T) {
+	clients := map[string]client.Interface{"east": client.NewFakeClient(), "west": client.NewFakeClient()}
+	if _, err := NewStaticRegistry(clients, "north", "bundle"); err == nil {
+		t.Fatal("Expected an unknown default cluster error")
+	}
+	registry, err := NewStaticRegistry(clients, "", "bundle")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	ctx := context.Background()
+	if _, err := registry.Client(ctx); err == nil || !strings.Contains(err.Error(), "cluster is required") {
+		t.Fatalf("Expected a cluster required error, got %v", err)
+	}
+	c, err := registry.Client(WithName(ctx, "east"))
+	if err != nil || c != clients["east"] {
+		t.Fatalf("Unexpected client %v, %v", c, err)
+	}
+	for _, status := range registry.Status(ctx) {
+		if !status.Connected || !status.Healthy || status.Source != "bundle" {
+			t.Fatalf("Unexpected status %+v", status)
+		}
+	}
+}
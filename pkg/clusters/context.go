@@ -0,0 +1,19 @@
+package clusters
+
+import "context"
+
+// nameKey is the context key of the cluster of a tool call.
+type nameKey struct{}
+
+// WithName returns a context targeting the cluster of a tool call. The cluster is
+// set from the cluster parameter of the live-cluster tools by the Cluster
+// middleware.
+func WithName(ctx context.Context, name string) context.Context {
+	return context.WithValue(ctx, nameKey{}, name)
+}
+
+// Name returns the cluster targeted by a tool call, empty for the default cluster.
+func Name(ctx context.Context) string {
+	name, _ := ctx.Value(nameKey{}).(string)
+	return name
+}
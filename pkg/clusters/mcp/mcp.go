@@ -0,0 +1,52 @@
+package mcp
+
+import (
+	"context"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/ovn-kubernetes/ovn-kubernetes-mcp/pkg/auth"
+	"github.com/ovn-kubernetes/ovn-kubernetes-mcp/pkg/clusters"
+	"github.com/ovn-kubernetes/ovn-kubernetes-mcp/pkg/clusters/types"
+	"github.com/ovn-kubernetes/ovn-kubernetes-mcp/pkg/completion"
+)
+
+// MCPServer provides the tools listing the clusters the live-cluster tools can
+// target.
+type MCPServer struct {
+	registry *clusters.Registry
+}
+
+// NewMCPServer creates a new MCP server listing the clusters of the registry.
+func NewMCPServer(registry *clusters.Registry) *MCPServer {
+	return &MCPServer{registry: registry}
+}
+
+// AddTools registers the cluster tools with the MCP server.
+func (s *MCPServer) AddTools(server *mcp.Server) {
+	mcp.AddTool(server,
+		&mcp.Tool{
+			Name: "cluster-list",
+			Description: `List the Kubernetes clusters the live-cluster tools can target, and check their health.
+
+Every live-cluster tool takes an optional cluster parameter with the name of one of these clusters.
+The tool calls without cluster target the default cluster.
+
+Returns the name, source kubeconfig and context, API server URL and Kubernetes version of every
+cluster, whether it is the default cluster, whether the server is connected to it (a cluster is
+connected on its first tool call), and whether its API server answered the health check, with the
+error otherwise.`,
+		}, s.List)
+}
+
+// List checks the health of the clusters and returns their status.
+func (s *MCPServer) List(ctx context.Context, req *mcp.CallToolRequest, in struct{}) (*mcp.CallToolResult, types.ClusterListResult, error) {
+	return nil, types.ClusterListResult{Clusters: s.registry.Status(ctx)}, nil
+}
+
+// AddCompletions adds the completion of the cluster argument with the names of the
+// clusters.
+func (s *MCPServer) AddCompletions(completer *completion.Completer) {
+	completer.Add(completion.ArgumentCluster, auth.FamilyKubernetes, func(ctx context.Context, arguments map[string]string) ([]string, error) {
+		return s.registry.Names(), nil
+	})
+}
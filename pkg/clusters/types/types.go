@@ -0,0 +1,8 @@
+package types
+
+import "github.com/ovn-kubernetes/ovn-kubernetes-mcp/pkg/clusters"
+
+// ClusterListResult contains the status of the clusters.
+type ClusterListResult struct {
+	Clusters []clusters.Status `json:"clusters"`
+}
@@ -0,0 +1,209 @@
+// Package completion implements the MCP completion capability: the clients
+// autocomplete the identifiers the tools need, like pod, node, table and bridge
+// names, from the values the server looks up in the cluster.
+//
+// The values are completed by argument name, whatever the prompt or resource
+// template they are requested for, and are looked up within the other arguments
+// already set, like the namespace of a pod or the pod of a bridge. A prefixed
+// argument, like source_pod, is completed as the argument without prefix, within
+// the arguments with the same prefix, like source_namespace.
+package completion
+
+import (
+	"context"
+	"encoding/json"
+	"maps"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/ovn-kubernetes/ovn-kubernetes-mcp/pkg/auth"
+	"github.com/ovn-kubernetes/ovn-kubernetes-mcp/pkg/clusters"
+	k8stypes "github.com/ovn-kubernetes/ovn-kubernetes-mcp/pkg/kubernetes/types"
+)
+
+const (
+	// DefaultTTL is how long the values of an argument are cached.
+	DefaultTTL = 30 * time.Second
+	// maxValues is the maximum number of values of a completion result, as
+	// required by the MCP specification.
+	maxValues = 100
+)
+
+// Names of the completed arguments.
+const (
+	ArgumentCluster      = "cluster"
+	ArgumentNamespace    = "namespace"
+	ArgumentPod          = "pod"
+	ArgumentNode         = "node"
+	ArgumentDatabase     = "database"
+	ArgumentTable        = "table"
+	ArgumentDatapath     = "datapath"
+	ArgumentBridge       = "bridge"
+	ArgumentMustGather   = "must_gather_path"
+	ArgumentDatabaseName = "database_name"
+)
+
+// Func returns the values of an argument, given the other arguments already set.
+type Func func(ctx context.Context, arguments map[string]string) ([]string, error)
+
+// PodTarget returns the pod of the namespace and pod arguments, or the node
+// argument if the pod is not set, whose commands complete the values.
+func PodTarget(arguments map[string]string) k8stypes.PodTargetParams {
+	if name := arguments[ArgumentPod]; name != "" {
+		return k8stypes.PodTargetParams{Namespace: arguments[ArgumentNamespace], Name: name}
+	}
+	return k8stypes.PodTargetParams{Namespace: arguments[ArgumentNamespace], Node: arguments[ArgumentNode]}
+}
+
+// Completer answers the completion requests with the values of the functions
+// added for their argument.
+type Completer struct {
+	ttl time.Duration
+	now func() time.Time
+
+	mu    sync.Mutex
+	funcs map[string]argumentFunc
+	cache map[string]cachedValues
+}
+
+// argumentFunc is the function returning the values of an argument, and the family
+// of the tools whose lookups it runs.
+type argumentFunc struct {
+	family string
+	f      Func
+}
+
+// cachedValues are the values of an argument looked up at a time.
+type cachedValues struct {
+	values []string
+	expiry time.Time
+}
+
+// NewCompleter creates a completer caching the values of the arguments for ttl.
+func NewCompleter(ttl time.Duration) *Completer {
+	return &Completer{ttl: ttl, now: time.Now, funcs: map[string]argumentFunc{}, cache: map[string]cachedValues{}}
+}
+
+// Add sets the function returning the values of an argument. The function runs
+// the lookups of the tools of the family, like the commands of the ovs tools or
+// the list calls of the kubernetes tools, and is only run for the callers the
+// authorization policy grants the family.
+func (c *Completer) Add(argument, family string, f Func) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.funcs[argument] = argumentFunc{family: family, f: f}
+}
+
+// Family returns the family of the function completing an argument, empty if the
+// argument has no function.
+func (c *Completer) Family(argument string) string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	argFunc, _, _ := c.lookupLocked(argument)
+	return argFunc.family
+}
+
+// lookupLocked returns the function completing an argument, and the prefix of the
+// argument if it is completed as the argument without prefix.
+func (c *Completer) lookupLocked(argument string) (argumentFunc, string, bool) {
+	if argFunc, found := c.funcs[argument]; found {
+		return argFunc, "", true
+	}
+	if prefix, argument, ok := strings.Cut(argument, "_"); ok {
+		if argFunc, found := c.funcs[argument]; found {
+			return argFunc, prefix, true
+		}
+	}
+	return argumentFunc{}, "", false
+}
+
+// Complete is the completion handler of the MCP server. It returns the values of
+// the argument starting with its current value, the arguments without function
+// having no value. The cluster argument targets the lookups at a cluster.
+func (c *Completer) Complete(ctx context.Context, req *mcp.CompleteRequest) (*mcp.CompleteResult, error) {
+	result := &mcp.CompleteResult{Completion: mcp.CompletionResultDetails{Values: []string{}}}
+	if req.Params == nil {
+		return result, nil
+	}
+	arguments := map[string]string{}
+	if req.Params.Context != nil {
+		maps.Copy(arguments, req.Params.Context.Arguments)
+	}
+	c.mu.Lock()
+	argFunc, prefix, found := c.lookupLocked(req.Params.Argument.Name)
+	c.mu.Unlock()
+	if !found {
+		return result, nil
+	}
+	if prefix != "" {
+		for name, value := range maps.Clone(arguments) {
+			if name, ok := strings.CutPrefix(name, prefix+"_"); ok {
+				arguments[name] = value
+			}
+		}
+	}
+	if cluster := arguments[ArgumentCluster]; cluster != "" {
+		ctx = clusters.WithName(ctx, cluster)
+	}
+	values, err := c.values(ctx, cacheKey(req, arguments), argFunc.f, arguments)
+	if err != nil {
+		return nil, err
+	}
+
+	var matches []string
+	for _, value := range values {
+		if strings.HasPrefix(value, req.Params.Argument.Value) {
+			matches = append(matches, value)
+		}
+	}
+	result.Completion.Total = len(matches)
+	if len(matches) > maxValues {
+		matches = matches[:maxValues]
+		result.Completion.HasMore = true
+	}
+	result.Completion.Values = append(result.Completion.Values, matches...)
+	return result, nil
+}
+
+// values returns the sorted values of an argument, from the cache if they were
+// looked up less than ttl ago. Failed lookups are not cached.
+func (c *Completer) values(ctx context.Context, key string, f Func, arguments map[string]string) ([]string, error) {
+	c.mu.Lock()
+	cached, found := c.cache[key]
+	c.mu.Unlock()
+	if found && c.now().Before(cached.expiry) {
+		return cached.values, nil
+	}
+
+	values, err := f(ctx, arguments)
+	if err != nil {
+		return nil, err
+	}
+	values = slices.Compact(slices.Sorted(slices.Values(values)))
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := c.now()
+	for key, cached := range c.cache {
+		if !now.Before(cached.expiry) {
+			delete(c.cache, key)
+		}
+	}
+	c.cache[key] = cachedValues{values: values, expiry: now.Add(c.ttl)}
+	return values, nil
+}
+
+// cacheKey identifies the values of the argument of a request. The values are
+// cached per caller, who may not be allowed to see the values of other callers.
+func cacheKey(req *mcp.CompleteRequest, arguments map[string]string) string {
+	var user string
+	if identity, ok := auth.IdentityFromRequest(req); ok {
+		user = identity.Username
+	}
+	// Maps are marshalled with sorted keys.
+	key, _ := json.Marshal([]any{user, req.Params.Argument.Name, arguments})
+	return string(key)
+}
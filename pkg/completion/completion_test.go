@@ -0,0 +1,167 @@
+package completion
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/ovn-kubernetes/ovn-kubernetes-mcp/pkg/auth"
+	"github.com/ovn-kubernetes/ovn-kubernetes-mcp/pkg/clusters"
+)
+
+func newRequest(user, argument, value string, arguments map[string]string) *mcp.CompleteRequest {
+	header := http.Header{}
+	if user != "" {
+		header.Set(auth.UserHeader, user)
+	}
+	return &mcp.CompleteRequest{
+		Params: &mcp.CompleteParams{
+			Ref:      &mcp.CompleteReference{Type: "ref/prompt", Name: "diagnose-pod"},
+			Argument: mcp.CompleteParamsArgument{Name: argument, Value: value},
+			Context:  &mcp.CompleteContext{Arguments: arguments},
+		},
+		Extra: &mcp.RequestExtra{Header: header},
+	}
+}
+
+func TestComplete(t *testing.T) {
+	var many []string
+	for i := range 150 {
+		many = append(many, fmt.Sprintf("worker-%03d", i))
+	}
+	completer := NewCompleter(DefaultTTL)
+	completer.Add(ArgumentPod, auth.FamilyKubernetes, func(ctx context.Context, arguments map[string]string) ([]string, error) {
+		if arguments[ArgumentNamespace] == "ovn-kubernetes" {
+			return []string{"ovnkube-node-b", "ovnkube-node-a", "ovnkube-control-plane", "ovnkube-node-a"}, nil
+		}
+		return []string{"coredns"}, nil
+	})
+	completer.Add(ArgumentNode, auth.FamilyKubernetes, func(ctx context.Context, arguments map[string]string) ([]string, error) {
+		return many, nil
+	})
+	completer.Add(ArgumentCluster, auth.FamilyKubernetes, func(ctx context.Context, arguments map[string]string) ([]string, error) {
+		return []string{clusters.Name(ctx)}, nil
+	})
+	completer.Add(ArgumentBridge, auth.FamilyOVS, func(ctx context.Context, arguments map[string]string) ([]string, error) {
+		return nil, errors.New("pod not found")
+	})
+
+	tests := []struct {
+		name        string
+		req         *mcp.CompleteRequest
+		wantValues  []string
+		wantTotal   int
+		wantHasMore bool
+		wantErr     bool
+	}{
+		{name: "prefix", req: newRequest("", ArgumentPod, "ovnkube-node", map[string]string{ArgumentNamespace: "ovn-kubernetes"}),
+			wantValues: []string{"ovnkube-node-a", "ovnkube-node-b"}, wantTotal: 2},
+		{name: "empty value", req: newRequest("", ArgumentPod, "", map[string]string{ArgumentNamespace: "ovn-kubernetes"}),
+			wantValues: []string{"ovnkube-control-plane", "ovnkube-node-a", "ovnkube-node-b"}, wantTotal: 3},
+		{name: "other arguments", req: newRequest("", ArgumentPod, "", nil), wantValues: []string{"coredns"}, wantTotal: 1},
+		{name: "prefixed argument", req: newRequest("", "source_pod", "ovnkube-node",
+			map[string]string{"source_namespace": "ovn-kubernetes", ArgumentNamespace: "default"}),
+			wantValues: []string{"ovnkube-node-a", "ovnkube-node-b"}, wantTotal: 2},
+		{name: "unknown prefixed argument", req: newRequest("", "source_ip", "", nil), wantValues: []string{}},
+		{name: "no match", req: newRequest("", ArgumentPod, "etcd", nil), wantValues: []string{}},
+		{name: "truncated", req: newRequest("", ArgumentNode, "", nil), wantValues: many[:maxValues], wantTotal: 150, wantHasMore: true},
+		{name: "cluster", req: newRequest("", ArgumentCluster, "", map[string]string{ArgumentCluster: "east"}),
+			wantValues: []string{"east"}, wantTotal: 1},
+		{name: "unknown argument", req: newRequest("", "microflow", "", nil), wantValues: []string{}},
+		{name: "lookup error", req: newRequest("", ArgumentBridge, "", nil), wantErr: true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			result, err := completer.Complete(context.Background(), test.req)
+			if test.wantErr {
+				if err == nil {
+					t.Fatal("Expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(result.Completion.Values, test.wantValues) {
+				t.Fatalf("Expected values %v, got %v", test.wantValues, result.Completion.Values)
+			}
+			if result.Completion.Total != test.wantTotal || result.Completion.HasMore != test.wantHasMore {
+				t.Fatalf("Unexpected total %d and has more %v", result.Completion.Total, result.Completion.HasMore)
+			}
+		})
+	}
+}
+
+func TestFamily(t *testing.T) {
+	completer := NewCompleter(DefaultTTL)
+	completer.Add(ArgumentPod, auth.FamilyKubernetes, func(ctx context.Context, arguments map[string]string) ([]string, error) {
+		return nil, nil
+	})
+	completer.Add(ArgumentBridge, auth.FamilyOVS, func(ctx context.Context, arguments map[string]string) ([]string, error) {
+		return nil, nil
+	})
+	for argument, want := range map[string]string{
+		ArgumentPod:    auth.FamilyKubernetes,
+		"source_pod":   auth.FamilyKubernetes,
+		ArgumentBridge: auth.FamilyOVS,
+		"microflow":    "",
+	} {
+		if family := completer.Family(argument); family != want {
+			t.Fatalf("Expected family %q for argument %s, got %q", want, argument, family)
+		}
+	}
+}
+
+func TestCompleteCache(t *testing.T) {
+	now := time.Now()
+	completer := NewCompleter(time.Minute)
+	completer.now = func() time.Time { return now }
+	lookups := 0
+	fail := false
+	completer.Add(ArgumentNode, auth.FamilyKubernetes, func(ctx context.Context, arguments map[string]string) ([]string, error) {
+		if fail {
+			return nil, errors.New("connection refused")
+		}
+		lookups++
+		return []string{"worker-0"}, nil
+	})
+
+	complete := func(user string) {
+		t.Helper()
+		if _, err := completer.Complete(context.Background(), newRequest(user, ArgumentNode, "", nil)); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+	complete("alice")
+	complete("alice")
+	if lookups != 1 {
+		t.Fatalf("Expected the values to be cached, got %d lookups", lookups)
+	}
+	// The values are cached per caller.
+	complete("bob")
+	if lookups != 2 {
+		t.Fatalf("Expected a lookup for another caller, got %d lookups", lookups)
+	}
+	now = now.Add(2 * time.Minute)
+	complete("alice")
+	if lookups != 3 {
+		t.Fatalf("Expected the expired values to be looked up, got %d lookups", lookups)
+	}
+
+	// Failed lookups are not cached.
+	fail = true
+	now = now.Add(2 * time.Minute)
+	if _, err := completer.Complete(context.Background(), newRequest("alice", ArgumentNode, "", nil)); err == nil {
+		t.Fatal("Expected a lookup error")
+	}
+	fail = false
+	complete("alice")
+	if lookups != 4 {
+		t.Fatalf("Expected the failed lookup to be retried, got %d lookups", lookups)
+	}
+}
@@ -0,0 +1,209 @@
+package config
+
+import (
+	"fmt"
+	"maps"
+	"os"
+	"path"
+	"slices"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	yaml "sigs.k8s.io/yaml"
+
+	"github.com/ovn-kubernetes/ovn-kubernetes-mcp/pkg/kubernetes/client"
+	"github.com/ovn-kubernetes/ovn-kubernetes-mcp/pkg/pagination"
+)
+
+const (
+	// MaxLinesParam is the name of the tool parameter limiting the number of output lines.
+	MaxLinesParam = "max_lines"
+	// PageSizeParam is the name of the parameter of the paginated tools setting the
+	// number of results per page.
+	PageSizeParam = "page_size"
+)
+
+// Config is the declarative configuration of the MCP server. Fields that are not
+// set keep the value of the corresponding command-line flag, and flags set on the
+// command line take precedence over the file.
+type Config struct {
+	// Mode is the server mode: live-cluster, offline, dual or replay.
+	Mode string `json:"mode,omitempty"`
+	// Transport is the MCP transport: stdio or http.
+	Transport string `json:"transport,omitempty"`
+	// Host and Port are the address of the HTTP transport.
+	Host string `json:"host,omitempty"`
+	Port string `json:"port,omitempty"`
+	// Kubeconfig is the path to the kubeconfig file.
+	Kubeconfig string `json:"kubeconfig,omitempty"`
+	// Images are the container images used by the node debugging tools.
+	Images Images `json:"images,omitempty"`
+	// ToolTimeout is the default timeout of a tool call. Zero disables it.
+	ToolTimeout *metav1.Duration `json:"tool_timeout,omitempty"`
+	// Tools configures which tools are enabled and their per-tool settings.
+	Tools Tools `json:"tools,omitempty"`
+	// DebugPod is the template of the node debug pods.
+	DebugPod client.DebugPodTemplate `json:"debug_pod,omitempty"`
+}
+
+// Images contains the container images used by the node debugging tools.
+type Images struct {
+	Pwru    string `json:"pwru,omitempty"`
+	Tcpdump string `json:"tcpdump,omitempty"`
+	Kernel  string `json:"kernel,omitempty"`
+}
+
+// Tools configures which tools are enabled and their per-tool settings.
+type Tools struct {
+	// Allow lists the tools that are enabled, as path.Match patterns. All tools
+	// are enabled if empty.
+	Allow []string `json:"allow,omitempty"`
+	// Deny lists the tools that are disabled, as path.Match patterns. It takes
+	// precedence over Allow.
+	Deny []string `json:"deny,omitempty"`
+	// Settings are the per-tool settings, keyed by tool name.
+	Settings map[string]ToolSettings `json:"settings,omitempty"`
+}
+
+// ToolSettings are the settings of a single tool.
+type ToolSettings struct {
+	// Timeout overrides the default tool timeout. Zero disables it.
+	Timeout *metav1.Duration `json:"timeout,omitempty"`
+	// MaxLines is the default of the max_lines parameter of the tool, used when
+	// the caller does not set it.
+	MaxLines int `json:"max_lines,omitempty"`
+	// PageSize is the default of the page_size parameter of the paginated tool,
+	// used when the caller does not set it.
+	PageSize int `json:"page_size,omitempty"`
+}
+
+// Load reads and validates the configuration file at path. Unknown fields are
+// rejected.
+func Load(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+	cfg := &Config{}
+	if err := yaml.UnmarshalStrict(data, cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse config file %s: %w", path, err)
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid config file %s: %w", path, err)
+	}
+	return cfg, nil
+}
+
+// Validate checks that the configuration is consistent.
+func (c *Config) Validate() error {
+	if c.Mode != "" && !slices.Contains([]string{"live-cluster", "offline", "dual", "replay"}, c.Mode) {
+		return fmt.Errorf("invalid mode %q, valid modes are: live-cluster, offline, dual, replay", c.Mode)
+	}
+	if c.Transport != "" && !slices.Contains([]string{"stdio", "http"}, c.Transport) {
+		return fmt.Errorf("invalid transport %q, valid transports are: stdio, http", c.Transport)
+	}
+	if c.ToolTimeout != nil && c.ToolTimeout.Duration < 0 {
+		return fmt.Errorf("tool_timeout must not be negative")
+	}
+	if err := c.DebugPod.Validate(); err != nil {
+		return err
+	}
+	return c.Tools.Validate()
+}
+
+// Validate checks that the patterns and the per-tool settings are valid.
+func (t *Tools) Validate() error {
+	for _, pattern := range slices.Concat(t.Allow, t.Deny) {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("invalid tool pattern %q: %w", pattern, err)
+		}
+	}
+	for name, settings := range t.Settings {
+		if settings.Timeout != nil && settings.Timeout.Duration < 0 {
+			return fmt.Errorf("timeout of tool %s must not be negative", name)
+		}
+		if settings.MaxLines < 0 {
+			return fmt.Errorf("max_lines of tool %s must not be negative", name)
+		}
+		if settings.PageSize < 0 || settings.PageSize > pagination.MaxPageSize {
+			return fmt.Errorf("page_size of tool %s must be between 1 and %d", name, pagination.MaxPageSize)
+		}
+	}
+	return nil
+}
+
+// ValidateTools checks the per-tool settings against the registered tools, and the
+// names of the tools of all the modes: every configured tool must exist in some
+// mode, max_lines may only be set for tools accepting a max_lines parameter, and
+// page_size for the paginated tools. It returns warnings about the settings it
+// ignored or fixed: the settings of the tools of the other modes are ignored, so
+// that a file can be shared by the servers of all the modes, and max_lines set for
+// a paginated tool, which no longer has a max_lines parameter, is used as its
+// default page_size.
+func (t *Tools) ValidateTools(tools []*mcp.Tool, allTools []string) ([]string, error) {
+	registered := make(map[string]*mcp.Tool, len(tools))
+	for _, tool := range tools {
+		registered[tool.Name] = tool
+	}
+	var warnings []string
+	for _, name := range slices.Sorted(maps.Keys(t.Settings)) {
+		settings := t.Settings[name]
+		tool, ok := registered[name]
+		if !ok {
+			if !slices.Contains(allTools, name) {
+				return warnings, fmt.Errorf("settings for unknown tool %s", name)
+			}
+			warnings = append(warnings, fmt.Sprintf("ignoring the settings of tool %s, which is not available in this mode", name))
+			continue
+		}
+		if settings.MaxLines > 0 && !hasParam(tool, MaxLinesParam) {
+			if !hasParam(tool, PageSizeParam) {
+				return warnings, fmt.Errorf("tool %s does not accept the %s parameter", name, MaxLinesParam)
+			}
+			if settings.PageSize == 0 {
+				settings.PageSize = min(settings.MaxLines, pagination.MaxPageSize)
+			}
+			settings.MaxLines = 0
+			t.Settings[name] = settings
+			warnings = append(warnings, fmt.Sprintf("tool %s returns its results in pages and has no %s parameter, "+
+				"using %s %d: replace %s with %s in its settings", name, MaxLinesParam, PageSizeParam, settings.PageSize,
+				MaxLinesParam, PageSizeParam))
+		}
+		if settings.PageSize > 0 && !hasParam(tool, PageSizeParam) {
+			return warnings, fmt.Errorf("tool %s does not accept the %s parameter", name, PageSizeParam)
+		}
+	}
+	return warnings, nil
+}
+
+// hasParam returns true if the input schema of the tool has the parameter.
+func hasParam(tool *mcp.Tool, param string) bool {
+	schema, ok := tool.InputSchema.(map[string]any)
+	if !ok {
+		return false
+	}
+	properties, ok := schema["properties"].(map[string]any)
+	if !ok {
+		return false
+	}
+	_, ok = properties[param]
+	return ok
+}
+
+// Enabled returns true if the tool is allowed and not denied.
+func (t *Tools) Enabled(name string) bool {
+	if matchAny(t.Deny, name) {
+		return false
+	}
+	return len(t.Allow) == 0 || matchAny(t.Allow, name)
+}
+
+// matchAny returns true if the name matches any of the patterns.
+func matchAny(patterns []string, name string) bool {
+	for _, pattern := range patterns {
+		if matched, _ := path.Match(pattern, name); matched {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,220 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestLoad(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		wantErr bool
+	}{
+		{
+			name: "valid config",
+			content: `mode: dual
+transport: http
+host: 0.0.0.0
+port: "9090"
+images:
+  kernel: registry.example.com/netshoot:latest
+tool_timeout: 60s
+tools:
+  deny: ["pwru", "tcpdump"]
+  settings:
+    ovs-ofctl-dump-flows:
+      timeout: 5m
+      max_lines: 500
+debug_pod:
+  namespace: ovnk-debug
+  service_account_name: debug
+  image_pull_secrets: ["registry"]
+  priority_class_name: system-node-critical
+  labels:
+    team: network
+  resources:
+    limits:
+      memory: 256Mi
+  volumes:
+  - name: scratch
+    emptyDir: {}
+  volume_mounts:
+  - name: scratch
+    mountPath: /scratch
+`,
+		},
+		{
+			name: "debug pod mount of unknown volume",
+			content: `debug_pod:
+  volume_mounts:
+  - name: scratch
+    mountPath: /scratch
+`,
+			wantErr: true,
+		},
+		{
+			name:    "invalid mode",
+			content: "mode: remote\n",
+			wantErr: true,
+		},
+		{
+			name:    "invalid transport",
+			content: "transport: grpc\n",
+			wantErr: true,
+		},
+		{
+			name:    "negative timeout",
+			content: "tool_timeout: -1s\n",
+			wantErr: true,
+		},
+		{
+			name: "invalid pattern",
+			content: `tools:
+  allow: ["ovn-["]
+`,
+			wantErr: true,
+		},
+		{
+			name: "negative max_lines",
+			content: `tools:
+  settings:
+    ovn-show:
+      max_lines: -1
+`,
+			wantErr: true,
+		},
+		{
+			name:    "unknown field",
+			content: "tool-timeout: 60s\n",
+			wantErr: true,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "config.yaml")
+			if err := os.WriteFile(path, []byte(test.content), 0o600); err != nil {
+				t.Fatalf("Failed to write config file: %v", err)
+			}
+			_, err := Load(path)
+			if (err != nil) != test.wantErr {
+				t.Fatalf("Load() error = %v, wantErr %v", err, test.wantErr)
+			}
+		})
+	}
+}
+
+func TestToolsEnabled(t *testing.T) {
+	tests := []struct {
+		name    string
+		tools   Tools
+		tool    string
+		enabled bool
+	}{
+		{"no lists", Tools{}, "pwru", true},
+		{"denied", Tools{Deny: []string{"pwru"}}, "pwru", false},
+		{"not denied", Tools{Deny: []string{"pwru"}}, "tcpdump", true},
+		{"allowed by pattern", Tools{Allow: []string{"ovn-*"}}, "ovn-show", true},
+		{"not allowed", Tools{Allow: []string{"ovn-*"}}, "ovs-list-br", false},
+		{"deny takes precedence", Tools{Allow: []string{"ovn-*"}, Deny: []string{"ovn-trace"}}, "ovn-trace", false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := test.tools.Enabled(test.tool); got != test.enabled {
+				t.Fatalf("Enabled(%q) = %v, want %v", test.tool, got, test.enabled)
+			}
+		})
+	}
+}
+
+func TestToolsValidateTools(t *testing.T) {
+	registered := []*mcp.Tool{
+		{Name: "ovn-show", InputSchema: map[string]any{"type": "object", "properties": map[string]any{"max_lines": map[string]any{"type": "integer"}}}},
+		{Name: "pod-logs", InputSchema: map[string]any{"type": "object", "properties": map[string]any{"name": map[string]any{"type": "string"}}}},
+		{Name: "ovn-get", InputSchema: map[string]any{"type": "object", "properties": map[string]any{"page_size": map[string]any{"type": "integer"}}}},
+	}
+	tests := []struct {
+		name         string
+		settings     map[string]ToolSettings
+		wantSettings map[string]ToolSettings
+		wantWarnings int
+		wantErr      bool
+	}{
+		{name: "max_lines on supported tool", settings: map[string]ToolSettings{"ovn-show": {MaxLines: 10}}},
+		{name: "timeout on any tool", settings: map[string]ToolSettings{"pod-logs": {Timeout: &metav1.Duration{Duration: time.Minute}}}},
+		{name: "max_lines on unsupported tool", settings: map[string]ToolSettings{"pod-logs": {MaxLines: 10}}, wantErr: true},
+		{name: "page_size on paginated tool", settings: map[string]ToolSettings{"ovn-get": {PageSize: 500}}},
+		{name: "page_size on unsupported tool", settings: map[string]ToolSettings{"ovn-show": {PageSize: 500}}, wantErr: true},
+		{
+			name:         "max_lines on paginated tool",
+			settings:     map[string]ToolSettings{"ovn-get": {MaxLines: 500}},
+			wantSettings: map[string]ToolSettings{"ovn-get": {PageSize: 500}},
+			wantWarnings: 1,
+		},
+		{
+			name:         "max_lines above the maximum page size",
+			settings:     map[string]ToolSettings{"ovn-get": {MaxLines: 5000}},
+			wantSettings: map[string]ToolSettings{"ovn-get": {PageSize: 1000}},
+			wantWarnings: 1,
+		},
+		{name: "unknown tool", settings: map[string]ToolSettings{"ovn-unknown": {MaxLines: 10}}, wantErr: true},
+		{
+			name:         "tool of another mode",
+			settings:     map[string]ToolSettings{"sos-search-commands": {Timeout: &metav1.Duration{Duration: time.Minute}}},
+			wantWarnings: 1,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			tools := Tools{Settings: test.settings}
+			warnings, err := tools.ValidateTools(registered, []string{"ovn-show", "pod-logs", "ovn-get", "sos-search-commands"})
+			if (err != nil) != test.wantErr {
+				t.Fatalf("ValidateTools() error = %v, wantErr %v", err, test.wantErr)
+			}
+			if len(warnings) != test.wantWarnings {
+				t.Fatalf("Expected %d warnings, got %v", test.wantWarnings, warnings)
+			}
+			if test.wantSettings != nil && !reflect.DeepEqual(tools.Settings, test.wantSettings) {
+				t.Fatalf("Expected settings %+v, got %+v", test.wantSettings, tools.Settings)
+			}
+		})
+	}
+}
+
+func TestToolStore(t *testing.T) {
+	store := NewToolStore(Tools{
+		Deny: []string{"pwru"},
+		Settings: map[string]ToolSettings{
+			"sos-report": {Timeout: &metav1.Duration{Duration: 10 * time.Minute}},
+			"tcpdump":    {Timeout: &metav1.Duration{}},
+			"ovn-show":   {MaxLines: 50},
+		},
+	}, time.Minute)
+
+	if store.Enabled("pwru") || !store.Enabled("ovn-show") {
+		t.Fatal("Unexpected enabled tools")
+	}
+	if got := store.Timeout("sos-report"); got != 10*time.Minute {
+		t.Fatalf("Expected the per-tool timeout, got %v", got)
+	}
+	if got := store.Timeout("tcpdump"); got != 0 {
+		t.Fatalf("Expected no timeout, got %v", got)
+	}
+	if got := store.Timeout("ovn-show"); got != time.Minute {
+		t.Fatalf("Expected the default timeout, got %v", got)
+	}
+	if got := store.MaxLines("ovn-show"); got != 50 {
+		t.Fatalf("Expected max_lines 50, got %d", got)
+	}
+
+	store.Set(Tools{}, 2*time.Minute)
+	if !store.Enabled("pwru") || store.Timeout("sos-report") != 2*time.Minute || store.MaxLines("ovn-show") != 0 {
+		t.Fatal("Expected the updated configuration to be in effect")
+	}
+}
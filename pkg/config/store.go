@@ -0,0 +1,56 @@
+package config
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// toolState is an immutable snapshot of the tool configuration.
+type toolState struct {
+	tools          Tools
+	defaultTimeout time.Duration
+}
+
+// ToolStore holds the tool configuration in effect. It is safe for concurrent use
+// and can be updated while the server is running, for example on a reload of the
+// configuration file.
+type ToolStore struct {
+	state atomic.Pointer[toolState]
+}
+
+// NewToolStore returns a store with the given tool configuration and default tool
+// timeout.
+func NewToolStore(tools Tools, defaultTimeout time.Duration) *ToolStore {
+	s := &ToolStore{}
+	s.Set(tools, defaultTimeout)
+	return s
+}
+
+// Set replaces the tool configuration and default tool timeout.
+func (s *ToolStore) Set(tools Tools, defaultTimeout time.Duration) {
+	s.state.Store(&toolState{tools: tools, defaultTimeout: defaultTimeout})
+}
+
+// Enabled returns true if the tool is enabled.
+func (s *ToolStore) Enabled(name string) bool {
+	return s.state.Load().tools.Enabled(name)
+}
+
+// Timeout returns the timeout of the tool, or zero if it has no timeout.
+func (s *ToolStore) Timeout(name string) time.Duration {
+	state := s.state.Load()
+	if settings, ok := state.tools.Settings[name]; ok && settings.Timeout != nil {
+		return settings.Timeout.Duration
+	}
+	return state.defaultTimeout
+}
+
+// MaxLines returns the default max_lines of the tool, or zero if it has none.
+func (s *ToolStore) MaxLines(name string) int {
+	return s.state.Load().tools.Settings[name].MaxLines
+}
+
+// PageSize returns the default page_size of the tool, or zero if it has none.
+func (s *ToolStore) PageSize(name string) int {
+	return s.state.Load().tools.Settings[name].PageSize
+}
@@ -0,0 +1,34 @@
+// Package executor defines how the live-cluster tools run their commands in the
+// data plane: through the Kubernetes API, in pods and node debug pods, or directly
+// on the local host when the server runs on a node or on a host without
+// Kubernetes.
+package executor
+
+import (
+	"context"
+	"io"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	k8stypes "github.com/ovn-kubernetes/ovn-kubernetes-mcp/pkg/kubernetes/types"
+)
+
+// StreamFunc runs a command until it exits or ctx is cancelled, writing its output
+// to stdout and stderr as it is produced.
+type StreamFunc func(ctx context.Context, stdout, stderr io.Writer) error
+
+// Executor runs the commands of the OVN, OVS, kernel and network tools. The
+// commands targeting a pod, like ovn-nbctl in an ovnkube pod, are run with
+// ExecPod, and the commands targeting a node, like ip or nft, with DebugNode.
+// The commands are recorded in the audit log of the tool call.
+type Executor interface {
+	// ExecPod runs a command in a pod.
+	ExecPod(ctx context.Context, req *mcp.CallToolRequest, in k8stypes.ExecPodParams) (*mcp.CallToolResult, k8stypes.ExecPodResult, error)
+	// PrepareExecPod validates a command to run in a pod in the background, and
+	// returns a function running it.
+	PrepareExecPod(ctx context.Context, in k8stypes.ExecPodParams) (StreamFunc, error)
+	// DebugNode runs a command on a node, in the image of the parameters.
+	DebugNode(ctx context.Context, req *mcp.CallToolRequest, in k8stypes.DebugNodeParams) (*mcp.CallToolResult, k8stypes.DebugNodeResult, error)
+	// PrepareDebugNode validates a command to run on a node in the background,
+	// and returns a function running it.
+	PrepareDebugNode(ctx context.Context, in k8stypes.DebugNodeParams) (StreamFunc, error)
+}
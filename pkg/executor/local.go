@@ -0,0 +1,209 @@
+package executor
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"syscall"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/ovn-kubernetes/ovn-kubernetes-mcp/pkg/audit"
+	"github.com/ovn-kubernetes/ovn-kubernetes-mcp/pkg/clusters"
+	k8stypes "github.com/ovn-kubernetes/ovn-kubernetes-mcp/pkg/kubernetes/types"
+	"github.com/ovn-kubernetes/ovn-kubernetes-mcp/pkg/progress"
+	"github.com/ovn-kubernetes/ovn-kubernetes-mcp/pkg/topology"
+)
+
+// stopGracePeriod is how long a command is given to exit after it is asked to stop,
+// for example to flush the packets captured by tcpdump, before it is killed.
+const stopGracePeriod = 5 * time.Second
+
+// LocalConfig is the configuration of the local executor.
+type LocalConfig struct {
+	// NodeName is the name of the node the server runs on, the hostname if it is
+	// not set. The commands targeting another node are rejected.
+	NodeName string
+}
+
+// Local is an executor running the commands directly on the local host, where the
+// OVN and OVS daemons and their databases run. The OVN and OVS pods resolved by
+// Resolve are the local host, other pods are not reachable. The image of the
+// commands is ignored: the binaries, like ovn-nbctl, ovs-ofctl, ip or nft, are
+// looked up in the PATH of the server.
+type Local struct {
+	cfg LocalConfig
+}
+
+var (
+	_ Executor             = &Local{}
+	_ topology.PodResolver = &Local{}
+)
+
+// NewLocal creates an executor running the commands on the local host.
+func NewLocal(cfg LocalConfig) (*Local, error) {
+	if cfg.NodeName == "" {
+		hostname, err := os.Hostname()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get the hostname as the node name: %w", err)
+		}
+		cfg.NodeName = hostname
+	}
+	return &Local{cfg: cfg}, nil
+}
+
+// checkNode returns an error if the node is not the local node.
+func (l *Local) checkNode(node string) error {
+	if node != l.cfg.NodeName {
+		return fmt.Errorf("node %s is not reachable: the server runs commands on node %s only", node, l.cfg.NodeName)
+	}
+	return nil
+}
+
+// checkPod returns an error if the command targets a pod: only the OVN and OVS
+// daemons resolved to the local host by Resolve, without a pod, are reachable.
+func checkPod(in k8stypes.ExecPodParams) error {
+	if in.Name != "" {
+		return fmt.Errorf("pod %s/%s is not reachable: the server runs commands on the local host only", in.Namespace, in.Name)
+	}
+	return nil
+}
+
+// checkCluster returns an error if the tool call targets a cluster: the commands
+// run on the local host only.
+func checkCluster(ctx context.Context) error {
+	if name := clusters.Name(ctx); name != "" {
+		return fmt.Errorf("cluster %s is not reachable: the server runs commands on the local host only", name)
+	}
+	return nil
+}
+
+// checkPaths returns an error if a host path must be mounted at another path:
+// the commands on the local host see the host paths only.
+func checkPaths(hostPath, mountPath string) error {
+	if hostPath != mountPath {
+		return fmt.Errorf("mounting host path %q at %q is not supported when running commands on the local host", hostPath, mountPath)
+	}
+	return nil
+}
+
+// Resolve returns the local host, without a pod, as the OVN or OVS pod of the
+// target, after checking that its node, if any, is the local node.
+func (l *Local) Resolve(ctx context.Context, req *mcp.CallToolRequest, target k8stypes.PodTargetParams,
+	command string) (k8stypes.ExecPodParams, error) {
+	if target.Node != "" {
+		if err := l.checkNode(target.Node); err != nil {
+			return k8stypes.ExecPodParams{}, err
+		}
+	}
+	return k8stypes.ExecPodParams{}, nil
+}
+
+func (l *Local) ExecPod(ctx context.Context, req *mcp.CallToolRequest, in k8stypes.ExecPodParams) (*mcp.CallToolResult, k8stypes.ExecPodResult, error) {
+	if err := checkCluster(ctx); err != nil {
+		return nil, k8stypes.ExecPodResult{}, err
+	}
+	if err := checkPod(in); err != nil {
+		return nil, k8stypes.ExecPodResult{}, err
+	}
+	audit.RecordCommand(ctx, audit.Command{Node: l.cfg.NodeName, Command: in.Command})
+	stdout, stderr, err := run(ctx, in.Command)
+	if err != nil {
+		return nil, k8stypes.ExecPodResult{}, err
+	}
+	return nil, k8stypes.ExecPodResult{Stdout: stdout, Stderr: stderr}, nil
+}
+
+func (l *Local) PrepareExecPod(ctx context.Context, in k8stypes.ExecPodParams) (StreamFunc, error) {
+	if err := checkCluster(ctx); err != nil {
+		return nil, err
+	}
+	if err := checkPod(in); err != nil {
+		return nil, err
+	}
+	audit.RecordCommand(ctx, audit.Command{Node: l.cfg.NodeName, Command: in.Command})
+	return func(ctx context.Context, stdout, stderr io.Writer) error {
+		return stream(ctx, in.Command, stdout, stderr)
+	}, nil
+}
+
+func (l *Local) DebugNode(ctx context.Context, req *mcp.CallToolRequest, in k8stypes.DebugNodeParams) (*mcp.CallToolResult, k8stypes.DebugNodeResult, error) {
+	if err := checkCluster(ctx); err != nil {
+		return nil, k8stypes.DebugNodeResult{}, err
+	}
+	if err := l.checkNode(in.Name); err != nil {
+		return nil, k8stypes.DebugNodeResult{}, err
+	}
+	if err := checkPaths(in.HostPath, in.MountPath); err != nil {
+		return nil, k8stypes.DebugNodeResult{}, err
+	}
+	audit.RecordCommand(ctx, audit.Command{Node: l.cfg.NodeName, Command: in.Command})
+	stdout, stderr, err := run(ctx, in.Command)
+	if err != nil {
+		return nil, k8stypes.DebugNodeResult{}, err
+	}
+	return nil, k8stypes.DebugNodeResult{Stdout: stdout, Stderr: stderr}, nil
+}
+
+func (l *Local) PrepareDebugNode(ctx context.Context, in k8stypes.DebugNodeParams) (StreamFunc, error) {
+	if err := checkCluster(ctx); err != nil {
+		return nil, err
+	}
+	if err := l.checkNode(in.Name); err != nil {
+		return nil, err
+	}
+	if err := checkPaths(in.HostPath, in.MountPath); err != nil {
+		return nil, err
+	}
+	audit.RecordCommand(ctx, audit.Command{Node: l.cfg.NodeName, Command: in.Command})
+	return func(ctx context.Context, stdout, stderr io.Writer) error {
+		return stream(ctx, in.Command, stdout, stderr)
+	}, nil
+}
+
+// run runs a command and returns its output.
+func run(ctx context.Context, command []string) (string, string, error) {
+	var stdout, stderr bytes.Buffer
+	// Report the progress of the commands producing output over time, like captures.
+	stdoutWriter, reportOutput := progress.LineWriter(ctx, &stdout)
+	err := stream(ctx, command, stdoutWriter, &stderr)
+	reportOutput()
+	if err != nil {
+		return "", "", err
+	}
+	return stdout.String(), stderr.String(), nil
+}
+
+// stream runs a command, writing its output to stdout and stderr as it is produced,
+// until it exits or ctx is cancelled. A cancelled command is terminated, and killed
+// if it does not exit within stopGracePeriod.
+func stream(ctx context.Context, command []string, stdout, stderr io.Writer) error {
+	if len(command) == 0 {
+		return errors.New("command is required")
+	}
+	cmd := exec.CommandContext(ctx, command[0], command[1:]...)
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+	cmd.Cancel = func() error {
+		return cmd.Process.Signal(syscall.SIGTERM)
+	}
+	cmd.WaitDelay = stopGracePeriod
+
+	progress.Report(ctx, "Running command on the local host")
+	err := cmd.Run()
+	var exitErr *exec.ExitError
+	switch {
+	case ctx.Err() != nil:
+		return ctx.Err()
+	case errors.As(err, &exitErr):
+		// Same error as a command run in a pod.
+		return fmt.Errorf("command terminated with exit code %d", exitErr.ExitCode())
+	case err != nil:
+		return fmt.Errorf("failed to run command %v: %w", command, err)
+	}
+	return nil
+}
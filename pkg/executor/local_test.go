@@ -0,0 +1,161 @@
+package executor
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ovn-kubernetes/ovn-kubernetes-mcp/pkg/clusters"
+	k8stypes "github.com/ovn-kubernetes/ovn-kubernetes-mcp/pkg/kubernetes/types"
+)
+
+// newLocal returns a local executor, failing the test on error.
+func newLocal(t *testing.T, cfg LocalConfig) *Local {
+	t.Helper()
+	local, err := NewLocal(cfg)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	return local
+}
+
+func TestLocalExecPod(t *testing.T) {
+	local := newLocal(t, LocalConfig{NodeName: "worker-0"})
+	tests := []struct {
+		name       string
+		command    []string
+		wantStdout string
+		wantStderr string
+		wantErr    string
+	}{
+		{name: "stdout", command: []string{"echo", "br-int"}, wantStdout: "br-int\n"},
+		{name: "stderr", command: []string{"sh", "-c", "echo warning >&2"}, wantStderr: "warning\n"},
+		{name: "exit code", command: []string{"sh", "-c", "exit 3"}, wantErr: "command terminated with exit code 3"},
+		{name: "unknown binary", command: []string{"ovn-nbctl-does-not-exist"}, wantErr: "failed to run command"},
+		{name: "no command", wantErr: "command is required"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, result, err := local.ExecPod(context.Background(), nil, k8stypes.ExecPodParams{Command: test.command})
+			if test.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), test.wantErr) {
+					t.Fatalf("Expected error %q, got %v", test.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if result.Stdout != test.wantStdout || result.Stderr != test.wantStderr {
+				t.Fatalf("Unexpected result %+v", result)
+			}
+		})
+	}
+}
+
+func TestLocalExecPodInPod(t *testing.T) {
+	local := newLocal(t, LocalConfig{NodeName: "worker-0"})
+	// Commands in pods other than the ones resolved to the host are rejected
+	// instead of running on the host.
+	in := k8stypes.ExecPodParams{Command: []string{"true"}}
+	in.Namespace, in.Name = "default", "client"
+	if _, _, err := local.ExecPod(context.Background(), nil, in); err == nil {
+		t.Fatal("Expected an error for a pod")
+	}
+	if _, err := local.PrepareExecPod(context.Background(), in); err == nil {
+		t.Fatal("Expected a prepare error for a pod")
+	}
+
+	pod, err := local.Resolve(context.Background(), nil, k8stypes.PodTargetParams{Namespace: "ovn-kubernetes", Name: "ovnkube-node-abc"}, "ovn-nbctl")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	pod.Command = []string{"true"}
+	if _, _, err := local.ExecPod(context.Background(), nil, pod); err != nil {
+		t.Fatalf("Unexpected error for the resolved pod: %v", err)
+	}
+}
+
+func TestLocalDebugNode(t *testing.T) {
+	hostname, err := os.Hostname()
+	if err != nil {
+		t.Fatalf("Failed to get the hostname: %v", err)
+	}
+	tests := []struct {
+		name     string
+		nodeName string
+		in       k8stypes.DebugNodeParams
+		cluster  string
+		wantErr  bool
+	}{
+		{name: "local node", nodeName: "worker-0", in: k8stypes.DebugNodeParams{Name: "worker-0", Command: []string{"true"}}},
+		{name: "other node", nodeName: "worker-0", in: k8stypes.DebugNodeParams{Name: "worker-1", Command: []string{"true"}}, wantErr: true},
+		{name: "hostname by default", in: k8stypes.DebugNodeParams{Name: hostname, Command: []string{"true"}}},
+		{name: "other node than the hostname", in: k8stypes.DebugNodeParams{Name: hostname + "-other", Command: []string{"true"}},
+			wantErr: true},
+		{name: "same host path", nodeName: "worker-0", in: k8stypes.DebugNodeParams{Name: "worker-0", Command: []string{"true"},
+			HostPath: "/sys/kernel/debug", MountPath: "/sys/kernel/debug"}},
+		{name: "other mount path", nodeName: "worker-0", in: k8stypes.DebugNodeParams{Name: "worker-0", Command: []string{"true"},
+			HostPath: "/var/log", MountPath: "/host/var/log"}, wantErr: true},
+		{name: "cluster", nodeName: "worker-0", cluster: "east", in: k8stypes.DebugNodeParams{Name: "worker-0", Command: []string{"true"}},
+			wantErr: true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			local := newLocal(t, LocalConfig{NodeName: test.nodeName})
+			ctx := clusters.WithName(context.Background(), test.cluster)
+			_, _, err := local.DebugNode(ctx, nil, test.in)
+			if (err != nil) != test.wantErr {
+				t.Fatalf("Unexpected error %v", err)
+			}
+			_, err = local.PrepareDebugNode(ctx, test.in)
+			if (err != nil) != test.wantErr {
+				t.Fatalf("Unexpected prepare error %v", err)
+			}
+		})
+	}
+}
+
+func TestLocalResolve(t *testing.T) {
+	local := newLocal(t, LocalConfig{NodeName: "worker-0"})
+	if _, err := local.Resolve(context.Background(), nil, k8stypes.PodTargetParams{Node: "worker-0"}, "ovs-vsctl"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if _, err := local.Resolve(context.Background(), nil, k8stypes.PodTargetParams{Node: "worker-1"}, "ovs-vsctl"); err == nil {
+		t.Fatal("Expected an error for another node")
+	}
+	pod, err := local.Resolve(context.Background(), nil, k8stypes.PodTargetParams{Namespace: "ovn-kubernetes", Name: "ovnkube-node-abc"}, "ovn-nbctl")
+	if err != nil || pod.Name != "" {
+		t.Fatalf("Expected the local host, got pod %+v and error %v", pod, err)
+	}
+}
+
+func TestLocalStream(t *testing.T) {
+	local := newLocal(t, LocalConfig{NodeName: "worker-0"})
+	run, err := local.PrepareDebugNode(context.Background(), k8stypes.DebugNodeParams{
+		Name:    "worker-0",
+		Command: []string{"sh", "-c", "echo started; exec sleep 10"},
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	// A cancelled command is stopped, keeping the output it produced.
+	ctx, cancel := context.WithTimeout(context.Background(), 500*time.Millisecond)
+	defer cancel()
+	var stdout, stderr bytes.Buffer
+	start := time.Now()
+	err = run(ctx, &stdout, &stderr)
+	if err != context.DeadlineExceeded {
+		t.Fatalf("Expected the deadline to be exceeded, got %v", err)
+	}
+	if stdout.String() != "started\n" {
+		t.Fatalf("Unexpected output %q", stdout.String())
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Fatalf("The command was stopped after %v", elapsed)
+	}
+}
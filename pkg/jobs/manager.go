@@ -0,0 +1,356 @@
+// Package jobs runs long captures and traces in the background, beyond the timeout
+// of a tool call, and keeps their output for the clients to fetch it.
+package jobs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/rand"
+)
+
+// State is the state of a job.
+type State string
+
+const (
+	StateRunning   State = "running"
+	StateSucceeded State = "succeeded"
+	StateFailed    State = "failed"
+	// StateStopped is the state of the jobs stopped by a client, or by the maximum
+	// duration or output size.
+	StateStopped State = "stopped"
+)
+
+const (
+	DefaultMaxDuration    = time.Hour
+	DefaultMaxOutputBytes = 10 * 1024 * 1024
+	DefaultMaxRunning     = 5
+	// DefaultRetention is how long finished jobs and their output are kept.
+	DefaultRetention = time.Hour
+)
+
+// Config contains the limits of the jobs.
+type Config struct {
+	// MaxDuration is the maximum duration of a job.
+	MaxDuration time.Duration
+	// MaxOutputBytes is the maximum size of the output kept for a job. The job is
+	// stopped once it is reached.
+	MaxOutputBytes int
+	// MaxRunning is the maximum number of jobs running at the same time.
+	MaxRunning int
+	// Retention is how long finished jobs are kept.
+	Retention time.Duration
+}
+
+// RunFunc runs the command of a job until it exits or ctx is cancelled, writing its
+// output to output. The writer is safe for concurrent use.
+type RunFunc func(ctx context.Context, output io.Writer) error
+
+// Status is the status of a job.
+type Status struct {
+	ID          string     `json:"id"`
+	Tool        string     `json:"tool"`
+	Description string     `json:"description"`
+	State       State      `json:"state"`
+	StartTime   time.Time  `json:"start_time"`
+	EndTime     *time.Time `json:"end_time,omitempty"`
+	// OutputBytes is the size of the output kept so far.
+	OutputBytes int `json:"output_bytes"`
+	// StopReason explains why a job was stopped.
+	StopReason string `json:"stop_reason,omitempty"`
+	Error      string `json:"error,omitempty"`
+}
+
+// Output is a range of the output of a job.
+type Output struct {
+	Output string `json:"output"`
+	// Offset is the offset of Output in the output of the job, and NextOffset the
+	// offset to fetch the next range from.
+	Offset     int `json:"offset"`
+	NextOffset int `json:"next_offset"`
+	// Complete is true once the job is finished and its whole output was fetched.
+	Complete bool  `json:"complete"`
+	State    State `json:"state"`
+}
+
+// job is a command running in the background.
+type job struct {
+	mu     sync.Mutex
+	status Status
+	output []byte
+	// owner is the user who started the job, empty without authentication. Only
+	// the owner can see, read and stop the job.
+	owner string
+
+	cancel context.CancelCauseFunc
+	done   chan struct{}
+}
+
+// stopError is the cancellation cause of a job stopped before its command exited.
+type stopError struct {
+	reason string
+}
+
+func (e *stopError) Error() string {
+	return e.reason
+}
+
+// Manager runs the jobs and keeps them until their retention expires.
+type Manager struct {
+	cfg Config
+
+	mu   sync.Mutex
+	jobs map[string]*job
+	now  func() time.Time
+}
+
+// NewManager returns a job manager. Zero limits take their default value.
+func NewManager(cfg Config) *Manager {
+	if cfg.MaxDuration <= 0 {
+		cfg.MaxDuration = DefaultMaxDuration
+	}
+	if cfg.MaxOutputBytes <= 0 {
+		cfg.MaxOutputBytes = DefaultMaxOutputBytes
+	}
+	if cfg.MaxRunning <= 0 {
+		cfg.MaxRunning = DefaultMaxRunning
+	}
+	if cfg.Retention <= 0 {
+		cfg.Retention = DefaultRetention
+	}
+	return &Manager{cfg: cfg, jobs: map[string]*job{}, now: time.Now}
+}
+
+// MaxDuration returns the maximum duration of a job.
+func (m *Manager) MaxDuration() time.Duration {
+	return m.cfg.MaxDuration
+}
+
+// Start runs a job of the tool for the owner in the background for at most
+// maxDuration, or the maximum duration of the manager if zero or longer.
+func (m *Manager) Start(tool, owner, description string, maxDuration time.Duration, run RunFunc) (Status, error) {
+	if maxDuration <= 0 || maxDuration > m.cfg.MaxDuration {
+		maxDuration = m.cfg.MaxDuration
+	}
+
+	m.mu.Lock()
+	m.pruneLocked()
+	running := 0
+	for _, j := range m.jobs {
+		if j.snapshot().State == StateRunning {
+			running++
+		}
+	}
+	if running >= m.cfg.MaxRunning {
+		m.mu.Unlock()
+		return Status{}, fmt.Errorf("%d jobs are already running, stop one of them or wait for it to finish", running)
+	}
+	id := m.newIDLocked()
+	ctx, cancel := context.WithCancelCause(context.Background())
+	j := &job{
+		status: Status{
+			ID:          id,
+			Tool:        tool,
+			Description: description,
+			State:       StateRunning,
+			StartTime:   m.now(),
+		},
+		owner:  owner,
+		cancel: cancel,
+		done:   make(chan struct{}),
+	}
+	m.jobs[id] = j
+	m.mu.Unlock()
+
+	go j.run(ctx, maxDuration, m.cfg.MaxOutputBytes, run, m.now)
+	return j.snapshot(), nil
+}
+
+// newIDLocked returns an unused job ID.
+func (m *Manager) newIDLocked() string {
+	for {
+		id := "job-" + rand.String(8)
+		if _, ok := m.jobs[id]; !ok {
+			return id
+		}
+	}
+}
+
+// run runs the command of the job and records its result.
+func (j *job) run(ctx context.Context, maxDuration time.Duration, maxOutputBytes int, run RunFunc, now func() time.Time) {
+	defer close(j.done)
+	defer j.cancel(nil)
+	timer := time.AfterFunc(maxDuration, func() {
+		j.cancel(&stopError{reason: fmt.Sprintf("maximum duration of %s reached", maxDuration)})
+	})
+	defer timer.Stop()
+
+	err := run(ctx, &jobWriter{job: j, maxBytes: maxOutputBytes})
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	endTime := now()
+	j.status.EndTime = &endTime
+	var stopErr *stopError
+	switch cause := context.Cause(ctx); {
+	case errors.As(cause, &stopErr):
+		j.status.State = StateStopped
+		j.status.StopReason = stopErr.reason
+	case err != nil:
+		j.status.State = StateFailed
+		j.status.Error = err.Error()
+	default:
+		j.status.State = StateSucceeded
+	}
+}
+
+// snapshot returns a copy of the status of the job.
+func (j *job) snapshot() Status {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	status := j.status
+	status.OutputBytes = len(j.output)
+	return status
+}
+
+// jobWriter keeps the output of a job, and stops the job once the maximum output
+// size is reached.
+type jobWriter struct {
+	job      *job
+	maxBytes int
+}
+
+func (w *jobWriter) Write(p []byte) (int, error) {
+	j := w.job
+	j.mu.Lock()
+	room := w.maxBytes - len(j.output)
+	if room <= 0 {
+		j.mu.Unlock()
+		return 0, io.ErrShortWrite
+	}
+	n := min(len(p), room)
+	j.output = append(j.output, p[:n]...)
+	j.mu.Unlock()
+	if n < len(p) {
+		j.cancel(&stopError{reason: fmt.Sprintf("maximum output size of %d bytes reached", w.maxBytes)})
+		return n, io.ErrShortWrite
+	}
+	return n, nil
+}
+
+// get returns the job with the ID if it belongs to the owner.
+func (m *Manager) get(id, owner string) (*job, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.pruneLocked()
+	j, ok := m.jobs[id]
+	// Do not tell the other users that the job exists.
+	if !ok || j.owner != owner {
+		return nil, fmt.Errorf("job %s not found, finished jobs are kept for %s", id, m.cfg.Retention)
+	}
+	return j, nil
+}
+
+// Status returns the status of a job of the owner.
+func (m *Manager) Status(id, owner string) (Status, error) {
+	j, err := m.get(id, owner)
+	if err != nil {
+		return Status{}, err
+	}
+	return j.snapshot(), nil
+}
+
+// List returns the status of the jobs of the owner, the most recent first.
+func (m *Manager) List(owner string) []Status {
+	m.mu.Lock()
+	m.pruneLocked()
+	statuses := []Status{}
+	for _, j := range m.jobs {
+		if j.owner == owner {
+			statuses = append(statuses, j.snapshot())
+		}
+	}
+	m.mu.Unlock()
+	slices.SortFunc(statuses, func(a, b Status) int {
+		if c := b.StartTime.Compare(a.StartTime); c != 0 {
+			return c
+		}
+		return strings.Compare(a.ID, b.ID)
+	})
+	return statuses
+}
+
+// Output returns at most maxBytes of the output of a job of the owner from
+// offset. The output of a running job can be fetched while it grows.
+func (m *Manager) Output(id, owner string, offset, maxBytes int) (Output, error) {
+	j, err := m.get(id, owner)
+	if err != nil {
+		return Output{}, err
+	}
+	if offset < 0 || maxBytes < 0 {
+		return Output{}, fmt.Errorf("offset and max_bytes must not be negative")
+	}
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	offset = min(offset, len(j.output))
+	end := len(j.output)
+	if maxBytes > 0 {
+		end = min(end, offset+maxBytes)
+	}
+	return Output{
+		Output:     string(j.output[offset:end]),
+		Offset:     offset,
+		NextOffset: end,
+		Complete:   j.status.State != StateRunning && end == len(j.output),
+		State:      j.status.State,
+	}, nil
+}
+
+// Stop stops a running job of the owner and waits for its command to be cleaned
+// up, or for ctx to be done.
+func (m *Manager) Stop(ctx context.Context, id, owner string) (Status, error) {
+	j, err := m.get(id, owner)
+	if err != nil {
+		return Status{}, err
+	}
+	j.cancel(&stopError{reason: "stopped by client"})
+	select {
+	case <-j.done:
+	case <-ctx.Done():
+		return Status{}, ctx.Err()
+	}
+	return j.snapshot(), nil
+}
+
+// Close stops all the running jobs and waits for their commands to be cleaned up.
+func (m *Manager) Close() {
+	m.mu.Lock()
+	jobs := make([]*job, 0, len(m.jobs))
+	for _, j := range m.jobs {
+		jobs = append(jobs, j)
+	}
+	m.mu.Unlock()
+	for _, j := range jobs {
+		j.cancel(&stopError{reason: "server shutting down"})
+	}
+	for _, j := range jobs {
+		<-j.done
+	}
+}
+
+// pruneLocked forgets the jobs finished for longer than the retention.
+func (m *Manager) pruneLocked() {
+	now := m.now()
+	for id, j := range m.jobs {
+		status := j.snapshot()
+		if status.EndTime != nil && now.Sub(*status.EndTime) > m.cfg.Retention {
+			delete(m.jobs, id)
+		}
+	}
+}
@@ -0,0 +1,175 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+// waitFor waits for a job to finish.
+func waitFor(t *testing.T, m *Manager, id string) Status {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		status, err := m.Status(id, "")
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if status.State != StateRunning {
+			return status
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("Job %s did not finish", id)
+	return Status{}
+}
+
+// untilCancelled writes a line and blocks until the job is cancelled.
+func untilCancelled(ctx context.Context, output io.Writer) error {
+	if _, err := io.WriteString(output, "listening\n"); err != nil {
+		return err
+	}
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+func TestManager(t *testing.T) {
+	t.Run("keeps the output of finished jobs", func(t *testing.T) {
+		m := NewManager(Config{})
+		status, err := m.Start("tcpdump-start", "", "tcpdump on node worker-0", 0, func(ctx context.Context, output io.Writer) error {
+			_, err := io.WriteString(output, "packet 1\npacket 2\n")
+			return err
+		})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if status = waitFor(t, m, status.ID); status.State != StateSucceeded || status.OutputBytes != 18 {
+			t.Fatalf("Unexpected status: %+v", status)
+		}
+
+		output, err := m.Output(status.ID, "", 0, 9)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if output.Output != "packet 1\n" || output.NextOffset != 9 || output.Complete {
+			t.Fatalf("Unexpected first range: %+v", output)
+		}
+		output, err = m.Output(status.ID, "", output.NextOffset, 0)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if output.Output != "packet 2\n" || !output.Complete {
+			t.Fatalf("Unexpected last range: %+v", output)
+		}
+	})
+
+	t.Run("records failures", func(t *testing.T) {
+		m := NewManager(Config{})
+		status, _ := m.Start("pwru-start", "", "pwru", 0, func(ctx context.Context, output io.Writer) error {
+			return errors.New("busy: too many debug pods")
+		})
+		if status = waitFor(t, m, status.ID); status.State != StateFailed || !strings.Contains(status.Error, "busy") {
+			t.Fatalf("Unexpected status: %+v", status)
+		}
+	})
+
+	t.Run("stops jobs", func(t *testing.T) {
+		m := NewManager(Config{})
+		status, _ := m.Start("tcpdump-start", "", "tcpdump", 0, untilCancelled)
+		status, err := m.Stop(context.Background(), status.ID, "")
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if status.State != StateStopped || status.StopReason != "stopped by client" || status.EndTime == nil {
+			t.Fatalf("Unexpected status: %+v", status)
+		}
+	})
+
+	t.Run("stops jobs after the maximum duration", func(t *testing.T) {
+		m := NewManager(Config{MaxDuration: time.Hour})
+		status, _ := m.Start("tcpdump-start", "", "tcpdump", 50*time.Millisecond, untilCancelled)
+		if status = waitFor(t, m, status.ID); status.State != StateStopped || !strings.Contains(status.StopReason, "maximum duration") {
+			t.Fatalf("Unexpected status: %+v", status)
+		}
+	})
+
+	t.Run("stops jobs at the maximum output size", func(t *testing.T) {
+		m := NewManager(Config{MaxOutputBytes: 16})
+		status, _ := m.Start("tcpdump-start", "", "tcpdump", 0, func(ctx context.Context, output io.Writer) error {
+			for ctx.Err() == nil {
+				if _, err := io.WriteString(output, "packet\n"); err != nil {
+					return err
+				}
+			}
+			return ctx.Err()
+		})
+		if status = waitFor(t, m, status.ID); status.State != StateStopped || status.OutputBytes != 16 {
+			t.Fatalf("Unexpected status: %+v", status)
+		}
+	})
+
+	t.Run("limits the running jobs", func(t *testing.T) {
+		m := NewManager(Config{MaxRunning: 1})
+		defer m.Close()
+		if _, err := m.Start("tcpdump-start", "", "tcpdump", 0, untilCancelled); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if _, err := m.Start("tcpdump-start", "", "tcpdump", 0, untilCancelled); err == nil {
+			t.Fatal("Expected an error above the maximum number of running jobs")
+		}
+	})
+
+	t.Run("forgets finished jobs after the retention", func(t *testing.T) {
+		m := NewManager(Config{Retention: time.Minute})
+		status, _ := m.Start("pwru-start", "", "pwru", 0, func(ctx context.Context, output io.Writer) error { return nil })
+		waitFor(t, m, status.ID)
+		if jobs := m.List(""); len(jobs) != 1 {
+			t.Fatalf("Expected 1 job, got %d", len(jobs))
+		}
+		m.now = func() time.Time { return time.Now().Add(2 * time.Minute) }
+		if _, err := m.Status(status.ID, ""); err == nil {
+			t.Fatal("Expected the job to be forgotten")
+		}
+	})
+
+	t.Run("only shows jobs to their owner", func(t *testing.T) {
+		m := NewManager(Config{})
+		defer m.Close()
+		alice, _ := m.Start("tcpdump-start", "alice", "tcpdump", 0, untilCancelled)
+		bob, _ := m.Start("pwru-start", "bob", "pwru", 0, untilCancelled)
+
+		if jobs := m.List("alice"); len(jobs) != 1 || jobs[0].ID != alice.ID {
+			t.Fatalf("Expected only the job of alice, got %+v", jobs)
+		}
+		if jobs := m.List(""); len(jobs) != 0 {
+			t.Fatalf("Expected no job without authentication, got %+v", jobs)
+		}
+		if _, err := m.Status(bob.ID, "alice"); err == nil {
+			t.Fatal("Expected alice not to get the status of the job of bob")
+		}
+		if _, err := m.Output(bob.ID, "alice", 0, 0); err == nil {
+			t.Fatal("Expected alice not to read the output of the job of bob")
+		}
+		if _, err := m.Stop(context.Background(), bob.ID, "alice"); err == nil {
+			t.Fatal("Expected alice not to stop the job of bob")
+		}
+		if status, err := m.Status(bob.ID, "bob"); err != nil || status.State != StateRunning {
+			t.Fatalf("Expected the job of bob to still run, got %+v: %v", status, err)
+		}
+		if status, err := m.Stop(context.Background(), bob.ID, "bob"); err != nil || status.State != StateStopped {
+			t.Fatalf("Expected bob to stop the job, got %+v: %v", status, err)
+		}
+	})
+
+	t.Run("stops all jobs on close", func(t *testing.T) {
+		m := NewManager(Config{})
+		status, _ := m.Start("tcpdump-start", "", "tcpdump", 0, untilCancelled)
+		m.Close()
+		if status, _ = m.Status(status.ID, ""); status.State != StateStopped {
+			t.Fatalf("Unexpected status: %+v", status)
+		}
+	})
+}
@@ -0,0 +1,122 @@
+package mcp
+
+import (
+	"context"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/ovn-kubernetes/ovn-kubernetes-mcp/pkg/auth"
+	"github.com/ovn-kubernetes/ovn-kubernetes-mcp/pkg/jobs"
+	"github.com/ovn-kubernetes/ovn-kubernetes-mcp/pkg/jobs/types"
+)
+
+const (
+	// defaultOutputBytes is the default size of a job output range.
+	defaultOutputBytes = 64 * 1024
+	// maxOutputBytes is the maximum size of a job output range.
+	maxOutputBytes = 1024 * 1024
+)
+
+// MCPServer provides the tools managing the background jobs started by other tools.
+type MCPServer struct {
+	manager *jobs.Manager
+}
+
+// NewMCPServer creates a new MCP server managing the jobs of the manager.
+func NewMCPServer(manager *jobs.Manager) *MCPServer {
+	return &MCPServer{manager: manager}
+}
+
+// AddTools registers the job tools with the MCP server.
+func (s *MCPServer) AddTools(server *mcp.Server) {
+	mcp.AddTool(server,
+		&mcp.Tool{
+			Name: "job-list",
+			Description: `List the background jobs started by tcpdump-start and pwru-start, the most recent first.
+
+Only the jobs started by the caller are listed, and only their owner can get their status, fetch their
+output or stop them.
+
+Returns the ID, tool, description, state (running, succeeded, failed or stopped), start and end
+time, and output size of every job. Finished jobs are kept for one hour.`,
+		}, s.List)
+	mcp.AddTool(server,
+		&mcp.Tool{
+			Name: "job-status",
+			Description: `Get the status of a background job.
+
+Parameters:
+- id (required): ID of the job
+
+Returns the state of the job (running, succeeded, failed or stopped), its output size, and the
+reason it was stopped or the error it failed with.
+
+Example: {"id": "job-x7k2m9qa"}`,
+		}, s.Status)
+	mcp.AddTool(server,
+		&mcp.Tool{
+			Name: "job-output",
+			Description: `Fetch the output of a background job, while it runs or once it is finished.
+
+Parameters:
+- id (required): ID of the job
+- offset (optional): Byte offset to fetch the output from (default: 0). Pass the next_offset of the
+  previous call to fetch only the new output.
+- max_bytes (optional): Maximum number of bytes to return (default: 65536, max: 1048576)
+
+Returns the output range, its offset, the next_offset to fetch from, the state of the job, and
+complete=true once the job is finished and its whole output was fetched.
+
+Examples:
+- From the beginning: {"id": "job-x7k2m9qa"}
+- New output only: {"id": "job-x7k2m9qa", "offset": 65536}`,
+		}, s.Output)
+	mcp.AddTool(server,
+		&mcp.Tool{
+			Name: "job-stop",
+			Description: `Stop a running background job and delete its debug pod.
+
+Parameters:
+- id (required): ID of the job
+
+Returns the final status of the job. Its output can still be fetched with job-output.
+
+Example: {"id": "job-x7k2m9qa"}`,
+		}, s.Stop)
+}
+
+// List lists the background jobs.
+func (s *MCPServer) List(ctx context.Context, req *mcp.CallToolRequest, in struct{}) (*mcp.CallToolResult, types.JobListResult, error) {
+	return nil, types.JobListResult{Jobs: s.manager.List(auth.Username(req))}, nil
+}
+
+// Status returns the status of a background job.
+func (s *MCPServer) Status(ctx context.Context, req *mcp.CallToolRequest, in types.JobParams) (*mcp.CallToolResult, jobs.Status, error) {
+	status, err := s.manager.Status(in.ID, auth.Username(req))
+	if err != nil {
+		return nil, jobs.Status{}, err
+	}
+	return nil, status, nil
+}
+
+// Output returns a range of the output of a background job.
+func (s *MCPServer) Output(ctx context.Context, req *mcp.CallToolRequest, in types.JobOutputParams) (*mcp.CallToolResult, jobs.Output, error) {
+	maxBytes := in.MaxBytes
+	if maxBytes == 0 {
+		maxBytes = defaultOutputBytes
+	}
+	maxBytes = min(maxBytes, maxOutputBytes)
+	output, err := s.manager.Output(in.ID, auth.Username(req), in.Offset, maxBytes)
+	if err != nil {
+		return nil, jobs.Output{}, err
+	}
+	return nil, output, nil
+}
+
+// Stop stops a background job.
+func (s *MCPServer) Stop(ctx context.Context, req *mcp.CallToolRequest, in types.JobParams) (*mcp.CallToolResult, jobs.Status, error) {
+	status, err := s.manager.Stop(ctx, in.ID, auth.Username(req))
+	if err != nil {
+		return nil, jobs.Status{}, err
+	}
+	return nil, status, nil
+}
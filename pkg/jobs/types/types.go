@@ -0,0 +1,23 @@
+package types
+
+import "github.com/ovn-kubernetes/ovn-kubernetes-mcp/pkg/jobs"
+
+// JobParams identifies a background job.
+type JobParams struct {
+	ID string `json:"id"`
+}
+
+// JobOutputParams contains the parameters for fetching the output of a job.
+type JobOutputParams struct {
+	JobParams
+	// Offset is the byte offset to fetch the output from, usually the next_offset
+	// of the previous call.
+	Offset int `json:"offset,omitempty"`
+	// MaxBytes is the maximum number of bytes to return.
+	MaxBytes int `json:"max_bytes,omitempty"`
+}
+
+// JobListResult contains the status of the background jobs.
+type JobListResult struct {
+	Jobs []jobs.Status `json:"jobs"`
+}
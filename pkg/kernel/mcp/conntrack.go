@@ -39,7 +39,7 @@ func (s *MCPServer) GetConntrack(ctx context.Context, req *mcp.CallToolRequest,
 		return nil, types.Result{}, fmt.Errorf("error while getting list of conntrack entries: %w", err)
 	}
 
-	stdout = limitOutputLines(stdout, in.MaxLines)
+	stdout = limitOutputLines(ctx, stdout, in.MaxLines)
 	return nil, types.Result{Data: stdout}, nil
 }
 
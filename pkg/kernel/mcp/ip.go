@@ -42,7 +42,7 @@ func (s *MCPServer) GetIPCommandOutput(ctx context.Context, req *mcp.CallToolReq
 	if err != nil {
 		return nil, types.Result{}, fmt.Errorf("error while getting ip data: %w", err)
 	}
-	stdout = limitOutputLines(stdout, in.MaxLines)
+	stdout = limitOutputLines(ctx, stdout, in.MaxLines)
 	return nil, types.Result{Data: stdout}, nil
 }
 
@@ -45,7 +45,7 @@ func (s *MCPServer) GetIptables(ctx context.Context, req *mcp.CallToolRequest, i
 	if err != nil {
 		return nil, types.Result{}, fmt.Errorf("error while getting list of iptables rules: %w", err)
 	}
-	stdout = limitOutputLines(stdout, in.MaxLines)
+	stdout = limitOutputLines(ctx, stdout, in.MaxLines)
 	return nil, types.Result{Data: stdout}, nil
 }
 
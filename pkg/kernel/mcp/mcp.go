@@ -5,7 +5,7 @@ import (
 	"fmt"
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
-	kubernetesmcp "github.com/ovn-kubernetes/ovn-kubernetes-mcp/pkg/kubernetes/mcp"
+	"github.com/ovn-kubernetes/ovn-kubernetes-mcp/pkg/executor"
 	k8stypes "github.com/ovn-kubernetes/ovn-kubernetes-mcp/pkg/kubernetes/types"
 )
 
@@ -17,15 +17,15 @@ type Config struct {
 
 // MCPServer provides MCP server functionality for kernel operations.
 type MCPServer struct {
-	k8sMcpServer *kubernetesmcp.MCPServer
-	cfg          Config
+	executor executor.Executor
+	cfg      Config
 }
 
 // NewMCPServer creates a new MCP server instance
-func NewMCPServer(k8sMcpServer *kubernetesmcp.MCPServer, cfg Config) *MCPServer {
+func NewMCPServer(executor executor.Executor, cfg Config) *MCPServer {
 	return &MCPServer{
-		k8sMcpServer: k8sMcpServer,
-		cfg:          cfg,
+		executor: executor,
+		cfg:      cfg,
 	}
 }
 
@@ -38,6 +38,7 @@ func (s *MCPServer) AddTools(server *mcp.Server) {
 			Description: `get-conntrack allows to interact with the connection tracking system of a Kubernetes node.
 			              Use this command to discover a list of all (or a filtered selection of) currently tracked connections.
 Parameters:
+- cluster (optional): Cluster of the node, from cluster-list (defaults to the default cluster)
 - node (required): Name of the node from where conntrack entries are expected to be extracted
 - command (optional): These options specify the particular operation to perform. These options can only be used if configured image has 'conntrack' utility available.
 					  -L, --dump : List connection tracking table.
@@ -66,6 +67,7 @@ tcp 6 91 ESTABLISHED src=1.2.3.4 dst=5.6.7.8 sport=32000 dport=10250 src=5.6.7.8
 			Description: `get-iptables allows to interact with kernel to list packet filter rules.
 			              Iptables and ip6tables are used to inspect the tables of IPv4 and IPv6 packet filter rules in the Linux kernel.
 Parameters:
+- cluster (optional): Cluster of the node, from cluster-list (defaults to the default cluster)
 - node (required): Name of the node from where packet filter rules are expected to be extracted
 - table (optional): There are currently five independent tables (which tables are present at any time depends on the kernel configuration options and which modules are present).
                     filter	: This is the default table
@@ -100,6 +102,7 @@ Chain POSTROUTING (policy ACCEPT 675K packets, 41M bytes)
 			Name: "get-nft",
 			Description: `get-nft allows to interact with kernel to list packet filtering and classification rules.
 Parameters:
+- cluster (optional): Cluster of the node, from cluster-list (defaults to the default cluster)
 - node (required): Name of the node from where packet filtering and classification rules are expected to be extracted
 - command (required): These options specify the desired action to perform. Only one of them can be specified on the command line unless otherwise stated below.
                     - list ruleset   : The ruleset keyword is used to identify the whole set of tables, chains, etc. Print the ruleset in human-readable format.
@@ -130,6 +133,7 @@ table inet ovn-kubernetes
 			Name: "get-ip",
 			Description: `get-ip allows to interact with kernel to list routing, network devices, interfaces.
 Parameters:
+- cluster (optional): Cluster of the node, from cluster-list (defaults to the default cluster)
 - node (required): Name of the node on which ip command is expected to be executed
 - options (optional): These options helps in providing more details or formattig output data.
                       -d, -details        : Output more detailed information.
@@ -162,7 +166,7 @@ default via 10.0.0.254 dev br-ex proto dhcp src 10.0.0.10 metric 48 				`,
 // executeCommand executes a command on a node via kubectl debug
 func (s *MCPServer) executeCommand(ctx context.Context, req *mcp.CallToolRequest, node string, command []string) (string, error) {
 	debugParameter := k8stypes.DebugNodeParams{Name: node, Image: s.cfg.Image, Command: command}
-	_, result, err := s.k8sMcpServer.DebugNode(ctx, req, debugParameter)
+	_, result, err := s.executor.DebugNode(ctx, req, debugParameter)
 	if err != nil {
 		return "", fmt.Errorf("error while establishing tty connection to the node: %w", err)
 	}
@@ -36,7 +36,7 @@ func (s *MCPServer) GetNFT(ctx context.Context, req *mcp.CallToolRequest, in typ
 	if err != nil {
 		return nil, types.Result{}, fmt.Errorf("error while getting nft data: %w", err)
 	}
-	stdout = limitOutputLines(stdout, in.MaxLines)
+	stdout = limitOutputLines(ctx, stdout, in.MaxLines)
 	return nil, types.Result{Data: stdout}, nil
 }
 
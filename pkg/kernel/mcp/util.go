@@ -7,6 +7,7 @@ import (
 	"strings"
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/ovn-kubernetes/ovn-kubernetes-mcp/pkg/artifacts"
 	k8stypes "github.com/ovn-kubernetes/ovn-kubernetes-mcp/pkg/kubernetes/types"
 )
 
@@ -56,7 +57,7 @@ func (cb *commandBuilder) build() []string {
 func (s *MCPServer) utilityExists(ctx context.Context, req *mcp.CallToolRequest, node, utility string) error {
 	cmd := newCommand(utility, "-V")
 	debugParameter := k8stypes.DebugNodeParams{Name: node, Image: s.cfg.Image, Command: cmd.build()}
-	_, result, err := s.k8sMcpServer.DebugNode(ctx, req, debugParameter)
+	_, result, err := s.executor.DebugNode(ctx, req, debugParameter)
 	if err != nil {
 		return fmt.Errorf("error while checking availability of the utility %s: %w", utility, err)
 	}
@@ -86,8 +87,9 @@ func filterWarnings(output string) string {
 }
 
 // limitOutputLines limits the output to a maximum number of lines.
-// If the output exceeds the maximum, it truncates and adds a message indicating truncation.
-func limitOutputLines(output string, maxLines int) string {
+// If the output exceeds the maximum, it truncates and adds a message indicating truncation,
+// and saves the complete output as an artifact of the tool call.
+func limitOutputLines(ctx context.Context, output string, maxLines int) string {
 	if output == "" {
 		return output
 	}
@@ -102,7 +104,11 @@ func limitOutputLines(output string, maxLines int) string {
 
 	// Truncate to maxLines and add a truncation message
 	truncatedLines := lines[:maxLines]
-	truncatedLines = append(truncatedLines, fmt.Sprintf("\n... Output truncated. Showing first %d lines out of %d total lines.", maxLines, len(lines)))
+	message := fmt.Sprintf("\n... Output truncated. Showing first %d lines out of %d total lines.", maxLines, len(lines))
+	if artifact := artifacts.Save(ctx, output); artifact != nil {
+		message += fmt.Sprintf(" The complete output is available at %s.", artifact.URI())
+	}
+	truncatedLines = append(truncatedLines, message)
 
 	return strings.Join(truncatedLines, "\n")
 }
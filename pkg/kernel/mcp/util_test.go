@@ -1,6 +1,7 @@
 package mcp
 
 import (
+	"context"
 	"fmt"
 	"slices"
 	"strings"
@@ -336,7 +337,7 @@ func TestLimitOutputLines(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			result := limitOutputLines(tt.input, tt.maxLines)
+			result := limitOutputLines(context.Background(), tt.input, tt.maxLines)
 			if result != tt.expected {
 				t.Errorf("limitOutputLines() =\n%q\nwant\n%q", result, tt.expected)
 			}
@@ -1,8 +1,11 @@
 package types
 
+import k8stypes "github.com/ovn-kubernetes/ovn-kubernetes-mcp/pkg/kubernetes/types"
+
 // CommonParams contains the common parameters required for executing kernel commands on a Kubernetes node.
 // These parameters are embedded in other specific parameter types.
 type CommonParams struct {
+	k8stypes.ClusterParams
 	Node     string `json:"node"`                // Node is the name of the Kubernetes node where the command will be executed
 	MaxLines int    `json:"max_lines,omitempty"` // Limit the number of lines in output
 }
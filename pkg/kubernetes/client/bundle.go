@@ -0,0 +1,361 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// BundleFile is the file of a bundle directory containing the recorded calls, one
+// JSON object per line.
+const BundleFile = "calls.jsonl"
+
+// DefaultBundleCluster is the cluster of the calls of a bundle without cluster.
+const DefaultBundleCluster = "default"
+
+// Methods of the recorded calls.
+const (
+	methodGetPodLogs    = "GetPodLogs"
+	methodExecPod       = "ExecPod"
+	methodDebugNode     = "DebugNode"
+	methodGetResource   = "GetResource"
+	methodListResources = "ListResources"
+)
+
+// redactedValue replaces the values of the secrets in a bundle.
+const redactedValue = "REDACTED"
+
+// bundleCall is a call recorded in a bundle. The calls without cluster are the
+// calls of DefaultBundleCluster.
+type bundleCall struct {
+	Time     time.Time       `json:"time"`
+	Cluster  string          `json:"cluster,omitempty"`
+	Method   string          `json:"method"`
+	Request  json.RawMessage `json:"request"`
+	Response *callResponse   `json:"response,omitempty"`
+	Error    *callError      `json:"error,omitempty"`
+}
+
+type podLogsRequest struct {
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+	Container string `json:"container,omitempty"`
+	Previous  bool   `json:"previous,omitempty"`
+}
+
+type execPodRequest struct {
+	Namespace string   `json:"namespace"`
+	Name      string   `json:"name"`
+	Container string   `json:"container,omitempty"`
+	Command   []string `json:"command"`
+}
+
+type debugNodeRequest struct {
+	Node      string   `json:"node"`
+	Image     string   `json:"image"`
+	Command   []string `json:"command"`
+	HostPath  string   `json:"host_path,omitempty"`
+	MountPath string   `json:"mount_path,omitempty"`
+}
+
+type getResourceRequest struct {
+	Group     string `json:"group,omitempty"`
+	Version   string `json:"version"`
+	Kind      string `json:"kind"`
+	Namespace string `json:"namespace,omitempty"`
+	Name      string `json:"name"`
+}
+
+type listResourcesRequest struct {
+	Group         string `json:"group,omitempty"`
+	Version       string `json:"version"`
+	Kind          string `json:"kind"`
+	Namespace     string `json:"namespace,omitempty"`
+	LabelSelector string `json:"label_selector,omitempty"`
+	Limit         int64  `json:"limit,omitempty"`
+	Continue      string `json:"continue,omitempty"`
+}
+
+// callResponse is the result of a recorded call. A streamed command interrupted
+// by its context has both its output and an error.
+type callResponse struct {
+	Stdout string                         `json:"stdout,omitempty"`
+	Stderr string                         `json:"stderr,omitempty"`
+	Lines  []string                       `json:"lines,omitempty"`
+	Object *unstructured.Unstructured     `json:"object,omitempty"`
+	List   *unstructured.UnstructuredList `json:"list,omitempty"`
+}
+
+// callError is the error of a recorded call.
+type callError struct {
+	Message string `json:"message"`
+	// Status is the status of a Kubernetes API error, so that the replayed error
+	// can be checked with the apierrors functions.
+	Status *metav1.Status `json:"status,omitempty"`
+	// Interrupted is set if the call was stopped by the cancellation of its
+	// context, like a capture stopped by the user.
+	Interrupted bool `json:"interrupted,omitempty"`
+}
+
+func newCallError(ctx context.Context, err error) *callError {
+	if err == nil {
+		return nil
+	}
+	e := &callError{Message: err.Error(), Interrupted: ctx.Err() != nil}
+	var status apierrors.APIStatus
+	if errors.As(err, &status) {
+		s := status.Status()
+		e.Status = &s
+	}
+	return e
+}
+
+// replayedError is a recorded error, wrapping the Kubernetes API error if any.
+type replayedError struct {
+	message string
+	status  *apierrors.StatusError
+}
+
+func (e *replayedError) Error() string {
+	return e.message
+}
+
+func (e *replayedError) Unwrap() error {
+	if e.status == nil {
+		return nil
+	}
+	return e.status
+}
+
+func (e *callError) err() error {
+	err := &replayedError{message: e.Message}
+	if e.Status != nil {
+		err.status = &apierrors.StatusError{ErrStatus: *e.Status}
+	}
+	return err
+}
+
+// callKey identifies the calls of a method with the same request.
+func callKey(method string, request []byte) (string, error) {
+	var compact bytes.Buffer
+	if err := json.Compact(&compact, request); err != nil {
+		return "", err
+	}
+	return method + " " + compact.String(), nil
+}
+
+// BundleWriter appends the calls recorded by the Recorders of the clusters to a
+// bundle.
+type BundleWriter struct {
+	dir string
+
+	mu      sync.Mutex
+	file    *os.File
+	encoder *json.Encoder
+}
+
+// OpenBundle opens the bundle directory dir to record calls. The calls are
+// appended to the bundle if it already exists.
+func OpenBundle(dir string) (*BundleWriter, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("failed to create bundle directory %s: %w", dir, err)
+	}
+	file, err := os.OpenFile(filepath.Join(dir, BundleFile), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open bundle %s: %w", dir, err)
+	}
+	return &BundleWriter{dir: dir, file: file, encoder: json.NewEncoder(file)}, nil
+}
+
+// Dir returns the directory of the bundle.
+func (b *BundleWriter) Dir() string {
+	return b.dir
+}
+
+// write appends a call to the bundle. Recording failures are logged, they do not
+// fail the call.
+func (b *BundleWriter) write(call *bundleCall) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.file == nil {
+		return
+	}
+	if err := b.encoder.Encode(call); err != nil {
+		log.Printf("Failed to record %s call to bundle %s: %v", call.Method, b.dir, err)
+	}
+}
+
+// Close closes the bundle, the calls recorded afterwards are dropped.
+func (b *BundleWriter) Close() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.file == nil {
+		return nil
+	}
+	err := b.file.Close()
+	b.file = nil
+	return err
+}
+
+// Recorder is a client recording the calls of the live-cluster tools to a cluster
+// and their results to a bundle, which can be served with a Replayer without
+// cluster. The values of the secrets are redacted from the bundle.
+type Recorder struct {
+	client  Interface
+	bundle  *BundleWriter
+	cluster string
+}
+
+var _ Interface = &Recorder{}
+
+// NewRecorder returns a client recording the calls of client to the cluster to
+// bundle.
+func NewRecorder(client Interface, bundle *BundleWriter, cluster string) *Recorder {
+	return &Recorder{client: client, bundle: bundle, cluster: cluster}
+}
+
+// record appends a call to the bundle.
+func (r *Recorder) record(ctx context.Context, method string, request any, response *callResponse, err error) {
+	call := &bundleCall{Time: time.Now().UTC(), Cluster: r.cluster, Method: method, Response: response, Error: newCallError(ctx, err)}
+	data, jsonErr := json.Marshal(request)
+	if jsonErr != nil {
+		log.Printf("Failed to record %s call: %v", method, jsonErr)
+		return
+	}
+	call.Request = data
+	r.bundle.write(call)
+}
+
+func (r *Recorder) GetPodLogs(ctx context.Context, namespace string, name string, container string, previous bool) ([]string, error) {
+	lines, err := r.client.GetPodLogs(ctx, namespace, name, container, previous)
+	var response *callResponse
+	if err == nil {
+		response = &callResponse{Lines: lines}
+	}
+	r.record(ctx, methodGetPodLogs, podLogsRequest{Namespace: namespace, Name: name, Container: container, Previous: previous}, response, err)
+	return lines, err
+}
+
+func (r *Recorder) ExecPod(ctx context.Context, name, namespace, container string, command []string) (string, string, error) {
+	stdout, stderr, err := r.client.ExecPod(ctx, name, namespace, container, command)
+	var response *callResponse
+	if err == nil {
+		response = &callResponse{Stdout: stdout, Stderr: stderr}
+	}
+	r.record(ctx, methodExecPod, execPodRequest{Namespace: namespace, Name: name, Container: container, Command: command}, response, err)
+	return stdout, stderr, err
+}
+
+// StreamExecPod records the streamed command as an ExecPod call, with the output
+// produced until it exits or is interrupted.
+func (r *Recorder) StreamExecPod(ctx context.Context, name, namespace, container string, command []string, stdout, stderr io.Writer) error {
+	var outBuf, errBuf bytes.Buffer
+	err := r.client.StreamExecPod(ctx, name, namespace, container, command, io.MultiWriter(stdout, &outBuf), io.MultiWriter(stderr, &errBuf))
+	r.record(ctx, methodExecPod, execPodRequest{Namespace: namespace, Name: name, Container: container, Command: command},
+		&callResponse{Stdout: outBuf.String(), Stderr: errBuf.String()}, err)
+	return err
+}
+
+func (r *Recorder) DebugNode(ctx context.Context, name, image string, command []string, hostPath, mountPath string) (string, string, error) {
+	stdout, stderr, err := r.client.DebugNode(ctx, name, image, command, hostPath, mountPath)
+	var response *callResponse
+	if err == nil {
+		response = &callResponse{Stdout: stdout, Stderr: stderr}
+	}
+	r.record(ctx, methodDebugNode, debugNodeRequest{Node: name, Image: image, Command: command, HostPath: hostPath, MountPath: mountPath}, response, err)
+	return stdout, stderr, err
+}
+
+// StreamDebugNode records the streamed command as a DebugNode call, with the
+// output produced until it exits or is interrupted.
+func (r *Recorder) StreamDebugNode(ctx context.Context, name, image string, command []string, hostPath, mountPath string, stdout, stderr io.Writer) error {
+	var outBuf, errBuf bytes.Buffer
+	err := r.client.StreamDebugNode(ctx, name, image, command, hostPath, mountPath, io.MultiWriter(stdout, &outBuf), io.MultiWriter(stderr, &errBuf))
+	r.record(ctx, methodDebugNode, debugNodeRequest{Node: name, Image: image, Command: command, HostPath: hostPath, MountPath: mountPath},
+		&callResponse{Stdout: outBuf.String(), Stderr: errBuf.String()}, err)
+	return err
+}
+
+func (r *Recorder) GetResource(ctx context.Context, group, version, kind, resourceName, namespace string) (*unstructured.Unstructured, error) {
+	resource, err := r.client.GetResource(ctx, group, version, kind, resourceName, namespace)
+	var response *callResponse
+	if err == nil {
+		recorded := resource.DeepCopy()
+		if isSecret(group, kind) {
+			redactSecret(recorded)
+		}
+		response = &callResponse{Object: recorded}
+	}
+	r.record(ctx, methodGetResource, getResourceRequest{Group: group, Version: version, Kind: kind, Namespace: namespace, Name: resourceName}, response, err)
+	return resource, err
+}
+
+func (r *Recorder) ListResources(ctx context.Context, group, version, kind, namespace, labelSelector string,
+	limit int64, continueToken string) (*unstructured.UnstructuredList, error) {
+	resources, err := r.client.ListResources(ctx, group, version, kind, namespace, labelSelector, limit, continueToken)
+	var response *callResponse
+	if err == nil {
+		recorded := resources.DeepCopy()
+		if recorded.GetKind() == "" {
+			// The kind of a list is required to read it back.
+			recorded.SetAPIVersion("v1")
+			recorded.SetKind("List")
+		}
+		if isSecret(group, kind) {
+			for i := range recorded.Items {
+				redactSecret(&recorded.Items[i])
+			}
+		}
+		response = &callResponse{List: recorded}
+	}
+	r.record(ctx, methodListResources, listResourcesRequest{Group: group, Version: version, Kind: kind, Namespace: namespace,
+		LabelSelector: labelSelector, Limit: limit, Continue: continueToken}, response, err)
+	return resources, err
+}
+
+func (r *Recorder) ValidateDebugPodTemplate(ctx context.Context, image string) error {
+	return r.client.ValidateDebugPodTemplate(ctx, image)
+}
+
+// Close closes the recorded client. The bundle is closed by its owner.
+func (r *Recorder) Close() {
+	r.client.Close()
+}
+
+// isSecret returns true if the resources of group and kind are Secrets.
+func isSecret(group, kind string) bool {
+	return group == "" && kind == "Secret"
+}
+
+// redactSecret replaces the values of a Secret, keeping its keys.
+func redactSecret(secret *unstructured.Unstructured) {
+	for _, field := range []string{"data", "stringData"} {
+		values, found, err := unstructured.NestedMap(secret.Object, field)
+		if err != nil || !found {
+			continue
+		}
+		for key := range values {
+			values[key] = redactedValue
+		}
+		_ = unstructured.SetNestedMap(secret.Object, values, field)
+	}
+	annotations := secret.GetAnnotations()
+	if _, found := annotations[corev1.LastAppliedConfigAnnotation]; found {
+		// The last applied configuration contains the values too.
+		annotations[corev1.LastAppliedConfigAnnotation] = redactedValue
+		secret.SetAnnotations(annotations)
+	}
+}
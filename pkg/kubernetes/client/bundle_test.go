@@ -0,0 +1,179 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/rest/fake"
+)
+
+func TestRecordReplay(t *testing.T) {
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "ovnkube-node-abc", Namespace: "ovn-kubernetes"},
+		Spec:       corev1.PodSpec{Containers: []corev1.Container{{Name: "ovnkube-controller"}}},
+		Status:     corev1.PodStatus{Phase: corev1.PodRunning},
+	}
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "ovn-cert", Namespace: "ovn-kubernetes"},
+		Data:       map[string][]byte{"tls.key": []byte("private key")},
+	}
+	live := NewFakeClient(pod, secret)
+	live.corev1RestClient = &fake.RESTClient{
+		VersionedAPIPath: "/api/v1",
+		GroupVersion:     schema.GroupVersion{Group: "", Version: "v1"},
+	}
+	live.podExecutor = &fakeExecutor{}
+
+	dir := filepath.Join(t.TempDir(), "bundle")
+	bundle, err := OpenBundle(dir)
+	if err != nil {
+		t.Fatalf("Failed to open bundle: %v", err)
+	}
+	recorder := NewRecorder(live, bundle, "kind")
+
+	// calls runs the calls of the live-cluster tools, returning their results.
+	ctx := context.Background()
+	calls := func(client Interface) []any {
+		var results []any
+		lines, err := client.GetPodLogs(ctx, "ovn-kubernetes", "ovnkube-node-abc", "", false)
+		results = append(results, lines, err)
+		stdout, stderr, err := client.ExecPod(ctx, "ovnkube-node-abc", "ovn-kubernetes", "", []string{string(successExecCommand)})
+		results = append(results, stdout, stderr, err)
+		pod, err := client.GetResource(ctx, "", "v1", "Pod", "ovnkube-node-abc", "ovn-kubernetes")
+		results = append(results, pod.GetName(), pod.GetResourceVersion(), err)
+		pods, err := client.ListResources(ctx, "", "v1", "Pod", "ovn-kubernetes", "", 10, "")
+		results = append(results, len(pods.Items), pods.Items[0].GetName(), err)
+		return results
+	}
+	recorded := calls(recorder)
+	if _, err := recorder.GetResource(ctx, "", "v1", "Pod", "missing", "ovn-kubernetes"); !apierrors.IsNotFound(err) {
+		t.Fatalf("Expected a not found error, got %v", err)
+	}
+	if _, err := recorder.GetResource(ctx, "", "v1", "Secret", "ovn-cert", "ovn-kubernetes"); err != nil {
+		t.Fatalf("Failed to get secret: %v", err)
+	}
+	recorder.Close()
+	if err := bundle.Close(); err != nil {
+		t.Fatalf("Failed to close bundle: %v", err)
+	}
+
+	replayers, err := LoadBundle(dir)
+	if err != nil {
+		t.Fatalf("Failed to load bundle: %v", err)
+	}
+	replayer, found := replayers["kind"]
+	if !found || len(replayers) != 1 {
+		t.Fatalf("Unexpected replayed clusters %v", replayers)
+	}
+	if replayed := calls(replayer); !reflect.DeepEqual(replayed, recorded) {
+		t.Fatalf("Unexpected replayed results %v, recorded %v", replayed, recorded)
+	}
+
+	t.Run("errors are replayed", func(t *testing.T) {
+		_, err := replayer.GetResource(ctx, "", "v1", "Pod", "missing", "ovn-kubernetes")
+		if !apierrors.IsNotFound(err) || !strings.Contains(err.Error(), "missing") {
+			t.Fatalf("Expected a not found error, got %v", err)
+		}
+	})
+
+	t.Run("secrets are redacted", func(t *testing.T) {
+		data, err := os.ReadFile(filepath.Join(dir, BundleFile))
+		if err != nil {
+			t.Fatalf("Failed to read bundle: %v", err)
+		}
+		if bytes.Contains(data, []byte("cHJpdmF0ZSBrZXk=")) {
+			t.Fatal("The bundle contains the secret value")
+		}
+		secret, err := replayer.GetResource(ctx, "", "v1", "Secret", "ovn-cert", "ovn-kubernetes")
+		if err != nil {
+			t.Fatalf("Failed to replay secret: %v", err)
+		}
+		if value, _, _ := unstructured.NestedString(secret.Object, "data", "tls.key"); value != redactedValue {
+			t.Fatalf("Unexpected secret value %q", value)
+		}
+	})
+
+	t.Run("unrecorded call", func(t *testing.T) {
+		_, _, err := replayer.ExecPod(ctx, "ovnkube-node-abc", "ovn-kubernetes", "", []string{"ovs-vsctl", "show"})
+		if err == nil || !strings.Contains(err.Error(), "no ExecPod call") {
+			t.Fatalf("Expected an unrecorded call error, got %v", err)
+		}
+	})
+}
+
+func TestReplayRecordedOrder(t *testing.T) {
+	dir := t.TempDir()
+	bundle := `{"method":"ExecPod","request":{"namespace":"default","name":"test","command":["date"]},"response":{"stdout":"first"}}
+{"cluster":"east","method":"ExecPod","request":{"namespace":"default","name":"test","command":["date"]},"response":{"stdout":"east"}}
+{"method":"ExecPod","request":{"namespace": "default", "name": "test", "command": ["date"]},"response":{"stdout":"second"}}
+{"method":"DebugNode","request":{"node":"worker-0","image":"netshoot","command":["tcpdump"]},"response":{"stdout":"packet\n"},"error":{"message":"context canceled","interrupted":true}}
+`
+	if err := os.WriteFile(filepath.Join(dir, BundleFile), []byte(bundle), 0o600); err != nil {
+		t.Fatalf("Failed to write bundle: %v", err)
+	}
+	replayers, err := LoadBundle(dir)
+	if err != nil {
+		t.Fatalf("Failed to load bundle: %v", err)
+	}
+	if len(replayers) != 2 {
+		t.Fatalf("Expected 2 replayed clusters, got %d", len(replayers))
+	}
+	replayer := replayers[DefaultBundleCluster]
+
+	ctx := context.Background()
+	// The calls to another cluster are replayed by its own replayer.
+	if stdout, _, err := replayers["east"].ExecPod(ctx, "test", "default", "", []string{"date"}); err != nil || stdout != "east" {
+		t.Fatalf("Expected %q, got %q, %v", "east", stdout, err)
+	}
+	for _, want := range []string{"first", "second", "second"} {
+		stdout, _, err := replayer.ExecPod(ctx, "test", "default", "", []string{"date"})
+		if err != nil || stdout != want {
+			t.Fatalf("Expected %q, got %q, %v", want, stdout, err)
+		}
+	}
+
+	// An interrupted stream returns once its context is cancelled.
+	ctx, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+	defer cancel()
+	var stdout, stderr bytes.Buffer
+	err = replayer.StreamDebugNode(ctx, "worker-0", "netshoot", []string{"tcpdump"}, "", "", &stdout, &stderr)
+	if err != context.DeadlineExceeded || stdout.String() != "packet\n" {
+		t.Fatalf("Unexpected stream result %q, %v", stdout.String(), err)
+	}
+}
+
+func TestLoadBundleErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		bundle string
+	}{
+		{"malformed", `{"method":`},
+		{"no response", `{"method":"ExecPod","request":{}}`},
+		{"no call", ``},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			dir := t.TempDir()
+			if err := os.WriteFile(filepath.Join(dir, BundleFile), []byte(test.bundle), 0o600); err != nil {
+				t.Fatalf("Failed to write bundle: %v", err)
+			}
+			if _, err := LoadBundle(dir); err == nil {
+				t.Fatal("Expected an error")
+			}
+		})
+	}
+	if _, err := LoadBundle(t.TempDir()); err == nil {
+		t.Fatal("Expected an error without bundle")
+	}
+}
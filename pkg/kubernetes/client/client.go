@@ -1,6 +1,12 @@
 package client
 
 import (
+	"context"
+	"io"
+	"sync"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/uuid"
 	"k8s.io/client-go/discovery/cached/memory"
 	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
@@ -9,6 +15,21 @@ import (
 	"k8s.io/kubectl/pkg/cmd/exec"
 )
 
+// Interface is the cluster access of the live-cluster tools. It is implemented by
+// OVNKMCPServerClientSet, by a Recorder capturing the calls to a bundle, and by a
+// Replayer serving them from a bundle.
+type Interface interface {
+	GetPodLogs(ctx context.Context, namespace string, name string, container string, previous bool) ([]string, error)
+	ExecPod(ctx context.Context, name, namespace, container string, command []string) (string, string, error)
+	StreamExecPod(ctx context.Context, name, namespace, container string, command []string, stdout, stderr io.Writer) error
+	DebugNode(ctx context.Context, name, image string, command []string, hostPath, mountPath string) (string, string, error)
+	StreamDebugNode(ctx context.Context, name, image string, command []string, hostPath, mountPath string, stdout, stderr io.Writer) error
+	GetResource(ctx context.Context, group, version, kind, resourceName, namespace string) (*unstructured.Unstructured, error)
+	ListResources(ctx context.Context, group, version, kind, namespace, labelSelector string, limit int64, continueToken string) (*unstructured.UnstructuredList, error)
+	ValidateDebugPodTemplate(ctx context.Context, image string) error
+	Close()
+}
+
 // OVNKMCPServerClientSet is a client set for the OVN Kubernetes MCP server.
 type OVNKMCPServerClientSet struct {
 	clientSet                   kubernetes.Interface
@@ -17,6 +38,19 @@ type OVNKMCPServerClientSet struct {
 	config                      *rest.Config
 	corev1RestClient            rest.Interface
 	podExecutor                 exec.RemoteExecutor
+	debugPodPool                *debugPodPool
+	debugPodTemplate            DebugPodTemplate
+	// debugPodLimiter reserves a debug pod on a node before it is created, if set.
+	debugPodLimiter func(node string) (func(), error)
+
+	// instanceID identifies the debug pods created by this server instance.
+	instanceID string
+	// debugPods are the debug pods created by this instance that are in use.
+	debugPods   map[string]bool
+	debugPodsMu sync.Mutex
+
+	collectorStopCh chan struct{}
+	collectorDoneCh chan struct{}
 }
 
 // NewOVNKMCPServerClientSet creates a new OVNKMCPServerClientSet.
@@ -32,5 +66,6 @@ func NewOVNKMCPServerClientSet(config *rest.Config) (*OVNKMCPServerClientSet, er
 		config:                      config,
 		corev1RestClient:            clientSet.CoreV1().RESTClient(),
 		podExecutor:                 &exec.DefaultRemoteExecutor{},
+		instanceID:                  string(uuid.NewUUID()),
 	}, nil
 }
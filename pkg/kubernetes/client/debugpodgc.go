@@ -0,0 +1,186 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"strconv"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+const (
+	// managedByLabel and managedByValue identify the debug pods of the MCP server.
+	managedByLabel = "app.kubernetes.io/managed-by"
+	managedByValue = "ovn-kubernetes-mcp"
+	// instanceLabel is the ID of the server instance that created a debug pod.
+	instanceLabel = "ovn-kubernetes-mcp.ovn.org/instance"
+	// createdAtLabel is the creation time of a debug pod in Unix seconds.
+	createdAtLabel = "ovn-kubernetes-mcp.ovn.org/created-at"
+	// heartbeatAnnotation is refreshed by the instance owning a debug pod for as long
+	// as the pod is in use, in RFC 3339 format.
+	heartbeatAnnotation = "ovn-kubernetes-mcp.ovn.org/heartbeat"
+
+	// debugPodDeleteTimeout bounds the deletion of a debug pod, which must not depend
+	// on the context of the tool call that created it.
+	debugPodDeleteTimeout = 30 * time.Second
+	// debugPodGCInterval is the interval between two garbage collections.
+	debugPodGCInterval = time.Minute
+	// debugPodStaleAfter is the time after which a debug pod without a heartbeat is
+	// considered orphaned. It is a multiple of debugPodGCInterval so that the pods of
+	// other running instances are never deleted.
+	debugPodStaleAfter = 5 * debugPodGCInterval
+	// debugPodCreateGracePeriod protects the pods of this instance that are being
+	// created from being collected before they are tracked.
+	debugPodCreateGracePeriod = time.Minute
+)
+
+// debugPodLabels returns the labels set on the debug pods created by this instance.
+func (c *OVNKMCPServerClientSet) debugPodLabels(now time.Time) map[string]string {
+	return map[string]string{
+		"app.kubernetes.io/name": "ovnk-mcp-debug-pod",
+		managedByLabel:           managedByValue,
+		instanceLabel:            c.instanceID,
+		createdAtLabel:           strconv.FormatInt(now.Unix(), 10),
+	}
+}
+
+// trackDebugPod records a debug pod created by this instance as in use.
+func (c *OVNKMCPServerClientSet) trackDebugPod(name string) {
+	c.debugPodsMu.Lock()
+	defer c.debugPodsMu.Unlock()
+	if c.debugPods == nil {
+		c.debugPods = map[string]bool{}
+	}
+	c.debugPods[name] = true
+}
+
+// isTrackedDebugPod returns true if the debug pod is in use by this instance.
+func (c *OVNKMCPServerClientSet) isTrackedDebugPod(name string) bool {
+	c.debugPodsMu.Lock()
+	defer c.debugPodsMu.Unlock()
+	return c.debugPods[name]
+}
+
+// trackedDebugPods returns the names of the debug pods in use by this instance.
+func (c *OVNKMCPServerClientSet) trackedDebugPods() []string {
+	c.debugPodsMu.Lock()
+	defer c.debugPodsMu.Unlock()
+	names := make([]string, 0, len(c.debugPods))
+	for name := range c.debugPods {
+		names = append(names, name)
+	}
+	return names
+}
+
+// deleteDebugPod deletes a debug pod with a fresh bounded context, so that the pod
+// is deleted even if the tool call that created it was cancelled.
+func (c *OVNKMCPServerClientSet) deleteDebugPod(namespace, name string) {
+	c.debugPodsMu.Lock()
+	delete(c.debugPods, name)
+	c.debugPodsMu.Unlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), debugPodDeleteTimeout)
+	defer cancel()
+	err := c.clientSet.CoreV1().Pods(namespace).Delete(ctx, name, metav1.DeleteOptions{})
+	if err != nil && !apierrors.IsNotFound(err) {
+		log.Printf("failed to delete debug pod %s: %v", name, err)
+	}
+}
+
+// StartDebugPodCollector deletes the orphaned debug pods left by this or other
+// server instances, for example after a crash, once now and then periodically until
+// Close is called. It also keeps the debug pods in use by this instance alive.
+func (c *OVNKMCPServerClientSet) StartDebugPodCollector() {
+	c.collectorStopCh = make(chan struct{})
+	c.collectorDoneCh = make(chan struct{})
+	go func() {
+		defer close(c.collectorDoneCh)
+		ticker := time.NewTicker(debugPodGCInterval)
+		defer ticker.Stop()
+		namespace := c.debugPodTemplate.GetNamespace()
+		for {
+			c.heartbeatDebugPods(namespace, time.Now())
+			c.collectDebugPods(namespace, time.Now())
+			select {
+			case <-c.collectorStopCh:
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// stopDebugPodCollector stops the collector started by StartDebugPodCollector.
+func (c *OVNKMCPServerClientSet) stopDebugPodCollector() {
+	if c.collectorStopCh == nil {
+		return
+	}
+	close(c.collectorStopCh)
+	<-c.collectorDoneCh
+	c.collectorStopCh = nil
+}
+
+// heartbeatDebugPods refreshes the heartbeat of the debug pods in use by this instance.
+func (c *OVNKMCPServerClientSet) heartbeatDebugPods(namespace string, now time.Time) {
+	patch, err := json.Marshal(map[string]any{
+		"metadata": map[string]any{
+			"annotations": map[string]string{heartbeatAnnotation: now.UTC().Format(time.RFC3339)},
+		},
+	})
+	if err != nil {
+		log.Printf("failed to build debug pod heartbeat: %v", err)
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), debugPodDeleteTimeout)
+	defer cancel()
+	for _, name := range c.trackedDebugPods() {
+		_, err := c.clientSet.CoreV1().Pods(namespace).Patch(ctx, name, types.MergePatchType, patch, metav1.PatchOptions{})
+		if err != nil && !apierrors.IsNotFound(err) {
+			log.Printf("failed to refresh heartbeat of debug pod %s: %v", name, err)
+		}
+	}
+}
+
+// collectDebugPods deletes the orphaned debug pods in the namespace.
+func (c *OVNKMCPServerClientSet) collectDebugPods(namespace string, now time.Time) {
+	ctx, cancel := context.WithTimeout(context.Background(), debugPodDeleteTimeout)
+	defer cancel()
+	pods, err := c.clientSet.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{
+		LabelSelector: labels.SelectorFromSet(labels.Set{managedByLabel: managedByValue}).String(),
+	})
+	if err != nil {
+		log.Printf("failed to list debug pods: %v", err)
+		return
+	}
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+		if pod.DeletionTimestamp != nil || !c.isOrphanedDebugPod(pod, now) {
+			continue
+		}
+		log.Printf("Deleting orphaned debug pod %s created by instance %q", pod.Name, pod.Labels[instanceLabel])
+		c.deleteDebugPod(namespace, pod.Name)
+	}
+}
+
+// isOrphanedDebugPod returns true if no server instance uses the debug pod anymore.
+// The pods of this instance are orphaned once they are not tracked anymore, and
+// the pods of other instances once their heartbeat is stale.
+func (c *OVNKMCPServerClientSet) isOrphanedDebugPod(pod *corev1.Pod, now time.Time) bool {
+	lastSeen := pod.CreationTimestamp.Time
+	if createdAt, err := strconv.ParseInt(pod.Labels[createdAtLabel], 10, 64); err == nil {
+		lastSeen = time.Unix(createdAt, 0)
+	}
+	if pod.Labels[instanceLabel] == c.instanceID {
+		return !c.isTrackedDebugPod(pod.Name) && now.Sub(lastSeen) > debugPodCreateGracePeriod
+	}
+	if heartbeat, err := time.Parse(time.RFC3339, pod.Annotations[heartbeatAnnotation]); err == nil && heartbeat.After(lastSeen) {
+		lastSeen = heartbeat
+	}
+	return now.Sub(lastSeen) > debugPodStaleAfter
+}
@@ -0,0 +1,126 @@
+package client
+
+import (
+	"context"
+	"strconv"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func newDebugPod(name, instance string, createdAt time.Time, heartbeat *time.Time) *corev1.Pod {
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: metav1.NamespaceDefault,
+			Labels: map[string]string{
+				managedByLabel: managedByValue,
+				instanceLabel:  instance,
+				createdAtLabel: strconv.FormatInt(createdAt.Unix(), 10),
+			},
+		},
+	}
+	if heartbeat != nil {
+		pod.Annotations = map[string]string{heartbeatAnnotation: heartbeat.UTC().Format(time.RFC3339)}
+	}
+	return pod
+}
+
+func TestIsOrphanedDebugPod(t *testing.T) {
+	now := time.Now()
+	recent := now.Add(-time.Minute)
+	old := now.Add(-time.Hour)
+
+	tests := []struct {
+		name     string
+		pod      *corev1.Pod
+		tracked  bool
+		orphaned bool
+	}{
+		{"own pod in use", newDebugPod("a", "self", old, nil), true, false},
+		{"own pod not in use", newDebugPod("a", "self", old, nil), false, true},
+		{"own pod being created", newDebugPod("a", "self", now, nil), false, false},
+		{"other instance with recent heartbeat", newDebugPod("a", "other", old, &recent), false, false},
+		{"other instance with stale heartbeat", newDebugPod("a", "other", old, &old), false, true},
+		{"other instance recently created", newDebugPod("a", "other", recent, nil), false, false},
+		{"other instance without heartbeat", newDebugPod("a", "other", old, nil), false, true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			client := NewFakeClient()
+			client.instanceID = "self"
+			if test.tracked {
+				client.trackDebugPod(test.pod.Name)
+			}
+			if got := client.isOrphanedDebugPod(test.pod, now); got != test.orphaned {
+				t.Fatalf("isOrphanedDebugPod() = %v, want %v", got, test.orphaned)
+			}
+		})
+	}
+}
+
+func TestCollectDebugPods(t *testing.T) {
+	now := time.Now()
+	old := now.Add(-time.Hour)
+	unmanaged := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "unmanaged", Namespace: metav1.NamespaceDefault, CreationTimestamp: metav1.NewTime(old)}}
+	client := NewFakeClient(
+		newDebugPod("orphaned", "crashed", old, nil),
+		newDebugPod("in-use", "self", old, nil),
+		newDebugPod("fresh", "other", now, nil),
+		unmanaged,
+	)
+	client.instanceID = "self"
+	client.trackDebugPod("in-use")
+
+	client.collectDebugPods(metav1.NamespaceDefault, now)
+
+	pods := listDebugPods(t, client)
+	remaining := map[string]bool{}
+	for _, pod := range pods {
+		remaining[pod.Name] = true
+	}
+	if remaining["orphaned"] || !remaining["in-use"] || !remaining["fresh"] || !remaining["unmanaged"] {
+		t.Fatalf("Unexpected remaining pods: %v", remaining)
+	}
+
+	// The heartbeat keeps the pods in use alive for the other instances.
+	client.heartbeatDebugPods(metav1.NamespaceDefault, now)
+	pod, err := client.clientSet.CoreV1().Pods(metav1.NamespaceDefault).Get(context.Background(), "in-use", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Failed to get pod: %v", err)
+	}
+	if pod.Annotations[heartbeatAnnotation] == "" {
+		t.Fatal("Expected the heartbeat annotation to be set")
+	}
+}
+
+func TestCreatePodCleanupWithCancelledContext(t *testing.T) {
+	client, _ := newFakeDebugPodClient()
+	client.instanceID = "self"
+	ctx, cancel := context.WithCancel(context.Background())
+	name, cleanup, err := client.createPod(ctx, "worker-0", "netshoot", "", "", false)
+	if err != nil {
+		t.Fatalf("Failed to create debug pod: %v", err)
+	}
+	pod, err := client.clientSet.CoreV1().Pods(metav1.NamespaceDefault).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Failed to get debug pod: %v", err)
+	}
+	if pod.Labels[instanceLabel] != "self" || pod.Labels[createdAtLabel] == "" {
+		t.Fatalf("Expected the instance and creation time labels, got %v", pod.Labels)
+	}
+	if !client.isTrackedDebugPod(name) {
+		t.Fatal("Expected the debug pod to be tracked")
+	}
+
+	cancel()
+	cleanup()
+	if pods := listDebugPods(t, client); len(pods) != 0 {
+		t.Fatalf("Expected the debug pod to be deleted, got %d pods", len(pods))
+	}
+	if client.isTrackedDebugPod(name) {
+		t.Fatal("Expected the debug pod not to be tracked anymore")
+	}
+}
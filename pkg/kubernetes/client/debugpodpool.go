@@ -0,0 +1,250 @@
+package client
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/ovn-kubernetes/ovn-kubernetes-mcp/pkg/progress"
+)
+
+// maxReapInterval is the maximum interval between two checks for idle debug pods.
+const maxReapInterval = 30 * time.Second
+
+// debugPodKey identifies the debug pods that can be shared between commands.
+type debugPodKey struct {
+	node      string
+	image     string
+	hostPath  string
+	mountPath string
+	// user is the user impersonated by the commands, whose debug pods are not
+	// shared with other users.
+	user string
+}
+
+// pooledDebugPod is a debug pod kept running for reuse.
+type pooledDebugPod struct {
+	name string
+	// cleanup deletes the pod.
+	cleanup  func()
+	inUse    int
+	lastUsed time.Time
+	// ready is closed once the pod is running or failed to start, in which case
+	// err is set.
+	ready chan struct{}
+	err   error
+}
+
+// debugPodPool keeps a debug pod per node, image and mounts running while it is
+// used, and deletes it once it has been idle for the idle TTL.
+type debugPodPool struct {
+	client    *OVNKMCPServerClientSet
+	namespace string
+	idleTTL   time.Duration
+
+	mu   sync.Mutex
+	pods map[debugPodKey]*pooledDebugPod
+
+	stopCh chan struct{}
+	doneCh chan struct{}
+}
+
+// newDebugPodPool returns a pool creating debug pods in the namespace with the client.
+func newDebugPodPool(client *OVNKMCPServerClientSet, namespace string, idleTTL time.Duration) *debugPodPool {
+	return &debugPodPool{
+		client:    client,
+		namespace: namespace,
+		idleTTL:   idleTTL,
+		pods:      map[debugPodKey]*pooledDebugPod{},
+		stopCh:    make(chan struct{}),
+		doneCh:    make(chan struct{}),
+	}
+}
+
+// StartDebugPodPool makes DebugNode reuse the debug pods of previous commands
+// instead of creating one per command. The debug pods are deleted once they have
+// been idle for idleTTL, or on Close.
+func (c *OVNKMCPServerClientSet) StartDebugPodPool(idleTTL time.Duration) {
+	c.debugPodPool = newDebugPodPool(c, c.debugPodTemplate.GetNamespace(), idleTTL)
+	go c.debugPodPool.run()
+}
+
+// Close stops the debug pod collector and deletes the pooled debug pods.
+func (c *OVNKMCPServerClientSet) Close() {
+	c.stopDebugPodCollector()
+	if c.debugPodPool != nil {
+		c.debugPodPool.close()
+	}
+}
+
+// get returns a running debug pod for the key, creating it if needed. The returned
+// function must be called once the pod is no longer used by the caller.
+func (p *debugPodPool) get(ctx context.Context, key debugPodKey) (string, func(), error) {
+	for {
+		p.mu.Lock()
+		entry, found := p.pods[key]
+		if !found {
+			entry = &pooledDebugPod{ready: make(chan struct{})}
+			p.pods[key] = entry
+		}
+		entry.inUse++
+		p.mu.Unlock()
+
+		release := func() {
+			p.mu.Lock()
+			defer p.mu.Unlock()
+			entry.inUse--
+			entry.lastUsed = time.Now()
+		}
+
+		if !found {
+			name, cleanup, err := p.client.createPod(ctx, key.node, key.image, key.hostPath, key.mountPath, false)
+			p.mu.Lock()
+			if err != nil {
+				delete(p.pods, key)
+				entry.err = err
+			}
+			entry.name = name
+			entry.cleanup = cleanup
+			close(entry.ready)
+			p.mu.Unlock()
+			if err != nil {
+				release()
+				return "", nil, err
+			}
+			return name, release, nil
+		}
+
+		select {
+		case <-entry.ready:
+		case <-ctx.Done():
+			release()
+			return "", nil, ctx.Err()
+		}
+		if entry.err != nil {
+			release()
+			return "", nil, entry.err
+		}
+		if p.healthy(ctx, entry.name) {
+			progress.Report(ctx, "Reusing debug pod %s on node %s", entry.name, key.node)
+			return entry.name, release, nil
+		}
+		if ctx.Err() != nil {
+			release()
+			return "", nil, ctx.Err()
+		}
+
+		// Replace the unhealthy pod with a new one. Only the caller removing it from
+		// the pool deletes it, the others retry with the replacement.
+		release()
+		p.mu.Lock()
+		removed := p.pods[key] == entry
+		if removed {
+			delete(p.pods, key)
+		}
+		p.mu.Unlock()
+		if removed {
+			log.Printf("Debug pod %s for node %s is not running anymore, replacing it", entry.name, key.node)
+			entry.cleanup()
+		}
+	}
+}
+
+// healthy returns true if the debug pod is running and not being deleted.
+func (p *debugPodPool) healthy(ctx context.Context, name string) bool {
+	pod, err := p.client.clientSet.CoreV1().Pods(p.namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return false
+	}
+	return pod.Status.Phase == corev1.PodRunning && pod.DeletionTimestamp == nil
+}
+
+// run deletes the idle debug pods periodically until the pool is closed.
+func (p *debugPodPool) run() {
+	defer close(p.doneCh)
+	interval := min(p.idleTTL/2, maxReapInterval)
+	if interval <= 0 {
+		interval = maxReapInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-p.stopCh:
+			return
+		case now := <-ticker.C:
+			p.reapIdle(now)
+		}
+	}
+}
+
+// reapIdle deletes the debug pods that have been idle for longer than the idle TTL.
+func (p *debugPodPool) reapIdle(now time.Time) {
+	var expired []*pooledDebugPod
+	p.mu.Lock()
+	for key, entry := range p.pods {
+		if entry.inUse == 0 && entry.name != "" && now.Sub(entry.lastUsed) >= p.idleTTL {
+			delete(p.pods, key)
+			expired = append(expired, entry)
+		}
+	}
+	p.mu.Unlock()
+	for _, entry := range expired {
+		entry.cleanup()
+	}
+}
+
+// evictIdle deletes the least recently used idle debug pod on the node, or on any
+// node if it is empty, and returns false if there is none.
+func (p *debugPodPool) evictIdle(node string) bool {
+	p.mu.Lock()
+	var evictedKey debugPodKey
+	var evicted *pooledDebugPod
+	for key, entry := range p.pods {
+		if entry.inUse > 0 || entry.name == "" || (node != "" && key.node != node) {
+			continue
+		}
+		if evicted == nil || entry.lastUsed.Before(evicted.lastUsed) {
+			evictedKey, evicted = key, entry
+		}
+	}
+	if evicted != nil {
+		delete(p.pods, evictedKey)
+	}
+	p.mu.Unlock()
+	if evicted == nil {
+		return false
+	}
+	log.Printf("Deleting idle debug pod %s on node %s to make room for another debug pod", evicted.name, evictedKey.node)
+	evicted.cleanup()
+	return true
+}
+
+// EvictIdleDebugPod deletes the least recently used idle pooled debug pod on the
+// node, or on any node if it is empty, and returns false if there is none.
+func (c *OVNKMCPServerClientSet) EvictIdleDebugPod(node string) bool {
+	return c.debugPodPool != nil && c.debugPodPool.evictIdle(node)
+}
+
+// close stops the reaper and deletes all the pooled debug pods.
+func (p *debugPodPool) close() {
+	close(p.stopCh)
+	<-p.doneCh
+
+	var entries []*pooledDebugPod
+	p.mu.Lock()
+	for key, entry := range p.pods {
+		if entry.name != "" {
+			entries = append(entries, entry)
+		}
+		delete(p.pods, key)
+	}
+	p.mu.Unlock()
+	for _, entry := range entries {
+		entry.cleanup()
+	}
+}
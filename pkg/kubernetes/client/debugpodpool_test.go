@@ -0,0 +1,280 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	fakeclient "k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+// newFakeDebugPodClient returns a fake client whose created pods get a generated
+// name and are immediately running, and a counter of the created pods.
+func newFakeDebugPodClient() (*OVNKMCPServerClientSet, *int) {
+	client := NewFakeClient()
+	created := 0
+	client.clientSet.(*fakeclient.Clientset).PrependReactor("create", "pods", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		pod := action.(k8stesting.CreateAction).GetObject().(*corev1.Pod)
+		created++
+		pod.Name = fmt.Sprintf("%s%d", pod.GenerateName, created)
+		pod.Status.Phase = corev1.PodRunning
+		// Let the default reactor store the pod.
+		return false, nil, nil
+	})
+	return client, &created
+}
+
+func listDebugPods(t *testing.T, client *OVNKMCPServerClientSet) []corev1.Pod {
+	t.Helper()
+	pods, err := client.clientSet.CoreV1().Pods(metav1.NamespaceDefault).List(context.Background(), metav1.ListOptions{})
+	if err != nil {
+		t.Fatalf("Failed to list pods: %v", err)
+	}
+	return pods.Items
+}
+
+func TestDebugPodPool(t *testing.T) {
+	ctx := context.Background()
+	key := debugPodKey{node: "worker-0", image: "netshoot"}
+
+	t.Run("reuses the pod of a node and image", func(t *testing.T) {
+		client, created := newFakeDebugPodClient()
+		pool := newDebugPodPool(client, metav1.NamespaceDefault, time.Minute)
+
+		name1, release1, err := pool.get(ctx, key)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		name2, release2, err := pool.get(ctx, key)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		release1()
+		release2()
+		if name1 != name2 || *created != 1 {
+			t.Fatalf("Expected a single reused pod, got %s and %s (%d created)", name1, name2, *created)
+		}
+
+		other, release, err := pool.get(ctx, debugPodKey{node: "worker-0", image: "other"})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		release()
+		if other == name1 || *created != 2 {
+			t.Fatalf("Expected a new pod for another image, got %s (%d created)", other, *created)
+		}
+
+		pods := listDebugPods(t, client)
+		if len(pods) != 2 || pods[0].Labels["app.kubernetes.io/managed-by"] != "ovn-kubernetes-mcp" {
+			t.Fatalf("Expected 2 labelled debug pods, got %+v", pods)
+		}
+	})
+
+	t.Run("replaces unhealthy pods", func(t *testing.T) {
+		client, created := newFakeDebugPodClient()
+		pool := newDebugPodPool(client, metav1.NamespaceDefault, time.Minute)
+
+		name, release, err := pool.get(ctx, key)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		release()
+		pod, _ := client.clientSet.CoreV1().Pods(metav1.NamespaceDefault).Get(ctx, name, metav1.GetOptions{})
+		pod.Status.Phase = corev1.PodFailed
+		if _, err := client.clientSet.CoreV1().Pods(metav1.NamespaceDefault).Update(ctx, pod, metav1.UpdateOptions{}); err != nil {
+			t.Fatalf("Failed to update pod: %v", err)
+		}
+
+		newName, release, err := pool.get(ctx, key)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		release()
+		if newName == name || *created != 2 {
+			t.Fatalf("Expected the failed pod to be replaced, got %s (%d created)", newName, *created)
+		}
+		if pods := listDebugPods(t, client); len(pods) != 1 || pods[0].Name != newName {
+			t.Fatalf("Expected only the new pod to remain, got %+v", pods)
+		}
+	})
+
+	t.Run("leaves unhealthy pods to the caller removing them", func(t *testing.T) {
+		client, _ := newFakeDebugPodClient()
+		running := 0
+		client.SetDebugPodLimiter(func(node string) (func(), error) {
+			running++
+			return func() { running-- }, nil
+		})
+		pool := newDebugPodPool(client, metav1.NamespaceDefault, time.Minute)
+
+		name, release, err := pool.get(ctx, key)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		release()
+		pod, _ := client.clientSet.CoreV1().Pods(metav1.NamespaceDefault).Get(ctx, name, metav1.GetOptions{})
+		pod.Status.Phase = corev1.PodFailed
+		if _, err := client.clientSet.CoreV1().Pods(metav1.NamespaceDefault).Update(ctx, pod, metav1.UpdateOptions{}); err != nil {
+			t.Fatalf("Failed to update pod: %v", err)
+		}
+		// Another caller removes the failed pod while it is checked.
+		var removed *pooledDebugPod
+		client.clientSet.(*fakeclient.Clientset).PrependReactor("get", "pods", func(action k8stesting.Action) (bool, runtime.Object, error) {
+			if removed == nil && action.(k8stesting.GetAction).GetName() == name {
+				pool.mu.Lock()
+				removed = pool.pods[key]
+				delete(pool.pods, key)
+				pool.mu.Unlock()
+			}
+			return false, nil, nil
+		})
+
+		_, release, err = pool.get(ctx, key)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		release()
+		removed.cleanup()
+		if running != 1 {
+			t.Fatalf("Expected the failed pod to be deleted once, got %d running", running)
+		}
+	})
+
+	t.Run("deletes idle pods", func(t *testing.T) {
+		client, _ := newFakeDebugPodClient()
+		pool := newDebugPodPool(client, metav1.NamespaceDefault, time.Minute)
+
+		_, release, err := pool.get(ctx, key)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		// Pods in use are never deleted.
+		pool.reapIdle(time.Now().Add(time.Hour))
+		if pods := listDebugPods(t, client); len(pods) != 1 {
+			t.Fatalf("Expected the pod in use to be kept, got %d pods", len(pods))
+		}
+		release()
+		pool.reapIdle(time.Now())
+		if pods := listDebugPods(t, client); len(pods) != 1 {
+			t.Fatalf("Expected the recently used pod to be kept, got %d pods", len(pods))
+		}
+		pool.reapIdle(time.Now().Add(2 * time.Minute))
+		if pods := listDebugPods(t, client); len(pods) != 0 {
+			t.Fatalf("Expected the idle pod to be deleted, got %d pods", len(pods))
+		}
+	})
+
+	t.Run("reserves a debug pod until it is deleted", func(t *testing.T) {
+		client, _ := newFakeDebugPodClient()
+		acquired, running := 0, 0
+		client.SetDebugPodLimiter(func(node string) (func(), error) {
+			acquired++
+			running++
+			return func() { running-- }, nil
+		})
+		pool := newDebugPodPool(client, metav1.NamespaceDefault, time.Minute)
+
+		for range 3 {
+			_, release, err := pool.get(ctx, key)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			release()
+		}
+		// Reusing the pod does not reserve another one.
+		if acquired != 1 || running != 1 {
+			t.Fatalf("Expected a single reservation kept by the idle pod, got %d (%d running)", acquired, running)
+		}
+		pool.reapIdle(time.Now().Add(2 * time.Minute))
+		if running != 0 {
+			t.Fatalf("Expected the reservation to be released with the pod, got %d running", running)
+		}
+	})
+
+	t.Run("fails when the limiter refuses the pod", func(t *testing.T) {
+		client, created := newFakeDebugPodClient()
+		client.SetDebugPodLimiter(func(node string) (func(), error) {
+			return nil, errors.New("busy")
+		})
+		pool := newDebugPodPool(client, metav1.NamespaceDefault, time.Minute)
+		if _, _, err := pool.get(ctx, key); err == nil || *created != 0 {
+			t.Fatalf("Expected an error without creating a pod, got %v (%d created)", err, *created)
+		}
+	})
+
+	t.Run("evicts idle pods", func(t *testing.T) {
+		client, _ := newFakeDebugPodClient()
+		pool := newDebugPodPool(client, metav1.NamespaceDefault, time.Minute)
+
+		inUse, release, err := pool.get(ctx, key)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if pool.evictIdle("") {
+			t.Fatal("Expected the pod in use not to be evicted")
+		}
+		otherKey := debugPodKey{node: "worker-1", image: "netshoot"}
+		_, releaseOther, err := pool.get(ctx, otherKey)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		releaseOther()
+		if pool.evictIdle("worker-0") {
+			t.Fatal("Expected no idle pod to be evicted on worker-0")
+		}
+		if !pool.evictIdle("") {
+			t.Fatal("Expected the idle pod to be evicted")
+		}
+		release()
+		if pods := listDebugPods(t, client); len(pods) != 1 || pods[0].Name != inUse {
+			t.Fatalf("Expected only the pod in use to remain, got %+v", pods)
+		}
+	})
+
+	t.Run("deletes all pods on close", func(t *testing.T) {
+		client, _ := newFakeDebugPodClient()
+		client.StartDebugPodPool(time.Minute)
+		for _, node := range []string{"worker-0", "worker-1"} {
+			_, release, err := client.debugPodPool.get(ctx, debugPodKey{node: node, image: "netshoot"})
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			release()
+		}
+		client.Close()
+		if pods := listDebugPods(t, client); len(pods) != 0 {
+			t.Fatalf("Expected all pods to be deleted, got %d pods", len(pods))
+		}
+	})
+}
+
+func TestDebugPodReservation(t *testing.T) {
+	for _, releaseRunning := range []bool{false, true} {
+		t.Run(fmt.Sprintf("release running %v", releaseRunning), func(t *testing.T) {
+			client, _ := newFakeDebugPodClient()
+			running := 0
+			client.SetDebugPodLimiter(func(node string) (func(), error) {
+				running++
+				return func() { running-- }, nil
+			})
+			_, cleanup, err := client.createPod(context.Background(), "worker-0", "netshoot", "", "", releaseRunning)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			// The debug pods of the background jobs are only reserved while they start.
+			if want := map[bool]int{false: 1, true: 0}[releaseRunning]; running != want {
+				t.Fatalf("Expected %d reserved debug pods once running, got %d", want, running)
+			}
+			cleanup()
+			if running != 0 {
+				t.Fatalf("Expected no reserved debug pod once deleted, got %d", running)
+			}
+		})
+	}
+}
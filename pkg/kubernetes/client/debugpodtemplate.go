@@ -0,0 +1,218 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"maps"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+	psaapi "k8s.io/pod-security-admission/api"
+	"k8s.io/utils/ptr"
+)
+
+const (
+	// debugContainerName is the name of the container running the node commands.
+	debugContainerName = "debug-container"
+	// hostVolumeName is the name of the volume mounting the host path.
+	hostVolumeName = "host"
+)
+
+// DebugPodTemplate customizes the node debug pods. The pods are always privileged,
+// host networked and scheduled on the debugged node, whatever the template.
+type DebugPodTemplate struct {
+	// Namespace is the namespace of the debug pods. Defaults to "default".
+	Namespace string `json:"namespace,omitempty"`
+	// ServiceAccountName is the service account of the debug pods.
+	ServiceAccountName string `json:"service_account_name,omitempty"`
+	// ImagePullSecrets are the names of the secrets used to pull the debug images.
+	ImagePullSecrets []string `json:"image_pull_secrets,omitempty"`
+	// PriorityClassName is the priority class of the debug pods.
+	PriorityClassName string `json:"priority_class_name,omitempty"`
+	// Labels and Annotations are added to the debug pods. The labels used to
+	// manage the debug pods cannot be overridden.
+	Labels      map[string]string `json:"labels,omitempty"`
+	Annotations map[string]string `json:"annotations,omitempty"`
+	// Resources are the resource requests and limits of the debug container.
+	Resources corev1.ResourceRequirements `json:"resources,omitempty"`
+	// Volumes are mounted in the debug container in addition to the host path.
+	Volumes      []corev1.Volume      `json:"volumes,omitempty"`
+	VolumeMounts []corev1.VolumeMount `json:"volume_mounts,omitempty"`
+	// Tolerations of the debug pods. Defaults to tolerating all taints.
+	Tolerations []corev1.Toleration `json:"tolerations,omitempty"`
+}
+
+// GetNamespace returns the namespace of the debug pods.
+func (t *DebugPodTemplate) GetNamespace() string {
+	if t.Namespace == "" {
+		return metav1.NamespaceDefault
+	}
+	return t.Namespace
+}
+
+// Validate checks the template without contacting the API server.
+func (t *DebugPodTemplate) Validate() error {
+	if t.Namespace != "" {
+		if errs := validation.IsDNS1123Label(t.Namespace); len(errs) > 0 {
+			return fmt.Errorf("invalid debug pod namespace %q: %v", t.Namespace, errs)
+		}
+	}
+	volumes := map[string]bool{hostVolumeName: true}
+	for _, volume := range t.Volumes {
+		if volume.Name == "" {
+			return fmt.Errorf("debug pod volumes must have a name")
+		}
+		if volumes[volume.Name] {
+			return fmt.Errorf("duplicate debug pod volume %q", volume.Name)
+		}
+		volumes[volume.Name] = true
+	}
+	for _, mount := range t.VolumeMounts {
+		if !volumes[mount.Name] {
+			return fmt.Errorf("debug pod volume mount %s refers to unknown volume %q", mount.MountPath, mount.Name)
+		}
+		if mount.Name == hostVolumeName {
+			return fmt.Errorf("debug pod volume %q is reserved for the host path", hostVolumeName)
+		}
+	}
+	return nil
+}
+
+// SetDebugPodTemplate sets the template of the debug pods created by DebugNode. It
+// must be called before the debug pod pool and collector are started.
+func (c *OVNKMCPServerClientSet) SetDebugPodTemplate(template DebugPodTemplate) {
+	c.debugPodTemplate = template
+}
+
+// newDebugPod returns the debug pod running image on the node with hostPath
+// mounted at mountPath, built from the debug pod template.
+func (c *OVNKMCPServerClientSet) newDebugPod(node, image, hostPath, mountPath string, labels map[string]string) *corev1.Pod {
+	template := &c.debugPodTemplate
+	hostPathType := corev1.HostPathDirectory
+
+	if hostPath == "" {
+		hostPath = "/"
+	}
+
+	if mountPath == "" {
+		mountPath = "/host"
+	}
+
+	var envVars []corev1.EnvVar
+	if hostPath == "/" {
+		// to collect sos report requires this env var is set when hostPath is /
+		envVars = []corev1.EnvVar{
+			{
+				Name:  "HOST",
+				Value: mountPath,
+			},
+		}
+	}
+
+	podLabels := maps.Clone(template.Labels)
+	if podLabels == nil {
+		podLabels = map[string]string{}
+	}
+	maps.Copy(podLabels, labels)
+
+	tolerations := template.Tolerations
+	if len(tolerations) == 0 {
+		tolerations = []corev1.Toleration{
+			{
+				Operator: corev1.TolerationOpExists,
+			},
+		}
+	}
+
+	var pullSecrets []corev1.LocalObjectReference
+	for _, secret := range template.ImagePullSecrets {
+		pullSecrets = append(pullSecrets, corev1.LocalObjectReference{Name: secret})
+	}
+
+	// Create a host networked privileged debug pod.
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			GenerateName: "debug-node-" + node + "-",
+			Namespace:    template.GetNamespace(),
+			Labels:       podLabels,
+			Annotations:  maps.Clone(template.Annotations),
+		},
+		Spec: corev1.PodSpec{
+			NodeName:           node,
+			RestartPolicy:      corev1.RestartPolicyNever,
+			Tolerations:        tolerations,
+			ServiceAccountName: template.ServiceAccountName,
+			ImagePullSecrets:   pullSecrets,
+			PriorityClassName:  template.PriorityClassName,
+			HostNetwork:        true,
+			HostPID:            true,
+			HostIPC:            true,
+			Volumes: append([]corev1.Volume{
+				{
+					Name: hostVolumeName,
+					VolumeSource: corev1.VolumeSource{
+						HostPath: &corev1.HostPathVolumeSource{
+							Path: hostPath,
+							Type: &hostPathType,
+						},
+					},
+				},
+			}, template.Volumes...),
+			Containers: []corev1.Container{
+				{
+					Name:    debugContainerName,
+					Image:   image,
+					Command: []string{"sleep", "infinity"},
+					SecurityContext: &corev1.SecurityContext{
+						Privileged: ptr.To(true),
+						RunAsUser:  ptr.To(int64(0)),
+					},
+					VolumeMounts: append([]corev1.VolumeMount{
+						{
+							Name:      hostVolumeName,
+							MountPath: mountPath,
+						},
+					}, template.VolumeMounts...),
+					Env:       envVars,
+					Resources: template.Resources,
+				}},
+		},
+	}
+}
+
+// ValidateDebugPodTemplate checks that debug pods running image can be created from
+// the template. The Pod Security Admission level enforced on the namespace must be
+// privileged, and the API server must accept the pod in a dry run, which also
+// checks the service account, priority class, quotas and admission webhooks.
+func (c *OVNKMCPServerClientSet) ValidateDebugPodTemplate(ctx context.Context, image string) error {
+	if err := c.debugPodTemplate.Validate(); err != nil {
+		return err
+	}
+	namespace := c.debugPodTemplate.GetNamespace()
+	ns, err := c.clientSet.CoreV1().Namespaces().Get(ctx, namespace, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to get debug pod namespace %s: %w", namespace, err)
+	}
+	privileged := psaapi.LevelVersion{Level: psaapi.LevelPrivileged, Version: psaapi.LatestVersion()}
+	policy, errs := psaapi.PolicyToEvaluate(ns.Labels, psaapi.Policy{Enforce: privileged, Audit: privileged, Warn: privileged})
+	if len(errs) > 0 {
+		return fmt.Errorf("invalid pod security labels on namespace %s: %v", namespace, errs.ToAggregate())
+	}
+	if policy.Enforce.Level != psaapi.LevelPrivileged {
+		return fmt.Errorf("namespace %s enforces the %q pod security level, but debug pods require %q: label it with %s=%s or set another debug pod namespace",
+			namespace, policy.Enforce.Level, psaapi.LevelPrivileged, psaapi.EnforceLevelLabel, psaapi.LevelPrivileged)
+	}
+	if !policy.FullyPrivileged() {
+		log.Printf("Warning: namespace %s audits or warns about the %s pod security policy, every debug pod will be reported", namespace, policy.String())
+	}
+
+	pod := c.newDebugPod("validation", image, "", "", c.debugPodLabels(time.Now()))
+	_, err = c.clientSet.CoreV1().Pods(namespace).Create(ctx, pod, metav1.CreateOptions{DryRun: []string{metav1.DryRunAll}})
+	if err != nil {
+		return fmt.Errorf("debug pods cannot be created in namespace %s: %w", namespace, err)
+	}
+	return nil
+}
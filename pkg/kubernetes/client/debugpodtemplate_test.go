@@ -0,0 +1,124 @@
+package client
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestNewDebugPod(t *testing.T) {
+	client := NewFakeClient()
+	client.instanceID = "self"
+	client.SetDebugPodTemplate(DebugPodTemplate{
+		Namespace:          "ovnk-debug",
+		ServiceAccountName: "debug",
+		ImagePullSecrets:   []string{"registry"},
+		PriorityClassName:  "system-node-critical",
+		Labels:             map[string]string{"team": "network", managedByLabel: "someone-else"},
+		Annotations:        map[string]string{"owner": "network"},
+		Resources: corev1.ResourceRequirements{
+			Limits: corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("256Mi")},
+		},
+		Volumes:      []corev1.Volume{{Name: "scratch", VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}}}},
+		VolumeMounts: []corev1.VolumeMount{{Name: "scratch", MountPath: "/scratch"}},
+	})
+
+	pod := client.newDebugPod("worker-0", "netshoot", "", "", map[string]string{managedByLabel: managedByValue})
+	if pod.Namespace != "ovnk-debug" || pod.Spec.ServiceAccountName != "debug" || pod.Spec.PriorityClassName != "system-node-critical" {
+		t.Fatalf("Template not applied: namespace %q, service account %q, priority class %q",
+			pod.Namespace, pod.Spec.ServiceAccountName, pod.Spec.PriorityClassName)
+	}
+	if pod.Labels["team"] != "network" || pod.Labels[managedByLabel] != managedByValue {
+		t.Fatalf("Expected the template labels without overriding the managed labels, got %v", pod.Labels)
+	}
+	if pod.Annotations["owner"] != "network" {
+		t.Fatalf("Expected the template annotations, got %v", pod.Annotations)
+	}
+	if len(pod.Spec.ImagePullSecrets) != 1 || pod.Spec.ImagePullSecrets[0].Name != "registry" {
+		t.Fatalf("Expected the image pull secret, got %v", pod.Spec.ImagePullSecrets)
+	}
+	if len(pod.Spec.Volumes) != 2 || pod.Spec.Volumes[0].Name != hostVolumeName || pod.Spec.Volumes[1].Name != "scratch" {
+		t.Fatalf("Expected the host and scratch volumes, got %v", pod.Spec.Volumes)
+	}
+	container := pod.Spec.Containers[0]
+	if len(container.VolumeMounts) != 2 || container.VolumeMounts[0].MountPath != "/host" {
+		t.Fatalf("Expected the host and scratch mounts, got %v", container.VolumeMounts)
+	}
+	if container.Resources.Limits.Memory().String() != "256Mi" {
+		t.Fatalf("Expected the template resources, got %v", container.Resources)
+	}
+	if len(pod.Spec.Tolerations) != 1 || pod.Spec.Tolerations[0].Operator != corev1.TolerationOpExists {
+		t.Fatalf("Expected the default toleration, got %v", pod.Spec.Tolerations)
+	}
+	if !pod.Spec.HostNetwork || !*container.SecurityContext.Privileged {
+		t.Fatal("Expected a host networked privileged debug pod")
+	}
+}
+
+func TestDebugPodTemplateValidate(t *testing.T) {
+	scratch := corev1.Volume{Name: "scratch"}
+	tests := []struct {
+		name     string
+		template DebugPodTemplate
+		wantErr  bool
+	}{
+		{"empty", DebugPodTemplate{}, false},
+		{"valid volume", DebugPodTemplate{Volumes: []corev1.Volume{scratch}, VolumeMounts: []corev1.VolumeMount{{Name: "scratch", MountPath: "/scratch"}}}, false},
+		{"invalid namespace", DebugPodTemplate{Namespace: "Debug_Pods"}, true},
+		{"unnamed volume", DebugPodTemplate{Volumes: []corev1.Volume{{}}}, true},
+		{"duplicate volume", DebugPodTemplate{Volumes: []corev1.Volume{scratch, scratch}}, true},
+		{"reserved volume", DebugPodTemplate{Volumes: []corev1.Volume{{Name: hostVolumeName}}}, true},
+		{"reserved mount", DebugPodTemplate{VolumeMounts: []corev1.VolumeMount{{Name: hostVolumeName, MountPath: "/other"}}}, true},
+		{"unknown volume", DebugPodTemplate{VolumeMounts: []corev1.VolumeMount{{Name: "scratch", MountPath: "/scratch"}}}, true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := test.template.Validate()
+			if (err != nil) != test.wantErr {
+				t.Fatalf("Validate() error = %v, wantErr %v", err, test.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateDebugPodTemplate(t *testing.T) {
+	tests := []struct {
+		name    string
+		labels  map[string]string
+		wantErr string
+	}{
+		{"no pod security labels", nil, ""},
+		{"privileged", map[string]string{"pod-security.kubernetes.io/enforce": "privileged"}, ""},
+		{"warns only", map[string]string{"pod-security.kubernetes.io/warn": "restricted"}, ""},
+		{"baseline", map[string]string{"pod-security.kubernetes.io/enforce": "baseline"}, `enforces the "baseline" pod security level`},
+		{"invalid level", map[string]string{"pod-security.kubernetes.io/enforce": "strict"}, "invalid pod security labels"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			client := NewFakeClient(&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "ovnk-debug", Labels: test.labels}})
+			client.SetDebugPodTemplate(DebugPodTemplate{Namespace: "ovnk-debug"})
+			err := client.ValidateDebugPodTemplate(context.Background(), "netshoot")
+			if test.wantErr == "" {
+				if err != nil {
+					t.Fatalf("Unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), test.wantErr) {
+				t.Fatalf("Expected error containing %q, got %v", test.wantErr, err)
+			}
+		})
+	}
+
+	t.Run("missing namespace", func(t *testing.T) {
+		client := NewFakeClient()
+		client.SetDebugPodTemplate(DebugPodTemplate{Namespace: "ovnk-debug"})
+		if err := client.ValidateDebugPodTemplate(context.Background(), "netshoot"); err == nil {
+			t.Fatal("Expected an error for a missing namespace")
+		}
+	})
+}
@@ -0,0 +1,101 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	authorizationv1 "k8s.io/api/authorization/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/transport"
+)
+
+// Impersonation is the Kubernetes user the cluster calls of a tool call are made
+// as, so that they are authorized with the RBAC permissions of the caller instead
+// of the ones of the server.
+type Impersonation struct {
+	UserName string
+	Groups   []string
+}
+
+// impersonationKey is the context key of the impersonated user.
+type impersonationKey struct{}
+
+// WithImpersonation returns a context whose cluster calls impersonate the user,
+// with a client created from an ImpersonatingConfig. A nil impersonation makes
+// the calls with the credentials of the server.
+func WithImpersonation(ctx context.Context, impersonation *Impersonation) context.Context {
+	return context.WithValue(ctx, impersonationKey{}, impersonation)
+}
+
+// ImpersonationFrom returns the user impersonated by the cluster calls made with
+// ctx, nil if they are made with the credentials of the server.
+func ImpersonationFrom(ctx context.Context) *Impersonation {
+	impersonation, _ := ctx.Value(impersonationKey{}).(*Impersonation)
+	return impersonation
+}
+
+// ImpersonatingConfig returns a copy of config whose requests impersonate the user
+// of their context, see WithImpersonation. The requests whose context has no
+// impersonation, like the collection of the orphaned debug pods, are made with
+// the credentials of config.
+func ImpersonatingConfig(config *rest.Config) (*rest.Config, error) {
+	if config.Impersonate.UserName != "" || config.Impersonate.UID != "" || len(config.Impersonate.Groups) > 0 {
+		return nil, errors.New("the kubeconfig already impersonates a user")
+	}
+	config = rest.CopyConfig(config)
+	config.Wrap(func(rt http.RoundTripper) http.RoundTripper {
+		return &impersonatingRoundTripper{rt: rt}
+	})
+	return config, nil
+}
+
+// impersonatingRoundTripper sets the impersonation headers of the user of the
+// request context.
+type impersonatingRoundTripper struct {
+	rt http.RoundTripper
+}
+
+func (t *impersonatingRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	impersonation := ImpersonationFrom(req.Context())
+	if impersonation == nil {
+		return t.rt.RoundTrip(req)
+	}
+	req = req.Clone(req.Context())
+	req.Header.Set(transport.ImpersonateUserHeader, impersonation.UserName)
+	req.Header.Del(transport.ImpersonateGroupHeader)
+	for _, group := range impersonation.Groups {
+		req.Header.Add(transport.ImpersonateGroupHeader, group)
+	}
+	return t.rt.RoundTrip(req)
+}
+
+// WrappedRoundTripper implements net.RoundTripperWrapper, like the round trippers
+// of client-go.
+func (t *impersonatingRoundTripper) WrappedRoundTripper() http.RoundTripper {
+	return t.rt
+}
+
+// CheckImpersonation checks that the server is allowed to impersonate the users
+// and groups of the callers, so that the tool calls do not fail one by one.
+func (c *OVNKMCPServerClientSet) CheckImpersonation(ctx context.Context) error {
+	// The check is made with the credentials of the server.
+	ctx = WithImpersonation(ctx, nil)
+	for _, resource := range []string{"users", "groups"} {
+		review := &authorizationv1.SelfSubjectAccessReview{
+			Spec: authorizationv1.SelfSubjectAccessReviewSpec{
+				ResourceAttributes: &authorizationv1.ResourceAttributes{Verb: "impersonate", Resource: resource},
+			},
+		}
+		result, err := c.clientSet.AuthorizationV1().SelfSubjectAccessReviews().Create(ctx, review, metav1.CreateOptions{})
+		if err != nil {
+			return fmt.Errorf("failed to check the permission to impersonate %s: %w", resource, err)
+		}
+		if !result.Status.Allowed {
+			return fmt.Errorf("the server is not allowed to impersonate %s: grant it the impersonate verb on %s", resource, resource)
+		}
+	}
+	return nil
+}
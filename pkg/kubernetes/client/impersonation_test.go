@@ -0,0 +1,102 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	authorizationv1 "k8s.io/api/authorization/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes"
+	fakeclient "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/rest"
+	k8stesting "k8s.io/client-go/testing"
+	"k8s.io/client-go/transport"
+)
+
+func TestImpersonatingConfig(t *testing.T) {
+	var headers http.Header
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		headers = r.Header.Clone()
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"kind":"Pod","apiVersion":"v1","metadata":{"name":"ovnkube-node-abc"}}`))
+	}))
+	defer server.Close()
+
+	config, err := ImpersonatingConfig(&rest.Config{Host: server.URL})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	clientSet, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+
+	tests := []struct {
+		name          string
+		impersonation *Impersonation
+		wantUser      string
+		wantGroups    []string
+	}{
+		{name: "server credentials"},
+		{name: "user", impersonation: &Impersonation{UserName: "alice"}, wantUser: "alice"},
+		{name: "user and groups", impersonation: &Impersonation{UserName: "alice", Groups: []string{"netops", "sre"}},
+			wantUser: "alice", wantGroups: []string{"netops", "sre"}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctx := WithImpersonation(context.Background(), test.impersonation)
+			if _, err := clientSet.CoreV1().Pods("ovn-kubernetes").Get(ctx, "ovnkube-node-abc", metav1.GetOptions{}); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if got := headers.Get(transport.ImpersonateUserHeader); got != test.wantUser {
+				t.Fatalf("Expected impersonated user %q, got %q", test.wantUser, got)
+			}
+			if got := headers.Values(transport.ImpersonateGroupHeader); !reflect.DeepEqual(got, test.wantGroups) {
+				t.Fatalf("Expected impersonated groups %v, got %v", test.wantGroups, got)
+			}
+		})
+	}
+
+	if _, err := ImpersonatingConfig(&rest.Config{Impersonate: rest.ImpersonationConfig{UserName: "admin"}}); err == nil {
+		t.Fatal("Expected an error for a kubeconfig impersonating a user")
+	}
+}
+
+func TestCheckImpersonation(t *testing.T) {
+	tests := []struct {
+		name    string
+		allowed map[string]bool
+		wantErr string
+	}{
+		{name: "allowed", allowed: map[string]bool{"users": true, "groups": true}},
+		{name: "users denied", allowed: map[string]bool{"groups": true}, wantErr: "not allowed to impersonate users"},
+		{name: "groups denied", allowed: map[string]bool{"users": true}, wantErr: "not allowed to impersonate groups"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			client := NewFakeClient()
+			client.clientSet.(*fakeclient.Clientset).PrependReactor("create", "selfsubjectaccessreviews",
+				func(action k8stesting.Action) (bool, runtime.Object, error) {
+					review := action.(k8stesting.CreateAction).GetObject().(*authorizationv1.SelfSubjectAccessReview)
+					attributes := review.Spec.ResourceAttributes
+					review.Status.Allowed = attributes.Verb == "impersonate" && test.allowed[attributes.Resource]
+					return true, review, nil
+				})
+			err := client.CheckImpersonation(context.Background())
+			if test.wantErr == "" {
+				if err != nil {
+					t.Fatalf("Unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), test.wantErr) {
+				t.Fatalf("Expected error %q, got %v", test.wantErr, err)
+			}
+		})
+	}
+}
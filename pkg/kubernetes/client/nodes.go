@@ -3,28 +3,47 @@ package client
 import (
 	"context"
 	"fmt"
-	"log"
+	"io"
 	"time"
 
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
 	"k8s.io/apimachinery/pkg/util/wait"
-	"k8s.io/utils/ptr"
+
+	"github.com/ovn-kubernetes/ovn-kubernetes-mcp/pkg/metrics"
+	"github.com/ovn-kubernetes/ovn-kubernetes-mcp/pkg/progress"
 )
 
 func (c *OVNKMCPServerClientSet) DebugNode(ctx context.Context, name, image string, command []string, hostPath, mountPath string) (string, string, error) {
-	namespace := metav1.NamespaceDefault
-	debugPodName, cleanupPod, err := c.createPod(ctx, name, namespace, image, hostPath, mountPath)
-	if err != nil {
-		return "", "", err
-	}
-
-	if cleanupPod != nil {
-		defer cleanupPod()
+	namespace := c.debugPodTemplate.GetNamespace()
+	var debugPodName string
+	if c.debugPodPool != nil {
+		// Reuse the pooled debug pod of the node.
+		key := debugPodKey{node: name, image: image, hostPath: hostPath, mountPath: mountPath}
+		if impersonation := ImpersonationFrom(ctx); impersonation != nil {
+			key.user = impersonation.UserName
+		}
+		podName, release, err := c.debugPodPool.get(ctx, key)
+		if err != nil {
+			return "", "", err
+		}
+		defer release()
+		debugPodName = podName
+	} else {
+		podName, cleanupPod, err := c.createPod(ctx, name, image, hostPath, mountPath, false)
+		if err != nil {
+			return "", "", err
+		}
+		if cleanupPod != nil {
+			defer cleanupPod()
+		}
+		debugPodName = podName
 	}
 
 	// Execute the command in the pod.
-	stdout, stderr, err := c.ExecPod(ctx, debugPodName, namespace, "debug-container", command)
+	progress.Report(ctx, "Running command in debug pod %s on node %s", debugPodName, name)
+	stdout, stderr, err := c.ExecPod(ctx, debugPodName, namespace, debugContainerName, command)
 	if err != nil {
 		return "", "", fmt.Errorf("failed to execute command in debug pod: %w", err)
 	}
@@ -32,89 +51,66 @@ func (c *OVNKMCPServerClientSet) DebugNode(ctx context.Context, name, image stri
 	return stdout, stderr, nil
 }
 
-func (c *OVNKMCPServerClientSet) createPod(ctx context.Context, node, namespace, image, hostPath, mountPath string) (string, func(), error) {
-	hostPathType := corev1.HostPathDirectory
-	sleepCommand := []string{"sleep", "infinity"}
-
-	if hostPath == "" {
-		hostPath = "/"
+// StreamDebugNode runs a command in a dedicated debug pod on a node, writing its
+// output to stdout and stderr as it is produced, until the command exits or ctx is
+// cancelled. The debug pod is never reused and is deleted when it returns, which
+// also stops the command. It runs the commands of the background jobs, which are
+// limited by the job manager: the debug pod only counts against the debug pod
+// limits until it is running.
+func (c *OVNKMCPServerClientSet) StreamDebugNode(ctx context.Context, name, image string, command []string, hostPath, mountPath string, stdout, stderr io.Writer) error {
+	podName, cleanupPod, err := c.createPod(ctx, name, image, hostPath, mountPath, true)
+	if err != nil {
+		return err
 	}
+	defer cleanupPod()
 
-	if mountPath == "" {
-		mountPath = "/host"
+	err = c.StreamExecPod(ctx, podName, c.debugPodTemplate.GetNamespace(), debugContainerName, command, stdout, stderr)
+	if err != nil {
+		return fmt.Errorf("failed to execute command in debug pod: %w", err)
 	}
+	return nil
+}
+
+// SetDebugPodLimiter makes the client call acquire before creating a debug pod on
+// a node, and fail if it returns an error. The function returned by acquire is
+// called once the debug pod has been deleted, so that pooled debug pods are counted
+// until they are deleted, but reusing them does not call acquire.
+func (c *OVNKMCPServerClientSet) SetDebugPodLimiter(acquire func(node string) (func(), error)) {
+	c.debugPodLimiter = acquire
+}
 
-	var envVars []corev1.EnvVar
-	if hostPath == "/" {
-		// to collect sos report requires this env var is set when hostPath is /
-		envVars = []corev1.EnvVar{
-			{
-				Name:  "HOST",
-				Value: mountPath,
-			},
+// createPod creates a debug pod on the node and waits for it to be running. The
+// returned function deletes it. The debug pod is reserved with the debug pod
+// limiter until it is deleted, or until it is running if releaseRunning is set.
+func (c *OVNKMCPServerClientSet) createPod(ctx context.Context, node, image, hostPath, mountPath string,
+	releaseRunning bool) (string, func(), error) {
+	release := func() {}
+	if c.debugPodLimiter != nil {
+		var err error
+		if release, err = c.debugPodLimiter(node); err != nil {
+			return "", nil, err
 		}
 	}
 
-	// Create a host networked privileged debug pod.
-	debugPod := &corev1.Pod{
-		ObjectMeta: metav1.ObjectMeta{
-			GenerateName: "debug-node-" + node + "-",
-			Namespace:    namespace,
-		},
-		Spec: corev1.PodSpec{
-			NodeName:      node,
-			RestartPolicy: corev1.RestartPolicyNever,
-			Tolerations: []corev1.Toleration{
-				{
-					Operator: corev1.TolerationOpExists,
-				},
-			},
-			HostNetwork: true,
-			HostPID:     true,
-			HostIPC:     true,
-			Volumes: []corev1.Volume{
-				{
-					Name: "host",
-					VolumeSource: corev1.VolumeSource{
-						HostPath: &corev1.HostPathVolumeSource{
-							Path: hostPath,
-							Type: &hostPathType,
-						},
-					},
-				},
-			},
-			Containers: []corev1.Container{
-				{
-					Name:    "debug-container",
-					Image:   image,
-					Command: sleepCommand,
-					SecurityContext: &corev1.SecurityContext{
-						Privileged: ptr.To(true),
-						RunAsUser:  ptr.To(int64(0)),
-					},
-					VolumeMounts: []corev1.VolumeMount{
-						{
-							Name:      "host",
-							MountPath: mountPath,
-						},
-					},
-					Env: envVars,
-				}},
-		},
-	}
+	debugPod := c.newDebugPod(node, image, hostPath, mountPath, c.debugPodLabels(time.Now()))
+	namespace := debugPod.Namespace
 
 	// Create the debug pod.
+	progress.Report(ctx, "Creating debug pod on node %s", node)
 	createdDebugPod, err := c.clientSet.CoreV1().Pods(namespace).Create(ctx, debugPod, metav1.CreateOptions{})
 	if err != nil {
+		release()
+		metrics.DebugPodFailures.Inc()
 		return "", nil, fmt.Errorf("failed to create debug pod: %w", err)
 	}
+	createdAt := time.Now()
+	c.trackDebugPod(createdDebugPod.Name)
+	progress.Report(ctx, "Created debug pod %s on node %s", createdDebugPod.Name, node)
 
 	cleanupPod := func() {
-		// Delete the pod.
-		err := c.clientSet.CoreV1().Pods(namespace).Delete(ctx, createdDebugPod.Name, metav1.DeleteOptions{})
-		if err != nil {
-			log.Printf("failed to cleanup debug pod: %v", err)
-		}
+		// Delete the pod, even if the context of the tool call is already cancelled.
+		c.deleteDebugPod(namespace, createdDebugPod.Name)
+		release()
 	}
 
 	// Wait for the pod to be running.
@@ -123,13 +119,55 @@ func (c *OVNKMCPServerClientSet) createPod(ctx context.Context, node, namespace,
 		if err != nil {
 			return false, err
 		}
+		c.reportDebugPodStatus(ctx, pod)
 		// Return true if the pod is running.
 		return pod.Status.Phase == corev1.PodRunning, nil
 	})
 	if err != nil {
+		metrics.DebugPodFailures.Inc()
 		cleanupPod()
 		return "", nil, fmt.Errorf("debug pod did not reach running state within timeout of 1 minute: %w", err)
 	}
+	metrics.DebugPodStartupDuration.Observe(time.Since(createdAt).Seconds())
+	if releaseRunning {
+		release()
+		release = func() {}
+	}
 
 	return createdDebugPod.Name, cleanupPod, nil
 }
+
+// reportDebugPodStatus reports the startup stage of a debug pod to the client.
+func (c *OVNKMCPServerClientSet) reportDebugPodStatus(ctx context.Context, pod *corev1.Pod) {
+	if !progress.Enabled(ctx) {
+		return
+	}
+	if pod.Status.Phase == corev1.PodRunning {
+		progress.Report(ctx, "Debug pod %s is running", pod.Name)
+		return
+	}
+	for _, status := range pod.Status.ContainerStatuses {
+		// Report the waiting reasons other than the generic one, like image pull
+		// errors.
+		if waiting := status.State.Waiting; waiting != nil && waiting.Reason != "" && waiting.Reason != "ContainerCreating" {
+			progress.Report(ctx, "Debug pod %s is waiting: %s", pod.Name, waiting.Reason)
+			return
+		}
+	}
+	if c.isPullingImage(ctx, pod) {
+		progress.Report(ctx, "Pulling image %s on node %s", pod.Spec.Containers[0].Image, pod.Spec.NodeName)
+		return
+	}
+	progress.Report(ctx, "Waiting for debug pod %s to start", pod.Name)
+}
+
+// isPullingImage returns true if the kubelet reported pulling the image of the pod.
+func (c *OVNKMCPServerClientSet) isPullingImage(ctx context.Context, pod *corev1.Pod) bool {
+	events, err := c.clientSet.CoreV1().Events(pod.Namespace).List(ctx, metav1.ListOptions{
+		FieldSelector: fields.SelectorFromSet(fields.Set{
+			"involvedObject.name": pod.Name,
+			"reason":              "Pulling",
+		}).String(),
+	})
+	return err == nil && len(events.Items) > 0
+}
@@ -4,11 +4,17 @@ import (
 	"bytes"
 	"context"
 	"fmt"
+	"io"
+	"net/url"
 	"strings"
 
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/remotecommand"
+
+	"github.com/ovn-kubernetes/ovn-kubernetes-mcp/pkg/progress"
 )
 
 // GetPodLogs gets the logs of a pod by name and namespace.
@@ -36,14 +42,33 @@ func (c *OVNKMCPServerClientSet) GetPodLogs(ctx context.Context, namespace strin
 
 // ExecPod executes a command in a pod by name and namespace.
 func (c *OVNKMCPServerClientSet) ExecPod(ctx context.Context, name, namespace, container string, command []string) (string, string, error) {
+	// Create buffers for the stdout and stderr.
+	stdout := bytes.NewBuffer(make([]byte, 0))
+	stderr := bytes.NewBuffer(make([]byte, 0))
+
+	// Report the progress of the commands producing output over time, like captures.
+	stdoutWriter, reportOutput := progress.LineWriter(ctx, stdout)
+	err := c.StreamExecPod(ctx, name, namespace, container, command, stdoutWriter, stderr)
+	reportOutput()
+	if err != nil {
+		return "", "", err
+	}
+
+	return stdout.String(), stderr.String(), nil
+}
+
+// StreamExecPod executes a command in a pod by name and namespace, writing its output
+// to stdout and stderr as it is produced. It returns once the command exits or ctx
+// is cancelled.
+func (c *OVNKMCPServerClientSet) StreamExecPod(ctx context.Context, name, namespace, container string, command []string, stdout, stderr io.Writer) error {
 	pod, err := c.clientSet.CoreV1().Pods(namespace).Get(ctx, name, metav1.GetOptions{})
 	if err != nil {
-		return "", "", fmt.Errorf("failed to fetch pod: %w", err)
+		return fmt.Errorf("failed to fetch pod: %w", err)
 	}
 
 	// Cannot exec into a container in a pod that is not running.
 	if pod.Status.Phase != corev1.PodRunning {
-		return "", "", fmt.Errorf("cannot exec and run command %v in a container in a pod that is not running; current phase is %s", command, pod.Status.Phase)
+		return fmt.Errorf("cannot exec and run command %v in a container in a pod that is not running; current phase is %s", command, pod.Status.Phase)
 	}
 
 	// If no container is specified, use the first container.
@@ -67,14 +92,20 @@ func (c *OVNKMCPServerClientSet) ExecPod(ctx context.Context, name, namespace, c
 		Stderr:    true,
 	}, scheme.ParameterCodec)
 
-	// Create buffers for the stdout and stderr.
-	stdout := bytes.NewBuffer(make([]byte, 0))
-	stderr := bytes.NewBuffer(make([]byte, 0))
-
-	err = c.podExecutor.Execute(req.URL(), c.config, nil, stdout, stderr, false, nil)
+	// Close the stream when the context is cancelled if the executor supports it.
+	if executor, ok := c.podExecutor.(contextRemoteExecutor); ok {
+		err = executor.ExecuteWithContext(ctx, req.URL(), c.config, nil, stdout, stderr, false, nil)
+	} else {
+		err = c.podExecutor.Execute(req.URL(), c.config, nil, stdout, stderr, false, nil)
+	}
 	if err != nil {
-		return "", "", fmt.Errorf("failed to execute command %v in pod: %w", command, err)
+		return fmt.Errorf("failed to execute command %v in pod: %w", command, err)
 	}
+	return nil
+}
 
-	return stdout.String(), stderr.String(), nil
+// contextRemoteExecutor is implemented by the pod executors that stop executing the
+// command when a context is cancelled.
+type contextRemoteExecutor interface {
+	ExecuteWithContext(ctx context.Context, url *url.URL, config *rest.Config, stdin io.Reader, stdout, stderr io.Writer, tty bool, terminalSizeQueue remotecommand.TerminalSizeQueue) error
 }
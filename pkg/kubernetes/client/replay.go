@@ -0,0 +1,202 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// Replayer is a client serving the calls to a cluster recorded in a bundle by a
+// Recorder, without cluster. A call is answered with the recorded result of the call of the
+// same method with the same request. Identical calls are answered with their
+// results in the order they were recorded, the last one being repeated.
+type Replayer struct {
+	dir string
+
+	mu    sync.Mutex
+	calls map[string][]*bundleCall
+	next  map[string]int
+}
+
+var _ Interface = &Replayer{}
+
+// LoadBundle returns the clients serving the calls recorded in the bundle
+// directory dir, by cluster name.
+func LoadBundle(dir string) (map[string]*Replayer, error) {
+	file, err := os.Open(filepath.Join(dir, BundleFile))
+	if err != nil {
+		return nil, fmt.Errorf("failed to open bundle %s: %w", dir, err)
+	}
+	defer file.Close()
+
+	replayers := map[string]*Replayer{}
+	decoder := json.NewDecoder(file)
+	for i := 1; ; i++ {
+		call := &bundleCall{}
+		if err := decoder.Decode(call); errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			return nil, fmt.Errorf("invalid call %d of bundle %s: %w", i, dir, err)
+		}
+		if call.Response == nil && call.Error == nil {
+			return nil, fmt.Errorf("invalid call %d of bundle %s: no response nor error", i, dir)
+		}
+		key, err := callKey(call.Method, call.Request)
+		if err != nil {
+			return nil, fmt.Errorf("invalid request of call %d of bundle %s: %w", i, dir, err)
+		}
+		cluster := call.Cluster
+		if cluster == "" {
+			cluster = DefaultBundleCluster
+		}
+		r, found := replayers[cluster]
+		if !found {
+			r = &Replayer{dir: dir, calls: map[string][]*bundleCall{}, next: map[string]int{}}
+			replayers[cluster] = r
+		}
+		r.calls[key] = append(r.calls[key], call)
+	}
+	if len(replayers) == 0 {
+		return nil, fmt.Errorf("bundle %s has no call", dir)
+	}
+	return replayers, nil
+}
+
+// replay returns the recorded call of method with the request.
+func (r *Replayer) replay(method string, request any) (*bundleCall, error) {
+	data, err := json.Marshal(request)
+	if err != nil {
+		return nil, err
+	}
+	key, err := callKey(method, data)
+	if err != nil {
+		return nil, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	calls := r.calls[key]
+	if len(calls) == 0 {
+		return nil, fmt.Errorf("no %s call with request %s recorded in bundle %s", method, data, r.dir)
+	}
+	i := r.next[key]
+	if i < len(calls)-1 {
+		r.next[key]++
+	}
+	return calls[i], nil
+}
+
+// replayStream writes the recorded output of a streamed command. If the command
+// was interrupted, it returns once ctx is cancelled, as the recorded command did.
+func replayStream(ctx context.Context, call *bundleCall, stdout, stderr io.Writer) error {
+	if call.Response != nil {
+		if _, err := io.WriteString(stdout, call.Response.Stdout); err != nil {
+			return err
+		}
+		if _, err := io.WriteString(stderr, call.Response.Stderr); err != nil {
+			return err
+		}
+	}
+	if call.Error == nil {
+		return nil
+	}
+	if call.Error.Interrupted {
+		<-ctx.Done()
+		return ctx.Err()
+	}
+	return call.Error.err()
+}
+
+func (r *Replayer) GetPodLogs(ctx context.Context, namespace string, name string, container string, previous bool) ([]string, error) {
+	call, err := r.replay(methodGetPodLogs, podLogsRequest{Namespace: namespace, Name: name, Container: container, Previous: previous})
+	if err != nil {
+		return nil, err
+	}
+	if call.Error != nil {
+		return nil, call.Error.err()
+	}
+	return append([]string(nil), call.Response.Lines...), nil
+}
+
+func (r *Replayer) ExecPod(ctx context.Context, name, namespace, container string, command []string) (string, string, error) {
+	call, err := r.replay(methodExecPod, execPodRequest{Namespace: namespace, Name: name, Container: container, Command: command})
+	if err != nil {
+		return "", "", err
+	}
+	if call.Error != nil {
+		return "", "", call.Error.err()
+	}
+	return call.Response.Stdout, call.Response.Stderr, nil
+}
+
+func (r *Replayer) StreamExecPod(ctx context.Context, name, namespace, container string, command []string, stdout, stderr io.Writer) error {
+	call, err := r.replay(methodExecPod, execPodRequest{Namespace: namespace, Name: name, Container: container, Command: command})
+	if err != nil {
+		return err
+	}
+	return replayStream(ctx, call, stdout, stderr)
+}
+
+func (r *Replayer) DebugNode(ctx context.Context, name, image string, command []string, hostPath, mountPath string) (string, string, error) {
+	call, err := r.replay(methodDebugNode, debugNodeRequest{Node: name, Image: image, Command: command, HostPath: hostPath, MountPath: mountPath})
+	if err != nil {
+		return "", "", err
+	}
+	if call.Error != nil {
+		return "", "", call.Error.err()
+	}
+	return call.Response.Stdout, call.Response.Stderr, nil
+}
+
+func (r *Replayer) StreamDebugNode(ctx context.Context, name, image string, command []string, hostPath, mountPath string, stdout, stderr io.Writer) error {
+	call, err := r.replay(methodDebugNode, debugNodeRequest{Node: name, Image: image, Command: command, HostPath: hostPath, MountPath: mountPath})
+	if err != nil {
+		return err
+	}
+	return replayStream(ctx, call, stdout, stderr)
+}
+
+func (r *Replayer) GetResource(ctx context.Context, group, version, kind, resourceName, namespace string) (*unstructured.Unstructured, error) {
+	call, err := r.replay(methodGetResource, getResourceRequest{Group: group, Version: version, Kind: kind, Namespace: namespace, Name: resourceName})
+	if err != nil {
+		return nil, err
+	}
+	if call.Error != nil {
+		return nil, call.Error.err()
+	}
+	if call.Response.Object == nil {
+		return nil, fmt.Errorf("no resource in the %s call recorded in bundle %s", methodGetResource, r.dir)
+	}
+	return call.Response.Object.DeepCopy(), nil
+}
+
+func (r *Replayer) ListResources(ctx context.Context, group, version, kind, namespace, labelSelector string,
+	limit int64, continueToken string) (*unstructured.UnstructuredList, error) {
+	call, err := r.replay(methodListResources, listResourcesRequest{Group: group, Version: version, Kind: kind, Namespace: namespace,
+		LabelSelector: labelSelector, Limit: limit, Continue: continueToken})
+	if err != nil {
+		return nil, err
+	}
+	if call.Error != nil {
+		return nil, call.Error.err()
+	}
+	if call.Response.List == nil {
+		return nil, fmt.Errorf("no resources in the %s call recorded in bundle %s", methodListResources, r.dir)
+	}
+	return call.Response.List.DeepCopy(), nil
+}
+
+// ValidateDebugPodTemplate does nothing: no debug pod is created in replay.
+func (r *Replayer) ValidateDebugPodTemplate(ctx context.Context, image string) error {
+	return nil
+}
+
+// Close does nothing: the bundle is read when the Replayer is created.
+func (r *Replayer) Close() {}
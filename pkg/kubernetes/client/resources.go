@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime/schema"
@@ -59,8 +60,11 @@ func (c *OVNKMCPServerClientSet) getResource(ctx context.Context, gvr schema.Gro
 	return resource, nil
 }
 
-// ListResources lists resources by group, version, kind and namespace.
-func (c *OVNKMCPServerClientSet) ListResources(ctx context.Context, group, version, kind, namespace, labelSelector string) (*unstructured.UnstructuredList, error) {
+// ListResources lists resources by group, version, kind and namespace. At most
+// limit resources are returned if limit is positive, starting from the continue
+// token of the previous list if set.
+func (c *OVNKMCPServerClientSet) ListResources(ctx context.Context, group, version, kind, namespace, labelSelector string,
+	limit int64, continueToken string) (*unstructured.UnstructuredList, error) {
 	gvk := schema.GroupVersionKind{
 		Group:   group,
 		Version: version,
@@ -70,14 +74,20 @@ func (c *OVNKMCPServerClientSet) ListResources(ctx context.Context, group, versi
 	if err != nil {
 		return nil, fmt.Errorf("failed to get REST mapping for resource %s: %w", gvk.String(), err)
 	}
-	return c.listResources(ctx, restMapping.Resource, namespace, labelSelector)
+	return c.listResources(ctx, restMapping.Resource, namespace, labelSelector, limit, continueToken)
 }
 
 // listResources lists resources by group, version, kind and namespace.
-func (c *OVNKMCPServerClientSet) listResources(ctx context.Context, gvr schema.GroupVersionResource, namespace, labelSelector string) (*unstructured.UnstructuredList, error) {
+func (c *OVNKMCPServerClientSet) listResources(ctx context.Context, gvr schema.GroupVersionResource, namespace, labelSelector string,
+	limit int64, continueToken string) (*unstructured.UnstructuredList, error) {
 	resources, err := c.dynamicClient.Resource(gvr).Namespace(namespace).List(ctx, metav1.ListOptions{
 		LabelSelector: labelSelector,
+		Limit:         limit,
+		Continue:      continueToken,
 	})
+	if apierrors.IsResourceExpired(err) {
+		return nil, fmt.Errorf("the list of resources %s changed too much since the previous page, list them again without a cursor: %w", gvr.String(), err)
+	}
 	if err != nil {
 		return nil, fmt.Errorf("failed to list resources %s with namespace %s and label selector %s: %w", gvr.String(), namespace, labelSelector, err)
 	}
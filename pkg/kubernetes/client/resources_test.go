@@ -367,7 +367,7 @@ func TestListResources(t *testing.T) {
 	for _, test := range tests {
 		t.Run(test.testName, func(t *testing.T) {
 			fakeclient := NewFakeClient(test.objects...)
-			resources, err := fakeclient.ListResources(context.Background(), test.gvk.Group, test.gvk.Version, test.gvk.Kind, test.namespace, test.labelSelector)
+			resources, err := fakeclient.ListResources(context.Background(), test.gvk.Group, test.gvk.Version, test.gvk.Kind, test.namespace, test.labelSelector, 0, "")
 			if (err != nil) != test.expectedErr {
 				t.Fatalf("Failed to list resources: %v", err)
 			}
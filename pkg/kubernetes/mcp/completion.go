@@ -0,0 +1,36 @@
+package mcp
+
+import (
+	"context"
+
+	"github.com/ovn-kubernetes/ovn-kubernetes-mcp/pkg/auth"
+	"github.com/ovn-kubernetes/ovn-kubernetes-mcp/pkg/completion"
+)
+
+// AddCompletions adds the completion of the namespace, pod and node arguments with
+// the resources of the cluster. The pods are the ones of the namespace argument if
+// set, of all the namespaces otherwise.
+func (s *MCPServer) AddCompletions(completer *completion.Completer) {
+	completer.Add(completion.ArgumentNamespace, auth.FamilyKubernetes, func(ctx context.Context, arguments map[string]string) ([]string, error) {
+		return s.resourceNames(ctx, "Namespace", "")
+	})
+	completer.Add(completion.ArgumentNode, auth.FamilyKubernetes, func(ctx context.Context, arguments map[string]string) ([]string, error) {
+		return s.resourceNames(ctx, "Node", "")
+	})
+	completer.Add(completion.ArgumentPod, auth.FamilyKubernetes, func(ctx context.Context, arguments map[string]string) ([]string, error) {
+		return s.resourceNames(ctx, "Pod", arguments[completion.ArgumentNamespace])
+	})
+}
+
+// resourceNames returns the names of the core resources of a kind in a namespace.
+func (s *MCPServer) resourceNames(ctx context.Context, kind, namespace string) ([]string, error) {
+	items, err := s.List(ctx, "", "v1", kind, namespace, "")
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(items))
+	for _, item := range items {
+		names = append(names, item.GetName())
+	}
+	return names, nil
+}
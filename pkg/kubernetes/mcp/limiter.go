@@ -0,0 +1,158 @@
+package mcp
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+
+	"github.com/ovn-kubernetes/ovn-kubernetes-mcp/pkg/metrics"
+)
+
+// busyRetryAfter is the retry delay suggested when a concurrency limit is reached.
+// Debug pod commands usually complete within a few seconds.
+const busyRetryAfter = 5 * time.Second
+
+// DebugPodLimits limits the creation of privileged debug pods. A zero value
+// disables the corresponding limit.
+type DebugPodLimits struct {
+	// MaxConcurrent is the maximum number of debug pods running at the same time,
+	// including the idle pooled ones. The debug pods of the background jobs, which
+	// are limited by the job manager, only count while they start.
+	MaxConcurrent int
+	// MaxConcurrentPerNode is the maximum number of debug pods running at the same
+	// time on a single node, including the idle pooled ones.
+	MaxConcurrentPerNode int
+	// Rate is the sustained number of debug pods that can be created per second.
+	Rate float64
+	// Burst is the number of debug pods that can be created at once above Rate.
+	Burst int
+}
+
+// BusyError is returned when a debug pod is refused because a limit is reached.
+type BusyError struct {
+	Reason     string
+	RetryAfter time.Duration
+}
+
+func (e *BusyError) Error() string {
+	return fmt.Sprintf("busy: %s, retry after %ds", e.Reason, int(math.Ceil(e.RetryAfter.Seconds())))
+}
+
+// debugPodLimiter enforces the debug pod limits. Callers are refused with a
+// BusyError instead of waiting, so that requests do not pile up.
+type debugPodLimiter struct {
+	limits  DebugPodLimits
+	bucket  *rate.Limiter
+	mu      sync.Mutex
+	running int
+	perNode map[string]int
+	// evictors delete an idle pooled debug pod on a node, or on any node if it
+	// is empty, and return false if there is none.
+	evictors []func(node string) bool
+}
+
+// newDebugPodLimiter returns a limiter enforcing the limits.
+func newDebugPodLimiter(limits DebugPodLimits) *debugPodLimiter {
+	l := &debugPodLimiter{limits: limits, perNode: map[string]int{}}
+	if limits.Rate > 0 {
+		burst := limits.Burst
+		if burst < 1 {
+			burst = 1
+		}
+		l.bucket = rate.NewLimiter(rate.Limit(limits.Rate), burst)
+	}
+	return l
+}
+
+// addEvictor makes the limiter delete idle pooled debug pods with evict when a
+// concurrency limit is reached.
+func (l *debugPodLimiter) addEvictor(evict func(node string) bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.evictors = append(l.evictors, evict)
+}
+
+// acquire reserves a debug pod on the node, before it is created. When a
+// concurrency limit is reached, idle pooled debug pods are deleted to make room.
+// The returned function must be called once the debug pod has been deleted.
+func (l *debugPodLimiter) acquire(node string) (func(), error) {
+	for {
+		release, throttled, err := l.tryAcquire(node)
+		if err == nil {
+			return release, nil
+		}
+		evicted := false
+		switch throttled {
+		case "concurrency":
+			evicted = l.evict("")
+		case "node_concurrency":
+			evicted = l.evict(node)
+		}
+		if !evicted {
+			metrics.DebugPodThrottled.WithLabelValues(throttled).Inc()
+			return nil, err
+		}
+	}
+}
+
+// evict deletes an idle pooled debug pod on the node, or on any node if it is
+// empty, and returns false if there is none.
+func (l *debugPodLimiter) evict(node string) bool {
+	l.mu.Lock()
+	evictors := slices.Clone(l.evictors)
+	l.mu.Unlock()
+	for _, evict := range evictors {
+		if evict(node) {
+			return true
+		}
+	}
+	return false
+}
+
+// tryAcquire reserves a debug pod on the node, or returns the limit that refused
+// it, as the label of the throttling metric.
+func (l *debugPodLimiter) tryAcquire(node string) (func(), string, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.limits.MaxConcurrent > 0 && l.running >= l.limits.MaxConcurrent {
+		return nil, "concurrency", &BusyError{
+			Reason:     fmt.Sprintf("%d debug pods are already running", l.running),
+			RetryAfter: busyRetryAfter,
+		}
+	}
+	if l.limits.MaxConcurrentPerNode > 0 && l.perNode[node] >= l.limits.MaxConcurrentPerNode {
+		return nil, "node_concurrency", &BusyError{
+			Reason:     fmt.Sprintf("%d debug pods are already running on node %s", l.perNode[node], node),
+			RetryAfter: busyRetryAfter,
+		}
+	}
+	if l.bucket != nil {
+		reservation := l.bucket.Reserve()
+		if delay := reservation.Delay(); delay > 0 {
+			reservation.Cancel()
+			return nil, "rate", &BusyError{
+				Reason:     fmt.Sprintf("debug pod creation rate limit of %g per second reached", l.limits.Rate),
+				RetryAfter: delay,
+			}
+		}
+	}
+
+	l.running++
+	l.perNode[node]++
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			l.mu.Lock()
+			defer l.mu.Unlock()
+			l.running--
+			if l.perNode[node]--; l.perNode[node] <= 0 {
+				delete(l.perNode, node)
+			}
+		})
+	}, "", nil
+}
@@ -0,0 +1,130 @@
+package mcp
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestDebugPodLimiter(t *testing.T) {
+	t.Run("global concurrency", func(t *testing.T) {
+		l := newDebugPodLimiter(DebugPodLimits{MaxConcurrent: 2})
+		release1, err := l.acquire("node-a")
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if _, err := l.acquire("node-b"); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		_, err = l.acquire("node-c")
+		var busyErr *BusyError
+		if !errors.As(err, &busyErr) {
+			t.Fatalf("Expected a busy error, got %v", err)
+		}
+		if !strings.Contains(err.Error(), "retry after 5s") {
+			t.Fatalf("Expected a retry delay in the error, got %q", err.Error())
+		}
+		release1()
+		// Releasing twice must not free another slot.
+		release1()
+		if _, err := l.acquire("node-c"); err != nil {
+			t.Fatalf("Expected a free slot after release, got %v", err)
+		}
+		if _, err := l.acquire("node-d"); err == nil {
+			t.Fatal("Expected a busy error after a double release")
+		}
+	})
+
+	t.Run("per-node concurrency", func(t *testing.T) {
+		l := newDebugPodLimiter(DebugPodLimits{MaxConcurrentPerNode: 1})
+		release, err := l.acquire("node-a")
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if _, err := l.acquire("node-a"); err == nil {
+			t.Fatal("Expected a busy error on the same node")
+		}
+		if _, err := l.acquire("node-b"); err != nil {
+			t.Fatalf("Unexpected error on another node: %v", err)
+		}
+		release()
+		if _, err := l.acquire("node-a"); err != nil {
+			t.Fatalf("Expected a free slot after release, got %v", err)
+		}
+	})
+
+	t.Run("rate limit", func(t *testing.T) {
+		l := newDebugPodLimiter(DebugPodLimits{Rate: 0.1, Burst: 2})
+		for range 2 {
+			release, err := l.acquire("node-a")
+			if err != nil {
+				t.Fatalf("Unexpected error within the burst: %v", err)
+			}
+			release()
+		}
+		_, err := l.acquire("node-a")
+		var busyErr *BusyError
+		if !errors.As(err, &busyErr) {
+			t.Fatalf("Expected a busy error, got %v", err)
+		}
+		if busyErr.RetryAfter <= 0 {
+			t.Fatalf("Expected a positive retry delay, got %v", busyErr.RetryAfter)
+		}
+	})
+
+	t.Run("evicts idle pooled pods", func(t *testing.T) {
+		l := newDebugPodLimiter(DebugPodLimits{MaxConcurrent: 3, MaxConcurrentPerNode: 1})
+		idle := map[string]func(){}
+		for _, node := range []string{"node-a", "node-b"} {
+			release, err := l.acquire(node)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			idle[node] = release
+		}
+		var evicted []string
+		l.addEvictor(func(node string) bool {
+			for idleNode, release := range idle {
+				if node == "" || node == idleNode {
+					evicted = append(evicted, idleNode)
+					delete(idle, idleNode)
+					release()
+					return true
+				}
+			}
+			return false
+		})
+
+		// The per-node limit evicts the idle pod of the node.
+		if _, err := l.acquire("node-a"); err != nil {
+			t.Fatalf("Expected the idle pod of the node to be evicted, got %v", err)
+		}
+		if len(evicted) != 1 || evicted[0] != "node-a" {
+			t.Fatalf("Expected the idle pod of node-a to be evicted, got %v", evicted)
+		}
+		if _, err := l.acquire("node-c"); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		// The global limit evicts the idle pod of any node.
+		if _, err := l.acquire("node-d"); err != nil {
+			t.Fatalf("Expected an idle pod to be evicted, got %v", err)
+		}
+		if len(evicted) != 2 || evicted[1] != "node-b" {
+			t.Fatalf("Expected the idle pod of node-b to be evicted, got %v", evicted)
+		}
+		// Pods in use are not evicted.
+		var busyErr *BusyError
+		if _, err := l.acquire("node-e"); !errors.As(err, &busyErr) {
+			t.Fatalf("Expected a busy error without idle pods, got %v", err)
+		}
+	})
+
+	t.Run("no limits", func(t *testing.T) {
+		l := newDebugPodLimiter(DebugPodLimits{})
+		for range 100 {
+			if _, err := l.acquire("node-a"); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+		}
+	})
+}
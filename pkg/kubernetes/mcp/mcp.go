@@ -1,46 +1,196 @@
 package mcp
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"log"
+	"time"
 
 	"k8s.io/client-go/rest"
-	"k8s.io/client-go/tools/clientcmd"
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/ovn-kubernetes/ovn-kubernetes-mcp/pkg/clusters"
+	"github.com/ovn-kubernetes/ovn-kubernetes-mcp/pkg/executor"
 	"github.com/ovn-kubernetes/ovn-kubernetes-mcp/pkg/kubernetes/client"
 )
 
 type Config struct {
-	Kubeconfig string
+	Clusters       clusters.Config
+	DebugPodLimits DebugPodLimits
+	// DebugPodIdleTTL is how long a node debug pod is kept for reuse after its last
+	// command. Zero disables the reuse of debug pods.
+	DebugPodIdleTTL time.Duration
+	// DebugPodTemplate customizes the node debug pods.
+	DebugPodTemplate client.DebugPodTemplate
+	// DebugPodImage is the image the debug pod template is validated with when
+	// connecting to a cluster.
+	DebugPodImage string
+	// Impersonate makes the cluster calls of the tools as the authenticated caller
+	// of the tool call, so that they are authorized with the RBAC permissions of
+	// the caller. The tool calls without authenticated caller are rejected.
+	Impersonate bool
+	// RecordDir is the bundle directory the cluster calls of the tools are recorded
+	// to, for replay without cluster. Recording is disabled if empty.
+	RecordDir string
 }
 
+// MCPServer provides the Kubernetes tools. It is also the executor running the
+// commands of the other live-cluster tools in pods and node debug pods, in the
+// cluster selected by the cluster parameter of the tool call.
 type MCPServer struct {
-	clientSet *client.OVNKMCPServerClientSet
+	clusters    *clusters.Registry
+	impersonate bool
+	bundle      *client.BundleWriter
+}
+
+var _ executor.Executor = &MCPServer{}
+
+// NewRESTConfig builds the Kubernetes client configuration of the default cluster,
+// from the kubeconfig files if set, or from the in-cluster ServiceAccount
+// credentials otherwise.
+func NewRESTConfig(cfg Config) (*rest.Config, error) {
+	registry, err := clusters.NewRegistry(cfg.Clusters, nil)
+	if err != nil {
+		return nil, err
+	}
+	return registry.DefaultConfig()
 }
 
 func NewMCPServer(cfg Config) (*MCPServer, error) {
-	var config *rest.Config
-	var err error
-	if cfg.Kubeconfig != "" {
-		config, err = clientcmd.BuildConfigFromFlags("", cfg.Kubeconfig)
+	s := &MCPServer{impersonate: cfg.Impersonate}
+	// The debug pods of all the clusters share the limits.
+	limiter := newDebugPodLimiter(cfg.DebugPodLimits)
+	if cfg.RecordDir != "" {
+		bundle, err := client.OpenBundle(cfg.RecordDir)
 		if err != nil {
 			return nil, err
 		}
-	} else {
-		config, err = rest.InClusterConfig()
+		s.bundle = bundle
+	}
+
+	// The client of a cluster is created on the first tool call targeting it.
+	newClient := func(ctx context.Context, name string, config *rest.Config) (client.Interface, error) {
+		// The client is set up with the credentials of the server.
+		ctx = client.WithImpersonation(ctx, nil)
+		if cfg.Impersonate {
+			var err error
+			if config, err = client.ImpersonatingConfig(config); err != nil {
+				return nil, err
+			}
+		}
+		clientSet, err := client.NewOVNKMCPServerClientSet(config)
 		if err != nil {
 			return nil, err
 		}
+		if cfg.Impersonate {
+			if err := clientSet.CheckImpersonation(ctx); err != nil {
+				return nil, err
+			}
+		}
+		clientSet.SetDebugPodTemplate(cfg.DebugPodTemplate)
+		clientSet.SetDebugPodLimiter(limiter.acquire)
+		if err := clientSet.ValidateDebugPodTemplate(ctx, cfg.DebugPodImage); err != nil {
+			return nil, fmt.Errorf("invalid debug pod template: %w", err)
+		}
+		if cfg.DebugPodIdleTTL > 0 {
+			clientSet.StartDebugPodPool(cfg.DebugPodIdleTTL)
+			limiter.addEvictor(clientSet.EvictIdleDebugPod)
+		}
+		clientSet.StartDebugPodCollector()
+		if s.bundle != nil {
+			return client.NewRecorder(clientSet, s.bundle, name), nil
+		}
+		return clientSet, nil
+	}
+	registry, err := clusters.NewRegistry(cfg.Clusters, newClient)
+	if err != nil {
+		s.closeBundle()
+		return nil, err
 	}
+	s.clusters = registry
+	return s, nil
+}
 
-	clientSet, err := client.NewOVNKMCPServerClientSet(config)
+// NewReplayMCPServer creates a server answering the tool calls with the cluster
+// calls recorded in a bundle directory, without cluster. The calls without
+// cluster target defaultCluster, which defaults to the only recorded cluster.
+func NewReplayMCPServer(bundleDir, defaultCluster string) (*MCPServer, error) {
+	replayers, err := client.LoadBundle(bundleDir)
+	if err != nil {
+		return nil, err
+	}
+	clients := map[string]client.Interface{}
+	for name, replayer := range replayers {
+		clients[name] = replayer
+	}
+	registry, err := clusters.NewStaticRegistry(clients, defaultCluster, bundleDir)
 	if err != nil {
 		return nil, err
 	}
+	return &MCPServer{clusters: registry}, nil
+}
+
+// Clusters returns the clusters the tools can target.
+func (s *MCPServer) Clusters() *clusters.Registry {
+	return s.clusters
+}
+
+// ConnectDefaultCluster creates the client of the default cluster, checking that
+// debug pods can be created in it from the configured template. It does nothing
+// if there is no default cluster.
+func (s *MCPServer) ConnectDefaultCluster(ctx context.Context) error {
+	if s.clusters.DefaultName() == "" {
+		return nil
+	}
+	_, err := s.clusters.Client(ctx)
+	return err
+}
+
+// client returns the client of the cluster targeted by the tool call. With
+// impersonation, it fails closed if the caller of the tool call is unknown, rather
+// than making the calls with the credentials of the server.
+func (s *MCPServer) client(ctx context.Context) (client.Interface, error) {
+	if s.impersonate && client.ImpersonationFrom(ctx) == nil {
+		return nil, errors.New("impersonation is enabled, but the caller of the tool call is not authenticated")
+	}
+	return s.clusters.Client(ctx)
+}
+
+// callContext returns a function restoring the cluster and impersonated user of the
+// tool call in the context of a command running in the background.
+func callContext(ctx context.Context) func(context.Context) context.Context {
+	cluster := clusters.Name(ctx)
+	impersonation := client.ImpersonationFrom(ctx)
+	return func(ctx context.Context) context.Context {
+		return client.WithImpersonation(clusters.WithName(ctx, cluster), impersonation)
+	}
+}
+
+// auditCluster returns the cluster targeted by the tool call in the audit log,
+// empty for the default cluster.
+func (s *MCPServer) auditCluster(ctx context.Context) string {
+	if name := clusters.Name(ctx); name != s.clusters.DefaultName() {
+		return name
+	}
+	return ""
+}
+
+// closeBundle closes the recorded bundle, if any.
+func (s *MCPServer) closeBundle() {
+	if s.bundle == nil {
+		return
+	}
+	if err := s.bundle.Close(); err != nil {
+		log.Printf("Failed to close bundle %s: %v", s.bundle.Dir(), err)
+	}
+}
 
-	return &MCPServer{
-		clientSet: clientSet,
-	}, nil
+// Close stops the debug pod garbage collection, deletes the debug pods kept for
+// reuse in every cluster and closes the recorded bundle.
+func (s *MCPServer) Close() {
+	s.clusters.Close()
+	s.closeBundle()
 }
 
 func (s *MCPServer) AddTools(server *mcp.Server) {
@@ -53,6 +203,7 @@ Retrieves logs from a running or terminated pod. Supports filtering by pattern,
 limiting output with head/tail, and retrieving logs from previous container instances.
 
 Parameters:
+- cluster (optional): Cluster of the pod, from cluster-list (defaults to the default cluster)
 - name (required): Name of the pod
 - namespace (optional): Namespace of the pod (defaults to "default")
 - container (optional): Specific container name (required for multi-container pods)
@@ -89,6 +240,7 @@ Retrieves a single resource from the cluster using its group, version, kind, and
 Supports different output formats for viewing the resource data.
 
 Parameters:
+- cluster (optional): Cluster of the resource, from cluster-list (defaults to the default cluster)
 - group (optional): API group of the resource (e.g., "apps", "networking.k8s.io"). Empty for core resources
 - version (required): API version of the resource (e.g., "v1", "v1beta1")
 - kind (required): Kind of the resource (e.g., "Pod", "Service", "Deployment", "ConfigMap")
@@ -117,15 +269,20 @@ Lists resources in a namespace or across all namespaces. Supports filtering by
 label selector and different output formats.
 
 Parameters:
+- cluster (optional): Cluster of the resource, from cluster-list (defaults to the default cluster)
 - group (optional): API group of the resource (e.g., "apps", "networking.k8s.io"). Empty for core resources
 - version (required): API version of the resource (e.g., "v1", "v1beta1")
 - kind (required): Kind of the resource (e.g., "Pod", "Service", "Deployment")
 - namespace (optional): Filter by namespace. If omitted, lists resources across all namespaces
 - label_selector (optional): Filter by label selector (e.g., "app=my-app", "component=network")
 - output_type (optional): Output format - 'yaml', 'json', 'jsonpath', or 'wide' (default: table format with name, namespace, age. wide will include labels and annotations)
+- page_size (optional): Number of resources per page (default: 100, max: 1000)
+- cursor (optional): next_cursor of the previous page, to get the next page
 
-Returns a list of matching resources. Use this to discover what resources exist in the
-cluster before retrieving specific ones with resource-get.
+Returns a page of matching resources, with a next_cursor until the last page. The total
+number of resources is returned on the last page, and on every page without label selector.
+With jsonpath, the template is applied to the list of the resources of the page. Use this to
+discover what resources exist in the cluster before retrieving specific ones with resource-get.
 
 Examples:
 - List all pods in a namespace: {"version": "v1", "kind": "Pod", "namespace": "default"}
@@ -3,10 +3,13 @@ package mcp
 import (
 	"context"
 	"fmt"
+	"io"
 	"path/filepath"
 	"strings"
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/ovn-kubernetes/ovn-kubernetes-mcp/pkg/audit"
+	"github.com/ovn-kubernetes/ovn-kubernetes-mcp/pkg/executor"
 	"github.com/ovn-kubernetes/ovn-kubernetes-mcp/pkg/kubernetes/types"
 )
 
@@ -56,10 +59,41 @@ func (s *MCPServer) DebugNode(ctx context.Context, req *mcp.CallToolRequest, in
 		return nil, types.DebugNodeResult{}, err
 	}
 
-	stdout, stderr, err := s.clientSet.DebugNode(ctx, in.Name, in.Image, in.Command, in.HostPath, in.MountPath)
+	clusterClient, err := s.client(ctx)
+	if err != nil {
+		return nil, types.DebugNodeResult{}, err
+	}
+
+	audit.RecordCommand(ctx, audit.Command{Cluster: s.auditCluster(ctx), Node: in.Name, Image: in.Image, Command: in.Command})
+	stdout, stderr, err := clusterClient.DebugNode(ctx, in.Name, in.Image, in.Command, in.HostPath, in.MountPath)
 	if err != nil {
 		return nil, types.DebugNodeResult{}, err
 	}
 
 	return nil, types.DebugNodeResult{Stdout: stdout, Stderr: stderr}, nil
 }
+
+// PrepareDebugNode validates a command to run in the background in a dedicated debug
+// pod on a node, and records it in the audit log of the tool call. The returned
+// function runs the command in the cluster and as the user of the tool call; the
+// debug pod is deleted once it returns.
+func (s *MCPServer) PrepareDebugNode(ctx context.Context, in types.DebugNodeParams) (executor.StreamFunc, error) {
+	if err := validatePath(in.HostPath, "hostPath"); err != nil {
+		return nil, err
+	}
+
+	if err := validatePath(in.MountPath, "mountPath"); err != nil {
+		return nil, err
+	}
+
+	withCallContext := callContext(ctx)
+	audit.RecordCommand(ctx, audit.Command{Cluster: s.auditCluster(ctx), Node: in.Name, Image: in.Image, Command: in.Command})
+	return func(ctx context.Context, stdout, stderr io.Writer) error {
+		ctx = withCallContext(ctx)
+		clusterClient, err := s.client(ctx)
+		if err != nil {
+			return err
+		}
+		return clusterClient.StreamDebugNode(ctx, in.Name, in.Image, in.Command, in.HostPath, in.MountPath, stdout, stderr)
+	}, nil
+}
@@ -2,8 +2,11 @@ package mcp
 
 import (
 	"context"
+	"io"
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/ovn-kubernetes/ovn-kubernetes-mcp/pkg/audit"
+	"github.com/ovn-kubernetes/ovn-kubernetes-mcp/pkg/executor"
 	"github.com/ovn-kubernetes/ovn-kubernetes-mcp/pkg/kubernetes/types"
 	"github.com/ovn-kubernetes/ovn-kubernetes-mcp/pkg/utils"
 )
@@ -13,9 +16,14 @@ const defaultMaxLines = 100
 // GetPodLogs gets the logs of a pod by name and namespace.
 func (s *MCPServer) GetPodLogs(ctx context.Context, req *mcp.CallToolRequest, in types.GetPodLogsParams) (*mcp.CallToolResult, types.GetPodLogsResult, error) {
 
+	clusterClient, err := s.client(ctx)
+	if err != nil {
+		return nil, types.GetPodLogsResult{}, err
+	}
+
 	// Match the pattern to the logs
 	lines, err := in.PatternParams.ExecuteWithMatch(func() ([]string, error) {
-		lines, err := s.clientSet.GetPodLogs(ctx, in.Namespace, in.Name, in.Container, in.Previous)
+		lines, err := clusterClient.GetPodLogs(ctx, in.Namespace, in.Name, in.Container, in.Previous)
 		if err != nil {
 			return nil, err
 		}
@@ -34,10 +42,31 @@ func (s *MCPServer) GetPodLogs(ctx context.Context, req *mcp.CallToolRequest, in
 
 // ExecPod executes a command in a pod by name and namespace.
 func (s *MCPServer) ExecPod(ctx context.Context, req *mcp.CallToolRequest, in types.ExecPodParams) (*mcp.CallToolResult, types.ExecPodResult, error) {
-	stdout, stderr, err := s.clientSet.ExecPod(ctx, in.Name, in.Namespace, in.Container, in.Command)
+	clusterClient, err := s.client(ctx)
+	if err != nil {
+		return nil, types.ExecPodResult{}, err
+	}
+	audit.RecordCommand(ctx, audit.Command{Cluster: s.auditCluster(ctx), Namespace: in.Namespace, Pod: in.Name, Container: in.Container, Command: in.Command})
+	stdout, stderr, err := clusterClient.ExecPod(ctx, in.Name, in.Namespace, in.Container, in.Command)
 	if err != nil {
 		return nil, types.ExecPodResult{}, err
 	}
 
 	return nil, types.ExecPodResult{Stdout: stdout, Stderr: stderr}, nil
 }
+
+// PrepareExecPod records a command to run in the background in a pod in the audit log
+// of the tool call. The returned function runs the command, in the cluster and as
+// the user of the tool call.
+func (s *MCPServer) PrepareExecPod(ctx context.Context, in types.ExecPodParams) (executor.StreamFunc, error) {
+	withCallContext := callContext(ctx)
+	audit.RecordCommand(ctx, audit.Command{Cluster: s.auditCluster(ctx), Namespace: in.Namespace, Pod: in.Name, Container: in.Container, Command: in.Command})
+	return func(ctx context.Context, stdout, stderr io.Writer) error {
+		ctx = withCallContext(ctx)
+		clusterClient, err := s.client(ctx)
+		if err != nil {
+			return err
+		}
+		return clusterClient.StreamExecPod(ctx, in.Name, in.Namespace, in.Container, in.Command, stdout, stderr)
+	}, nil
+}
@@ -7,6 +7,9 @@ import (
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 	"github.com/ovn-kubernetes/ovn-kubernetes-mcp/pkg/kubernetes/types"
+	"github.com/ovn-kubernetes/ovn-kubernetes-mcp/pkg/pagination"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/utils/ptr"
 )
 
 // GetResource gets a resource by group, version, kind, name and namespace.
@@ -29,8 +32,13 @@ func (s *MCPServer) GetResource(ctx context.Context, req *mcp.CallToolRequest, i
 		return nil, types.GetResourceResult{}, err
 	}
 
+	clusterClient, err := s.client(ctx)
+	if err != nil {
+		return nil, types.GetResourceResult{}, err
+	}
+
 	// Get the resource by group, version, kind, name and namespace.
-	resource, err := s.clientSet.GetResource(ctx, in.Group, in.Version, in.Kind, in.Name, in.Namespace)
+	resource, err := clusterClient.GetResource(ctx, in.Group, in.Version, in.Kind, in.Name, in.Namespace)
 	if err != nil {
 		return nil, types.GetResourceResult{}, err
 	}
@@ -81,15 +89,35 @@ func (s *MCPServer) ListResources(ctx context.Context, req *mcp.CallToolRequest,
 		return nil, types.ListResourcesResult{}, err
 	}
 
+	// The pages are listed with the Kubernetes limit and continue options, the
+	// cursor keeps the continue token and the number of resources of the previous
+	// pages.
+	pageSize, err := in.Params.Size()
+	if err != nil {
+		return nil, types.ListResourcesResult{}, err
+	}
+	query := []any{in.Cluster, in.Group, in.Version, in.Kind, in.Namespace, in.LabelSelector}
+	cursor, err := in.Params.Decode(query...)
+	if err != nil {
+		return nil, types.ListResourcesResult{}, err
+	}
+
+	clusterClient, err := s.client(ctx)
+	if err != nil {
+		return nil, types.ListResourcesResult{}, err
+	}
+
 	// List the resources by group, version, kind and namespace.
-	resources, err := s.clientSet.ListResources(ctx, in.Group, in.Version, in.Kind, in.Namespace, in.LabelSelector)
+	resources, err := clusterClient.ListResources(ctx, in.Group, in.Version, in.Kind, in.Namespace, in.LabelSelector,
+		int64(pageSize), cursor.Continue)
 	if err != nil {
 		return nil, types.ListResourcesResult{}, err
 	}
+	page := listPagination(resources, cursor, query)
 
 	// If there are no resources, return an empty list.
 	if len(resources.Items) == 0 {
-		return nil, types.ListResourcesResult{Resources: []types.Resource{}}, nil
+		return nil, types.ListResourcesResult{Resources: []types.Resource{}, Result: page}, nil
 	}
 
 	resourcesData := make([]types.Resource, 0)
@@ -125,5 +153,47 @@ func (s *MCPServer) ListResources(ctx context.Context, req *mcp.CallToolRequest,
 		}
 	}
 
-	return nil, types.ListResourcesResult{Resources: resourcesData}, nil
+	return nil, types.ListResourcesResult{Resources: resourcesData, Result: page}, nil
+}
+
+// Get returns a resource by name, as the user of the tool call. It lets the other
+// servers look up the cluster.
+func (s *MCPServer) Get(ctx context.Context, group, version, kind, namespace, name string) (*unstructured.Unstructured, error) {
+	clusterClient, err := s.client(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return clusterClient.GetResource(ctx, group, version, kind, name, namespace)
+}
+
+// List returns all the resources of a kind in a namespace, or in all the
+// namespaces if namespace is empty, matching the label selector, as the user of the
+// tool call. It lets the other servers look up the cluster.
+func (s *MCPServer) List(ctx context.Context, group, version, kind, namespace, labelSelector string) ([]unstructured.Unstructured, error) {
+	clusterClient, err := s.client(ctx)
+	if err != nil {
+		return nil, err
+	}
+	list, err := clusterClient.ListResources(ctx, group, version, kind, namespace, labelSelector, 0, "")
+	if err != nil {
+		return nil, err
+	}
+	return list.Items, nil
+}
+
+// listPagination returns the pagination of a page of resources listed from the
+// cursor. The total is known on the last page, or when the API server returns the
+// number of remaining resources, which it does not for lists with a label selector.
+func listPagination(resources *unstructured.UnstructuredList, cursor pagination.Cursor, query []any) pagination.Result {
+	var result pagination.Result
+	listed := cursor.Offset + len(resources.Items)
+	if continueToken := resources.GetContinue(); continueToken != "" {
+		result.NextCursor = pagination.Encode(pagination.Cursor{Offset: listed, Continue: continueToken}, query...)
+		if remaining := resources.GetRemainingItemCount(); remaining != nil {
+			result.Total = ptr.To(listed + int(*remaining))
+		}
+	} else {
+		result.Total = &listed
+	}
+	return result
 }
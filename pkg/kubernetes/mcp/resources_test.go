@@ -0,0 +1,53 @@
+package mcp
+
+import (
+	"testing"
+
+	"github.com/ovn-kubernetes/ovn-kubernetes-mcp/pkg/pagination"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/utils/ptr"
+)
+
+func TestListPagination(t *testing.T) {
+	query := []any{"", "v1", "Pod", "default", ""}
+	newList := func(items int, continueToken string, remaining *int64) *unstructured.UnstructuredList {
+		list := &unstructured.UnstructuredList{Items: make([]unstructured.Unstructured, items)}
+		list.SetContinue(continueToken)
+		list.SetRemainingItemCount(remaining)
+		return list
+	}
+
+	tests := []struct {
+		name         string
+		list         *unstructured.UnstructuredList
+		cursor       pagination.Cursor
+		wantNext     bool
+		wantContinue string
+		wantTotal    *int
+	}{
+		{"single page", newList(3, "", nil), pagination.Cursor{}, false, "", ptr.To(3)},
+		{"first page with remaining count", newList(2, "token-1", ptr.To(int64(5))), pagination.Cursor{}, true, "token-1", ptr.To(7)},
+		{"page with label selector", newList(2, "token-2", nil), pagination.Cursor{Offset: 2}, true, "token-2", nil},
+		{"last page", newList(1, "", nil), pagination.Cursor{Offset: 4, Continue: "token-2"}, false, "", ptr.To(5)},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			result := listPagination(test.list, test.cursor, query)
+			if (result.NextCursor != "") != test.wantNext {
+				t.Fatalf("Unexpected next cursor %q", result.NextCursor)
+			}
+			if test.wantNext {
+				next, err := pagination.Params{Cursor: result.NextCursor}.Decode(query...)
+				if err != nil {
+					t.Fatalf("Failed to decode next cursor: %v", err)
+				}
+				if next.Continue != test.wantContinue || next.Offset != test.cursor.Offset+len(test.list.Items) {
+					t.Fatalf("Unexpected next cursor %+v", next)
+				}
+			}
+			if (result.Total == nil) != (test.wantTotal == nil) || result.Total != nil && *result.Total != *test.wantTotal {
+				t.Fatalf("Unexpected total %v, want %v", result.Total, test.wantTotal)
+			}
+		})
+	}
+}
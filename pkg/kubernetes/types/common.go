@@ -58,6 +58,24 @@ type NamespacedNameParams struct {
 	Namespace string `json:"namespace,omitempty"`
 }
 
+// PodTargetParams is a type that contains the pod a command runs in: either the
+// name and namespace of the pod, or the node whose OVN-Kubernetes pod runs the
+// command. The container is selected automatically if it is not set.
+type PodTargetParams struct {
+	Name      string `json:"name,omitempty"`
+	Namespace string `json:"namespace,omitempty"`
+	Node      string `json:"node,omitempty"`
+	Container string `json:"container,omitempty"`
+}
+
+// Target returns the pod or node of the target, for the error messages.
+func (p PodTargetParams) Target() string {
+	if p.Name == "" && p.Node != "" {
+		return "node " + p.Node
+	}
+	return fmt.Sprintf("pod %s/%s", p.Namespace, p.Name)
+}
+
 // NamespacedNameResult is a type that contains the name and namespace of a resource.
 // The fields are optional.
 type NamespacedNameResult struct {
@@ -65,6 +83,12 @@ type NamespacedNameResult struct {
 	Namespace string `json:"namespace,omitempty"`
 }
 
+// ClusterParams is a type that contains the cluster targeted by a live-cluster
+// tool. The default cluster is targeted if the cluster is empty.
+type ClusterParams struct {
+	Cluster string `json:"cluster,omitempty"`
+}
+
 // Resource is a type that contains the name, namespace, age, labels and annotations of a resource.
 type Resource struct {
 	NamespacedNameResult
@@ -4,6 +4,7 @@ import "github.com/ovn-kubernetes/ovn-kubernetes-mcp/pkg/utils"
 
 // GetPodLogsParams is a type that contains the name, namespace and container of a pod.
 type GetPodLogsParams struct {
+	ClusterParams
 	NamespacedNameParams
 	Container string `json:"container,omitempty"`
 	Previous  bool   `json:"previous,omitempty"`
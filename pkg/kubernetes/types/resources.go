@@ -1,7 +1,12 @@
 package types
 
+import (
+	"github.com/ovn-kubernetes/ovn-kubernetes-mcp/pkg/pagination"
+)
+
 // GetResourceParams is a type that contains the group, version, kind, name and namespace of a resource.
 type GetResourceParams struct {
+	ClusterParams
 	GroupVersionKind
 	GetParams
 }
@@ -18,13 +23,17 @@ type ListParams struct {
 	OutputParams
 }
 
-// ListResourcesParams is a type that contains the group, version, kind, namespace and output type of a resource.
+// ListResourcesParams is a type that contains the group, version, kind, namespace, output type and
+// pagination of a resource list.
 type ListResourcesParams struct {
+	ClusterParams
 	GroupVersionKind
 	ListParams
+	pagination.Params
 }
 
-// ListResourcesResult is a type that contains the resource data.
+// ListResourcesResult is a type that contains a page of the resource data.
 type ListResourcesResult struct {
 	Resources []Resource `json:"resources"`
+	pagination.Result
 }
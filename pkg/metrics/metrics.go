@@ -0,0 +1,92 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// namespace is the prefix of all the metrics exposed by the MCP server.
+const namespace = "ovnk_mcp"
+
+var (
+	// ToolCalls counts the tool calls per tool.
+	ToolCalls = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "tool_calls_total",
+		Help:      "Total number of tool calls.",
+	}, []string{"tool"})
+
+	// ToolErrors counts the tool calls that ended in an error per tool.
+	ToolErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "tool_errors_total",
+		Help:      "Total number of tool calls that ended in an error.",
+	}, []string{"tool"})
+
+	// ToolTimeouts counts the tool calls that hit the tool timeout per tool.
+	ToolTimeouts = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "tool_timeouts_total",
+		Help:      "Total number of tool calls that exceeded the tool timeout.",
+	}, []string{"tool"})
+
+	// ToolDuration observes the duration of the tool calls per tool.
+	ToolDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "tool_call_duration_seconds",
+		Help:      "Duration of tool calls in seconds.",
+		// 50ms to ~200s
+		Buckets: prometheus.ExponentialBuckets(0.05, 2, 13),
+	}, []string{"tool"})
+
+	// ToolOutputBytes observes the size of the tool results per tool.
+	ToolOutputBytes = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "tool_output_bytes",
+		Help:      "Size of the tool call results in bytes.",
+		// 256B to 64MiB
+		Buckets: prometheus.ExponentialBuckets(256, 4, 10),
+	}, []string{"tool"})
+
+	// DebugPodStartupDuration observes the time a debug pod takes to reach the running phase.
+	DebugPodStartupDuration = prometheus.NewHistogram(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "debug_pod_startup_duration_seconds",
+		Help:      "Time from the creation of a debug pod until it is running, in seconds.",
+		// 250ms to ~128s
+		Buckets: prometheus.ExponentialBuckets(0.25, 2, 10),
+	})
+
+	// DebugPodFailures counts the debug pods that could not be created or did not start.
+	DebugPodFailures = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "debug_pod_failures_total",
+		Help:      "Total number of debug pods that failed to be created or to reach the running phase.",
+	})
+
+	// DebugPodThrottled counts the debug pods that were refused by the debug pod
+	// limits per limit.
+	DebugPodThrottled = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "debug_pod_throttled_total",
+		Help:      "Total number of debug pods refused because a concurrency or rate limit was reached.",
+	}, []string{"limit"})
+)
+
+// Register registers all the MCP server metrics with the registerer.
+func Register(registerer prometheus.Registerer) error {
+	for _, collector := range []prometheus.Collector{
+		ToolCalls,
+		ToolErrors,
+		ToolTimeouts,
+		ToolDuration,
+		ToolOutputBytes,
+		DebugPodStartupDuration,
+		DebugPodFailures,
+		DebugPodThrottled,
+	} {
+		if err := registerer.Register(collector); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,43 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/ovn-kubernetes/ovn-kubernetes-mcp/pkg/artifacts"
+	"github.com/ovn-kubernetes/ovn-kubernetes-mcp/pkg/auth"
+)
+
+// Artifacts returns an MCP receiving middleware that lets the tools save their
+// complete output in the artifact store when they truncate it, see the artifacts
+// package, and adds a resource link to every saved artifact to the tool result.
+func Artifacts(store *artifacts.Store) mcp.Middleware {
+	return func(next mcp.MethodHandler) mcp.MethodHandler {
+		return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+			if method != "tools/call" {
+				return next(ctx, method, req)
+			}
+			ctx, saved := artifacts.WithCollector(ctx, store, toolName(req), auth.Username(req))
+			result, err := next(ctx, method, req)
+			callResult, ok := result.(*mcp.CallToolResult)
+			if err != nil || !ok || callResult == nil {
+				return result, err
+			}
+			for _, artifact := range saved() {
+				size := artifact.Size
+				callResult.Content = append(callResult.Content, &mcp.ResourceLink{
+					URI:      artifact.URI(),
+					Name:     artifact.ID,
+					Title:    fmt.Sprintf("Complete output of %s", artifact.Tool),
+					MIMEType: "text/plain",
+					Size:     &size,
+					Description: fmt.Sprintf("The output above is truncated. The complete output has %d lines and %d bytes: "+
+						"read it with resources/read, adding ?lines=<start>-<end> to page through it or ?grep=<regex> to search it.",
+						artifact.Lines, artifact.Size),
+				})
+			}
+			return callResult, nil
+		}
+	}
+}
@@ -0,0 +1,92 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/ovn-kubernetes/ovn-kubernetes-mcp/pkg/artifacts"
+	artifactsmcp "github.com/ovn-kubernetes/ovn-kubernetes-mcp/pkg/artifacts/mcp"
+)
+
+func TestArtifacts(t *testing.T) {
+	ctx := context.Background()
+	store, err := artifacts.NewStore(artifacts.Config{Dir: t.TempDir()})
+	if err != nil {
+		t.Fatalf("Failed to create store: %v", err)
+	}
+	defer store.Close()
+
+	server := mcp.NewServer(&mcp.Implementation{Name: "test"}, nil)
+	server.AddReceivingMiddleware(Artifacts(store))
+	artifactsmcp.NewMCPServer(store).AddResources(server)
+	mcp.AddTool(server, &mcp.Tool{Name: "dump-flows"}, func(ctx context.Context, req *mcp.CallToolRequest, in struct{}) (*mcp.CallToolResult, any, error) {
+		var lines []string
+		for i := 1; i <= 5000; i++ {
+			lines = append(lines, fmt.Sprintf("flow %d", i))
+		}
+		artifacts.Save(ctx, strings.Join(lines, "\n"))
+		return &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: strings.Join(lines[:10], "\n")}}}, nil, nil
+	})
+
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+	serverSession, err := server.Connect(ctx, serverTransport, nil)
+	if err != nil {
+		t.Fatalf("Failed to connect server: %v", err)
+	}
+	defer serverSession.Close()
+	client := mcp.NewClient(&mcp.Implementation{Name: "client"}, nil)
+	clientSession, err := client.Connect(ctx, clientTransport, nil)
+	if err != nil {
+		t.Fatalf("Failed to connect client: %v", err)
+	}
+	defer clientSession.Close()
+
+	result, err := clientSession.CallTool(ctx, &mcp.CallToolParams{Name: "dump-flows"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(result.Content) != 2 {
+		t.Fatalf("Expected the output and a resource link, got %d contents", len(result.Content))
+	}
+	link, ok := result.Content[1].(*mcp.ResourceLink)
+	if !ok || !strings.HasPrefix(link.URI, "artifact://") || link.Size == nil || *link.Size == 0 {
+		t.Fatalf("Unexpected resource link: %#v", result.Content[1])
+	}
+
+	tests := []struct {
+		name     string
+		query    string
+		wantText string
+		wantNext string
+	}{
+		{"default page", "", "flow 1\n", "?lines=1001-2000"},
+		{"line range", "?lines=4999-5000", "flow 4999\nflow 5000\n", ""},
+		{"regex search", "?grep=flow+1.0%24&max_matches=2", "100:flow 100\n110:flow 110\n", "?grep=flow+1.0%24&lines=120-&max_matches=2"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			read, err := clientSession.ReadResource(ctx, &mcp.ReadResourceParams{URI: link.URI + test.query})
+			if err != nil {
+				t.Fatalf("Failed to read artifact: %v", err)
+			}
+			contents := read.Contents[0]
+			if !strings.HasPrefix(contents.Text, test.wantText) {
+				t.Fatalf("Unexpected text: %q", contents.Text[:min(len(contents.Text), 100)])
+			}
+			next, _ := contents.Meta["next"].(string)
+			if test.wantNext == "" && next != "" || test.wantNext != "" && next != link.URI+test.wantNext {
+				t.Fatalf("Unexpected next page %q", next)
+			}
+			if total, _ := contents.Meta["total_lines"].(float64); total != 5000 {
+				t.Fatalf("Expected 5000 total lines, got %v", contents.Meta["total_lines"])
+			}
+		})
+	}
+
+	if _, err := clientSession.ReadResource(ctx, &mcp.ReadResourceParams{URI: "artifact://unknown"}); err == nil {
+		t.Fatal("Expected an error reading an unknown artifact")
+	}
+}
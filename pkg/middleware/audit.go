@@ -0,0 +1,116 @@
+package middleware
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/ovn-kubernetes/ovn-kubernetes-mcp/pkg/audit"
+	"github.com/ovn-kubernetes/ovn-kubernetes-mcp/pkg/auth"
+)
+
+// Audit returns an MCP receiving middleware that writes an audit record for every
+// tools/call request, including the commands executed on pods and nodes, and for
+// every completion/complete request executing commands. It must be added after
+// Authorization so that rejected calls are recorded too.
+func Audit(logger *audit.Logger) mcp.Middleware {
+	return func(next mcp.MethodHandler) mcp.MethodHandler {
+		return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+			if method == "completion/complete" {
+				return auditCompletion(ctx, logger, next, method, req)
+			}
+			if method != "tools/call" {
+				return next(ctx, method, req)
+			}
+
+			ctx, commands := audit.WithRecorder(ctx)
+			start := time.Now()
+			result, err := next(ctx, method, req)
+
+			record := &audit.Record{
+				Time:     start.UTC(),
+				Tool:     toolName(req),
+				Commands: commands(),
+				Duration: time.Since(start).Seconds(),
+				Outcome:  audit.OutcomeSuccess,
+			}
+			if callReq, ok := req.(*mcp.CallToolRequest); ok {
+				if callReq.Params != nil {
+					record.Arguments = callReq.Params.Arguments
+				}
+				if callReq.Session != nil {
+					record.SessionID = callReq.Session.ID()
+				}
+			}
+			if identity, ok := auth.IdentityFromRequest(req); ok {
+				record.User = identity.Username
+				record.Groups = identity.Groups
+			}
+			if err != nil {
+				record.Outcome = audit.OutcomeError
+				record.Error = err.Error()
+			} else if callResult, ok := result.(*mcp.CallToolResult); ok && callResult.IsError {
+				record.Outcome = audit.OutcomeError
+				record.Error = resultText(callResult)
+			}
+			if logErr := logger.Log(record); logErr != nil {
+				log.Printf("Failed to write audit record for tool %s: %v", record.Tool, logErr)
+			}
+			return result, err
+		}
+	}
+}
+
+// auditCompletion writes an audit record for a completion/complete request if it
+// executed commands on pods or nodes, like the bridges completed with ovs-vsctl.
+// The cached and static completions are not recorded.
+func auditCompletion(ctx context.Context, logger *audit.Logger, next mcp.MethodHandler, method string,
+	req mcp.Request) (mcp.Result, error) {
+	ctx, commands := audit.WithRecorder(ctx)
+	start := time.Now()
+	result, err := next(ctx, method, req)
+	record := &audit.Record{
+		Time:     start.UTC(),
+		Commands: commands(),
+		Duration: time.Since(start).Seconds(),
+		Outcome:  audit.OutcomeSuccess,
+	}
+	if len(record.Commands) == 0 {
+		return result, err
+	}
+	if completeReq, ok := req.(*mcp.CompleteRequest); ok && completeReq.Params != nil {
+		record.Completion = completeReq.Params.Argument.Name
+		if completeReq.Params.Context != nil {
+			record.Arguments, _ = json.Marshal(completeReq.Params.Context.Arguments)
+		}
+		if completeReq.Session != nil {
+			record.SessionID = completeReq.Session.ID()
+		}
+	}
+	if identity, ok := auth.IdentityFromRequest(req); ok {
+		record.User = identity.Username
+		record.Groups = identity.Groups
+	}
+	if err != nil {
+		record.Outcome = audit.OutcomeError
+		record.Error = err.Error()
+	}
+	if logErr := logger.Log(record); logErr != nil {
+		log.Printf("Failed to write audit record for completion of %s: %v", record.Completion, logErr)
+	}
+	return result, err
+}
+
+// resultText returns the text content of a tool result.
+func resultText(result *mcp.CallToolResult) string {
+	var texts []string
+	for _, content := range result.Content {
+		if text, ok := content.(*mcp.TextContent); ok {
+			texts = append(texts, text.Text)
+		}
+	}
+	return strings.Join(texts, "\n")
+}
@@ -0,0 +1,112 @@
+package middleware
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/ovn-kubernetes/ovn-kubernetes-mcp/pkg/audit"
+	"github.com/ovn-kubernetes/ovn-kubernetes-mcp/pkg/auth"
+)
+
+func TestAudit(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.log")
+	logger, err := audit.NewLogger(audit.Config{Path: path, Key: []byte("audit-log-key")})
+	if err != nil {
+		t.Fatalf("Failed to create audit logger: %v", err)
+	}
+	defer logger.Close()
+
+	handler := Audit(logger)(func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+		if method == "completion/complete" {
+			if req.(*mcp.CompleteRequest).Params.Argument.Name == "bridge" {
+				audit.RecordCommand(ctx, audit.Command{Namespace: "ovn-kubernetes", Pod: "ovnkube-node-abc", Command: []string{"ovs-vsctl", "list-br"}})
+			}
+			return &mcp.CompleteResult{}, nil
+		}
+		if method != "tools/call" {
+			return nil, nil
+		}
+		switch req.(*mcp.CallToolRequest).Params.Name {
+		case "ovn-show":
+			audit.RecordCommand(ctx, audit.Command{Namespace: "ovn-kubernetes", Pod: "ovnkube-node-abc", Command: []string{"ovn-nbctl", "show"}})
+			return &mcp.CallToolResult{}, nil
+		case "get-nft":
+			result := &mcp.CallToolResult{}
+			result.SetError(errors.New("command failed"))
+			return result, nil
+		default:
+			return nil, errors.New("user alice is not allowed to call tool tcpdump")
+		}
+	})
+
+	header := http.Header{}
+	header.Set(auth.UserHeader, "alice")
+	header.Add(auth.GroupHeader, "sre")
+	for _, tool := range []string{"ovn-show", "get-nft", "tcpdump"} {
+		req := &mcp.CallToolRequest{
+			Params: &mcp.CallToolParamsRaw{Name: tool, Arguments: json.RawMessage(`{"name":"value"}`)},
+			Extra:  &mcp.RequestExtra{Header: header},
+		}
+		_, _ = handler(context.Background(), "tools/call", req)
+	}
+	// Completions are audited when they execute commands.
+	for _, argument := range []string{"bridge", "namespace"} {
+		req := &mcp.CompleteRequest{
+			Params: &mcp.CompleteParams{
+				Argument: mcp.CompleteParamsArgument{Name: argument},
+				Context:  &mcp.CompleteContext{Arguments: map[string]string{"pod": "ovnkube-node-abc"}},
+			},
+			Extra: &mcp.RequestExtra{Header: header},
+		}
+		_, _ = handler(context.Background(), "completion/complete", req)
+	}
+	// Other methods are not audited.
+	_, _ = handler(context.Background(), "tools/list", &mcp.ListToolsRequest{})
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read audit log: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if len(lines) != 4 {
+		t.Fatalf("Expected 4 audit records, got %d", len(lines))
+	}
+	var records []audit.Record
+	for _, line := range lines {
+		var record audit.Record
+		if err := json.Unmarshal([]byte(line), &record); err != nil {
+			t.Fatalf("Invalid audit record %q: %v", line, err)
+		}
+		records = append(records, record)
+	}
+
+	if records[0].Tool != "ovn-show" || records[0].Outcome != audit.OutcomeSuccess {
+		t.Fatalf("Unexpected record: %+v", records[0])
+	}
+	if records[0].User != "alice" || len(records[0].Groups) != 1 || records[0].Groups[0] != "sre" {
+		t.Fatalf("Expected the caller identity to be recorded, got %+v", records[0])
+	}
+	if string(records[0].Arguments) != `{"name":"value"}` {
+		t.Fatalf("Expected the arguments to be recorded, got %s", records[0].Arguments)
+	}
+	if len(records[0].Commands) != 1 || strings.Join(records[0].Commands[0].Command, " ") != "ovn-nbctl show" {
+		t.Fatalf("Expected the executed command to be recorded, got %+v", records[0].Commands)
+	}
+	if records[1].Outcome != audit.OutcomeError || records[1].Error != "command failed" {
+		t.Fatalf("Expected the tool error to be recorded, got %+v", records[1])
+	}
+	if records[2].Outcome != audit.OutcomeError || !strings.Contains(records[2].Error, "not allowed") {
+		t.Fatalf("Expected the rejection to be recorded, got %+v", records[2])
+	}
+	if records[3].Completion != "bridge" || records[3].Tool != "" || records[3].User != "alice" ||
+		string(records[3].Arguments) != `{"pod":"ovnkube-node-abc"}` || len(records[3].Commands) != 1 {
+		t.Fatalf("Expected the commands of the bridge completion to be recorded, got %+v", records[3])
+	}
+}
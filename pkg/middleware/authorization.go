@@ -0,0 +1,85 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/ovn-kubernetes/ovn-kubernetes-mcp/pkg/auth"
+)
+
+// Authorization returns an MCP receiving middleware that enforces the authorization
+// policy. A tools/call request is rejected unless the policy grants the caller the
+// family of the tool, and tools/list only returns the tools the caller may invoke.
+// Tool calls, resource reads and completions without an authenticated identity
+// are rejected. A completion/complete request is rejected unless the policy grants
+// the caller the family returned by completionFamily for the completed argument,
+// the family of the tools whose lookups complete it.
+func Authorization(policy *auth.Policy, completionFamily func(argument string) string) mcp.Middleware {
+	return func(next mcp.MethodHandler) mcp.MethodHandler {
+		return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+			switch method {
+			case "tools/call":
+				identity, ok := auth.IdentityFromRequest(req)
+				if !ok {
+					return nil, fmt.Errorf("unauthenticated caller is not allowed to call tools")
+				}
+				callReq, ok := req.(*mcp.CallToolRequest)
+				if !ok || callReq.Params == nil {
+					return next(ctx, method, req)
+				}
+				if !policy.Allowed(identity, callReq.Params.Name) {
+					log.Printf("Denied tool %s for user %s", callReq.Params.Name, identity.Username)
+					return nil, fmt.Errorf("user %s is not allowed to call tool %s", identity.Username, callReq.Params.Name)
+				}
+				return next(ctx, method, req)
+			case "resources/read":
+				// Artifacts can only be read by the user who called the tool producing
+				// them, which requires an authenticated identity.
+				if _, ok := auth.IdentityFromRequest(req); !ok {
+					return nil, fmt.Errorf("unauthenticated caller is not allowed to read resources")
+				}
+				return next(ctx, method, req)
+			case "completion/complete":
+				// Completions look up resource names in the cluster, and run the
+				// commands of the tools in the pods and nodes.
+				identity, ok := auth.IdentityFromRequest(req)
+				if !ok {
+					return nil, fmt.Errorf("unauthenticated caller is not allowed to complete arguments")
+				}
+				completeReq, ok := req.(*mcp.CompleteRequest)
+				if !ok || completeReq.Params == nil {
+					return next(ctx, method, req)
+				}
+				argument := completeReq.Params.Argument.Name
+				// The arguments without completion have no values to protect.
+				if family := completionFamily(argument); family != "" && !policy.AllowedFamily(identity, family) {
+					log.Printf("Denied completion of %s for user %s", argument, identity.Username)
+					return nil, fmt.Errorf("user %s is not allowed to complete argument %s", identity.Username, argument)
+				}
+				return next(ctx, method, req)
+			case "tools/list":
+				result, err := next(ctx, method, req)
+				if err != nil {
+					return result, err
+				}
+				listResult, ok := result.(*mcp.ListToolsResult)
+				if !ok {
+					return result, nil
+				}
+				identity, _ := auth.IdentityFromRequest(req)
+				tools := make([]*mcp.Tool, 0, len(listResult.Tools))
+				for _, tool := range listResult.Tools {
+					if policy.Allowed(identity, tool.Name) {
+						tools = append(tools, tool)
+					}
+				}
+				listResult.Tools = tools
+				return listResult, nil
+			default:
+				return next(ctx, method, req)
+			}
+		}
+	}
+}
@@ -0,0 +1,131 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/ovn-kubernetes/ovn-kubernetes-mcp/pkg/auth"
+)
+
+func TestAuthorization(t *testing.T) {
+	policy := &auth.Policy{
+		Rules: []auth.PolicyRule{
+			{Users: []string{"alice"}, Families: []string{auth.FamilyOVN}},
+		},
+	}
+	m := Authorization(policy, func(argument string) string {
+		return map[string]string{"datapath": auth.FamilyOVN, "bridge": auth.FamilyOVS}[argument]
+	})
+
+	newExtra := func(user string) *mcp.RequestExtra {
+		header := http.Header{}
+		if user != "" {
+			header.Set(auth.UserHeader, user)
+		}
+		return &mcp.RequestExtra{Header: header}
+	}
+
+	t.Run("allows granted tools/call", func(t *testing.T) {
+		called := false
+		handler := m(func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+			called = true
+			return &mcp.CallToolResult{}, nil
+		})
+		req := &mcp.CallToolRequest{Params: &mcp.CallToolParamsRaw{Name: "ovn-show"}, Extra: newExtra("alice")}
+		if _, err := handler(context.Background(), "tools/call", req); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if !called {
+			t.Fatal("Expected the tool handler to be called")
+		}
+	})
+
+	t.Run("denies tools/call outside of the policy", func(t *testing.T) {
+		handler := m(func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+			t.Fatal("Tool handler should not be called")
+			return nil, nil
+		})
+		req := &mcp.CallToolRequest{Params: &mcp.CallToolParamsRaw{Name: "tcpdump"}, Extra: newExtra("alice")}
+		if _, err := handler(context.Background(), "tools/call", req); err == nil {
+			t.Fatal("Expected an authorization error")
+		}
+	})
+
+	t.Run("denies unauthenticated tools/call", func(t *testing.T) {
+		handler := m(func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+			t.Fatal("Tool handler should not be called")
+			return nil, nil
+		})
+		req := &mcp.CallToolRequest{Params: &mcp.CallToolParamsRaw{Name: "ovn-show"}, Extra: newExtra("")}
+		if _, err := handler(context.Background(), "tools/call", req); err == nil {
+			t.Fatal("Expected an authorization error")
+		}
+	})
+
+	t.Run("denies unauthenticated resources/read", func(t *testing.T) {
+		handler := m(func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+			t.Fatal("Resource handler should not be called")
+			return nil, nil
+		})
+		req := &mcp.ReadResourceRequest{Params: &mcp.ReadResourceParams{URI: "artifact://abc"}, Extra: newExtra("")}
+		if _, err := handler(context.Background(), "resources/read", req); err == nil {
+			t.Fatal("Expected an authorization error")
+		}
+	})
+
+	t.Run("denies unauthenticated completion/complete", func(t *testing.T) {
+		handler := m(func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+			t.Fatal("Completion handler should not be called")
+			return nil, nil
+		})
+		req := &mcp.CompleteRequest{Params: &mcp.CompleteParams{Argument: mcp.CompleteParamsArgument{Name: "pod"}}, Extra: newExtra("")}
+		if _, err := handler(context.Background(), "completion/complete", req); err == nil {
+			t.Fatal("Expected an authorization error")
+		}
+	})
+
+	t.Run("allows completion/complete of granted families", func(t *testing.T) {
+		for _, argument := range []string{"datapath", "unknown"} {
+			called := false
+			handler := m(func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+				called = true
+				return &mcp.CompleteResult{}, nil
+			})
+			req := &mcp.CompleteRequest{Params: &mcp.CompleteParams{Argument: mcp.CompleteParamsArgument{Name: argument}}, Extra: newExtra("alice")}
+			if _, err := handler(context.Background(), "completion/complete", req); err != nil {
+				t.Fatalf("Unexpected error completing %s: %v", argument, err)
+			}
+			if !called {
+				t.Fatalf("Expected the completion handler to be called for %s", argument)
+			}
+		}
+	})
+
+	t.Run("denies completion/complete outside of the policy", func(t *testing.T) {
+		handler := m(func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+			t.Fatal("Completion handler should not be called")
+			return nil, nil
+		})
+		req := &mcp.CompleteRequest{Params: &mcp.CompleteParams{Argument: mcp.CompleteParamsArgument{Name: "bridge"}}, Extra: newExtra("alice")}
+		if _, err := handler(context.Background(), "completion/complete", req); err == nil {
+			t.Fatal("Expected an authorization error")
+		}
+	})
+
+	t.Run("filters tools/list", func(t *testing.T) {
+		handler := m(func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+			return &mcp.ListToolsResult{Tools: []*mcp.Tool{{Name: "ovn-show"}, {Name: "ovs-list-br"}, {Name: "tcpdump"}}}, nil
+		})
+		req := &mcp.ListToolsRequest{Extra: newExtra("alice")}
+		result, err := handler(context.Background(), "tools/list", req)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		tools := result.(*mcp.ListToolsResult).Tools
+		if len(tools) != 1 || tools[0].Name != "ovn-show" {
+			t.Fatalf("Expected only ovn-show to be listed, got %v", tools)
+		}
+	})
+}
@@ -0,0 +1,31 @@
+package middleware
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/ovn-kubernetes/ovn-kubernetes-mcp/pkg/clusters"
+)
+
+// Cluster returns an MCP receiving middleware that targets the tools/call
+// requests at the cluster of their cluster argument, see the clusters package.
+// The other requests, and the calls without cluster, target the default cluster.
+func Cluster() mcp.Middleware {
+	return func(next mcp.MethodHandler) mcp.MethodHandler {
+		return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+			callReq, ok := req.(*mcp.CallToolRequest)
+			if method != "tools/call" || !ok || callReq.Params == nil || len(callReq.Params.Arguments) == 0 {
+				return next(ctx, method, req)
+			}
+			// Invalid arguments are reported by the validation of the tool input.
+			var arguments struct {
+				Cluster string `json:"cluster"`
+			}
+			if err := json.Unmarshal(callReq.Params.Arguments, &arguments); err == nil && arguments.Cluster != "" {
+				ctx = clusters.WithName(ctx, arguments.Cluster)
+			}
+			return next(ctx, method, req)
+		}
+	}
+}
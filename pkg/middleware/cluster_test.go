@@ -0,0 +1,56 @@
+package middleware
+
+import (
+	"context"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/ovn-kubernetes/ovn-kubernetes-mcp/pkg/clusters"
+)
+
+func TestCluster(t *testing.T) {
+	ctx := context.Background()
+	server := mcp.NewServer(&mcp.Implementation{Name: "test"}, nil)
+	server.AddReceivingMiddleware(Cluster())
+	type params struct {
+		Cluster string `json:"cluster,omitempty"`
+		Name    string `json:"name,omitempty"`
+	}
+	mcp.AddTool(server, &mcp.Tool{Name: "cluster-name"}, func(ctx context.Context, req *mcp.CallToolRequest, in params) (*mcp.CallToolResult, any, error) {
+		return &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: clusters.Name(ctx)}}}, nil, nil
+	})
+
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+	serverSession, err := server.Connect(ctx, serverTransport, nil)
+	if err != nil {
+		t.Fatalf("Failed to connect server: %v", err)
+	}
+	defer serverSession.Close()
+	client := mcp.NewClient(&mcp.Implementation{Name: "client"}, nil)
+	clientSession, err := client.Connect(ctx, clientTransport, nil)
+	if err != nil {
+		t.Fatalf("Failed to connect client: %v", err)
+	}
+	defer clientSession.Close()
+
+	tests := []struct {
+		name      string
+		arguments any
+		want      string
+	}{
+		{name: "no arguments", want: ""},
+		{name: "default cluster", arguments: map[string]any{"name": "worker-0"}, want: ""},
+		{name: "cluster", arguments: map[string]any{"cluster": "east", "name": "worker-0"}, want: "east"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			result, err := clientSession.CallTool(ctx, &mcp.CallToolParams{Name: "cluster-name", Arguments: test.arguments})
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if got := result.Content[0].(*mcp.TextContent).Text; got != test.want {
+				t.Fatalf("Expected cluster %q, got %q", test.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,24 @@
+package middleware
+
+import (
+	"context"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/ovn-kubernetes/ovn-kubernetes-mcp/pkg/auth"
+	"github.com/ovn-kubernetes/ovn-kubernetes-mcp/pkg/kubernetes/client"
+)
+
+// Impersonation returns an MCP receiving middleware that makes the cluster calls
+// of a request as its authenticated caller, see client.WithImpersonation. The
+// requests without authenticated caller are left without impersonation, and the
+// Kubernetes tools reject them when impersonation is enabled.
+func Impersonation() mcp.Middleware {
+	return func(next mcp.MethodHandler) mcp.MethodHandler {
+		return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+			if identity, ok := auth.IdentityFromRequest(req); ok {
+				ctx = client.WithImpersonation(ctx, &client.Impersonation{UserName: identity.Username, Groups: identity.Groups})
+			}
+			return next(ctx, method, req)
+		}
+	}
+}
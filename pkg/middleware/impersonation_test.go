@@ -0,0 +1,49 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/ovn-kubernetes/ovn-kubernetes-mcp/pkg/auth"
+	"github.com/ovn-kubernetes/ovn-kubernetes-mcp/pkg/kubernetes/client"
+)
+
+func TestImpersonation(t *testing.T) {
+	tests := []struct {
+		name   string
+		user   string
+		groups []string
+		want   *client.Impersonation
+	}{
+		{name: "unauthenticated"},
+		{name: "user", user: "alice", want: &client.Impersonation{UserName: "alice"}},
+		{name: "user and groups", user: "alice", groups: []string{"netops", "sre"},
+			want: &client.Impersonation{UserName: "alice", Groups: []string{"netops", "sre"}}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			header := http.Header{}
+			if test.user != "" {
+				header.Set(auth.UserHeader, test.user)
+			}
+			for _, group := range test.groups {
+				header.Add(auth.GroupHeader, group)
+			}
+			var got *client.Impersonation
+			handler := Impersonation()(func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+				got = client.ImpersonationFrom(ctx)
+				return &mcp.CallToolResult{}, nil
+			})
+			req := &mcp.CallToolRequest{Params: &mcp.CallToolParamsRaw{Name: "pod-logs"}, Extra: &mcp.RequestExtra{Header: header}}
+			if _, err := handler(context.Background(), "tools/call", req); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(got, test.want) {
+				t.Fatalf("Expected impersonation %+v, got %+v", test.want, got)
+			}
+		})
+	}
+}
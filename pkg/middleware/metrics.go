@@ -0,0 +1,61 @@
+package middleware
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/jsonrpc"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/ovn-kubernetes/ovn-kubernetes-mcp/pkg/metrics"
+)
+
+// unknownTool is the tool label used for calls to tools that are not registered, so
+// that clients cannot create arbitrary metric series.
+const unknownTool = "unknown"
+
+// ToolMetrics returns an MCP receiving middleware that records the number of calls,
+// errors and timeouts, the latency and the output size of every tools/call request.
+// It must be added before ToolTimeout so that it sees the tool deadline.
+func ToolMetrics() mcp.Middleware {
+	return func(next mcp.MethodHandler) mcp.MethodHandler {
+		return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+			if method != "tools/call" {
+				return next(ctx, method, req)
+			}
+			tool := toolName(req)
+
+			start := time.Now()
+			result, err := next(ctx, method, req)
+			var rpcErr *jsonrpc.Error
+			if errors.As(err, &rpcErr) && rpcErr.Code == jsonrpc.CodeInvalidParams {
+				tool = unknownTool
+			}
+			metrics.ToolDuration.WithLabelValues(tool).Observe(time.Since(start).Seconds())
+			metrics.ToolCalls.WithLabelValues(tool).Inc()
+
+			callResult, _ := result.(*mcp.CallToolResult)
+			if err != nil || (callResult != nil && callResult.IsError) {
+				metrics.ToolErrors.WithLabelValues(tool).Inc()
+			}
+			if errors.Is(err, context.DeadlineExceeded) || errors.Is(ctx.Err(), context.DeadlineExceeded) {
+				metrics.ToolTimeouts.WithLabelValues(tool).Inc()
+			}
+			if callResult != nil {
+				if data, err := json.Marshal(callResult); err == nil {
+					metrics.ToolOutputBytes.WithLabelValues(tool).Observe(float64(len(data)))
+				}
+			}
+			return result, err
+		}
+	}
+}
+
+// toolName returns the name of the tool called by a tools/call request.
+func toolName(req mcp.Request) string {
+	if callReq, ok := req.(*mcp.CallToolRequest); ok && callReq.Params != nil {
+		return callReq.Params.Name
+	}
+	return ""
+}
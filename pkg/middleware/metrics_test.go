@@ -0,0 +1,116 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/jsonrpc"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+
+	"github.com/ovn-kubernetes/ovn-kubernetes-mcp/pkg/metrics"
+)
+
+// counterValue returns the current value of a counter.
+func counterValue(t *testing.T, counter prometheus.Counter) float64 {
+	t.Helper()
+	m := &dto.Metric{}
+	if err := counter.Write(m); err != nil {
+		t.Fatalf("Failed to read counter: %v", err)
+	}
+	return m.GetCounter().GetValue()
+}
+
+// histogramCount returns the number of observations of a histogram.
+func histogramCount(t *testing.T, observer prometheus.Observer) uint64 {
+	t.Helper()
+	m := &dto.Metric{}
+	if err := observer.(prometheus.Metric).Write(m); err != nil {
+		t.Fatalf("Failed to read histogram: %v", err)
+	}
+	return m.GetHistogram().GetSampleCount()
+}
+
+func TestToolMetrics(t *testing.T) {
+	newRequest := func(tool string) *mcp.CallToolRequest {
+		return &mcp.CallToolRequest{Params: &mcp.CallToolParamsRaw{Name: tool}}
+	}
+
+	t.Run("records successful calls", func(t *testing.T) {
+		tool := "metrics-success"
+		handler := ToolMetrics()(func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+			return &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: "output"}}}, nil
+		})
+		for range 2 {
+			if _, err := handler(context.Background(), "tools/call", newRequest(tool)); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+		}
+		if got := counterValue(t, metrics.ToolCalls.WithLabelValues(tool)); got != 2 {
+			t.Fatalf("Expected 2 calls, got %v", got)
+		}
+		if got := counterValue(t, metrics.ToolErrors.WithLabelValues(tool)); got != 0 {
+			t.Fatalf("Expected 0 errors, got %v", got)
+		}
+		if got := histogramCount(t, metrics.ToolDuration.WithLabelValues(tool)); got != 2 {
+			t.Fatalf("Expected 2 duration observations, got %v", got)
+		}
+		if got := histogramCount(t, metrics.ToolOutputBytes.WithLabelValues(tool)); got != 2 {
+			t.Fatalf("Expected 2 output size observations, got %v", got)
+		}
+	})
+
+	t.Run("records tool errors", func(t *testing.T) {
+		tool := "metrics-error"
+		handler := ToolMetrics()(func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+			result := &mcp.CallToolResult{}
+			result.SetError(errors.New("failed"))
+			return result, nil
+		})
+		_, _ = handler(context.Background(), "tools/call", newRequest(tool))
+		if got := counterValue(t, metrics.ToolErrors.WithLabelValues(tool)); got != 1 {
+			t.Fatalf("Expected 1 error, got %v", got)
+		}
+	})
+
+	t.Run("records timeouts", func(t *testing.T) {
+		tool := "metrics-timeout"
+		handler := ToolTimeout(10 * time.Millisecond)(ToolMetrics()(func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+			<-ctx.Done()
+			result := &mcp.CallToolResult{}
+			result.SetError(ctx.Err())
+			return result, nil
+		}))
+		_, _ = handler(context.Background(), "tools/call", newRequest(tool))
+		if got := counterValue(t, metrics.ToolTimeouts.WithLabelValues(tool)); got != 1 {
+			t.Fatalf("Expected 1 timeout, got %v", got)
+		}
+		if got := counterValue(t, metrics.ToolErrors.WithLabelValues(tool)); got != 1 {
+			t.Fatalf("Expected 1 error, got %v", got)
+		}
+	})
+
+	t.Run("groups unknown tools", func(t *testing.T) {
+		handler := ToolMetrics()(func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+			return nil, &jsonrpc.Error{Code: jsonrpc.CodeInvalidParams, Message: "unknown tool"}
+		})
+		before := counterValue(t, metrics.ToolCalls.WithLabelValues(unknownTool))
+		_, _ = handler(context.Background(), "tools/call", newRequest("does-not-exist"))
+		if got := counterValue(t, metrics.ToolCalls.WithLabelValues(unknownTool)); got != before+1 {
+			t.Fatalf("Expected unknown tool calls to be %v, got %v", before+1, got)
+		}
+	})
+
+	t.Run("ignores other methods", func(t *testing.T) {
+		handler := ToolMetrics()(func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+			return nil, nil
+		})
+		_, _ = handler(context.Background(), "tools/list", nil)
+		if got := counterValue(t, metrics.ToolCalls.WithLabelValues("")); got != 0 {
+			t.Fatalf("Expected no calls to be recorded, got %v", got)
+		}
+	})
+}
@@ -0,0 +1,26 @@
+package middleware
+
+import (
+	"context"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/ovn-kubernetes/ovn-kubernetes-mcp/pkg/progress"
+)
+
+// Progress returns an MCP receiving middleware that lets the tools report the
+// progress of the tools/call requests carrying a progress token, see the progress
+// package.
+func Progress() mcp.Middleware {
+	return func(next mcp.MethodHandler) mcp.MethodHandler {
+		return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+			callReq, ok := req.(*mcp.CallToolRequest)
+			if method != "tools/call" || !ok || callReq.Session == nil || callReq.Params == nil {
+				return next(ctx, method, req)
+			}
+			if token := callReq.Params.GetProgressToken(); token != nil {
+				ctx = progress.WithReporter(ctx, callReq.Session, token)
+			}
+			return next(ctx, method, req)
+		}
+	}
+}
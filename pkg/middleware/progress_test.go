@@ -0,0 +1,65 @@
+package middleware
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/ovn-kubernetes/ovn-kubernetes-mcp/pkg/progress"
+)
+
+func TestProgress(t *testing.T) {
+	ctx := context.Background()
+	server := mcp.NewServer(&mcp.Implementation{Name: "test"}, nil)
+	server.AddReceivingMiddleware(Progress())
+	mcp.AddTool(server, &mcp.Tool{Name: "slow"}, func(ctx context.Context, req *mcp.CallToolRequest, in struct{}) (*mcp.CallToolResult, any, error) {
+		progress.Report(ctx, "Creating debug pod on node %s", "worker-0")
+		progress.Report(ctx, "Debug pod %s is running", "debug-1")
+		return &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: "done"}}}, nil, nil
+	})
+
+	var mu sync.Mutex
+	var messages []string
+	client := mcp.NewClient(&mcp.Implementation{Name: "client"}, &mcp.ClientOptions{
+		ProgressNotificationHandler: func(_ context.Context, req *mcp.ProgressNotificationClientRequest) {
+			mu.Lock()
+			defer mu.Unlock()
+			if req.Params.ProgressToken == "call-1" {
+				messages = append(messages, req.Params.Message)
+			}
+		},
+	})
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+	serverSession, err := server.Connect(ctx, serverTransport, nil)
+	if err != nil {
+		t.Fatalf("Failed to connect server: %v", err)
+	}
+	defer serverSession.Close()
+	clientSession, err := client.Connect(ctx, clientTransport, nil)
+	if err != nil {
+		t.Fatalf("Failed to connect client: %v", err)
+	}
+	defer clientSession.Close()
+
+	params := &mcp.CallToolParams{Name: "slow"}
+	if _, err := clientSession.CallTool(ctx, params); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	mu.Lock()
+	if len(messages) != 0 {
+		t.Fatalf("Expected no progress without a token, got %v", messages)
+	}
+	mu.Unlock()
+
+	params.SetProgressToken("call-1")
+	if _, err := clientSession.CallTool(ctx, params); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	// Notifications are sent before the response on the same connection.
+	mu.Lock()
+	defer mu.Unlock()
+	if len(messages) != 2 || messages[1] != "Debug pod debug-1 is running" {
+		t.Fatalf("Unexpected progress messages: %v", messages)
+	}
+}
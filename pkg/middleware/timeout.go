@@ -11,12 +11,24 @@ import (
 // timeout to every tools/call request. This ensures all tool operations
 // have a bounded execution time without requiring per-tool code.
 func ToolTimeout(timeout time.Duration) mcp.Middleware {
+	return ToolTimeouts(func(string) time.Duration { return timeout })
+}
+
+// ToolTimeouts returns an MCP receiving middleware that applies the timeout
+// returned by timeoutFor for the called tool to every tools/call request. A
+// zero timeout leaves the tool call without deadline.
+func ToolTimeouts(timeoutFor func(tool string) time.Duration) mcp.Middleware {
 	return func(next mcp.MethodHandler) mcp.MethodHandler {
 		return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
 			if method != "tools/call" {
 				return next(ctx, method, req)
 			}
 
+			timeout := timeoutFor(toolName(req))
+			if timeout <= 0 {
+				return next(ctx, method, req)
+			}
+
 			var cancel context.CancelFunc
 			ctx, cancel = context.WithTimeout(ctx, timeout)
 			defer cancel()
@@ -0,0 +1,84 @@
+package middleware
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/ovn-kubernetes/ovn-kubernetes-mcp/pkg/config"
+)
+
+// ToolConfig returns an MCP receiving middleware that applies the tool
+// configuration of the store. Disabled tools are hidden from tools/list and
+// rejected on tools/call, and the configured default max_lines and page_size are
+// set on tools/call requests that do not set them.
+func ToolConfig(store *config.ToolStore) mcp.Middleware {
+	return func(next mcp.MethodHandler) mcp.MethodHandler {
+		return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+			switch method {
+			case "tools/call":
+				callReq, ok := req.(*mcp.CallToolRequest)
+				if !ok || callReq.Params == nil {
+					return next(ctx, method, req)
+				}
+				if !store.Enabled(callReq.Params.Name) {
+					return nil, fmt.Errorf("tool %s is disabled", callReq.Params.Name)
+				}
+				for param, value := range map[string]int{
+					config.MaxLinesParam: store.MaxLines(callReq.Params.Name),
+					config.PageSizeParam: store.PageSize(callReq.Params.Name),
+				} {
+					if value <= 0 {
+						continue
+					}
+					arguments, err := setDefaultArgument(callReq.Params.Arguments, param, value)
+					if err != nil {
+						return nil, err
+					}
+					callReq.Params.Arguments = arguments
+				}
+				return next(ctx, method, req)
+			case "tools/list":
+				result, err := next(ctx, method, req)
+				if err != nil {
+					return result, err
+				}
+				listResult, ok := result.(*mcp.ListToolsResult)
+				if !ok {
+					return result, nil
+				}
+				tools := make([]*mcp.Tool, 0, len(listResult.Tools))
+				for _, tool := range listResult.Tools {
+					if store.Enabled(tool.Name) {
+						tools = append(tools, tool)
+					}
+				}
+				listResult.Tools = tools
+				return listResult, nil
+			default:
+				return next(ctx, method, req)
+			}
+		}
+	}
+}
+
+// setDefaultArgument sets the argument to value in the raw tool arguments unless
+// it is already set.
+func setDefaultArgument(raw json.RawMessage, name string, value any) (json.RawMessage, error) {
+	arguments := map[string]json.RawMessage{}
+	if len(raw) > 0 && string(raw) != "null" {
+		if err := json.Unmarshal(raw, &arguments); err != nil {
+			return nil, fmt.Errorf("invalid tool arguments: %w", err)
+		}
+	}
+	if _, ok := arguments[name]; ok {
+		return raw, nil
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	arguments[name] = data
+	return json.Marshal(arguments)
+}
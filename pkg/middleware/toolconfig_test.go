@@ -0,0 +1,109 @@
+package middleware
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/ovn-kubernetes/ovn-kubernetes-mcp/pkg/config"
+)
+
+func TestToolConfig(t *testing.T) {
+	store := config.NewToolStore(config.Tools{
+		Deny:     []string{"pwru", "tcpdump"},
+		Settings: map[string]config.ToolSettings{"ovn-show": {MaxLines: 50}, "ovn-get": {PageSize: 500}},
+	}, 0)
+	m := ToolConfig(store)
+
+	t.Run("rejects disabled tools", func(t *testing.T) {
+		handler := m(func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+			t.Fatal("Tool handler should not be called")
+			return nil, nil
+		})
+		req := &mcp.CallToolRequest{Params: &mcp.CallToolParamsRaw{Name: "pwru"}}
+		if _, err := handler(context.Background(), "tools/call", req); err == nil {
+			t.Fatal("Expected an error for a disabled tool")
+		}
+	})
+
+	t.Run("sets default max_lines and page_size", func(t *testing.T) {
+		tests := []struct {
+			name      string
+			tool      string
+			arguments string
+			param     string
+			expected  float64
+		}{
+			{"no arguments", "ovn-show", "", config.MaxLinesParam, 50},
+			{"unset max_lines", "ovn-show", `{"name":"ovnkube-node-abc"}`, config.MaxLinesParam, 50},
+			{"caller max_lines", "ovn-show", `{"max_lines":10}`, config.MaxLinesParam, 10},
+			{"unset page_size", "ovn-get", `{"table":"ACL"}`, config.PageSizeParam, 500},
+			{"caller page_size", "ovn-get", `{"page_size":10}`, config.PageSizeParam, 10},
+		}
+		for _, test := range tests {
+			t.Run(test.name, func(t *testing.T) {
+				var arguments map[string]any
+				handler := m(func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+					if err := json.Unmarshal(req.(*mcp.CallToolRequest).Params.Arguments, &arguments); err != nil {
+						t.Fatalf("Invalid arguments: %v", err)
+					}
+					return &mcp.CallToolResult{}, nil
+				})
+				req := &mcp.CallToolRequest{Params: &mcp.CallToolParamsRaw{Name: test.tool, Arguments: json.RawMessage(test.arguments)}}
+				if _, err := handler(context.Background(), "tools/call", req); err != nil {
+					t.Fatalf("Unexpected error: %v", err)
+				}
+				if arguments[test.param] != test.expected {
+					t.Fatalf("Expected %s %v, got %v", test.param, test.expected, arguments[test.param])
+				}
+			})
+		}
+	})
+
+	t.Run("filters tools/list", func(t *testing.T) {
+		handler := m(func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+			return &mcp.ListToolsResult{Tools: []*mcp.Tool{{Name: "ovn-show"}, {Name: "pwru"}, {Name: "tcpdump"}}}, nil
+		})
+		result, err := handler(context.Background(), "tools/list", &mcp.ListToolsRequest{})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		tools := result.(*mcp.ListToolsResult).Tools
+		if len(tools) != 1 || tools[0].Name != "ovn-show" {
+			t.Fatalf("Expected only ovn-show to be listed, got %v", tools)
+		}
+	})
+}
+
+func TestToolTimeouts(t *testing.T) {
+	m := ToolTimeouts(func(tool string) time.Duration {
+		if tool == "sos-report" {
+			return 0
+		}
+		return 200 * time.Millisecond
+	})
+
+	tests := []struct {
+		tool        string
+		hasDeadline bool
+	}{
+		{"ovn-show", true},
+		{"sos-report", false},
+	}
+	for _, test := range tests {
+		t.Run(test.tool, func(t *testing.T) {
+			var capturedCtx context.Context
+			handler := m(func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+				capturedCtx = ctx
+				return nil, nil
+			})
+			req := &mcp.CallToolRequest{Params: &mcp.CallToolParamsRaw{Name: test.tool}}
+			_, _ = handler(context.Background(), "tools/call", req)
+			if _, ok := capturedCtx.Deadline(); ok != test.hasDeadline {
+				t.Fatalf("Expected deadline %v, got %v", test.hasDeadline, ok)
+			}
+		})
+	}
+}
@@ -0,0 +1,44 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/ovn-kubernetes/ovn-kubernetes-mcp/pkg/auth"
+	"github.com/ovn-kubernetes/ovn-kubernetes-mcp/pkg/completion"
+)
+
+// AddCompletions adds the completion of the database_name argument with the
+// Northbound and Southbound databases of the must-gather of the must_gather_path
+// argument.
+func (s *MustGatherMCPServer) AddCompletions(completer *completion.Completer) {
+	completer.Add(completion.ArgumentDatabaseName, auth.FamilyMustGather, s.completeDatabaseNames)
+}
+
+// completeDatabaseNames returns the names of the databases of the must-gather.
+func (s *MustGatherMCPServer) completeDatabaseNames(ctx context.Context, arguments map[string]string) ([]string, error) {
+	mustGatherPath := arguments[completion.ArgumentMustGather]
+	if s.ovsdbTool == nil || mustGatherPath == "" {
+		return nil, nil
+	}
+	var names []string
+	for _, list := range []func(context.Context, string) (string, error){
+		s.ovsdbTool.ListNorthboundDatabases, s.ovsdbTool.ListSouthboundDatabases,
+	} {
+		output, err := list(ctx, mustGatherPath)
+		if err != nil {
+			return nil, err
+		}
+		var databases []struct {
+			Database string `json:"database"`
+		}
+		if err := json.Unmarshal([]byte(output), &databases); err != nil {
+			return nil, fmt.Errorf("failed to parse the databases of must-gather %s: %w", mustGatherPath, err)
+		}
+		for _, database := range databases {
+			names = append(names, database.Database)
+		}
+	}
+	return names, nil
+}
@@ -32,6 +32,13 @@ func NewMCPServer() (*MustGatherMCPServer, error) {
 	}, nil
 }
 
+// NewToolListMCPServer creates a MustGatherMCPServer registering all the must
+// gather tools, including the ovsdb-tool tools, without the omc and ovsdb-tool
+// binaries. It is only used to list the tools, which cannot be called.
+func NewToolListMCPServer() *MustGatherMCPServer {
+	return &MustGatherMCPServer{ovsdbTool: &ovsdbtool.OvsdbTool{}}
+}
+
 // AddTools registers must gather tools with the MCP server
 func (s *MustGatherMCPServer) AddTools(server *mcp.Server) {
 	mcp.AddTool(server, &mcp.Tool{
@@ -0,0 +1,114 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/ovn-kubernetes/ovn-kubernetes-mcp/pkg/auth"
+	"github.com/ovn-kubernetes/ovn-kubernetes-mcp/pkg/executor"
+	"github.com/ovn-kubernetes/ovn-kubernetes-mcp/pkg/jobs"
+	k8stypes "github.com/ovn-kubernetes/ovn-kubernetes-mcp/pkg/kubernetes/types"
+	"github.com/ovn-kubernetes/ovn-kubernetes-mcp/pkg/network-tools/types"
+)
+
+// TcpdumpStart starts tcpdump as a background job on a node or inside a pod. Without
+// a packet count, packets are captured until the job is stopped.
+func (s *MCPServer) TcpdumpStart(ctx context.Context, req *mcp.CallToolRequest, in types.TcpdumpJobParams) (*mcp.CallToolResult, jobs.Status, error) {
+	maxDuration, err := parseMaxDuration(in.MaxDuration)
+	if err != nil {
+		return nil, jobs.Status{}, err
+	}
+	cmd, err := tcpdumpCommand(in.TcpdumpParams, 0)
+	if err != nil {
+		return nil, jobs.Status{}, err
+	}
+
+	var run executor.StreamFunc
+	var description string
+	switch in.TargetType {
+	case "node":
+		if in.NodeName == "" {
+			return nil, jobs.Status{}, fmt.Errorf("node's name is required when target type is 'node'")
+		}
+		run, err = s.executor.PrepareDebugNode(ctx, k8stypes.DebugNodeParams{
+			Name:    in.NodeName,
+			Image:   s.tcpdumpImage,
+			Command: cmd,
+		})
+		if err != nil {
+			return nil, jobs.Status{}, err
+		}
+		description = fmt.Sprintf("%s on node %s", strings.Join(cmd, " "), in.NodeName)
+	case "pod":
+		if in.PodName == "" {
+			return nil, jobs.Status{}, fmt.Errorf("pod's name is required when target type is 'pod'")
+		}
+		namespace := stringWithDefault(in.PodNamespace, "default")
+		run, err = s.executor.PrepareExecPod(ctx, k8stypes.ExecPodParams{
+			NamespacedNameParams: k8stypes.NamespacedNameParams{
+				Name:      in.PodName,
+				Namespace: namespace,
+			},
+			Container: in.ContainerName,
+			Command:   cmd,
+		})
+		if err != nil {
+			return nil, jobs.Status{}, err
+		}
+		description = fmt.Sprintf("%s in pod %s/%s", strings.Join(cmd, " "), namespace, in.PodName)
+	default:
+		return nil, jobs.Status{}, fmt.Errorf("invalid target_type: %s (must be 'node' or 'pod')", in.TargetType)
+	}
+	return s.startJob(req, description, maxDuration, run)
+}
+
+// PwruStart starts pwru as a background job on a node. Without an output limit,
+// events are traced until the job is stopped.
+func (s *MCPServer) PwruStart(ctx context.Context, req *mcp.CallToolRequest, in types.PwruJobParams) (*mcp.CallToolResult, jobs.Status, error) {
+	maxDuration, err := parseMaxDuration(in.MaxDuration)
+	if err != nil {
+		return nil, jobs.Status{}, err
+	}
+	if in.NodeName == "" {
+		return nil, jobs.Status{}, fmt.Errorf("node's name is required")
+	}
+	cmd, err := pwruCommand(in.PwruParams, 0)
+	if err != nil {
+		return nil, jobs.Status{}, err
+	}
+	run, err := s.executor.PrepareDebugNode(ctx, s.pwruTarget(in.NodeName, cmd))
+	if err != nil {
+		return nil, jobs.Status{}, err
+	}
+	return s.startJob(req, fmt.Sprintf("%s on node %s", strings.Join(cmd, " "), in.NodeName), maxDuration, run)
+}
+
+// startJob starts a job running the command, with its stdout and stderr as output.
+func (s *MCPServer) startJob(req *mcp.CallToolRequest, description string, maxDuration time.Duration, run executor.StreamFunc) (*mcp.CallToolResult, jobs.Status, error) {
+	status, err := s.jobManager.Start(req.Params.Name, auth.Username(req), description, maxDuration, func(ctx context.Context, output io.Writer) error {
+		return run(ctx, output, output)
+	})
+	if err != nil {
+		return nil, jobs.Status{}, err
+	}
+	return nil, status, nil
+}
+
+// parseMaxDuration parses the maximum duration of a job, zero if not set.
+func parseMaxDuration(value string) (time.Duration, error) {
+	if value == "" {
+		return 0, nil
+	}
+	duration, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid max_duration %q: %w", value, err)
+	}
+	if duration <= 0 {
+		return 0, fmt.Errorf("max_duration must be positive")
+	}
+	return duration, nil
+}
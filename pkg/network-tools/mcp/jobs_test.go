@@ -0,0 +1,81 @@
+package mcp
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/ovn-kubernetes/ovn-kubernetes-mcp/pkg/network-tools/types"
+)
+
+func TestTcpdumpCommand(t *testing.T) {
+	tests := []struct {
+		name               string
+		params             types.TcpdumpParams
+		defaultPacketCount int
+		want               []string
+		wantErr            bool
+	}{
+		{
+			name:               "default packet count",
+			params:             types.TcpdumpParams{Interface: "eth0"},
+			defaultPacketCount: DefaultPacketCount,
+			want:               []string{"tcpdump", "-l", "-n", "-v", "-s", "96", "-c", "100", "-i", "eth0"},
+		},
+		{
+			name:   "until stopped",
+			params: types.TcpdumpParams{BaseNetworkDiagParams: types.BaseNetworkDiagParams{BPFFilter: "port 53"}},
+			want:   []string{"tcpdump", "-l", "-n", "-v", "-s", "96", "port 53"},
+		},
+		{
+			name:    "too many packets",
+			params:  types.TcpdumpParams{PacketCount: MaxPacketCount + 1},
+			wantErr: true,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := tcpdumpCommand(test.params, test.defaultPacketCount)
+			if (err != nil) != test.wantErr {
+				t.Fatalf("tcpdumpCommand() error = %v, wantErr %v", err, test.wantErr)
+			}
+			if !test.wantErr && !slices.Equal(got, test.want) {
+				t.Fatalf("tcpdumpCommand() = %v, want %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestPwruCommand(t *testing.T) {
+	got, err := pwruCommand(types.PwruParams{BaseNetworkDiagParams: types.BaseNetworkDiagParams{BPFFilter: "icmp"}}, 0)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if want := []string{"pwru", "icmp"}; !slices.Equal(got, want) {
+		t.Fatalf("pwruCommand() = %v, want %v", got, want)
+	}
+}
+
+func TestParseMaxDuration(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    time.Duration
+		wantErr bool
+	}{
+		{"", 0, false},
+		{"30m", 30 * time.Minute, false},
+		{"-1m", 0, true},
+		{"forever", 0, true},
+	}
+	for _, test := range tests {
+		t.Run(test.value, func(t *testing.T) {
+			got, err := parseMaxDuration(test.value)
+			if (err != nil) != test.wantErr {
+				t.Fatalf("parseMaxDuration() error = %v, wantErr %v", err, test.wantErr)
+			}
+			if got != test.want {
+				t.Fatalf("parseMaxDuration() = %v, want %v", got, test.want)
+			}
+		})
+	}
+}
@@ -1,23 +1,29 @@
 package mcp
 
 import (
+	"fmt"
+
 	"github.com/modelcontextprotocol/go-sdk/mcp"
-	kubernetesmcp "github.com/ovn-kubernetes/ovn-kubernetes-mcp/pkg/kubernetes/mcp"
+	"github.com/ovn-kubernetes/ovn-kubernetes-mcp/pkg/executor"
+	"github.com/ovn-kubernetes/ovn-kubernetes-mcp/pkg/jobs"
 )
 
 // MCPServer provides MCP server functionality for network tools operations.
 type MCPServer struct {
-	k8sMcpServer *kubernetesmcp.MCPServer
+	executor     executor.Executor
 	pwruImage    string
 	tcpdumpImage string
+	jobManager   *jobs.Manager
 }
 
-// NewMCPServer creates a new MCP server instance
-func NewMCPServer(k8sMcpServer *kubernetesmcp.MCPServer, pwruImage, tcpdumpImage string) *MCPServer {
+// NewMCPServer creates a new MCP server instance. The tools starting background jobs
+// are only registered if jobManager is not nil.
+func NewMCPServer(executor executor.Executor, pwruImage, tcpdumpImage string, jobManager *jobs.Manager) *MCPServer {
 	return &MCPServer{
-		k8sMcpServer: k8sMcpServer,
+		executor:     executor,
 		pwruImage:    pwruImage,
 		tcpdumpImage: tcpdumpImage,
+		jobManager:   jobManager,
 	}
 }
 
@@ -35,6 +41,7 @@ The node image can be configured via --tcpdump-image flag or will default to nic
 The image must contain the tcpdump utility
 
 Parameters:
+- cluster: Cluster of the node or pod, from cluster-list (optional, defaults to the default cluster)
 - target_type: 'node' or 'pod' (required)
 - node_name: Name of the node (required when target_type is 'node')
 - pod_name: Name of the pod (required when target_type is 'pod')
@@ -64,6 +71,7 @@ The node image can be configured via --pwru-image flag or will default to docker
 The image must contain the pwru utility
 
 Parameters:
+- cluster: Cluster of the node or pod, from cluster-list (optional, defaults to the default cluster)
 - node_name: Name of the node to run pwru on (required)
 - bpf_filter: BPF filter expression to match packets (optional, e.g., "tcp and dst port 8080", "host 10.0.0.1")
 - output_limit_lines: Maximum number of trace events to capture (default: 100, max: 1000)
@@ -73,4 +81,56 @@ Examples:
 - TCP traffic: {"node_name": "worker-1", "bpf_filter": "tcp and dst port 8080", "output_limit_lines": 50}
 - ICMP packets: {"node_name": "worker-1", "bpf_filter": "icmp", "output_limit_lines": 100}`,
 		}, s.Pwru)
+	if s.jobManager == nil {
+		return
+	}
+	mcp.AddTool(server,
+		&mcp.Tool{
+			Name: "tcpdump-start",
+			Description: fmt.Sprintf(`Start a packet capture on a node or inside a pod as a background job.
+
+Unlike tcpdump, the capture is not bounded by the tool timeout: use it to capture until a problem
+reproduces. The call returns immediately with the job ID; use job-status to poll the job, job-output
+to fetch the packets captured so far, and job-stop to stop the capture. Node captures run in a
+dedicated debug pod that is deleted when the job finishes or is stopped.
+
+Parameters:
+- cluster: Cluster of the node or pod, from cluster-list (optional, defaults to the default cluster)
+- target_type: 'node' or 'pod' (required)
+- node_name: Name of the node (required when target_type is 'node')
+- pod_name: Name of the pod (required when target_type is 'pod')
+- pod_namespace: Namespace of the pod (defaults to "default")
+- container_name: Name of the container in the pod (optional, uses default container if not specified)
+- interface: Network interface name or 'any' (optional, uses default if not specified)
+- packet_count: Number of packets after which the capture ends (optional, max: 1000, captures until stopped if not set)
+- bpf_filter: BPF filter expression to match packets (optional, e.g., "tcp and dst port 8080", "host 10.0.0.1")
+- snaplen: Snapshot length in bytes (default: 96, max: 1500)
+- max_duration: Maximum duration of the capture, e.g. "30m" (optional, default and max: %s)
+
+The job is also stopped when its output reaches the maximum output size of the server.
+
+Examples:
+- Capture DNS until stopped: {"target_type": "node", "node_name": "worker-1", "interface": "any", "bpf_filter": "port 53"}
+- Capture for 10 minutes in a pod: {"target_type": "pod", "pod_name": "my-pod", "pod_namespace": "default", "bpf_filter": "host 10.0.0.1", "max_duration": "10m"}`, s.jobManager.MaxDuration()),
+		}, s.TcpdumpStart)
+	mcp.AddTool(server,
+		&mcp.Tool{
+			Name: "pwru-start",
+			Description: fmt.Sprintf(`Start tracing packets through the Linux kernel of a node with pwru as a background job.
+
+Unlike pwru, the trace is not bounded by the tool timeout: use it to trace until a problem reproduces.
+The call returns immediately with the job ID; use job-status to poll the job, job-output to fetch
+the events traced so far, and job-stop to stop the trace. The trace runs in a dedicated debug pod
+that is deleted when the job finishes or is stopped.
+
+Parameters:
+- cluster: Cluster of the node or pod, from cluster-list (optional, defaults to the default cluster)
+- node_name: Name of the node to run pwru on (required)
+- bpf_filter: BPF filter expression to match packets (optional, e.g., "tcp and dst port 8080", "host 10.0.0.1")
+- output_limit_lines: Number of trace events after which the trace ends (optional, max: 1000, traces until stopped if not set)
+- max_duration: Maximum duration of the trace, e.g. "30m" (optional, default and max: %s)
+
+Examples:
+- Trace drops for a pod IP: {"node_name": "worker-1", "bpf_filter": "host 10.244.0.5"}`, s.jobManager.MaxDuration()),
+		}, s.PwruStart)
 }
@@ -1,12 +1,14 @@
 package mcp
 
 import (
+	"bytes"
 	"context"
 	"strconv"
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 	k8stypes "github.com/ovn-kubernetes/ovn-kubernetes-mcp/pkg/kubernetes/types"
 	"github.com/ovn-kubernetes/ovn-kubernetes-mcp/pkg/network-tools/types"
+	"github.com/ovn-kubernetes/ovn-kubernetes-mcp/pkg/progress"
 )
 
 const (
@@ -18,33 +20,54 @@ const (
 // It creates a specialized debug pod with eBPF capabilities and traces packet processing paths.
 // This is useful for debugging packet drops, routing issues, and understanding kernel networking behavior.
 func (s *MCPServer) Pwru(ctx context.Context, req *mcp.CallToolRequest, in types.PwruParams) (*mcp.CallToolResult, types.CommandResult, error) {
-	outputLimitLines := in.OutputLimitLines
-	if outputLimitLines == 0 {
-		outputLimitLines = DefaultOutputLimitLines
-	}
-	if err := validateIntMax(outputLimitLines, MaxOutputLimitLines, "output_limit_lines", ""); err != nil {
+	cmd, err := pwruCommand(in, DefaultOutputLimitLines)
+	if err != nil {
 		return nil, types.CommandResult{}, err
 	}
 
-	if err := validatePacketFilter(in.BPFFilter); err != nil {
+	target := s.pwruTarget(in.NodeName, cmd)
+
+	// Every traced event starts with the address of the skb, after a header line.
+	ctx = progress.WithLineCounter(ctx, "events traced", func(line []byte) bool {
+		return bytes.HasPrefix(line, []byte("0x"))
+	})
+	result, err := s.runDebugNode(ctx, req, target)
+	if err != nil {
 		return nil, types.CommandResult{}, err
 	}
+	return nil, result, nil
+}
 
-	cmd := newCommand("pwru", "--output-limit-lines", strconv.Itoa(outputLimitLines))
-	// pwru accepts pcap filter as positional argument(s)
-	cmd.addIfNotEmpty(in.BPFFilter, in.BPFFilter)
-
-	target := k8stypes.DebugNodeParams{
-		Name:      in.NodeName,
+// pwruTarget returns the debug node parameters running the pwru command on a node.
+func (s *MCPServer) pwruTarget(nodeName string, cmd []string) k8stypes.DebugNodeParams {
+	return k8stypes.DebugNodeParams{
+		Name:      nodeName,
 		Image:     s.pwruImage,
 		HostPath:  "/sys/kernel/debug",
 		MountPath: "/sys/kernel/debug",
-		Command:   cmd.build(),
+		Command:   cmd,
 	}
+}
 
-	result, err := s.runDebugNode(ctx, req, target)
-	if err != nil {
-		return nil, types.CommandResult{}, err
+// pwruCommand validates the parameters and returns the pwru command. Without an output
+// limit, defaultOutputLimitLines events are traced, or events are traced until pwru is
+// stopped if it is zero.
+func pwruCommand(in types.PwruParams, defaultOutputLimitLines int) ([]string, error) {
+	outputLimitLines := in.OutputLimitLines
+	if outputLimitLines == 0 {
+		outputLimitLines = defaultOutputLimitLines
 	}
-	return nil, result, nil
+	if err := validateIntMax(outputLimitLines, MaxOutputLimitLines, "output_limit_lines", ""); err != nil {
+		return nil, err
+	}
+
+	if err := validatePacketFilter(in.BPFFilter); err != nil {
+		return nil, err
+	}
+
+	cmd := newCommand("pwru")
+	cmd.addIf(outputLimitLines > 0, "--output-limit-lines", strconv.Itoa(outputLimitLines))
+	// pwru accepts pcap filter as positional argument(s)
+	cmd.addIfNotEmpty(in.BPFFilter, in.BPFFilter)
+	return cmd.build(), nil
 }
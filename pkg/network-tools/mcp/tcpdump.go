@@ -8,6 +8,7 @@ import (
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 	k8stypes "github.com/ovn-kubernetes/ovn-kubernetes-mcp/pkg/kubernetes/types"
 	"github.com/ovn-kubernetes/ovn-kubernetes-mcp/pkg/network-tools/types"
+	"github.com/ovn-kubernetes/ovn-kubernetes-mcp/pkg/progress"
 )
 
 const (
@@ -23,41 +24,20 @@ const (
 
 // Tcpdump executes the tcpdump packet capture tool on a node or inside a pod.
 func (s *MCPServer) Tcpdump(ctx context.Context, req *mcp.CallToolRequest, in types.TcpdumpParams) (*mcp.CallToolResult, types.CommandResult, error) {
-	if err := validateInterface(in.Interface); err != nil {
-		return nil, types.CommandResult{}, err
-	}
-	if err := validatePacketFilter(in.BPFFilter); err != nil {
+	cmd, err := tcpdumpCommand(in, DefaultPacketCount)
+	if err != nil {
 		return nil, types.CommandResult{}, err
 	}
 
-	packetCount := in.PacketCount
-	if packetCount == 0 {
-		packetCount = DefaultPacketCount
-	}
-	if err := validateIntMax(packetCount, MaxPacketCount, "packet_count", ""); err != nil {
-		return nil, types.CommandResult{}, err
-	}
-
-	snaplen := in.Snaplen
-	if snaplen == 0 {
-		snaplen = DefaultSnaplen
-	}
-	if err := validateIntMax(snaplen, MaxSnaplen, "snaplen", "bytes"); err != nil {
-		return nil, types.CommandResult{}, err
-	}
-
-	cmd := newCommand("tcpdump", "-n", "-v",
-		"-s", strconv.Itoa(snaplen),
-		"-c", strconv.Itoa(packetCount))
-	cmd.addIfNotEmpty(in.Interface, "-i", in.Interface)
-	cmd.addIfNotEmpty(in.BPFFilter, in.BPFFilter)
+	packetCount := packetCountOrDefault(in.PacketCount, DefaultPacketCount)
+	ctx = progress.WithLineCounter(ctx, fmt.Sprintf("of %d packets captured", packetCount), isPacketLine)
 
 	switch in.TargetType {
 	case "node":
 		result, err := s.runDebugNode(ctx, req, k8stypes.DebugNodeParams{
 			Name:    in.NodeName,
 			Image:   s.tcpdumpImage,
-			Command: cmd.build(),
+			Command: cmd,
 		})
 		if err != nil {
 			return nil, types.CommandResult{}, err
@@ -70,7 +50,7 @@ func (s *MCPServer) Tcpdump(ctx context.Context, req *mcp.CallToolRequest, in ty
 				Namespace: in.PodNamespace,
 			},
 			Container: in.ContainerName,
-			Command:   cmd.build(),
+			Command:   cmd,
 		})
 		if err != nil {
 			return nil, types.CommandResult{}, err
@@ -80,3 +60,50 @@ func (s *MCPServer) Tcpdump(ctx context.Context, req *mcp.CallToolRequest, in ty
 		return nil, types.CommandResult{}, fmt.Errorf("invalid target_type: %s (must be 'node' or 'pod')", in.TargetType)
 	}
 }
+
+// tcpdumpCommand validates the parameters and returns the tcpdump command. Without a
+// packet count, defaultPacketCount packets are captured, or packets are captured until
+// tcpdump is stopped if it is zero.
+func tcpdumpCommand(in types.TcpdumpParams, defaultPacketCount int) ([]string, error) {
+	if err := validateInterface(in.Interface); err != nil {
+		return nil, err
+	}
+	if err := validatePacketFilter(in.BPFFilter); err != nil {
+		return nil, err
+	}
+
+	packetCount := packetCountOrDefault(in.PacketCount, defaultPacketCount)
+	if err := validateIntMax(packetCount, MaxPacketCount, "packet_count", ""); err != nil {
+		return nil, err
+	}
+
+	snaplen := in.Snaplen
+	if snaplen == 0 {
+		snaplen = DefaultSnaplen
+	}
+	if err := validateIntMax(snaplen, MaxSnaplen, "snaplen", "bytes"); err != nil {
+		return nil, err
+	}
+
+	// Line buffering makes the packets available as soon as they are captured.
+	cmd := newCommand("tcpdump", "-l", "-n", "-v",
+		"-s", strconv.Itoa(snaplen))
+	cmd.addIf(packetCount > 0, "-c", strconv.Itoa(packetCount))
+	cmd.addIfNotEmpty(in.Interface, "-i", in.Interface)
+	cmd.addIfNotEmpty(in.BPFFilter, in.BPFFilter)
+	return cmd.build(), nil
+}
+
+// isPacketLine returns true if a line of the tcpdump output starts a new packet. With
+// -v, the details of a packet continue on indented lines.
+func isPacketLine(line []byte) bool {
+	return len(line) > 0 && line[0] != ' ' && line[0] != '\t'
+}
+
+// packetCountOrDefault returns the packet count, or the default if not set.
+func packetCountOrDefault(packetCount, defaultPacketCount int) int {
+	if packetCount == 0 {
+		return defaultPacketCount
+	}
+	return packetCount
+}
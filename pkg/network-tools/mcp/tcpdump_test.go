@@ -0,0 +1,34 @@
+package mcp
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/ovn-kubernetes/ovn-kubernetes-mcp/pkg/executor"
+	"github.com/ovn-kubernetes/ovn-kubernetes-mcp/pkg/jobs"
+	"github.com/ovn-kubernetes/ovn-kubernetes-mcp/pkg/network-tools/types"
+)
+
+func TestTcpdumpPodWithLocalExecutor(t *testing.T) {
+	local, err := executor.NewLocal(executor.LocalConfig{NodeName: "worker-0"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	jobManager := jobs.NewManager(jobs.Config{})
+	defer jobManager.Close()
+	s := NewMCPServer(local, "", "", jobManager)
+	params := types.TcpdumpParams{TargetType: "pod", PodName: "client", PodNamespace: "default", PacketCount: 1}
+
+	// The capture must not run on the host instead of in the pod.
+	req := &mcp.CallToolRequest{Params: &mcp.CallToolParamsRaw{Name: "tcpdump"}}
+	if _, _, err := s.Tcpdump(context.Background(), req, params); err == nil || !strings.Contains(err.Error(), "not reachable") {
+		t.Fatalf("Expected the pod to be unreachable, got %v", err)
+	}
+	req = &mcp.CallToolRequest{Params: &mcp.CallToolParamsRaw{Name: "tcpdump-start"}}
+	if _, _, err := s.TcpdumpStart(context.Background(), req, types.TcpdumpJobParams{TcpdumpParams: params}); err == nil ||
+		!strings.Contains(err.Error(), "not reachable") {
+		t.Fatalf("Expected the pod to be unreachable, got %v", err)
+	}
+}
@@ -21,7 +21,7 @@ func (s *MCPServer) runDebugNode(ctx context.Context, req *mcp.CallToolRequest,
 	if target.Image == "" {
 		return types.CommandResult{}, fmt.Errorf("node's image is required when target type is 'node'")
 	}
-	_, output, err := s.k8sMcpServer.DebugNode(ctx, req, target)
+	_, output, err := s.executor.DebugNode(ctx, req, target)
 	if err != nil {
 		return types.CommandResult{}, err
 	}
@@ -36,7 +36,7 @@ func (s *MCPServer) runExecPod(ctx context.Context, req *mcp.CallToolRequest, ta
 	if target.Namespace == "" {
 		target.Namespace = "default"
 	}
-	_, output, err := s.k8sMcpServer.ExecPod(ctx, req, target)
+	_, output, err := s.executor.ExecPod(ctx, req, target)
 	if err != nil {
 		return types.CommandResult{}, err
 	}
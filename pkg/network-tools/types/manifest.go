@@ -1,5 +1,7 @@
 package types
 
+import k8stypes "github.com/ovn-kubernetes/ovn-kubernetes-mcp/pkg/kubernetes/types"
+
 // BaseNetworkDiagParams contains common parameters shared across network diagnostic tools.
 type BaseNetworkDiagParams struct {
 	BPFFilter string `json:"bpf_filter,omitempty"`
@@ -8,6 +10,7 @@ type BaseNetworkDiagParams struct {
 // TcpdumpParams contains parameters for running tcpdump packet capture.
 // Supports both node-level and pod-level packet capture with BPF filtering.
 type TcpdumpParams struct {
+	k8stypes.ClusterParams
 	BaseNetworkDiagParams
 
 	TargetType string `json:"target_type"`
@@ -28,12 +31,31 @@ type TcpdumpParams struct {
 // PwruParams contains parameters for running pwru (packet, where are you?) eBPF-based
 // kernel packet tracing. This tool traces packets through the Linux kernel networking stack.
 type PwruParams struct {
+	k8stypes.ClusterParams
 	BaseNetworkDiagParams
 
 	NodeName         string `json:"node_name"`
 	OutputLimitLines int    `json:"output_limit_lines,omitempty"`
 }
 
+// JobParams contains the parameters shared by the tools starting a background job.
+type JobParams struct {
+	// MaxDuration is the maximum duration of the job, as a Go duration like "30m".
+	MaxDuration string `json:"max_duration,omitempty"`
+}
+
+// TcpdumpJobParams contains parameters for running tcpdump as a background job.
+type TcpdumpJobParams struct {
+	TcpdumpParams
+	JobParams
+}
+
+// PwruJobParams contains parameters for running pwru as a background job.
+type PwruJobParams struct {
+	PwruParams
+	JobParams
+}
+
 // CommandResult represents the output and status of an executed command.
 type CommandResult struct {
 	Output   string `json:"output"`
@@ -7,8 +7,10 @@ import (
 	"strings"
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/ovn-kubernetes/ovn-kubernetes-mcp/pkg/artifacts"
 	k8stypes "github.com/ovn-kubernetes/ovn-kubernetes-mcp/pkg/kubernetes/types"
 	ovntypes "github.com/ovn-kubernetes/ovn-kubernetes-mcp/pkg/ovn/types"
+	"github.com/ovn-kubernetes/ovn-kubernetes-mcp/pkg/ovsdb"
 )
 
 const defaultMaxLines = 100
@@ -16,19 +18,45 @@ const defaultMaxLines = 100
 // validTableNamePattern matches valid OVN table names: start with letter, alphanumeric and underscores.
 var validTableNamePattern = regexp.MustCompile(`^[a-zA-Z][a-zA-Z0-9_]*$`)
 
-func (s *MCPServer) runCommand(ctx context.Context, req *mcp.CallToolRequest, namespacedName k8stypes.NamespacedNameParams,
+// runCommand runs an OVN command in the pod of the target, or in the pod of the
+// node of the target running the database the command connects to, and in the
+// container of the target or the container with the command and its socket.
+func (s *MCPServer) runCommand(ctx context.Context, req *mcp.CallToolRequest, target k8stypes.PodTargetParams,
 	commands []string) ([]string, error) {
-	_, result, err := s.k8sMcpServer.ExecPod(ctx, req, k8stypes.ExecPodParams{NamespacedNameParams: namespacedName, Command: commands})
+	exec, err := s.pods.Resolve(ctx, req, target, commands[0])
+	if err != nil {
+		return nil, err
+	}
+	exec.Command = commands
+	_, result, err := s.executor.ExecPod(ctx, req, exec)
 	if err != nil {
 		return nil, err
 	}
 	if result.Stderr != "" {
-		return nil, fmt.Errorf("error occurred while running command %v on pod %s/%s: %s", commands, namespacedName.Namespace,
-			namespacedName.Name, result.Stderr)
+		return nil, fmt.Errorf("error occurred while running command %v on pod %s/%s: %s", commands, exec.Namespace,
+			exec.Name, result.Stderr)
 	}
 	return parseOutput(result.Stdout), nil
 }
 
+// ovsdbDatabases are the OVN databases, for ovsdb-client.
+var ovsdbDatabases = map[ovntypes.Database]ovsdb.Database{
+	ovntypes.NorthboundDB: ovsdb.Northbound,
+	ovntypes.SouthboundDB: ovsdb.Southbound,
+}
+
+// ovsdbClient returns a client of an OVN database, running ovsdb-client in the pod
+// and container of the target that run the ovn-nbctl or ovn-sbctl commands of the
+// database. They are resolved once for all the commands of the client.
+func (s *MCPServer) ovsdbClient(ctx context.Context, req *mcp.CallToolRequest, target k8stypes.PodTargetParams,
+	database ovntypes.Database) (*ovsdb.Client, error) {
+	exec, err := s.pods.Resolve(ctx, req, target, getDBCommand(database))
+	if err != nil {
+		return nil, err
+	}
+	return ovsdb.NewPodClient(s.executor, req, exec, ovsdbDatabases[database]), nil
+}
+
 // parseOutput parses command output into lines, trimming whitespace and removing empty lines.
 func parseOutput(stdout string) []string {
 	output := []string{} // Initialize with empty slice to ensure valid JSON when there's no output
@@ -61,12 +89,14 @@ func filterLines(lines []string, pattern string) ([]string, error) {
 	return filtered, nil
 }
 
-// limitLines limits the number of lines returned.
-func limitLines(lines []string, maxLines int) []string {
+// limitLines limits the number of lines returned. The complete lines are saved as
+// an artifact of the tool call when they are truncated.
+func limitLines(ctx context.Context, lines []string, maxLines int) []string {
 	if maxLines <= 0 {
 		maxLines = defaultMaxLines
 	}
 	if len(lines) > maxLines {
+		artifacts.Save(ctx, strings.Join(lines, "\n"))
 		return lines[:maxLines]
 	}
 	return lines
@@ -148,6 +178,21 @@ func validateColumnSpec(columns string) error {
 	return validateSafeString(columns, "column specification", true)
 }
 
+// traceCommand returns the ovn-trace command of a microflow entering a datapath,
+// with the output of the mode, detailed by default.
+func traceCommand(mode ovntypes.TraceMode, datapath, microflow string) []string {
+	cmdArgs := []string{"ovn-trace"}
+	switch mode {
+	case ovntypes.TraceModeSummary:
+		cmdArgs = append(cmdArgs, "--summary")
+	case ovntypes.TraceModeMinimal:
+		cmdArgs = append(cmdArgs, "--minimal")
+	case ovntypes.TraceModeDetailed, "":
+		cmdArgs = append(cmdArgs, "--detailed")
+	}
+	return append(cmdArgs, datapath, microflow)
+}
+
 // getDBCommand returns the appropriate command (ovn-nbctl or ovn-sbctl) for the given database.
 func getDBCommand(db ovntypes.Database) string {
 	if db == ovntypes.SouthboundDB {
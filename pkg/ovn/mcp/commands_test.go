@@ -1,6 +1,7 @@
 package mcp
 
 import (
+	"context"
 	"os"
 	"path/filepath"
 	"strings"
@@ -253,7 +254,7 @@ func TestLimitLinesWithOVNOutput(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			result := limitLines(lines, tt.maxLines)
+			result := limitLines(context.Background(), lines, tt.maxLines)
 			if len(result) != tt.wantLines {
 				t.Errorf("limitLines() = %d lines, want %d", len(result), tt.wantLines)
 			}
@@ -0,0 +1,63 @@
+package mcp
+
+import (
+	"context"
+
+	"github.com/ovn-kubernetes/ovn-kubernetes-mcp/pkg/auth"
+	"github.com/ovn-kubernetes/ovn-kubernetes-mcp/pkg/completion"
+	ovntypes "github.com/ovn-kubernetes/ovn-kubernetes-mcp/pkg/ovn/types"
+)
+
+// schemaTables are the tables of the OVN Northbound and Southbound schemas.
+var schemaTables = map[ovntypes.Database][]string{
+	ovntypes.NorthboundDB: {
+		"ACL", "Address_Set", "BFD", "Chassis_Template_Var", "Connection", "Copp", "DHCP_Options", "DNS",
+		"Forwarding_Group", "Gateway_Chassis", "HA_Chassis", "HA_Chassis_Group", "Load_Balancer",
+		"Load_Balancer_Group", "Load_Balancer_Health_Check", "Logical_Router", "Logical_Router_Policy",
+		"Logical_Router_Port", "Logical_Router_Static_Route", "Logical_Switch", "Logical_Switch_Port", "Meter",
+		"Meter_Band", "Mirror", "NAT", "NB_Global", "Port_Group", "QoS", "SSL", "Static_MAC_Binding",
+	},
+	ovntypes.SouthboundDB: {
+		"Address_Set", "BFD", "Chassis", "Chassis_Private", "Chassis_Template_Var", "Connection", "Controller_Event",
+		"DHCP_Options", "DHCPv6_Options", "DNS", "Datapath_Binding", "Encap", "FDB", "Gateway_Chassis",
+		"HA_Chassis", "HA_Chassis_Group", "IGMP_Group", "IP_Multicast", "Load_Balancer", "Logical_DP_Group",
+		"Logical_Flow", "MAC_Binding", "Meter", "Meter_Band", "Mirror", "Multicast_Group", "Port_Binding",
+		"Port_Group", "RBAC_Permission", "RBAC_Role", "SB_Global", "SSL", "Service_Monitor", "Static_MAC_Binding",
+	},
+}
+
+// AddCompletions adds the completion of the database and table arguments, with
+// the tables of the schema of the database argument, and of the datapath argument,
+// with the logical switches and routers of the pod of the namespace and pod
+// arguments, or of the node argument.
+func (s *MCPServer) AddCompletions(completer *completion.Completer) {
+	completer.Add(completion.ArgumentDatabase, auth.FamilyOVN, func(ctx context.Context, arguments map[string]string) ([]string, error) {
+		return []string{string(ovntypes.NorthboundDB), string(ovntypes.SouthboundDB)}, nil
+	})
+	completer.Add(completion.ArgumentTable, auth.FamilyOVN, func(ctx context.Context, arguments map[string]string) ([]string, error) {
+		database := ovntypes.Database(arguments[completion.ArgumentDatabase])
+		if database == "" {
+			return append(schemaTables[ovntypes.NorthboundDB], schemaTables[ovntypes.SouthboundDB]...), nil
+		}
+		return schemaTables[database], nil
+	})
+	completer.Add(completion.ArgumentDatapath, auth.FamilyOVN, s.completeDatapaths)
+}
+
+// completeDatapaths returns the names of the logical switches and routers, the
+// datapaths of the logical flows.
+func (s *MCPServer) completeDatapaths(ctx context.Context, arguments map[string]string) ([]string, error) {
+	pod := completion.PodTarget(arguments)
+	if pod.Name == "" && pod.Node == "" {
+		return nil, nil
+	}
+	var datapaths []string
+	for _, table := range []string{"Logical_Switch", "Logical_Router"} {
+		names, err := s.runCommand(ctx, nil, pod, []string{"ovn-nbctl", "--bare", "--columns=name", "list", table})
+		if err != nil {
+			return nil, err
+		}
+		datapaths = append(datapaths, names...)
+	}
+	return datapaths, nil
+}
@@ -0,0 +1,356 @@
+package mcp
+
+import (
+	"cmp"
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+
+	ovntypes "github.com/ovn-kubernetes/ovn-kubernetes-mcp/pkg/ovn/types"
+	"github.com/ovn-kubernetes/ovn-kubernetes-mcp/pkg/ovsdb"
+)
+
+// Kinds of the nodes and edges of the logical topology graph.
+const (
+	graphSwitch = "switch"
+	graphRouter = "router"
+	graphPort   = "port"
+
+	edgeRouterPort = "router-port"
+	edgePeer       = "peer"
+	edgePort       = "port"
+)
+
+// defaultGraphDepth is the number of hops of the subgraph around a port.
+const defaultGraphDepth = 2
+
+// networkExternalID is the external ID of the switches and routers of the
+// user-defined networks, with the name of their network.
+const networkExternalID = "k8s.ovn.org/network"
+
+// defaultNetwork is the network of the switches and routers without network.
+const defaultNetwork = "default"
+
+// graphTables are the Northbound tables and columns of the logical topology.
+var graphTables = map[string][]string{
+	"Logical_Switch":      {"name", "ports", "other_config", "external_ids"},
+	"Logical_Switch_Port": {"name", "type", "addresses", "options"},
+	"Logical_Router":      {"name", "ports", "options", "external_ids"},
+	"Logical_Router_Port": {"name", "networks", "peer"},
+}
+
+// logicalGraph is the graph of the logical topology.
+type logicalGraph struct {
+	nodes []ovntypes.GraphNode
+	edges []ovntypes.GraphEdge
+}
+
+// buildGraph builds the graph of the rows of the graph tables, selected with their
+// UUIDs. The routers are linked to the switches by their router ports, and to the
+// routers of their peer ports, and the other switch ports are nodes linked to
+// their switch.
+func buildGraph(tables map[string][]ovsdb.Row) *logicalGraph {
+	g := &logicalGraph{}
+	switchPorts := rowsByUUID(tables["Logical_Switch_Port"])
+	routerPorts := rowsByUUID(tables["Logical_Router_Port"])
+
+	// routerOf maps the names of the router ports to the IDs of their routers.
+	routerOf := map[string]string{}
+	portsByName := map[string]ovsdb.Row{}
+	for _, router := range tables["Logical_Router"] {
+		name, network := router.String("name"), rowNetwork(router)
+		node := ovntypes.GraphNode{ID: graphRouter + ":" + name, Kind: graphRouter, Type: "cluster", Name: name, Network: network}
+		if nodeName, found := strings.CutPrefix(baseName(name, network), "GR_"); found {
+			node.Type, node.Node = "gateway", nodeName
+		} else if router.Map("options")["chassis"] != "" {
+			node.Type = "gateway"
+		}
+		for _, uuid := range router.Strings("ports") {
+			if port, found := routerPorts[uuid]; found {
+				routerOf[port.String("name")] = node.ID
+				portsByName[port.String("name")] = port
+			}
+		}
+		g.nodes = append(g.nodes, node)
+	}
+
+	peers := map[[2]string]bool{}
+	for _, name := range slices.Sorted(maps.Keys(portsByName)) {
+		peer := portsByName[name].String("peer")
+		if _, found := routerOf[peer]; !found {
+			continue
+		}
+		pair := [2]string{min(name, peer), max(name, peer)}
+		if peers[pair] {
+			continue
+		}
+		peers[pair] = true
+		g.edges = append(g.edges, ovntypes.GraphEdge{
+			From:      routerOf[name],
+			To:        routerOf[peer],
+			Kind:      edgePeer,
+			Port:      name,
+			PeerPort:  peer,
+			Addresses: slices.Concat(portsByName[name].Strings("networks"), portsByName[peer].Strings("networks")),
+		})
+	}
+
+	for _, ls := range tables["Logical_Switch"] {
+		name, network := ls.String("name"), rowNetwork(ls)
+		node := ovntypes.GraphNode{ID: graphSwitch + ":" + name, Kind: graphSwitch, Name: name, Network: network}
+		base := baseName(name, network)
+		otherConfig := ls.Map("other_config")
+		switch {
+		case otherConfig["interconn-ts"] != "" || strings.HasSuffix(base, "transit_switch"):
+			node.Type = "transit"
+		case base == "join" || strings.HasPrefix(base, "join_"):
+			node.Type = "join"
+		case strings.HasPrefix(base, "ext_"):
+			node.Type, node.Node = "external", strings.TrimPrefix(base, "ext_")
+		case otherConfig["subnet"] != "" || otherConfig["ipv6_prefix"] != "":
+			node.Type, node.Node = "node", base
+			node.Addresses = strings.Fields(otherConfig["subnet"] + " " + otherConfig["ipv6_prefix"])
+		}
+		g.nodes = append(g.nodes, node)
+
+		for _, uuid := range ls.Strings("ports") {
+			lsp, found := switchPorts[uuid]
+			if !found {
+				continue
+			}
+			lspName, lspType, options := lsp.String("name"), lsp.String("type"), lsp.Map("options")
+			if routerID, found := routerOf[options["router-port"]]; found && lspType == "router" {
+				g.edges = append(g.edges, ovntypes.GraphEdge{
+					From:      routerID,
+					To:        node.ID,
+					Kind:      edgeRouterPort,
+					Port:      options["router-port"],
+					PeerPort:  lspName,
+					Addresses: portsByName[options["router-port"]].Strings("networks"),
+				})
+				continue
+			}
+			port := ovntypes.GraphNode{
+				ID:        graphPort + ":" + lspName,
+				Kind:      graphPort,
+				Type:      cmp.Or(lspType, "vif"),
+				Name:      lspName,
+				Node:      cmp.Or(options["requested-chassis"], node.Node),
+				Network:   network,
+				Addresses: lsp.Strings("addresses"),
+			}
+			g.nodes = append(g.nodes, port)
+			g.edges = append(g.edges, ovntypes.GraphEdge{From: node.ID, To: port.ID, Kind: edgePort})
+		}
+	}
+	g.sort()
+	return g
+}
+
+// sort sorts the nodes by ID, and the edges by their nodes.
+func (g *logicalGraph) sort() {
+	slices.SortFunc(g.nodes, func(a, b ovntypes.GraphNode) int { return strings.Compare(a.ID, b.ID) })
+	slices.SortFunc(g.edges, func(a, b ovntypes.GraphEdge) int {
+		return cmp.Or(strings.Compare(a.From, b.From), strings.Compare(a.To, b.To), strings.Compare(a.Port, b.Port))
+	})
+}
+
+// keep keeps the nodes of the IDs, and the edges between them.
+func (g *logicalGraph) keep(ids map[string]bool) {
+	g.nodes = slices.DeleteFunc(g.nodes, func(node ovntypes.GraphNode) bool { return !ids[node.ID] })
+	g.edges = slices.DeleteFunc(g.edges, func(edge ovntypes.GraphEdge) bool { return !ids[edge.From] || !ids[edge.To] })
+}
+
+// neighbors returns the IDs of the nodes linked to every node.
+func (g *logicalGraph) neighbors() map[string][]string {
+	neighbors := map[string][]string{}
+	for _, edge := range g.edges {
+		neighbors[edge.From] = append(neighbors[edge.From], edge.To)
+		neighbors[edge.To] = append(neighbors[edge.To], edge.From)
+	}
+	return neighbors
+}
+
+// filterNetwork keeps the nodes of a network, by name or by the prefix of the
+// names of its switches and routers.
+func (g *logicalGraph) filterNetwork(network string) {
+	prefix := strings.TrimSuffix(network, "_") + "_"
+	ids := map[string]bool{}
+	for _, node := range g.nodes {
+		if node.Network == network || (network != defaultNetwork && strings.HasPrefix(node.Name, prefix)) {
+			ids[node.ID] = true
+		}
+	}
+	g.keep(ids)
+}
+
+// filterNode keeps the nodes of a Kubernetes node, and the nodes they are linked
+// to, like the cluster router or the join switch.
+func (g *logicalGraph) filterNode(nodeName string) {
+	neighbors := g.neighbors()
+	ids := map[string]bool{}
+	for _, node := range g.nodes {
+		if node.Node != nodeName {
+			continue
+		}
+		ids[node.ID] = true
+		for _, neighbor := range neighbors[node.ID] {
+			ids[neighbor] = true
+		}
+	}
+	g.keep(ids)
+}
+
+// around keeps the nodes at most depth hops away from a switch port, or from the
+// router of a router port and its peer. It returns an error if there is no such port.
+func (g *logicalGraph) around(port string, depth int) error {
+	var start []string
+	if slices.ContainsFunc(g.nodes, func(node ovntypes.GraphNode) bool { return node.ID == graphPort+":"+port }) {
+		start = append(start, graphPort+":"+port)
+	}
+	for _, edge := range g.edges {
+		// The router of a router port is the start of its edges, and its peer,
+		// a router port or a switch port of type router, is on the other end.
+		switch port {
+		case edge.Port:
+			start = append(start, edge.From)
+		case edge.PeerPort:
+			start = append(start, edge.To)
+		}
+	}
+	if len(start) == 0 {
+		return fmt.Errorf("port %s not found in the logical topology", port)
+	}
+
+	neighbors := g.neighbors()
+	ids := map[string]bool{}
+	for _, id := range start {
+		ids[id] = true
+	}
+	for range depth {
+		var next []string
+		for _, id := range start {
+			for _, neighbor := range neighbors[id] {
+				if !ids[neighbor] {
+					ids[neighbor] = true
+					next = append(next, neighbor)
+				}
+			}
+		}
+		start = next
+	}
+	g.keep(ids)
+	return nil
+}
+
+// hidePorts removes the switch ports, except the port the graph is built around.
+func (g *logicalGraph) hidePorts(except string) {
+	ids := map[string]bool{}
+	for _, node := range g.nodes {
+		if node.Kind != graphPort || node.Name == except {
+			ids[node.ID] = true
+		}
+	}
+	g.keep(ids)
+}
+
+// dotShapes are the shapes of the kinds of nodes in DOT and Mermaid.
+var (
+	dotShapes     = map[string]string{graphRouter: "box", graphSwitch: "ellipse", graphPort: "plaintext"}
+	mermaidShapes = map[string][2]string{graphRouter: {"[", "]"}, graphSwitch: {"([", "])"}, graphPort: {"(", ")"}}
+)
+
+// dot returns the graph in the Graphviz DOT language.
+func (g *logicalGraph) dot() string {
+	var b strings.Builder
+	b.WriteString("graph ovn {\n  rankdir=LR;\n")
+	for _, node := range g.nodes {
+		fmt.Fprintf(&b, "  %s [label=%s, shape=%s];\n", strconv.Quote(node.ID), strconv.Quote(nodeLabel(node, "\n")), dotShapes[node.Kind])
+	}
+	for _, edge := range g.edges {
+		fmt.Fprintf(&b, "  %s -- %s", strconv.Quote(edge.From), strconv.Quote(edge.To))
+		if label := edgeLabel(edge, "\n"); label != "" {
+			fmt.Fprintf(&b, " [label=%s]", strconv.Quote(label))
+		}
+		b.WriteString(";\n")
+	}
+	b.WriteString("}\n")
+	return b.String()
+}
+
+// mermaid returns the graph as a Mermaid flowchart. The nodes have short IDs, as
+// the names are not valid Mermaid IDs.
+func (g *logicalGraph) mermaid() string {
+	var b strings.Builder
+	b.WriteString("flowchart LR\n")
+	ids := map[string]string{}
+	for i, node := range g.nodes {
+		ids[node.ID] = "n" + strconv.Itoa(i)
+		shape := mermaidShapes[node.Kind]
+		fmt.Fprintf(&b, "  %s%s%s%s\n", ids[node.ID], shape[0], mermaidQuote(nodeLabel(node, "<br/>")), shape[1])
+	}
+	for _, edge := range g.edges {
+		if label := edgeLabel(edge, "<br/>"); label != "" {
+			fmt.Fprintf(&b, "  %s ---|%s| %s\n", ids[edge.From], mermaidQuote(label), ids[edge.To])
+		} else {
+			fmt.Fprintf(&b, "  %s --- %s\n", ids[edge.From], ids[edge.To])
+		}
+	}
+	return b.String()
+}
+
+// mermaidQuote quotes a Mermaid label.
+func mermaidQuote(label string) string {
+	return `"` + strings.ReplaceAll(label, `"`, "#quot;") + `"`
+}
+
+// nodeLabel returns the lines of the label of a node: its name, its type and
+// kind, and its addresses.
+func nodeLabel(node ovntypes.GraphNode, separator string) string {
+	lines := []string{node.Name, strings.TrimSpace(node.Type + " " + node.Kind)}
+	if len(node.Addresses) > 0 {
+		lines = append(lines, strings.Join(node.Addresses, " "))
+	}
+	return strings.Join(lines, separator)
+}
+
+// edgeLabel returns the lines of the label of an edge: its router ports and their
+// addresses.
+func edgeLabel(edge ovntypes.GraphEdge, separator string) string {
+	var lines []string
+	switch edge.Kind {
+	case edgePeer:
+		lines = append(lines, edge.Port+" - "+edge.PeerPort)
+	case edgeRouterPort:
+		lines = append(lines, edge.Port)
+	}
+	if len(edge.Addresses) > 0 {
+		lines = append(lines, strings.Join(edge.Addresses, " "))
+	}
+	return strings.Join(lines, separator)
+}
+
+// rowsByUUID returns the rows by UUID.
+func rowsByUUID(rows []ovsdb.Row) map[string]ovsdb.Row {
+	byUUID := make(map[string]ovsdb.Row, len(rows))
+	for _, row := range rows {
+		byUUID[row.String("_uuid")] = row
+	}
+	return byUUID
+}
+
+// rowNetwork returns the network of a switch or router.
+func rowNetwork(row ovsdb.Row) string {
+	return cmp.Or(row.Map("external_ids")[networkExternalID], defaultNetwork)
+}
+
+// baseName returns the name of a switch or router without the prefix of its
+// user-defined network, whose dashes are replaced by dots.
+func baseName(name, network string) string {
+	if network == defaultNetwork {
+		return name
+	}
+	return strings.TrimPrefix(name, strings.ReplaceAll(network, "-", ".")+"_")
+}
@@ -0,0 +1,219 @@
+package mcp
+
+import (
+	"reflect"
+	"slices"
+	"testing"
+
+	ovntypes "github.com/ovn-kubernetes/ovn-kubernetes-mcp/pkg/ovn/types"
+	"github.com/ovn-kubernetes/ovn-kubernetes-mcp/pkg/ovsdb"
+)
+
+// testTopology is the logical topology of the zone of node ovn-worker, with the
+// transit switch and router of interconnect and a user-defined network.
+func testTopology() map[string][]ovsdb.Row {
+	tables := map[string][]ovsdb.Row{}
+	router := func(name string, externalIDs map[string]any, ports ...ovsdb.Row) {
+		row := ovsdb.Row{"_uuid": "u-" + name, "name": name, "ports": []any{}, "options": map[string]any{}, "external_ids": externalIDs}
+		for _, port := range ports {
+			row["ports"] = append(row["ports"].([]any), port["_uuid"])
+			tables["Logical_Router_Port"] = append(tables["Logical_Router_Port"], port)
+		}
+		tables["Logical_Router"] = append(tables["Logical_Router"], row)
+	}
+	routerPort := func(name, peer string, networks ...any) ovsdb.Row {
+		return ovsdb.Row{"_uuid": "u-" + name, "name": name, "networks": networks, "peer": peer}
+	}
+	switchPort := func(name, portType string, options map[string]any, addresses ...any) ovsdb.Row {
+		return ovsdb.Row{"_uuid": "u-" + name, "name": name, "type": portType, "options": options, "addresses": addresses}
+	}
+	routerType := func(name, routerPort string) ovsdb.Row {
+		return switchPort(name, "router", map[string]any{"router-port": routerPort}, "router")
+	}
+	logicalSwitch := func(name string, otherConfig, externalIDs map[string]any, ports ...ovsdb.Row) {
+		row := ovsdb.Row{"_uuid": "u-" + name, "name": name, "ports": []any{}, "other_config": otherConfig, "external_ids": externalIDs}
+		for _, port := range ports {
+			row["ports"] = append(row["ports"].([]any), port["_uuid"])
+			tables["Logical_Switch_Port"] = append(tables["Logical_Switch_Port"], port)
+		}
+		tables["Logical_Switch"] = append(tables["Logical_Switch"], row)
+	}
+	blue := map[string]any{networkExternalID: "tenant-blue"}
+
+	router("ovn_cluster_router", map[string]any{},
+		routerPort("rtos-ovn-worker", "", "10.244.1.1/24"),
+		routerPort("rtoj-ovn_cluster_router", "", "100.64.0.1/16"),
+		routerPort("rtots-ovn-worker", "", "100.88.0.2/16"),
+		routerPort("rtotr-ovn-worker", "trtor-ovn-worker", "100.89.0.2/16"))
+	router("transit_router", map[string]any{}, routerPort("trtor-ovn-worker", "rtotr-ovn-worker", "100.89.0.1/16"))
+	router("GR_ovn-worker", map[string]any{},
+		routerPort("rtoj-GR_ovn-worker", "", "100.64.0.2/16"),
+		routerPort("rtoe-GR_ovn-worker", "", "172.18.0.3/16"))
+	router("tenant.blue_ovn_cluster_router", blue, routerPort("rtos-tenant.blue_ovn-worker", "", "10.200.1.1/24"))
+
+	logicalSwitch("ovn-worker", map[string]any{"subnet": "10.244.1.0/24"}, map[string]any{},
+		routerType("stor-ovn-worker", "rtos-ovn-worker"),
+		switchPort("default_client", "", map[string]any{"requested-chassis": "ovn-worker"}, "0a:58:0a:f4:01:05 10.244.1.5"),
+		switchPort("k8s-ovn-worker", "", map[string]any{}, "0a:58:0a:f4:01:02 10.244.1.2"))
+	logicalSwitch("join", map[string]any{}, map[string]any{},
+		routerType("jtor-ovn_cluster_router", "rtoj-ovn_cluster_router"),
+		routerType("jtor-GR_ovn-worker", "rtoj-GR_ovn-worker"))
+	logicalSwitch("ext_ovn-worker", map[string]any{}, map[string]any{},
+		routerType("etor-GR_ovn-worker", "rtoe-GR_ovn-worker"),
+		switchPort("breth0_ovn-worker", "localnet", map[string]any{"network_name": "physnet"}, "unknown"))
+	logicalSwitch("transit_switch", map[string]any{"interconn-ts": "transit_switch"}, map[string]any{},
+		routerType("tstor-ovn-worker", "rtots-ovn-worker"),
+		switchPort("tstor-ovn-worker2", "remote", map[string]any{"requested-chassis": "ovn-worker2"}, "0a:58:64:58:00:03 100.88.0.3/16"))
+	logicalSwitch("tenant.blue_ovn-worker", map[string]any{"subnet": "10.200.1.0/24"}, blue,
+		routerType("stor-tenant.blue_ovn-worker", "rtos-tenant.blue_ovn-worker"),
+		switchPort("tenant.blue_default_web", "", map[string]any{}, "0a:58:0a:c8:01:05 10.200.1.5"))
+	return tables
+}
+
+func nodeIDs(g *logicalGraph) []string {
+	ids := []string{}
+	for _, node := range g.nodes {
+		ids = append(ids, node.ID)
+	}
+	return ids
+}
+
+func TestBuildGraph(t *testing.T) {
+	g := buildGraph(testTopology())
+	nodes := map[string]ovntypes.GraphNode{}
+	for _, node := range g.nodes {
+		nodes[node.ID] = node
+	}
+	wantNodes := []ovntypes.GraphNode{
+		{ID: "router:GR_ovn-worker", Kind: "router", Type: "gateway", Name: "GR_ovn-worker", Node: "ovn-worker", Network: "default"},
+		{ID: "router:ovn_cluster_router", Kind: "router", Type: "cluster", Name: "ovn_cluster_router", Network: "default"},
+		{ID: "switch:ovn-worker", Kind: "switch", Type: "node", Name: "ovn-worker", Node: "ovn-worker", Network: "default", Addresses: []string{"10.244.1.0/24"}},
+		{ID: "switch:join", Kind: "switch", Type: "join", Name: "join", Network: "default"},
+		{ID: "switch:ext_ovn-worker", Kind: "switch", Type: "external", Name: "ext_ovn-worker", Node: "ovn-worker", Network: "default"},
+		{ID: "switch:transit_switch", Kind: "switch", Type: "transit", Name: "transit_switch", Network: "default"},
+		{ID: "switch:tenant.blue_ovn-worker", Kind: "switch", Type: "node", Name: "tenant.blue_ovn-worker", Node: "ovn-worker", Network: "tenant-blue", Addresses: []string{"10.200.1.0/24"}},
+		{ID: "port:default_client", Kind: "port", Type: "vif", Name: "default_client", Node: "ovn-worker", Network: "default", Addresses: []string{"0a:58:0a:f4:01:05 10.244.1.5"}},
+		{ID: "port:tstor-ovn-worker2", Kind: "port", Type: "remote", Name: "tstor-ovn-worker2", Node: "ovn-worker2", Network: "default", Addresses: []string{"0a:58:64:58:00:03 100.88.0.3/16"}},
+	}
+	for _, want := range wantNodes {
+		if !reflect.DeepEqual(nodes[want.ID], want) {
+			t.Fatalf("Expected node %+v, got %+v", want, nodes[want.ID])
+		}
+	}
+	if len(g.nodes) != 14 || len(g.edges) != 12 {
+		t.Fatalf("Expected 14 nodes and 12 edges, got %v and %+v", nodeIDs(g), g.edges)
+	}
+
+	wantEdges := []ovntypes.GraphEdge{
+		{From: "router:ovn_cluster_router", To: "switch:ovn-worker", Kind: "router-port", Port: "rtos-ovn-worker", PeerPort: "stor-ovn-worker", Addresses: []string{"10.244.1.1/24"}},
+		{From: "router:ovn_cluster_router", To: "router:transit_router", Kind: "peer", Port: "rtotr-ovn-worker", PeerPort: "trtor-ovn-worker", Addresses: []string{"100.89.0.2/16", "100.89.0.1/16"}},
+		{From: "switch:ovn-worker", To: "port:default_client", Kind: "port"},
+	}
+	for _, want := range wantEdges {
+		found := false
+		for _, edge := range g.edges {
+			found = found || reflect.DeepEqual(edge, want)
+		}
+		if !found {
+			t.Fatalf("Expected edge %+v in %+v", want, g.edges)
+		}
+	}
+}
+
+func TestFilterGraph(t *testing.T) {
+	tests := []struct {
+		name      string
+		filter    func(g *logicalGraph) error
+		wantNodes []string
+		wantErr   string
+	}{
+		{
+			name:      "network",
+			filter:    func(g *logicalGraph) error { g.filterNetwork("tenant-blue"); return nil },
+			wantNodes: []string{"port:tenant.blue_default_web", "router:tenant.blue_ovn_cluster_router", "switch:tenant.blue_ovn-worker"},
+		},
+		{
+			name:      "network prefix",
+			filter:    func(g *logicalGraph) error { g.filterNetwork("tenant.blue_"); return nil },
+			wantNodes: []string{"port:tenant.blue_default_web", "router:tenant.blue_ovn_cluster_router", "switch:tenant.blue_ovn-worker"},
+		},
+		{
+			name:      "node",
+			filter:    func(g *logicalGraph) error { g.filterNode("ovn-worker2"); return nil },
+			wantNodes: []string{"port:tstor-ovn-worker2", "switch:transit_switch"},
+		},
+		{
+			name:      "switch port",
+			filter:    func(g *logicalGraph) error { return g.around("default_client", 1) },
+			wantNodes: []string{"port:default_client", "switch:ovn-worker"},
+		},
+		{
+			name:   "router port",
+			filter: func(g *logicalGraph) error { return g.around("rtos-ovn-worker", 1) },
+			wantNodes: []string{"router:ovn_cluster_router", "router:transit_router", "switch:join", "switch:ovn-worker",
+				"switch:transit_switch"},
+		},
+		{
+			name: "default network without ports",
+			filter: func(g *logicalGraph) error {
+				g.filterNetwork("default")
+				g.hidePorts("")
+				return nil
+			},
+			wantNodes: []string{"router:GR_ovn-worker", "router:ovn_cluster_router", "router:transit_router", "switch:ext_ovn-worker",
+				"switch:join", "switch:ovn-worker", "switch:transit_switch"},
+		},
+		{
+			name:    "unknown port",
+			filter:  func(g *logicalGraph) error { return g.around("default_server", 2) },
+			wantErr: "port default_server not found in the logical topology",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			g := buildGraph(testTopology())
+			err := test.filter(g)
+			if test.wantErr != "" {
+				if err == nil || err.Error() != test.wantErr {
+					t.Fatalf("Expected error %q, got %v", test.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if ids := nodeIDs(g); !reflect.DeepEqual(ids, test.wantNodes) {
+				t.Fatalf("Expected nodes %v, got %v", test.wantNodes, ids)
+			}
+			for _, edge := range g.edges {
+				if !slices.Contains(test.wantNodes, edge.From) || !slices.Contains(test.wantNodes, edge.To) {
+					t.Fatalf("Unexpected edge %+v", edge)
+				}
+			}
+		})
+	}
+}
+
+func TestRenderGraph(t *testing.T) {
+	g := buildGraph(testTopology())
+	g.keep(map[string]bool{"router:ovn_cluster_router": true, "switch:ovn-worker": true})
+
+	wantDOT := `graph ovn {
+  rankdir=LR;
+  "router:ovn_cluster_router" [label="ovn_cluster_router\ncluster router", shape=box];
+  "switch:ovn-worker" [label="ovn-worker\nnode switch\n10.244.1.0/24", shape=ellipse];
+  "router:ovn_cluster_router" -- "switch:ovn-worker" [label="rtos-ovn-worker\n10.244.1.1/24"];
+}
+`
+	if dot := g.dot(); dot != wantDOT {
+		t.Fatalf("Expected DOT:\n%s\ngot:\n%s", wantDOT, dot)
+	}
+	wantMermaid := `flowchart LR
+  n0["ovn_cluster_router<br/>cluster router"]
+  n1(["ovn-worker<br/>node switch<br/>10.244.1.0/24"])
+  n0 ---|"rtos-ovn-worker<br/>10.244.1.1/24"| n1
+`
+	if mermaid := g.mermaid(); mermaid != wantMermaid {
+		t.Fatalf("Expected Mermaid:\n%s\ngot:\n%s", wantMermaid, mermaid)
+	}
+}
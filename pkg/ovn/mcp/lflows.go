@@ -0,0 +1,181 @@
+package mcp
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	k8stypes "github.com/ovn-kubernetes/ovn-kubernetes-mcp/pkg/kubernetes/types"
+	ovntypes "github.com/ovn-kubernetes/ovn-kubernetes-mcp/pkg/ovn/types"
+	"github.com/ovn-kubernetes/ovn-kubernetes-mcp/pkg/ovsdb"
+)
+
+// logicalFlowColumns are the Southbound columns of the logical flows.
+var logicalFlowColumns = []string{"logical_datapath", "logical_dp_group", "pipeline", "table_id", "priority", "match",
+	"actions", "external_ids"}
+
+// pipelineOrder orders the ingress pipeline before the egress pipeline, like
+// lflow-list.
+var pipelineOrder = map[string]int{"ingress": 0, "egress": 1}
+
+// readLogicalFlows returns the logical flows of the Southbound database of the
+// target, on a datapath if it is set, by name or UUID. The flows of the datapath
+// groups are returned once per datapath of their group, like lflow-list.
+func (s *MCPServer) readLogicalFlows(ctx context.Context, req *mcp.CallToolRequest, target k8stypes.PodTargetParams,
+	datapath string) ([]ovntypes.LogicalFlow, error) {
+	client, err := s.ovsdbClient(ctx, req, target, ovntypes.SouthboundDB)
+	if err != nil {
+		return nil, err
+	}
+	schema, err := client.Schema(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	bindings, err := client.Select(ctx, schema, ovsdb.Query{Table: "Datapath_Binding", Columns: []string{"external_ids"},
+		KeepUUIDs: true})
+	if err != nil {
+		return nil, err
+	}
+	datapaths := map[string]string{}
+	selected := ""
+	for _, binding := range bindings {
+		uuid := binding.String("_uuid")
+		datapaths[uuid] = cmp.Or(binding.Map("external_ids")["name"], uuid)
+		if datapath != "" && (datapath == uuid || datapath == datapaths[uuid]) {
+			selected = uuid
+		}
+	}
+	if datapath != "" && selected == "" {
+		return nil, fmt.Errorf("datapath %s not found", datapath)
+	}
+
+	groupQuery := ovsdb.Query{Table: "Logical_DP_Group", Columns: []string{"datapaths"}, KeepUUIDs: true}
+	flowQueries := []ovsdb.Query{{Table: "Logical_Flow", Columns: logicalFlowColumns, KeepUUIDs: true}}
+	if selected != "" {
+		groupQuery.Where = []ovsdb.Condition{{Column: "datapaths", Function: "includes", Value: selected}}
+		flowQueries[0].Where = []ovsdb.Condition{{Column: "logical_datapath", Function: "==", Value: selected}}
+	}
+	groupRows, err := client.Select(ctx, schema, groupQuery)
+	if err != nil {
+		return nil, err
+	}
+	groups := map[string][]string{}
+	for _, group := range groupRows {
+		uuid := group.String("_uuid")
+		groups[uuid] = group.Strings("datapaths")
+		if selected != "" {
+			groups[uuid] = []string{selected}
+			flowQueries = append(flowQueries, ovsdb.Query{
+				Table:     "Logical_Flow",
+				Columns:   logicalFlowColumns,
+				Where:     []ovsdb.Condition{{Column: "logical_dp_group", Function: "==", Value: uuid}},
+				KeepUUIDs: true,
+			})
+		}
+	}
+
+	flows := []ovntypes.LogicalFlow{}
+	for _, query := range flowQueries {
+		rows, err := client.Select(ctx, schema, query)
+		if err != nil {
+			return nil, err
+		}
+		for _, row := range rows {
+			flowDatapaths := row.Strings("logical_datapath")
+			if len(flowDatapaths) == 0 {
+				flowDatapaths = groups[row.String("logical_dp_group")]
+			}
+			for _, flowDatapath := range flowDatapaths {
+				flows = append(flows, ovntypes.ParseLogicalFlow(row, cmp.Or(datapaths[flowDatapath], flowDatapath)))
+			}
+		}
+	}
+	sortLogicalFlows(flows)
+	return flows, nil
+}
+
+// sortLogicalFlows sorts the logical flows like lflow-list: by datapath, pipeline
+// and table, and by decreasing priority.
+func sortLogicalFlows(flows []ovntypes.LogicalFlow) {
+	slices.SortFunc(flows, func(a, b ovntypes.LogicalFlow) int {
+		return cmp.Or(
+			strings.Compare(a.Datapath, b.Datapath),
+			cmp.Compare(pipelineOrder[a.Pipeline], pipelineOrder[b.Pipeline]),
+			cmp.Compare(a.Table, b.Table),
+			cmp.Compare(b.Priority, a.Priority),
+			strings.Compare(a.Match, b.Match),
+			strings.Compare(a.UUID, b.UUID),
+		)
+	})
+}
+
+// logicalFlowLine returns a logical flow in the format of lflow-list.
+func logicalFlowLine(flow ovntypes.LogicalFlow) string {
+	return fmt.Sprintf("table=%d (%s), priority=%d, match=(%s), action=(%s)", flow.Table, flow.Stage, flow.Priority,
+		flow.Match, flow.Actions)
+}
+
+// referencePattern returns the pattern of the references to a logical port, port
+// group or address set in the matches and actions of the logical flows: the
+// quoted name of a port, or the name of a port group or address set after @ or $.
+func referencePattern(name string) *regexp.Regexp {
+	quoted := regexp.QuoteMeta(name)
+	return regexp.MustCompile(`"` + quoted + `"|[@$]` + quoted + `(\W|$)`)
+}
+
+// filterLogicalFlows returns the logical flows of the stage, with the minimum
+// priority, whose match contains the substring, that reference the port, port
+// group or address set, and whose lflow-list line matches the filter pattern,
+// for the parameters that are set.
+func filterLogicalFlows(flows []ovntypes.LogicalFlow, in ovntypes.LogicalFlowListParams) ([]ovntypes.LogicalFlow, error) {
+	var filter, reference *regexp.Regexp
+	if in.Filter != "" {
+		var err error
+		if filter, err = regexp.Compile(in.Filter); err != nil {
+			return nil, fmt.Errorf("invalid filter pattern %s: %w", in.Filter, err)
+		}
+	}
+	if in.Reference != "" {
+		reference = referencePattern(in.Reference)
+	}
+	filtered := []ovntypes.LogicalFlow{}
+	for _, flow := range flows {
+		switch {
+		case in.Stage != "" && flow.Stage != in.Stage:
+		case flow.Priority < in.MinPriority:
+		case in.MatchContains != "" && !strings.Contains(flow.Match, in.MatchContains):
+		case reference != nil && !reference.MatchString(flow.Match) && !reference.MatchString(flow.Actions):
+		case filter != nil && !filter.MatchString(logicalFlowLine(flow)):
+		default:
+			filtered = append(filtered, flow)
+		}
+	}
+	return filtered, nil
+}
+
+// countStages returns the number of logical flows of each stage, by pipeline and
+// table.
+func countStages(flows []ovntypes.LogicalFlow) []ovntypes.StageCount {
+	counts := map[ovntypes.StageCount]int{}
+	for _, flow := range flows {
+		counts[ovntypes.StageCount{Pipeline: flow.Pipeline, Table: flow.Table, Stage: flow.Stage}]++
+	}
+	stages := []ovntypes.StageCount{}
+	for stage, count := range counts {
+		stage.Count = count
+		stages = append(stages, stage)
+	}
+	slices.SortFunc(stages, func(a, b ovntypes.StageCount) int {
+		return cmp.Or(
+			cmp.Compare(pipelineOrder[a.Pipeline], pipelineOrder[b.Pipeline]),
+			cmp.Compare(a.Table, b.Table),
+			strings.Compare(a.Stage, b.Stage),
+		)
+	})
+	return stages
+}
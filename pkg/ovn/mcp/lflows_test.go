@@ -0,0 +1,111 @@
+package mcp
+
+import (
+	"reflect"
+	"testing"
+
+	ovntypes "github.com/ovn-kubernetes/ovn-kubernetes-mcp/pkg/ovn/types"
+)
+
+// testLogicalFlows are logical flows of a node switch and the cluster router.
+var testLogicalFlows = []ovntypes.LogicalFlow{
+	{UUID: "5", Datapath: "ovn-worker", Pipeline: "egress", Table: 4, Stage: "ls_out_acl_eval", Priority: 2000,
+		Match: `outport == @a4743249366342378346 && ip4.src == $a13607449821398607916`, Actions: "drop;"},
+	{UUID: "1", Datapath: "ovn-worker", Pipeline: "ingress", Table: 0, Stage: "ls_in_check_port_sec", Priority: 50,
+		Match: `inport == "default_client"`, Actions: "reg0[15] = check_in_port_sec(); next;"},
+	{UUID: "2", Datapath: "ovn-worker", Pipeline: "ingress", Table: 0, Stage: "ls_in_check_port_sec", Priority: 100,
+		Match: "vlan.present", Actions: "drop;"},
+	{UUID: "3", Datapath: "ovn-worker", Pipeline: "ingress", Table: 27, Stage: "ls_in_l2_lkup", Priority: 50,
+		Match: "eth.dst == 0a:58:0a:f4:01:05", Actions: `outport = "default_client_old"; output;`},
+	{UUID: "4", Datapath: "ovn_cluster_router", Pipeline: "ingress", Table: 13, Stage: "lr_in_ip_routing", Priority: 74,
+		Match: "ip4.dst == 10.244.1.0/24", Actions: "ip.ttl--; next;"},
+}
+
+func TestFilterLogicalFlows(t *testing.T) {
+	tests := []struct {
+		name      string
+		params    ovntypes.LogicalFlowListParams
+		wantUUIDs []string
+		wantErr   string
+	}{
+		{
+			name:      "all flows sorted like lflow-list",
+			wantUUIDs: []string{"2", "1", "3", "5", "4"},
+		},
+		{
+			name:      "stage",
+			params:    ovntypes.LogicalFlowListParams{Stage: "ls_in_check_port_sec"},
+			wantUUIDs: []string{"2", "1"},
+		},
+		{
+			name:      "minimum priority",
+			params:    ovntypes.LogicalFlowListParams{MinPriority: 100},
+			wantUUIDs: []string{"2", "5"},
+		},
+		{
+			name:      "match substring",
+			params:    ovntypes.LogicalFlowListParams{MatchContains: "ip4"},
+			wantUUIDs: []string{"5", "4"},
+		},
+		{
+			name:      "referenced port in the match",
+			params:    ovntypes.LogicalFlowListParams{Reference: "default_client"},
+			wantUUIDs: []string{"1"},
+		},
+		{
+			name:      "referenced address set",
+			params:    ovntypes.LogicalFlowListParams{Reference: "a13607449821398607916"},
+			wantUUIDs: []string{"5"},
+		},
+		{
+			name:      "referenced port group prefix",
+			params:    ovntypes.LogicalFlowListParams{Reference: "a474324936"},
+			wantUUIDs: []string{},
+		},
+		{
+			name:      "filter on the lflow-list line",
+			params:    ovntypes.LogicalFlowListParams{Filter: `table=0 \(ls_in_check_port_sec\), priority=100`},
+			wantUUIDs: []string{"2"},
+		},
+		{
+			name:    "invalid filter",
+			params:  ovntypes.LogicalFlowListParams{Filter: "("},
+			wantErr: "invalid filter pattern (: error parsing regexp: missing closing ): `(`",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			flows := append([]ovntypes.LogicalFlow{}, testLogicalFlows...)
+			sortLogicalFlows(flows)
+			flows, err := filterLogicalFlows(flows, test.params)
+			if test.wantErr != "" {
+				if err == nil || err.Error() != test.wantErr {
+					t.Fatalf("Expected error %q, got %v", test.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			uuids := []string{}
+			for _, flow := range flows {
+				uuids = append(uuids, flow.UUID)
+			}
+			if !reflect.DeepEqual(uuids, test.wantUUIDs) {
+				t.Fatalf("Expected flows %v, got %v", test.wantUUIDs, uuids)
+			}
+		})
+	}
+}
+
+func TestCountStages(t *testing.T) {
+	want := []ovntypes.StageCount{
+		{Pipeline: "ingress", Table: 0, Stage: "ls_in_check_port_sec", Count: 2},
+		{Pipeline: "ingress", Table: 13, Stage: "lr_in_ip_routing", Count: 1},
+		{Pipeline: "ingress", Table: 27, Stage: "ls_in_l2_lkup", Count: 1},
+		{Pipeline: "egress", Table: 4, Stage: "ls_out_acl_eval", Count: 1},
+	}
+	if stages := countStages(testLogicalFlows); !reflect.DeepEqual(stages, want) {
+		t.Fatalf("Expected stages %+v, got %+v", want, stages)
+	}
+}
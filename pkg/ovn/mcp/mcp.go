@@ -3,22 +3,39 @@ package mcp
 import (
 	"context"
 	"fmt"
+	"maps"
+	"slices"
 	"strings"
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
-	kubernetesmcp "github.com/ovn-kubernetes/ovn-kubernetes-mcp/pkg/kubernetes/mcp"
+	"github.com/ovn-kubernetes/ovn-kubernetes-mcp/pkg/executor"
 	ovntypes "github.com/ovn-kubernetes/ovn-kubernetes-mcp/pkg/ovn/types"
+	"github.com/ovn-kubernetes/ovn-kubernetes-mcp/pkg/ovsdb"
+	"github.com/ovn-kubernetes/ovn-kubernetes-mcp/pkg/pagination"
+	"github.com/ovn-kubernetes/ovn-kubernetes-mcp/pkg/topology"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 )
 
+// Resources looks up the Kubernetes resources of the cluster of the tool call.
+type Resources interface {
+	Get(ctx context.Context, group, version, kind, namespace, name string) (*unstructured.Unstructured, error)
+}
+
 // MCPServer provides OVN layer analysis tools
 type MCPServer struct {
-	k8sMcpServer *kubernetesmcp.MCPServer
+	executor  executor.Executor
+	pods      topology.PodResolver
+	resources Resources
 }
 
-// NewMCPServer creates a new OVN MCP server
-func NewMCPServer(k8sMcpServer *kubernetesmcp.MCPServer) *MCPServer {
+// NewMCPServer creates a new OVN MCP server. The commands run in the pods
+// resolved by pods. The tools tracing the packets of the pods are only added
+// with the resources of a cluster.
+func NewMCPServer(executor executor.Executor, pods topology.PodResolver, resources Resources) *MCPServer {
 	return &MCPServer{
-		k8sMcpServer: k8sMcpServer,
+		executor:  executor,
+		pods:      pods,
+		resources: resources,
 	}
 }
 
@@ -36,8 +53,12 @@ For Southbound (sbdb): Runs 'ovn-sbctl show' and displays chassis information, p
 and their relationships.
 
 Parameters:
-- namespace: Kubernetes namespace of the OVN pod (e.g., "openshift-ovn-kubernetes")
+- cluster: Cluster of the pod, from cluster-list (optional, defaults to the default cluster)
+- namespace: Kubernetes namespace of the OVN pod (e.g., "openshift-ovn-kubernetes"), detected with node
 - name: Name of the pod running OVN (e.g., "ovnkube-node-xxxxx")
+- node: Node to run the command on instead of the pod, from ovnk-topology (the command runs in the pod with the
+  database of the zone of the node with interconnect, of the leader otherwise)
+- container (optional): Container of the pod to run the command in, selected automatically if not set
 - database: OVN database to query - "nbdb" for Northbound or "sbdb" for Southbound
 - max_lines (optional): Limit the number of output lines returned (default: 100)
 
@@ -64,14 +85,21 @@ Common Southbound tables: Chassis, Port_Binding, Datapath_Binding, Logical_Flow,
 MAC_Binding, Multicast_Group, SB_Global
 
 Parameters:
-- namespace: Kubernetes namespace of the OVN pod
+- cluster: Cluster of the pod, from cluster-list (optional, defaults to the default cluster)
+- namespace: Kubernetes namespace of the OVN pod, detected with node
 - name: Name of the pod running OVN
+- node: Node to run the command on instead of the pod, from ovnk-topology (the command runs in the pod with the
+  database of the zone of the node with interconnect, of the leader otherwise)
+- container (optional): Container of the pod to run the command in, selected automatically if not set
 - database: OVN database to query - "nbdb" for Northbound or "sbdb" for Southbound
 - table: Name of the table (e.g., "Logical_Switch", "Port_Binding")
 - record (optional): Record identifier (UUID or name). If not specified, lists all records
 - columns (optional): Comma-separated list of columns to display (e.g., "name,_uuid,ports")
 - filter (optional): Regex pattern to filter results
-- max_lines (optional): Limit the number of lines returned (default: 100)
+- page_size (optional): Number of output lines per page (default: 100, max: 1000)
+- cursor (optional): next_cursor of the previous page, to get the next page
+
+The result contains the total number of output lines, and a next_cursor until the last page.
 
 Example listing all records:
 {
@@ -97,28 +125,165 @@ Example getting specific columns:
 }`,
 		}, s.Get)
 
+	mcp.AddTool(server,
+		&mcp.Tool{
+			Name: "ovn-query",
+			Description: `Select the rows of an OVN database table as typed JSON objects, with OVSDB conditions.
+
+Runs an OVSDB select transaction with 'ovsdb-client transact' rather than parsing the text output of
+ovn-nbctl or ovn-sbctl, so that a row is never split by a filter. The table, columns and conditions
+are validated against the schema of the live database, and the errors list the valid tables or
+columns. The UUIDs referencing the rows of other tables are resolved to the names of the rows when
+the tables have names (for example the ports of a Logical_Switch), unless keep_uuids is set.
+
+The values are typed: a string, number or boolean for the columns with one value (null if an optional
+column is not set), an array for the sets and an object for the maps. The rows always have their
+_uuid, and are sorted by name if the table has names.
+
+Parameters:
+- cluster: Cluster of the pod, from cluster-list (optional, defaults to the default cluster)
+- namespace: Kubernetes namespace of the OVN pod, detected with node
+- name: Name of the pod running OVN
+- node: Node to run the command on instead of the pod, from ovnk-topology (the command runs in the pod with the
+  database of the zone of the node with interconnect, of the leader otherwise)
+- container (optional): Container of the pod to run the command in, selected automatically if not set
+- database: OVN database to query - "nbdb" for Northbound or "sbdb" for Southbound
+- table: Name of the table (e.g., "Logical_Switch_Port", "Port_Binding")
+- columns (optional): Columns of the rows (e.g., ["name", "addresses"]), all of them if not set
+- where (optional): Conditions the rows match, all of them, each with a column, a function and a value.
+  The functions are ==, !=, includes and excludes, and <, <=, >, >= for the integer and real columns.
+  The value of a set column is an array or a single value, the value of a map column is an object,
+  and UUIDs are strings.
+- keep_uuids (optional): Return the UUIDs referencing other tables instead of the names of their rows
+- page_size (optional): Number of rows per page (default: 100, max: 1000)
+- cursor (optional): next_cursor of the previous page, to get the next page
+
+Example conditions:
+- [{"column": "name", "function": "==", "value": "default_client"}]
+- [{"column": "external_ids", "function": "includes", "value": {"k8s.ovn.org/name": "allow-dns"}}]
+- [{"column": "priority", "function": ">=", "value": 1000}, {"column": "action", "function": "==", "value": "drop"}]
+
+Example output:
+{
+  "database": "nbdb",
+  "table": "Logical_Switch",
+  "rows": [
+    {
+      "_uuid": "4c4a0a35-348c-41cc-8417-53a618e0c383",
+      "name": "ovn-worker",
+      "ports": ["default_client", "k8s-ovn-worker", "stor-ovn-worker"],
+      "other_config": {"subnet": "10.244.1.0/24"},
+      "acls": []
+    }
+  ],
+  "total": 1
+}`,
+		}, s.Query)
+
+	mcp.AddTool(server,
+		&mcp.Tool{
+			Name: "ovn-topology-graph",
+			Description: `Build the graph of the logical topology of the OVN Northbound database, as JSON, Graphviz DOT or Mermaid.
+
+Reads the logical switches, routers, router ports and switch ports of the Northbound database with
+OVSDB select transactions, and links them:
+- The routers are linked to the switches by their router ports (edge kind "router-port"), and to each
+  other by their peer router ports (edge kind "peer").
+- The other switch ports, like the pod ports, are nodes of kind "port" linked to their switch (edge kind
+  "port"), with their type ("vif" for pods, "localnet", "remote", ...) and addresses.
+- The switches have a type: "node" with the subnet of the node, "join", "transit" or "external". The
+  routers are "cluster" routers or "gateway" routers. The switches, routers and ports have the
+  Kubernetes node and the network they belong to when it is known.
+
+With interconnect, the database of a node only has the logical topology of its zone. On large clusters,
+filter the graph rather than reading the whole topology:
+
+Parameters:
+- cluster: Cluster of the pod, from cluster-list (optional, defaults to the default cluster)
+- namespace: Kubernetes namespace of the OVN pod, detected with node
+- name: Name of the pod running OVN
+- node: Node to run the command on instead of the pod, from ovnk-topology (the command runs in the pod with the
+  database of the zone of the node with interconnect, of the leader otherwise)
+- container (optional): Container of the pod to run the command in, selected automatically if not set
+- format (optional): "json" (default) for nodes and edges, "dot" for Graphviz or "mermaid" for a Mermaid flowchart
+- filter_node (optional): Keep the switches, routers and ports of a Kubernetes node, and what they are linked to
+- network (optional): Keep a network: "default", or the name or name prefix of a user-defined network
+- around_port (optional): Keep the subgraph around a switch port or router port (e.g., "default_client" or "rtos-ovn-worker")
+- depth (optional): Number of hops of the subgraph around around_port (default: 2)
+- hide_ports (optional): Remove the switch ports other than around_port, keeping the switches and routers only
+
+Example output with format json:
+{
+  "format": "json",
+  "nodes": [
+    {"id": "router:GR_ovn-worker", "kind": "router", "type": "gateway", "name": "GR_ovn-worker", "node": "ovn-worker", "network": "default"},
+    {"id": "router:ovn_cluster_router", "kind": "router", "type": "cluster", "name": "ovn_cluster_router", "network": "default"},
+    {"id": "switch:ovn-worker", "kind": "switch", "type": "node", "name": "ovn-worker", "node": "ovn-worker", "network": "default", "addresses": ["10.244.1.0/24"]},
+    {"id": "port:default_client", "kind": "port", "type": "vif", "name": "default_client", "node": "ovn-worker", "network": "default", "addresses": ["0a:58:0a:f4:01:05 10.244.1.5"]}
+  ],
+  "edges": [
+    {"from": "router:ovn_cluster_router", "to": "switch:ovn-worker", "kind": "router-port", "port": "rtos-ovn-worker", "peer_port": "stor-ovn-worker", "addresses": ["10.244.1.1/24"]},
+    {"from": "switch:ovn-worker", "to": "port:default_client", "kind": "port"}
+  ],
+  "node_count": 4,
+  "edge_count": 2
+}`,
+		}, s.TopologyGraph)
+
 	mcp.AddTool(server,
 		&mcp.Tool{
 			Name: "ovn-lflow-list",
-			Description: `List logical flows from the OVN Southbound database.
+			Description: `List logical flows from the OVN Southbound database, with their stage, priority, match and actions.
+
+Reads the logical flows of the Southbound database with OVSDB select transactions, like
+'ovn-sbctl lflow-list', which represent the compiled logical network pipeline. This is essential for
+debugging packet forwarding. The flows of datapath groups are listed once per datapath of the group,
+and the flows are sorted like lflow-list: by datapath, pipeline (ingress, then egress), table and
+decreasing priority.
 
-Runs 'ovn-sbctl lflow-list' to retrieve logical flows which represent the compiled 
-logical network pipeline. This is essential for debugging packet forwarding.
+The stages list the number of flows of each stage of the filtered flows, across all the pages: list
+them first (e.g., with page_size 1) to find the stage that matters, then list the flows of the stage.
 
 Parameters:
-- namespace: Kubernetes namespace of the OVN pod
+- cluster: Cluster of the pod, from cluster-list (optional, defaults to the default cluster)
+- namespace: Kubernetes namespace of the OVN pod, detected with node
 - name: Name of the pod running OVN
+- node: Node to run the command on instead of the pod, from ovnk-topology (the command runs in the pod with the
+  database of the zone of the node with interconnect, of the leader otherwise)
+- container (optional): Container of the pod to run the command in, selected automatically if not set
 - datapath (optional): Datapath name or UUID to filter flows for a specific logical switch/router
-- filter (optional): Regex pattern to filter flows
-- max_lines (optional): Limit the number of flows returned (default: 100)
+- stage (optional): Stage name of the flows (e.g., "ls_in_acl_eval", "lr_in_ip_routing")
+- min_priority (optional): Minimum priority of the flows
+- match_contains (optional): Substring of the match of the flows (e.g., "10.244.1.5")
+- reference (optional): Logical port, port group or address set referenced by the match or actions of the
+  flows (e.g., "default_client", or a port group or address set name without its @ or $)
+- filter (optional): Regex pattern matching the flows in the lflow-list format
+  ("table=0 (ls_in_check_port_sec), priority=50, match=(...), action=(...)")
+- page_size (optional): Number of flows per page (default: 100, max: 1000)
+- cursor (optional): next_cursor of the previous page, to get the next page
 
 Example output:
 {
-  "datapath": "node1",
+  "datapath": "ovn-worker",
   "flows": [
-    "table=0 (ls_in_port_sec_l2), priority=100, match=(inport == \"pod1\"), action=(next;)",
-    "table=1 (ls_in_port_sec_ip), priority=90, match=(ip4), action=(next;)"
-  ]
+    {
+      "uuid": "8c1bd2e4-7a5e-4c2f-9d0a-3f6b1e2c4d5a",
+      "datapath": "ovn-worker",
+      "pipeline": "ingress",
+      "table": 0,
+      "stage": "ls_in_check_port_sec",
+      "priority": 50,
+      "match": "inport == \"default_client\"",
+      "actions": "reg0[15] = check_in_port_sec(); next;",
+      "external_ids": {"source": "northd.c:6255", "stage-name": "ls_in_check_port_sec"}
+    }
+  ],
+  "stages": [
+    {"pipeline": "ingress", "table": 0, "stage": "ls_in_check_port_sec", "count": 6},
+    {"pipeline": "ingress", "table": 9, "stage": "ls_in_acl_eval", "count": 42}
+  ],
+  "next_cursor": "eyJvIjoxMDAsInEiOiIxZzZ5In0",
+  "total": 2481
 }`,
 		}, s.ListLogicalFlows)
 
@@ -134,8 +299,12 @@ The trace is essential for debugging connectivity issues and understanding how t
 flows through the OVN logical network.
 
 Parameters:
-- namespace: Kubernetes namespace of the OVN pod
+- cluster: Cluster of the pod, from cluster-list (optional, defaults to the default cluster)
+- namespace: Kubernetes namespace of the OVN pod, detected with node
 - name: Name of the pod running OVN
+- node: Node to run the command on instead of the pod, from ovnk-topology (the command runs in the pod with the
+  database of the zone of the node with interconnect, of the leader otherwise)
+- container (optional): Container of the pod to run the command in, selected automatically if not set
 - datapath: Name of the logical switch or router to start the trace
 - microflow: Microflow specification describing the packet (e.g., "inport==\"pod1\" && eth.src==00:00:00:00:00:01 && ip4.src==10.244.0.5 && ip4.dst==10.244.1.5")
 - mode (optional): Output verbosity mode - "detailed" (default), "summary", or "minimal"
@@ -153,6 +322,60 @@ Example output:
   "output": "ingress(dp=\"node1\", inport=\"pod1\")\n  0. ls_in_port_sec_l2: inport == \"pod1\", priority 50, uuid 1234\n     next;\n..."
 }`,
 		}, s.Trace)
+
+	if s.resources == nil {
+		return
+	}
+	mcp.AddTool(server,
+		&mcp.Tool{
+			Name: "ovn-trace-pod",
+			Description: `Trace a packet from a pod to a pod, Service, IP address or Service DNS name through the OVN logical network.
+
+Builds the microflow of 'ovn-trace' from the pods, like ovnkube-trace: the logical port, MAC and IP
+addresses of the pods are read from their k8s.ovn.org/pod-networks annotation, on their primary network
+(a primary user-defined network, or the default network). The packet enters the logical switch of the
+source pod, addressed to the destination pod on the same switch and to the router of the switch
+otherwise, and the trace runs in the database of the zone of the source node with interconnect.
+
+With interconnect, the packet to a pod of another zone leaves the zone by the transit switch: the trace
+continues in the database of the zone of the destination node, from the transit switch port of the
+source node. The traces of the Services show their load balancing, but are not followed to the
+endpoints of the Services.
+
+Parameters:
+- cluster: Cluster of the pods, from cluster-list (optional, defaults to the default cluster)
+- source_namespace: Namespace of the source pod
+- source_pod: Name of the source pod
+- destination_namespace (optional): Namespace of the destination pod or Service, defaults to source_namespace
+- destination_pod: Name of the destination pod
+- destination_service: Name of the destination Service, traced to its cluster IP
+- destination: IP address of the destination, or cluster DNS name of a Service (e.g.,
+  "web.default.svc.cluster.local"), traced to its cluster IP; other DNS names are rejected, since the server
+  does not resolve them like the source pod does with the cluster DNS
+  (exactly one of destination_pod, destination_service and destination must be set)
+- protocol (optional): "tcp" (default, or the protocol of the first port of the Service), "udp", "sctp" or
+  "icmp" (echo request)
+- port (optional): Destination port, required for tcp, udp and sctp unless the Service has a port
+- ovn_namespace (optional): Kubernetes namespace of the OVN pods, detected if not set
+- mode (optional): Output verbosity mode - "detailed" (default), "summary", or "minimal"
+- max_lines (optional): Limit the number of output lines of each trace (default: 100)
+
+Example output:
+{
+  "source": {"kind": "pod", "namespace": "default", "name": "client", "node": "ovn-worker", "network": "default",
+    "logical_port": "default_client", "mac": "0a:58:0a:f4:01:05", "ip": "10.244.1.5"},
+  "destination": {"kind": "pod", "namespace": "default", "name": "server", "node": "ovn-worker2", ...,
+    "ip": "10.244.2.7"},
+  "protocol": "tcp",
+  "port": 8080,
+  "traces": [
+    {"node": "ovn-worker", "datapath": "ovn-worker", "microflow": "inport==\"default_client\" && ...",
+      "output": "ingress(dp=\"ovn-worker\", inport=\"default_client\")\n..."},
+    {"node": "ovn-worker2", "datapath": "transit_switch", "microflow": "inport==\"tstor-ovn-worker\" && ...",
+      "output": "ingress(dp=\"transit_switch\", inport=\"tstor-ovn-worker\")\n..."}
+  ]
+}`,
+		}, s.TracePod)
 }
 
 // Show displays a comprehensive overview of OVN configuration.
@@ -169,14 +392,14 @@ func (s *MCPServer) Show(ctx context.Context, req *mcp.CallToolRequest,
 
 	// Build command
 	cmd := getDBCommand(in.Database)
-	lines, err := s.runCommand(ctx, req, in.NamespacedNameParams, []string{cmd, "show"})
+	lines, err := s.runCommand(ctx, req, in.PodTargetParams, []string{cmd, "show"})
 	if err != nil {
-		return nil, result, fmt.Errorf("failed to retrieve OVN configuration from pod %s/%s: %w",
-			in.Namespace, in.Name, err)
+		return nil, result, fmt.Errorf("failed to retrieve OVN configuration from %s: %w",
+			in.Target(), err)
 	}
 
 	// Limit to MaxLines if specified
-	lines = limitLines(lines, in.MaxLines)
+	lines = limitLines(ctx, lines, in.MaxLines)
 
 	// Join all lines into a single output string
 	result.Output = strings.Join(lines, "\n")
@@ -226,14 +449,14 @@ func (s *MCPServer) Get(ctx context.Context, req *mcp.CallToolRequest,
 		cmdArgs = append(cmdArgs, "list", in.Table, in.Record)
 	}
 
-	lines, err := s.runCommand(ctx, req, in.NamespacedNameParams, cmdArgs)
+	lines, err := s.runCommand(ctx, req, in.PodTargetParams, cmdArgs)
 	if err != nil {
 		if in.Record != "" {
-			return nil, result, fmt.Errorf("failed to get record %s from table %s on pod %s/%s: %w",
-				in.Record, in.Table, in.Namespace, in.Name, err)
+			return nil, result, fmt.Errorf("failed to get record %s from table %s on %s: %w",
+				in.Record, in.Table, in.Target(), err)
 		}
-		return nil, result, fmt.Errorf("failed to list table %s from pod %s/%s: %w",
-			in.Table, in.Namespace, in.Name, err)
+		return nil, result, fmt.Errorf("failed to list table %s from %s: %w",
+			in.Table, in.Target(), err)
 	}
 
 	// Filter if pattern provided (for list mode)
@@ -244,19 +467,125 @@ func (s *MCPServer) Get(ctx context.Context, req *mcp.CallToolRequest,
 		}
 	}
 
-	// Limit to MaxLines if specified
-	lines = limitLines(lines, in.MaxLines)
+	lines, result.Result, err = pagination.Paginate(lines, in.Params,
+		in.Cluster, in.Namespace, in.Name, in.Node, in.Database, in.Table, in.Record, in.Columns, in.Filter)
+	if err != nil {
+		return nil, result, err
+	}
 
 	result.Output = strings.Join(lines, "\n")
 	return nil, result, nil
 }
 
-// ListLogicalFlows lists logical flows from the Southbound database.
+// Query selects the rows of an OVN table matching the conditions, with the OVSDB
+// JSON-RPC interface of the database.
+func (s *MCPServer) Query(ctx context.Context, req *mcp.CallToolRequest,
+	in ovntypes.QueryParams) (*mcp.CallToolResult, ovntypes.QueryResult, error) {
+	result := ovntypes.QueryResult{
+		Database: in.Database,
+		Table:    in.Table,
+	}
+	if err := validateDatabase(in.Database); err != nil {
+		return nil, result, err
+	}
+	if err := validateTableName(in.Table); err != nil {
+		return nil, result, err
+	}
+
+	client, err := s.ovsdbClient(ctx, req, in.PodTargetParams, in.Database)
+	if err != nil {
+		return nil, result, fmt.Errorf("failed to query table %s from %s: %w", in.Table, in.Target(), err)
+	}
+	schema, err := client.Schema(ctx)
+	if err != nil {
+		return nil, result, fmt.Errorf("failed to query table %s from %s: %w", in.Table, in.Target(), err)
+	}
+	rows, err := client.Select(ctx, schema, ovsdb.Query{Table: in.Table, Columns: in.Columns, Where: in.Where, KeepUUIDs: in.KeepUUIDs})
+	if err != nil {
+		return nil, result, fmt.Errorf("failed to query table %s from %s: %w", in.Table, in.Target(), err)
+	}
+
+	result.Rows, result.Result, err = pagination.Paginate(rows, in.Params,
+		in.Cluster, in.Namespace, in.Name, in.Node, in.Database, in.Table, in.Columns, in.Where, in.KeepUUIDs)
+	if err != nil {
+		return nil, result, err
+	}
+	return nil, result, nil
+}
+
+// TopologyGraph builds the graph of the logical topology of the Northbound
+// database, filters it and renders it in the format of the parameters.
+func (s *MCPServer) TopologyGraph(ctx context.Context, req *mcp.CallToolRequest,
+	in ovntypes.TopologyGraphParams) (*mcp.CallToolResult, ovntypes.TopologyGraphResult, error) {
+	result := ovntypes.TopologyGraphResult{Format: in.Format}
+	if result.Format == "" {
+		result.Format = ovntypes.GraphFormatJSON
+	}
+	switch result.Format {
+	case ovntypes.GraphFormatJSON, ovntypes.GraphFormatDOT, ovntypes.GraphFormatMermaid:
+	default:
+		return nil, result, fmt.Errorf("invalid format %q: must be 'json', 'dot' or 'mermaid'", in.Format)
+	}
+	depth := in.Depth
+	if depth == 0 {
+		depth = defaultGraphDepth
+	}
+	if depth < 0 {
+		return nil, result, fmt.Errorf("invalid depth %d: must be positive", in.Depth)
+	}
+
+	client, err := s.ovsdbClient(ctx, req, in.PodTargetParams, ovntypes.NorthboundDB)
+	if err != nil {
+		return nil, result, fmt.Errorf("failed to read the logical topology from %s: %w", in.Target(), err)
+	}
+	schema, err := client.Schema(ctx)
+	if err != nil {
+		return nil, result, fmt.Errorf("failed to read the logical topology from %s: %w", in.Target(), err)
+	}
+	tables := map[string][]ovsdb.Row{}
+	for _, table := range slices.Sorted(maps.Keys(graphTables)) {
+		tables[table], err = client.Select(ctx, schema, ovsdb.Query{Table: table, Columns: graphTables[table], KeepUUIDs: true})
+		if err != nil {
+			return nil, result, fmt.Errorf("failed to read the logical topology from %s: %w", in.Target(), err)
+		}
+	}
+
+	graph := buildGraph(tables)
+	if in.Network != "" {
+		graph.filterNetwork(in.Network)
+	}
+	if in.FilterNode != "" {
+		graph.filterNode(in.FilterNode)
+	}
+	if in.AroundPort != "" {
+		if err := graph.around(in.AroundPort, depth); err != nil {
+			return nil, result, err
+		}
+	}
+	if in.HidePorts {
+		graph.hidePorts(in.AroundPort)
+	}
+
+	result.NodeCount, result.EdgeCount = len(graph.nodes), len(graph.edges)
+	switch result.Format {
+	case ovntypes.GraphFormatDOT:
+		result.Graph = graph.dot()
+	case ovntypes.GraphFormatMermaid:
+		result.Graph = graph.mermaid()
+	default:
+		result.Nodes, result.Edges = graph.nodes, graph.edges
+	}
+	return nil, result, nil
+}
+
+// ListLogicalFlows lists the logical flows of the Southbound database, filtered by
+// the parameters, with the number of flows of each stage.
 func (s *MCPServer) ListLogicalFlows(ctx context.Context, req *mcp.CallToolRequest,
 	in ovntypes.LogicalFlowListParams) (*mcp.CallToolResult, ovntypes.LogicalFlowListResult, error) {
 	result := ovntypes.LogicalFlowListResult{
 		Datapath: in.Datapath,
-		Flows:    []string{},
+		Flows:    []ovntypes.LogicalFlow{},
+		Stages:   []ovntypes.StageCount{},
 	}
 
 	// Validate datapath if provided
@@ -265,29 +594,27 @@ func (s *MCPServer) ListLogicalFlows(ctx context.Context, req *mcp.CallToolReque
 			return nil, result, err
 		}
 	}
-
-	// Build command
-	cmdArgs := []string{"ovn-sbctl", "lflow-list"}
-	if in.Datapath != "" {
-		cmdArgs = append(cmdArgs, in.Datapath)
+	if in.MinPriority < 0 {
+		return nil, result, fmt.Errorf("invalid min_priority %d: must be positive", in.MinPriority)
 	}
 
-	lines, err := s.runCommand(ctx, req, in.NamespacedNameParams, cmdArgs)
+	flows, err := s.readLogicalFlows(ctx, req, in.PodTargetParams, in.Datapath)
 	if err != nil {
-		return nil, result, fmt.Errorf("failed to list logical flows from pod %s/%s: %w",
-			in.Namespace, in.Name, err)
+		return nil, result, fmt.Errorf("failed to list logical flows from %s: %w",
+			in.Target(), err)
 	}
 
-	// Filter flows if pattern provided
-	lines, err = filterLines(lines, in.Filter)
+	flows, err = filterLogicalFlows(flows, in)
 	if err != nil {
-		return nil, result, fmt.Errorf("invalid filter pattern: %w", err)
+		return nil, result, err
 	}
+	result.Stages = countStages(flows)
 
-	// Limit to MaxLines if specified
-	lines = limitLines(lines, in.MaxLines)
-
-	result.Flows = lines
+	result.Flows, result.Result, err = pagination.Paginate(flows, in.Params, in.Cluster, in.Namespace, in.Name, in.Node,
+		in.Datapath, in.Stage, in.MinPriority, in.MatchContains, in.Reference, in.Filter)
+	if err != nil {
+		return nil, result, err
+	}
 	return nil, result, nil
 }
 
@@ -307,25 +634,10 @@ func (s *MCPServer) Trace(ctx context.Context, req *mcp.CallToolRequest,
 		return nil, result, err
 	}
 
-	// Build command: ovn-trace <datapath> '<microflow>'
-	cmdArgs := []string{"ovn-trace"}
-
-	// Add output format flag based on mode (default to detailed)
-	switch in.Mode {
-	case ovntypes.TraceModeSummary:
-		cmdArgs = append(cmdArgs, "--summary")
-	case ovntypes.TraceModeMinimal:
-		cmdArgs = append(cmdArgs, "--minimal")
-	case ovntypes.TraceModeDetailed, "":
-		cmdArgs = append(cmdArgs, "--detailed")
-	}
-
-	cmdArgs = append(cmdArgs, in.Datapath, in.Microflow)
-
-	lines, err := s.runCommand(ctx, req, in.NamespacedNameParams, cmdArgs)
+	lines, err := s.runCommand(ctx, req, in.PodTargetParams, traceCommand(in.Mode, in.Datapath, in.Microflow))
 	if err != nil {
-		return nil, result, fmt.Errorf("failed to trace packet on pod %s/%s: %w",
-			in.Namespace, in.Name, err)
+		return nil, result, fmt.Errorf("failed to trace packet on %s: %w",
+			in.Target(), err)
 	}
 
 	// Filter lines if pattern provided
@@ -335,7 +647,7 @@ func (s *MCPServer) Trace(ctx context.Context, req *mcp.CallToolRequest,
 	}
 
 	// Limit to MaxLines if specified
-	lines = limitLines(lines, in.MaxLines)
+	lines = limitLines(ctx, lines, in.MaxLines)
 
 	result.Output = strings.Join(lines, "\n")
 	return nil, result, nil
@@ -0,0 +1,479 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"net"
+	"slices"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	k8stypes "github.com/ovn-kubernetes/ovn-kubernetes-mcp/pkg/kubernetes/types"
+	ovntypes "github.com/ovn-kubernetes/ovn-kubernetes-mcp/pkg/ovn/types"
+	"github.com/ovn-kubernetes/ovn-kubernetes-mcp/pkg/ovsdb"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// podNetworksAnnotation is the annotation of the pods with the addresses of their
+// networks.
+const podNetworksAnnotation = "k8s.ovn.org/pod-networks"
+
+// primaryRole is the role of the primary network of a pod in the pod-networks
+// annotation.
+const primaryRole = "primary"
+
+// Kinds of the endpoints of a pod trace.
+const (
+	endpointPod     = "pod"
+	endpointService = "service"
+	endpointIP      = "ip"
+)
+
+// traceSourcePort is the source port of the TCP, UDP and SCTP packets of the pod
+// traces.
+const traceSourcePort = 52888
+
+// traceProtocols are the protocols of the pod traces.
+var traceProtocols = []string{"tcp", "udp", "sctp", "icmp"}
+
+// podNetwork is the network of a pod in the pod-networks annotation.
+type podNetwork struct {
+	IPAddresses []string `json:"ip_addresses"`
+	MACAddress  string   `json:"mac_address"`
+	Role        string   `json:"role,omitempty"`
+}
+
+// primaryNetwork returns the name and the addresses of the primary network of a
+// pod-networks annotation: the network with the primary role, or the default
+// network.
+func primaryNetwork(annotation string) (string, podNetwork, error) {
+	networks := map[string]podNetwork{}
+	if err := json.Unmarshal([]byte(annotation), &networks); err != nil {
+		return "", podNetwork{}, fmt.Errorf("invalid %s annotation: %w", podNetworksAnnotation, err)
+	}
+	for _, name := range slices.Sorted(maps.Keys(networks)) {
+		if networks[name].Role == primaryRole {
+			return name, networks[name], nil
+		}
+	}
+	if network, found := networks[defaultNetwork]; found {
+		return defaultNetwork, network, nil
+	}
+	return "", podNetwork{}, fmt.Errorf("no primary network in %s annotation", podNetworksAnnotation)
+}
+
+// logicalPortName returns the name of the logical switch port of a pod on a
+// network, prefixed by the network attachment of the user-defined networks whose
+// dashes and slashes are replaced by dots.
+func logicalPortName(network, namespace, pod string) string {
+	name := namespace + "_" + pod
+	if network == defaultNetwork {
+		return name
+	}
+	return strings.NewReplacer("-", ".", "/", ".").Replace(network) + "_" + name
+}
+
+// serviceHost returns the name and namespace of the Service of a cluster DNS name,
+// like "web.default.svc" or "web.default.svc.cluster.local".
+func serviceHost(host string) (string, string, bool) {
+	labels := strings.Split(strings.TrimSuffix(host, "."), ".")
+	if len(labels) < 3 || labels[2] != "svc" {
+		return "", "", false
+	}
+	return labels[0], labels[1], true
+}
+
+// selectAddresses returns the first source and destination addresses of the same
+// family.
+func selectAddresses(sources, destinations []net.IP) (net.IP, net.IP, error) {
+	for _, source := range sources {
+		for _, destination := range destinations {
+			if (source.To4() == nil) == (destination.To4() == nil) {
+				return source, destination, nil
+			}
+		}
+	}
+	return nil, nil, fmt.Errorf("no destination address %v of the family of the source addresses %v", destinations, sources)
+}
+
+// traceMicroflow is the packet of a pod trace entering a logical port.
+type traceMicroflow struct {
+	inport   string
+	ethSrc   string
+	ethDst   string
+	src      net.IP
+	dst      net.IP
+	ttl      int
+	protocol string
+	port     int
+}
+
+// String returns the microflow of ovn-trace: an ICMP echo request, or a packet to
+// the port from traceSourcePort.
+func (m traceMicroflow) String() string {
+	ip, icmp := "ip4", "icmp4 && icmp4.type==8"
+	if m.src.To4() == nil {
+		ip, icmp = "ip6", "icmp6 && icmp6.type==128"
+	}
+	match := []string{
+		fmt.Sprintf("inport==%q", m.inport),
+		"eth.src==" + m.ethSrc,
+		"eth.dst==" + m.ethDst,
+		ip + ".src==" + m.src.String(),
+		ip + ".dst==" + m.dst.String(),
+		fmt.Sprintf("ip.ttl==%d", m.ttl),
+	}
+	if m.protocol == "icmp" {
+		match = append(match, icmp)
+	} else {
+		match = append(match, m.protocol, fmt.Sprintf("%s.src==%d", m.protocol, traceSourcePort),
+			fmt.Sprintf("%s.dst==%d", m.protocol, m.port))
+	}
+	return strings.Join(match, " && ")
+}
+
+// podEndpoint returns the endpoint of a pod on its primary network, and its
+// addresses.
+func (s *MCPServer) podEndpoint(ctx context.Context, namespace, name string) (ovntypes.TraceEndpoint, []net.IP, error) {
+	endpoint := ovntypes.TraceEndpoint{Kind: endpointPod, Namespace: namespace, Name: name}
+	pod, err := s.resources.Get(ctx, "", "v1", "Pod", namespace, name)
+	if err != nil {
+		return endpoint, nil, fmt.Errorf("failed to get pod %s/%s: %w", namespace, name, err)
+	}
+	endpoint.Node, _, _ = unstructured.NestedString(pod.Object, "spec", "nodeName")
+	if endpoint.Node == "" {
+		return endpoint, nil, fmt.Errorf("pod %s/%s is not scheduled", namespace, name)
+	}
+	annotation, found := pod.GetAnnotations()[podNetworksAnnotation]
+	if !found {
+		return endpoint, nil, fmt.Errorf("pod %s/%s has no %s annotation, it is not on an OVN-Kubernetes network",
+			namespace, name, podNetworksAnnotation)
+	}
+	network, addresses, err := primaryNetwork(annotation)
+	if err != nil {
+		return endpoint, nil, fmt.Errorf("pod %s/%s: %w", namespace, name, err)
+	}
+	endpoint.Network = network
+	endpoint.LogicalPort = logicalPortName(network, namespace, name)
+	endpoint.MAC = addresses.MACAddress
+	var ips []net.IP
+	for _, address := range addresses.IPAddresses {
+		ip, _, err := net.ParseCIDR(address)
+		if err != nil {
+			return endpoint, nil, fmt.Errorf("invalid address %s of pod %s/%s: %w", address, namespace, name, err)
+		}
+		ips = append(ips, ip)
+	}
+	return endpoint, ips, nil
+}
+
+// serviceEndpoint returns the endpoint of a Service, its cluster IPs and the first
+// of its ports and protocols.
+func (s *MCPServer) serviceEndpoint(ctx context.Context, namespace, name string) (ovntypes.TraceEndpoint, []net.IP,
+	int, string, error) {
+	endpoint := ovntypes.TraceEndpoint{Kind: endpointService, Namespace: namespace, Name: name}
+	service, err := s.resources.Get(ctx, "", "v1", "Service", namespace, name)
+	if err != nil {
+		return endpoint, nil, 0, "", fmt.Errorf("failed to get service %s/%s: %w", namespace, name, err)
+	}
+	clusterIPs, _, _ := unstructured.NestedStringSlice(service.Object, "spec", "clusterIPs")
+	if len(clusterIPs) == 0 {
+		clusterIP, _, _ := unstructured.NestedString(service.Object, "spec", "clusterIP")
+		clusterIPs = []string{clusterIP}
+	}
+	var ips []net.IP
+	for _, clusterIP := range clusterIPs {
+		if ip := net.ParseIP(clusterIP); ip != nil {
+			ips = append(ips, ip)
+		}
+	}
+	if len(ips) == 0 {
+		return endpoint, nil, 0, "", fmt.Errorf("service %s/%s has no cluster IP", namespace, name)
+	}
+	ports, _, _ := unstructured.NestedSlice(service.Object, "spec", "ports")
+	if len(ports) == 0 {
+		return endpoint, ips, 0, "", nil
+	}
+	port, _ := ports[0].(map[string]any)
+	number, _, _ := unstructured.NestedInt64(port, "port")
+	protocol, _, _ := unstructured.NestedString(port, "protocol")
+	return endpoint, ips, int(number), strings.ToLower(protocol), nil
+}
+
+// northbound is the Northbound database of the zone of a node.
+type northbound struct {
+	client *ovsdb.Client
+	schema *ovsdb.Schema
+}
+
+// northbound returns the Northbound database of the target.
+func (s *MCPServer) northbound(ctx context.Context, req *mcp.CallToolRequest,
+	target k8stypes.PodTargetParams) (*northbound, error) {
+	client, err := s.ovsdbClient(ctx, req, target, ovntypes.NorthboundDB)
+	if err != nil {
+		return nil, err
+	}
+	schema, err := client.Schema(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &northbound{client: client, schema: schema}, nil
+}
+
+// row returns the row of a table by name.
+func (n *northbound) row(ctx context.Context, table, name string, columns ...string) (ovsdb.Row, error) {
+	rows, err := n.client.Select(ctx, n.schema, ovsdb.Query{
+		Table:     table,
+		Columns:   append([]string{"name"}, columns...),
+		Where:     []ovsdb.Condition{{Column: "name", Function: "==", Value: name}},
+		KeepUUIDs: true,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(rows) == 0 {
+		return nil, fmt.Errorf("%s %s not found", table, name)
+	}
+	return rows[0], nil
+}
+
+// switchOf returns the logical switch of a logical switch port, with the names of
+// its ports.
+func (n *northbound) switchOf(ctx context.Context, port ovsdb.Row) (ovsdb.Row, error) {
+	rows, err := n.client.Select(ctx, n.schema, ovsdb.Query{
+		Table:   "Logical_Switch",
+		Columns: []string{"name", "ports"},
+		Where:   []ovsdb.Condition{{Column: "ports", Function: "includes", Value: port.String("_uuid")}},
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(rows) == 0 {
+		return nil, fmt.Errorf("logical switch of port %s not found", port.String("name"))
+	}
+	return rows[0], nil
+}
+
+// routerMAC returns the MAC address of the router port connected to a logical
+// switch, the next hop of the packets leaving the switch.
+func (n *northbound) routerMAC(ctx context.Context, logicalSwitch ovsdb.Row) (string, error) {
+	rows, err := n.client.Select(ctx, n.schema, ovsdb.Query{
+		Table:   "Logical_Switch_Port",
+		Columns: []string{"name", "options"},
+		Where:   []ovsdb.Condition{{Column: "type", Function: "==", Value: "router"}},
+	})
+	if err != nil {
+		return "", err
+	}
+	ports := logicalSwitch.Strings("ports")
+	for _, row := range rows {
+		if !slices.Contains(ports, row.String("name")) {
+			continue
+		}
+		routerPort, err := n.row(ctx, "Logical_Router_Port", row.Map("options")["router-port"], "mac")
+		if err != nil {
+			return "", err
+		}
+		return routerPort.String("mac"), nil
+	}
+	return "", fmt.Errorf("no router port on logical switch %s", logicalSwitch.String("name"))
+}
+
+// sameZone returns whether the commands of two targets run in the same
+// Northbound database, as they do without interconnect.
+func (s *MCPServer) sameZone(ctx context.Context, req *mcp.CallToolRequest, a, b k8stypes.PodTargetParams) (bool, error) {
+	podA, err := s.pods.Resolve(ctx, req, a, "ovn-nbctl")
+	if err != nil {
+		return false, err
+	}
+	podB, err := s.pods.Resolve(ctx, req, b, "ovn-nbctl")
+	if err != nil {
+		return false, err
+	}
+	return podA.Namespace == podB.Namespace && podA.Name == podB.Name, nil
+}
+
+// zoneTrace runs ovn-trace on the datapath of the zone of the target.
+func (s *MCPServer) zoneTrace(ctx context.Context, req *mcp.CallToolRequest, target k8stypes.PodTargetParams,
+	in ovntypes.PodTraceParams, datapath string, flow traceMicroflow) (ovntypes.ZoneTrace, error) {
+	trace := ovntypes.ZoneTrace{Node: target.Node, Datapath: datapath, Microflow: flow.String()}
+	if err := validateDatapath(trace.Datapath); err != nil {
+		return trace, err
+	}
+	if err := validateMicroflow(trace.Microflow); err != nil {
+		return trace, err
+	}
+	lines, err := s.runCommand(ctx, req, target, traceCommand(in.Mode, trace.Datapath, trace.Microflow))
+	if err != nil {
+		return trace, fmt.Errorf("failed to trace packet on %s: %w", target.Target(), err)
+	}
+	trace.Output = strings.Join(limitLines(ctx, lines, in.MaxLines), "\n")
+	return trace, nil
+}
+
+// remoteMicroflow returns the datapath and the microflow of the packet of flow
+// entering the zone of the destination node from the transit switch, after the
+// cluster router of the zone of the source node. The switches and ports of the
+// transit switch have the prefix of the network.
+func remoteMicroflow(ctx context.Context, nb *northbound, prefix, sourceNode, destinationNode string,
+	flow traceMicroflow) (string, traceMicroflow, error) {
+	remotePort, err := nb.row(ctx, "Logical_Switch_Port", prefix+"tstor-"+sourceNode, "addresses")
+	if err != nil {
+		return "", flow, fmt.Errorf("transit switch port of node %s: %w", sourceNode, err)
+	}
+	transitSwitch, err := nb.switchOf(ctx, remotePort)
+	if err != nil {
+		return "", flow, err
+	}
+	routerPort, err := nb.row(ctx, "Logical_Router_Port", prefix+"rtots-"+destinationNode, "mac")
+	if err != nil {
+		return "", flow, fmt.Errorf("transit router port of node %s: %w", destinationNode, err)
+	}
+	addresses := strings.Fields(strings.Join(remotePort.Strings("addresses"), " "))
+	if len(addresses) == 0 {
+		return "", flow, fmt.Errorf("transit switch port %s has no address", remotePort.String("name"))
+	}
+	flow.inport = remotePort.String("name")
+	flow.ethSrc = addresses[0]
+	flow.ethDst = routerPort.String("mac")
+	flow.ttl--
+	return transitSwitch.String("name"), flow, nil
+}
+
+// TracePod traces a packet from a pod to a pod, a Service, an IP address or the DNS
+// name of a Service, in the zone of the source node, and in the zone of the destination pod
+// with interconnect.
+func (s *MCPServer) TracePod(ctx context.Context, req *mcp.CallToolRequest,
+	in ovntypes.PodTraceParams) (*mcp.CallToolResult, ovntypes.PodTraceResult, error) {
+	result := ovntypes.PodTraceResult{Protocol: in.Protocol, Port: in.Port, Traces: []ovntypes.ZoneTrace{}}
+	if in.SourceNamespace == "" || in.SourcePod == "" {
+		return nil, result, fmt.Errorf("source_namespace and source_pod are required")
+	}
+	destinations := 0
+	for _, destination := range []string{in.DestinationPod, in.DestinationService, in.Destination} {
+		if destination != "" {
+			destinations++
+		}
+	}
+	if destinations != 1 {
+		return nil, result, fmt.Errorf("exactly one of destination_pod, destination_service and destination must be set")
+	}
+	destinationNamespace := in.DestinationNamespace
+	if destinationNamespace == "" {
+		destinationNamespace = in.SourceNamespace
+	}
+
+	src, srcIPs, err := s.podEndpoint(ctx, in.SourceNamespace, in.SourcePod)
+	if err != nil {
+		return nil, result, err
+	}
+	var dst ovntypes.TraceEndpoint
+	var dstIPs []net.IP
+	servicePort, serviceProtocol := 0, ""
+	service, serviceNamespace, isService := in.DestinationService, destinationNamespace, in.DestinationService != ""
+	if in.Destination != "" && !isService {
+		service, serviceNamespace, isService = serviceHost(in.Destination)
+	}
+	switch {
+	case in.DestinationPod != "":
+		dst, dstIPs, err = s.podEndpoint(ctx, destinationNamespace, in.DestinationPod)
+	case isService:
+		dst, dstIPs, servicePort, serviceProtocol, err = s.serviceEndpoint(ctx, serviceNamespace, service)
+	default:
+		// The other DNS names are not resolved: the server does not use the cluster
+		// DNS and the search domains of the source pod, and could resolve them to
+		// other addresses than the pod does.
+		dst = ovntypes.TraceEndpoint{Kind: endpointIP}
+		if ip := net.ParseIP(in.Destination); ip != nil {
+			dstIPs = []net.IP{ip}
+		} else {
+			err = fmt.Errorf("destination %s is neither an IP address nor the DNS name of a Service "+
+				"(<service>.<namespace>.svc): the server cannot resolve other DNS names like the source pod does with the "+
+				"cluster DNS, resolve it from the pod and trace its IP address", in.Destination)
+		}
+	}
+	if err != nil {
+		return nil, result, err
+	}
+
+	if result.Protocol == "" {
+		result.Protocol = serviceProtocol
+	}
+	if result.Protocol == "" {
+		result.Protocol = "tcp"
+	}
+	if !slices.Contains(traceProtocols, result.Protocol) {
+		return nil, result, fmt.Errorf("invalid protocol %q: must be one of %s", in.Protocol, strings.Join(traceProtocols, ", "))
+	}
+	if result.Port == 0 && result.Protocol != "icmp" {
+		result.Port = servicePort
+	}
+	if result.Protocol == "icmp" {
+		result.Port = 0
+	} else if result.Port < 1 || result.Port > 65535 {
+		return nil, result, fmt.Errorf("invalid port %d: must be between 1 and 65535 for protocol %s", result.Port, result.Protocol)
+	}
+	srcIP, dstIP, err := selectAddresses(srcIPs, dstIPs)
+	if err != nil {
+		return nil, result, err
+	}
+	src.IP, dst.IP = srcIP.String(), dstIP.String()
+	result.Source, result.Destination = src, dst
+
+	// The packet leaves the pod to the pod on the same switch, and to the router
+	// of the switch otherwise.
+	target := k8stypes.PodTargetParams{Namespace: in.OVNNamespace, Node: src.Node}
+	nb, err := s.northbound(ctx, req, target)
+	if err != nil {
+		return nil, result, fmt.Errorf("failed to read the logical port of pod %s/%s from %s: %w", src.Namespace, src.Name,
+			target.Target(), err)
+	}
+	srcPort, err := nb.row(ctx, "Logical_Switch_Port", src.LogicalPort)
+	if err != nil {
+		return nil, result, fmt.Errorf("failed to read the logical port of pod %s/%s from %s: %w", src.Namespace, src.Name,
+			target.Target(), err)
+	}
+	srcSwitch, err := nb.switchOf(ctx, srcPort)
+	if err != nil {
+		return nil, result, err
+	}
+	flow := traceMicroflow{inport: src.LogicalPort, ethSrc: src.MAC, src: srcIP, dst: dstIP, ttl: 64,
+		protocol: result.Protocol, port: result.Port}
+	if dst.Kind == endpointPod && slices.Contains(srcSwitch.Strings("ports"), dst.LogicalPort) {
+		flow.ethDst = dst.MAC
+	} else if flow.ethDst, err = nb.routerMAC(ctx, srcSwitch); err != nil {
+		return nil, result, err
+	}
+	trace, err := s.zoneTrace(ctx, req, target, in, srcSwitch.String("name"), flow)
+	if err != nil {
+		return nil, result, err
+	}
+	result.Traces = append(result.Traces, trace)
+
+	// With interconnect, the packet to a pod of another zone leaves the zone by the
+	// transit switch, and its trace continues in the zone of the destination pod.
+	if dst.Kind != endpointPod || dst.Node == src.Node {
+		return nil, result, nil
+	}
+	dstTarget := k8stypes.PodTargetParams{Namespace: in.OVNNamespace, Node: dst.Node}
+	sameZone, err := s.sameZone(ctx, req, target, dstTarget)
+	if err != nil || sameZone {
+		return nil, result, err
+	}
+	dstNB, err := s.northbound(ctx, req, dstTarget)
+	if err != nil {
+		return nil, result, fmt.Errorf("failed to read the transit switch from %s: %w", dstTarget.Target(), err)
+	}
+	prefix := strings.TrimSuffix(srcSwitch.String("name"), src.Node)
+	datapath, flow, err := remoteMicroflow(ctx, dstNB, prefix, src.Node, dst.Node, flow)
+	if err != nil {
+		return nil, result, fmt.Errorf("failed to follow the trace to %s: %w", dstTarget.Target(), err)
+	}
+	if trace, err = s.zoneTrace(ctx, req, dstTarget, in, datapath, flow); err != nil {
+		return nil, result, err
+	}
+	result.Traces = append(result.Traces, trace)
+	return nil, result, nil
+}
@@ -0,0 +1,405 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/ovn-kubernetes/ovn-kubernetes-mcp/pkg/executor"
+	k8stypes "github.com/ovn-kubernetes/ovn-kubernetes-mcp/pkg/kubernetes/types"
+	ovntypes "github.com/ovn-kubernetes/ovn-kubernetes-mcp/pkg/ovn/types"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestPrimaryNetwork(t *testing.T) {
+	tests := []struct {
+		name            string
+		annotation      string
+		wantNetwork     string
+		wantMAC         string
+		wantLogicalPort string
+		wantErr         string
+	}{
+		{
+			name:            "default network",
+			annotation:      `{"default":{"ip_addresses":["10.244.1.5/24","fd00:10:244:2::5/64"],"mac_address":"0a:58:0a:f4:01:05","role":"primary"}}`,
+			wantNetwork:     "default",
+			wantMAC:         "0a:58:0a:f4:01:05",
+			wantLogicalPort: "default_client",
+		},
+		{
+			name:            "default network without role",
+			annotation:      `{"default":{"ip_addresses":["10.244.1.5/24"],"mac_address":"0a:58:0a:f4:01:05"}}`,
+			wantNetwork:     "default",
+			wantMAC:         "0a:58:0a:f4:01:05",
+			wantLogicalPort: "default_client",
+		},
+		{
+			name: "primary user-defined network",
+			annotation: `{"default":{"ip_addresses":["10.244.1.5/24"],"mac_address":"0a:58:0a:f4:01:05","role":"infrastructure-locked"},` +
+				`"tenant-blue/blue-net":{"ip_addresses":["10.200.1.5/24"],"mac_address":"0a:58:0a:c8:01:05","role":"primary"}}`,
+			wantNetwork:     "tenant-blue/blue-net",
+			wantMAC:         "0a:58:0a:c8:01:05",
+			wantLogicalPort: "tenant.blue.blue.net_default_client",
+		},
+		{
+			name:       "no primary network",
+			annotation: `{"tenant-blue/blue-net":{"ip_addresses":["10.200.1.5/24"],"role":"secondary"}}`,
+			wantErr:    "no primary network in k8s.ovn.org/pod-networks annotation",
+		},
+		{
+			name:       "invalid annotation",
+			annotation: `{"default":`,
+			wantErr:    "invalid k8s.ovn.org/pod-networks annotation: unexpected end of JSON input",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			network, addresses, err := primaryNetwork(test.annotation)
+			if test.wantErr != "" {
+				if err == nil || err.Error() != test.wantErr {
+					t.Fatalf("Expected error %q, got %v", test.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if network != test.wantNetwork || addresses.MACAddress != test.wantMAC {
+				t.Fatalf("Expected network %s with MAC %s, got %s with %+v", test.wantNetwork, test.wantMAC, network, addresses)
+			}
+			if port := logicalPortName(network, "default", "client"); port != test.wantLogicalPort {
+				t.Fatalf("Expected logical port %s, got %s", test.wantLogicalPort, port)
+			}
+		})
+	}
+}
+
+func TestServiceHost(t *testing.T) {
+	tests := []struct {
+		host          string
+		wantName      string
+		wantNamespace string
+		wantService   bool
+	}{
+		{host: "web.default.svc", wantName: "web", wantNamespace: "default", wantService: true},
+		{host: "web.default.svc.cluster.local.", wantName: "web", wantNamespace: "default", wantService: true},
+		{host: "web.default"},
+		{host: "www.example.com"},
+	}
+	for _, test := range tests {
+		t.Run(test.host, func(t *testing.T) {
+			name, namespace, isService := serviceHost(test.host)
+			if name != test.wantName || namespace != test.wantNamespace || isService != test.wantService {
+				t.Fatalf("Expected %s/%s (%v), got %s/%s (%v)", test.wantNamespace, test.wantName, test.wantService,
+					namespace, name, isService)
+			}
+		})
+	}
+}
+
+func TestTraceMicroflow(t *testing.T) {
+	tests := []struct {
+		name          string
+		sources       []net.IP
+		destinations  []net.IP
+		protocol      string
+		port          int
+		wantMicroflow string
+		wantErr       string
+	}{
+		{
+			name:         "tcp",
+			sources:      []net.IP{net.ParseIP("10.244.1.5")},
+			destinations: []net.IP{net.ParseIP("10.244.2.7")},
+			protocol:     "tcp",
+			port:         8080,
+			wantMicroflow: `inport=="default_client" && eth.src==0a:58:0a:f4:01:05 && eth.dst==0a:58:0a:f4:01:01 && ` +
+				`ip4.src==10.244.1.5 && ip4.dst==10.244.2.7 && ip.ttl==64 && tcp && tcp.src==52888 && tcp.dst==8080`,
+		},
+		{
+			name:         "icmp of the family of the destination",
+			sources:      []net.IP{net.ParseIP("10.244.1.5"), net.ParseIP("fd00:10:244:2::5")},
+			destinations: []net.IP{net.ParseIP("fd00:10:96::10")},
+			protocol:     "icmp",
+			wantMicroflow: `inport=="default_client" && eth.src==0a:58:0a:f4:01:05 && eth.dst==0a:58:0a:f4:01:01 && ` +
+				`ip6.src==fd00:10:244:2::5 && ip6.dst==fd00:10:96::10 && ip.ttl==64 && icmp6 && icmp6.type==128`,
+		},
+		{
+			name:         "no address of the same family",
+			sources:      []net.IP{net.ParseIP("10.244.1.5")},
+			destinations: []net.IP{net.ParseIP("fd00:10:96::10")},
+			wantErr:      "no destination address [fd00:10:96::10] of the family of the source addresses [10.244.1.5]",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			src, dst, err := selectAddresses(test.sources, test.destinations)
+			if test.wantErr != "" {
+				if err == nil || err.Error() != test.wantErr {
+					t.Fatalf("Expected error %q, got %v", test.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			flow := traceMicroflow{inport: "default_client", ethSrc: "0a:58:0a:f4:01:05", ethDst: "0a:58:0a:f4:01:01",
+				src: src, dst: dst, ttl: 64, protocol: test.protocol, port: test.port}
+			if microflow := flow.String(); microflow != test.wantMicroflow {
+				t.Fatalf("Expected microflow %s, got %s", test.wantMicroflow, microflow)
+			}
+			if err := validateMicroflow(flow.String()); err != nil {
+				t.Fatalf("Unexpected invalid microflow: %v", err)
+			}
+		})
+	}
+}
+
+// testTraceSchema is the subset of the OVN Northbound schema read by the pod
+// traces.
+const testTraceSchema = `{
+  "name": "OVN_Northbound",
+  "version": "7.3.0",
+  "tables": {
+    "Logical_Switch": {"columns": {
+      "name": {"type": "string"},
+      "ports": {"type": {"key": {"type": "uuid", "refTable": "Logical_Switch_Port"}, "min": 0, "max": "unlimited"}}
+    }},
+    "Logical_Switch_Port": {"columns": {
+      "name": {"type": "string"},
+      "type": {"type": "string"},
+      "addresses": {"type": {"key": "string", "min": 0, "max": "unlimited"}},
+      "options": {"type": {"key": "string", "value": "string", "min": 0, "max": "unlimited"}}
+    }, "indexes": [["name"]]},
+    "Logical_Router_Port": {"columns": {
+      "name": {"type": "string"},
+      "mac": {"type": "string"}
+    }, "indexes": [["name"]]}
+  }
+}`
+
+// fakeNorthbound answers the get-schema and transact commands of ovsdb-client with
+// the rows of its tables, in the wire format. The select operations support the
+// == and includes conditions, and return all the columns.
+type fakeNorthbound struct {
+	tables map[string][]string
+}
+
+func (f *fakeNorthbound) run(ctx context.Context, args ...string) (string, error) {
+	switch args[0] {
+	case "get-schema":
+		return testTraceSchema, nil
+	case "transact":
+		var transaction []json.RawMessage
+		if err := json.Unmarshal([]byte(args[2]), &transaction); err != nil {
+			return "", err
+		}
+		var results []string
+		for _, operation := range transaction[1:] {
+			var op struct {
+				Table string  `json:"table"`
+				Where [][]any `json:"where"`
+			}
+			if err := json.Unmarshal(operation, &op); err != nil {
+				return "", err
+			}
+			var rows []string
+			for _, data := range f.tables[op.Table] {
+				var row map[string]any
+				if err := json.Unmarshal([]byte(data), &row); err != nil {
+					return "", err
+				}
+				if matchesAll(row, op.Where) {
+					rows = append(rows, data)
+				}
+			}
+			results = append(results, `{"rows":[`+strings.Join(rows, ",")+`]}`)
+		}
+		return "[" + strings.Join(results, ",") + "]", nil
+	}
+	return "", errors.New("unknown command")
+}
+
+// matchesAll returns whether a row in the wire format matches the == and includes
+// conditions.
+func matchesAll(row map[string]any, where [][]any) bool {
+	for _, condition := range where {
+		column, function, value := condition[0].(string), condition[1].(string), condition[2]
+		switch function {
+		case "==":
+			if !reflect.DeepEqual(row[column], value) {
+				return false
+			}
+		case "includes":
+			set, _ := row[column].([]any)
+			for _, element := range value.([]any)[1].([]any) {
+				if len(set) != 2 || !slices.ContainsFunc(set[1].([]any), func(e any) bool { return reflect.DeepEqual(e, element) }) {
+					return false
+				}
+			}
+		}
+	}
+	return true
+}
+
+// fakeZones resolves the OVN commands of a node to the ovnkube-node pod of its zone,
+// and runs ovsdb-client on the Northbound database of the zone and ovn-trace.
+type fakeZones struct {
+	executor.Executor
+	// zones are the pods of the zones by node.
+	zones     map[string]string
+	databases map[string]*fakeNorthbound
+	traces    []string
+}
+
+func (f *fakeZones) Resolve(ctx context.Context, req *mcp.CallToolRequest, target k8stypes.PodTargetParams,
+	command string) (k8stypes.ExecPodParams, error) {
+	pod, found := f.zones[target.Node]
+	if !found {
+		return k8stypes.ExecPodParams{}, fmt.Errorf("no ovnkube-node pod on node %s", target.Node)
+	}
+	return k8stypes.ExecPodParams{NamespacedNameParams: k8stypes.NamespacedNameParams{Namespace: "ovn-kubernetes", Name: pod},
+		Container: "nb-ovsdb"}, nil
+}
+
+func (f *fakeZones) ExecPod(ctx context.Context, req *mcp.CallToolRequest,
+	in k8stypes.ExecPodParams) (*mcp.CallToolResult, k8stypes.ExecPodResult, error) {
+	switch in.Command[0] {
+	case "ovsdb-client":
+		output, err := f.databases[in.Name].run(ctx, in.Command[1:]...)
+		return nil, k8stypes.ExecPodResult{Stdout: output}, err
+	case "ovn-trace":
+		f.traces = append(f.traces, in.Name)
+		return nil, k8stypes.ExecPodResult{Stdout: "trace in " + in.Name}, nil
+	}
+	return nil, k8stypes.ExecPodResult{}, fmt.Errorf("unexpected command %v", in.Command)
+}
+
+// fakePods returns the pods by name.
+type fakePods map[string]*unstructured.Unstructured
+
+func (f fakePods) Get(ctx context.Context, group, version, kind, namespace, name string) (*unstructured.Unstructured, error) {
+	if pod, found := f[name]; found && kind == "Pod" {
+		return pod, nil
+	}
+	return nil, fmt.Errorf("%s %s/%s not found", kind, namespace, name)
+}
+
+func testPod(name, node, ip, mac string) *unstructured.Unstructured {
+	pod := &unstructured.Unstructured{Object: map[string]any{
+		"metadata": map[string]any{"name": name, "namespace": "default"},
+		"spec":     map[string]any{"nodeName": node},
+	}}
+	pod.SetAnnotations(map[string]string{podNetworksAnnotation: fmt.Sprintf(
+		`{"default":{"ip_addresses":["%s/24"],"mac_address":"%s","role":"primary"}}`, ip, mac)})
+	return pod
+}
+
+func TestTracePod(t *testing.T) {
+	// The client pod is on ovn-worker, and the server pod on ovn-worker2.
+	pods := fakePods{
+		"client": testPod("client", "ovn-worker", "10.244.1.5", "0a:58:0a:f4:01:05"),
+		"server": testPod("server", "ovn-worker2", "10.244.2.7", "0a:58:0a:f4:02:07"),
+	}
+	// The zone of ovn-worker has its node switch and the router port of the switch.
+	workerZone := &fakeNorthbound{tables: map[string][]string{
+		"Logical_Switch": {`{"_uuid":["uuid","a0000001-0000-4000-8000-000000000001"],"name":"ovn-worker",` +
+			`"ports":["set",[["uuid","b0000001-0000-4000-8000-000000000001"],["uuid","b0000002-0000-4000-8000-000000000002"]]]}`},
+		"Logical_Switch_Port": {
+			`{"_uuid":["uuid","b0000001-0000-4000-8000-000000000001"],"name":"default_client","type":"",` +
+				`"addresses":["set",["0a:58:0a:f4:01:05 10.244.1.5"]],"options":["map",[]]}`,
+			`{"_uuid":["uuid","b0000002-0000-4000-8000-000000000002"],"name":"stor-ovn-worker","type":"router",` +
+				`"addresses":["set",["router"]],"options":["map",[["router-port","rtos-ovn-worker"]]]}`,
+		},
+		"Logical_Router_Port": {`{"_uuid":["uuid","c0000001-0000-4000-8000-000000000001"],"name":"rtos-ovn-worker",` +
+			`"mac":"0a:58:0a:f4:01:01"}`},
+	}}
+	// The zone of ovn-worker2 has the transit switch, with the remote port of
+	// ovn-worker, and the transit router port of ovn-worker2.
+	worker2Zone := &fakeNorthbound{tables: map[string][]string{
+		"Logical_Switch": {`{"_uuid":["uuid","a0000002-0000-4000-8000-000000000002"],"name":"transit_switch",` +
+			`"ports":["set",[["uuid","b0000003-0000-4000-8000-000000000003"],["uuid","b0000004-0000-4000-8000-000000000004"]]]}`},
+		"Logical_Switch_Port": {
+			`{"_uuid":["uuid","b0000003-0000-4000-8000-000000000003"],"name":"tstor-ovn-worker","type":"remote",` +
+				`"addresses":["set",["0a:58:64:58:00:02 100.88.0.2/16"]],"options":["map",[]]}`,
+			`{"_uuid":["uuid","b0000004-0000-4000-8000-000000000004"],"name":"tstor-ovn-worker2","type":"router",` +
+				`"addresses":["set",["router"]],"options":["map",[["router-port","rtots-ovn-worker2"]]]}`,
+		},
+		"Logical_Router_Port": {`{"_uuid":["uuid","c0000002-0000-4000-8000-000000000002"],"name":"rtots-ovn-worker2",` +
+			`"mac":"0a:58:64:58:00:03"}`},
+	}}
+	firstTrace := ovntypes.ZoneTrace{Node: "ovn-worker", Datapath: "ovn-worker",
+		Microflow: `inport=="default_client" && eth.src==0a:58:0a:f4:01:05 && eth.dst==0a:58:0a:f4:01:01 && ` +
+			`ip4.src==10.244.1.5 && ip4.dst==10.244.2.7 && ip.ttl==64 && tcp && tcp.src==52888 && tcp.dst==8080`}
+
+	tests := []struct {
+		name       string
+		zones      map[string]string
+		databases  map[string]*fakeNorthbound
+		wantTraces []ovntypes.ZoneTrace
+	}{
+		{
+			name:      "interconnect",
+			zones:     map[string]string{"ovn-worker": "ovnkube-node-a", "ovn-worker2": "ovnkube-node-b"},
+			databases: map[string]*fakeNorthbound{"ovnkube-node-a": workerZone, "ovnkube-node-b": worker2Zone},
+			wantTraces: []ovntypes.ZoneTrace{
+				{Node: "ovn-worker", Datapath: "ovn-worker", Microflow: firstTrace.Microflow, Output: "trace in ovnkube-node-a"},
+				// The packet enters the zone of the destination from the transit switch
+				// port of the source node, to the transit router port of the destination
+				// node, after the cluster router of the source zone.
+				{Node: "ovn-worker2", Datapath: "transit_switch",
+					Microflow: `inport=="tstor-ovn-worker" && eth.src==0a:58:64:58:00:02 && eth.dst==0a:58:64:58:00:03 && ` +
+						`ip4.src==10.244.1.5 && ip4.dst==10.244.2.7 && ip.ttl==63 && tcp && tcp.src==52888 && tcp.dst==8080`,
+					Output: "trace in ovnkube-node-b"},
+			},
+		},
+		{
+			// Without interconnect, the database of both nodes is the same, and the
+			// trace of the source zone covers the whole path.
+			name:      "same zone",
+			zones:     map[string]string{"ovn-worker": "ovnkube-db", "ovn-worker2": "ovnkube-db"},
+			databases: map[string]*fakeNorthbound{"ovnkube-db": workerZone},
+			wantTraces: []ovntypes.ZoneTrace{
+				{Node: "ovn-worker", Datapath: "ovn-worker", Microflow: firstTrace.Microflow, Output: "trace in ovnkube-db"},
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			zones := &fakeZones{zones: test.zones, databases: test.databases}
+			s := NewMCPServer(zones, zones, pods)
+			_, result, err := s.TracePod(context.Background(), nil, ovntypes.PodTraceParams{SourceNamespace: "default",
+				SourcePod: "client", DestinationPod: "server", Port: 8080, OVNNamespace: "ovn-kubernetes"})
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(result.Traces, test.wantTraces) {
+				t.Fatalf("Expected traces:\n%+v\ngot:\n%+v", test.wantTraces, result.Traces)
+			}
+			if len(zones.traces) != len(test.wantTraces) {
+				t.Fatalf("Expected %d ovn-trace commands, got %v", len(test.wantTraces), zones.traces)
+			}
+		})
+	}
+
+	t.Run("rejects the DNS names of other hosts", func(t *testing.T) {
+		zones := &fakeZones{}
+		s := NewMCPServer(zones, zones, pods)
+		_, _, err := s.TracePod(context.Background(), nil, ovntypes.PodTraceParams{SourceNamespace: "default",
+			SourcePod: "client", Destination: "www.example.com", Port: 443})
+		if err == nil || !strings.Contains(err.Error(), "destination www.example.com is neither an IP address nor the DNS name of a Service") {
+			t.Fatalf("Expected the DNS name to be rejected, got %v", err)
+		}
+		if len(zones.traces) != 0 {
+			t.Fatalf("Expected no trace, got %v", zones.traces)
+		}
+	})
+}
@@ -2,6 +2,8 @@ package types
 
 import (
 	k8stypes "github.com/ovn-kubernetes/ovn-kubernetes-mcp/pkg/kubernetes/types"
+	"github.com/ovn-kubernetes/ovn-kubernetes-mcp/pkg/ovsdb"
+	"github.com/ovn-kubernetes/ovn-kubernetes-mcp/pkg/pagination"
 )
 
 // Database represents an OVN database type.
@@ -16,7 +18,8 @@ const (
 
 // ShowParams are the parameters for ovn-nbctl/ovn-sbctl show command.
 type ShowParams struct {
-	k8stypes.NamespacedNameParams
+	k8stypes.ClusterParams
+	k8stypes.PodTargetParams
 	Database Database `json:"database"`
 	MaxLines int      `json:"max_lines,omitempty"`
 }
@@ -27,18 +30,147 @@ type ShowResult struct {
 	Output   string   `json:"output"`
 }
 
+// QueryParams are the parameters for selecting the rows of an OVN table with the
+// OVSDB JSON-RPC interface.
+type QueryParams struct {
+	k8stypes.ClusterParams
+	k8stypes.PodTargetParams
+	Database  Database          `json:"database"`
+	Table     string            `json:"table"`
+	Columns   []string          `json:"columns,omitempty"`
+	Where     []ovsdb.Condition `json:"where,omitempty"`
+	KeepUUIDs bool              `json:"keep_uuids,omitempty"`
+	pagination.Params
+}
+
+// QueryResult contains a page of the rows of an OVN table.
+type QueryResult struct {
+	Database Database    `json:"database"`
+	Table    string      `json:"table"`
+	Rows     []ovsdb.Row `json:"rows"`
+	pagination.Result
+}
+
+// GraphFormat is the output format of the logical topology graph.
+type GraphFormat string
+
+const (
+	// GraphFormatJSON returns the nodes and edges of the graph.
+	GraphFormatJSON GraphFormat = "json"
+	// GraphFormatDOT returns the graph in the Graphviz DOT language.
+	GraphFormatDOT GraphFormat = "dot"
+	// GraphFormatMermaid returns the graph as a Mermaid flowchart.
+	GraphFormatMermaid GraphFormat = "mermaid"
+)
+
+// TopologyGraphParams are the parameters for building the graph of the logical
+// topology of the Northbound database.
+type TopologyGraphParams struct {
+	k8stypes.ClusterParams
+	k8stypes.PodTargetParams
+	Format     GraphFormat `json:"format,omitempty"`
+	FilterNode string      `json:"filter_node,omitempty"`
+	Network    string      `json:"network,omitempty"`
+	AroundPort string      `json:"around_port,omitempty"`
+	Depth      int         `json:"depth,omitempty"`
+	HidePorts  bool        `json:"hide_ports,omitempty"`
+}
+
+// GraphNode is a logical switch, router or switch port of the logical topology.
+type GraphNode struct {
+	ID   string `json:"id"`
+	Kind string `json:"kind"`
+	// Type is the role of the switch (node, join, transit, external), of the
+	// router (cluster, gateway) or the type of the switch port.
+	Type      string   `json:"type,omitempty"`
+	Name      string   `json:"name"`
+	Node      string   `json:"node,omitempty"`
+	Network   string   `json:"network,omitempty"`
+	Addresses []string `json:"addresses,omitempty"`
+}
+
+// GraphEdge is a link of the logical topology: a router port connected to a
+// switch, two peer router ports, or a port of a switch.
+type GraphEdge struct {
+	From      string   `json:"from"`
+	To        string   `json:"to"`
+	Kind      string   `json:"kind"`
+	Port      string   `json:"port,omitempty"`
+	PeerPort  string   `json:"peer_port,omitempty"`
+	Addresses []string `json:"addresses,omitempty"`
+}
+
+// TopologyGraphResult contains the graph of the logical topology, as nodes and
+// edges in the JSON format and as text in the other formats.
+type TopologyGraphResult struct {
+	Format    GraphFormat `json:"format"`
+	Nodes     []GraphNode `json:"nodes,omitempty"`
+	Edges     []GraphEdge `json:"edges,omitempty"`
+	Graph     string      `json:"graph,omitempty"`
+	NodeCount int         `json:"node_count"`
+	EdgeCount int         `json:"edge_count"`
+}
+
 // LogicalFlowListParams are the parameters for listing logical flows from SBDB.
 type LogicalFlowListParams struct {
-	k8stypes.NamespacedNameParams
-	Datapath string `json:"datapath,omitempty"`
-	Filter   string `json:"filter,omitempty"`
-	MaxLines int    `json:"max_lines,omitempty"`
+	k8stypes.ClusterParams
+	k8stypes.PodTargetParams
+	Datapath      string `json:"datapath,omitempty"`
+	Stage         string `json:"stage,omitempty"`
+	MinPriority   int    `json:"min_priority,omitempty"`
+	MatchContains string `json:"match_contains,omitempty"`
+	Reference     string `json:"reference,omitempty"`
+	Filter        string `json:"filter,omitempty"`
+	pagination.Params
+}
+
+// LogicalFlow is a logical flow of the Southbound database, on one of its
+// datapaths.
+type LogicalFlow struct {
+	UUID        string            `json:"uuid"`
+	Datapath    string            `json:"datapath"`
+	Pipeline    string            `json:"pipeline"`
+	Table       int               `json:"table"`
+	Stage       string            `json:"stage,omitempty"`
+	Priority    int               `json:"priority"`
+	Match       string            `json:"match"`
+	Actions     string            `json:"actions"`
+	ExternalIDs map[string]string `json:"external_ids,omitempty"`
+}
+
+// ParseLogicalFlow returns the logical flow of a row of the Logical_Flow table, on
+// a datapath, which is named by the caller since the row only references it or
+// its datapath group.
+func ParseLogicalFlow(row ovsdb.Row, datapath string) LogicalFlow {
+	externalIDs := row.Map("external_ids")
+	return LogicalFlow{
+		UUID:        row.String("_uuid"),
+		Datapath:    datapath,
+		Pipeline:    row.String("pipeline"),
+		Table:       int(row.Int("table_id")),
+		Stage:       externalIDs["stage-name"],
+		Priority:    int(row.Int("priority")),
+		Match:       row.String("match"),
+		Actions:     row.String("actions"),
+		ExternalIDs: externalIDs,
+	}
+}
+
+// StageCount is the number of logical flows of a stage.
+type StageCount struct {
+	Pipeline string `json:"pipeline"`
+	Table    int    `json:"table"`
+	Stage    string `json:"stage,omitempty"`
+	Count    int    `json:"count"`
 }
 
-// LogicalFlowListResult contains the list of logical flows.
+// LogicalFlowListResult contains a page of the list of logical flows, and the
+// number of flows of each stage of the whole list.
 type LogicalFlowListResult struct {
-	Datapath string   `json:"datapath,omitempty"`
-	Flows    []string `json:"flows"`
+	Datapath string        `json:"datapath,omitempty"`
+	Flows    []LogicalFlow `json:"flows"`
+	Stages   []StageCount  `json:"stages"`
+	pagination.Result
 }
 
 // TraceMode represents the output verbosity mode for ovn-trace.
@@ -55,7 +187,8 @@ const (
 
 // OVNTraceParams are the parameters for ovn-trace command.
 type OVNTraceParams struct {
-	k8stypes.NamespacedNameParams
+	k8stypes.ClusterParams
+	k8stypes.PodTargetParams
 	Datapath  string    `json:"datapath"`
 	Microflow string    `json:"microflow"`
 	Mode      TraceMode `json:"mode,omitempty"` // Output mode: detailed (default), summary, or minimal
@@ -70,25 +203,76 @@ type OVNTraceResult struct {
 	Output    string `json:"output"`
 }
 
+// PodTraceParams are the parameters for tracing a packet from a pod to a pod, a
+// Service, an IP address or the DNS name of a Service. Exactly one of DestinationPod,
+// DestinationService and Destination is set.
+type PodTraceParams struct {
+	k8stypes.ClusterParams
+	SourceNamespace      string    `json:"source_namespace"`
+	SourcePod            string    `json:"source_pod"`
+	DestinationNamespace string    `json:"destination_namespace,omitempty"`
+	DestinationPod       string    `json:"destination_pod,omitempty"`
+	DestinationService   string    `json:"destination_service,omitempty"`
+	Destination          string    `json:"destination,omitempty"`
+	Protocol             string    `json:"protocol,omitempty"`
+	Port                 int       `json:"port,omitempty"`
+	OVNNamespace         string    `json:"ovn_namespace,omitempty"`
+	Mode                 TraceMode `json:"mode,omitempty"`
+	MaxLines             int       `json:"max_lines,omitempty"`
+}
+
+// TraceEndpoint is the source or destination of a pod trace.
+type TraceEndpoint struct {
+	// Kind is pod, service or ip.
+	Kind        string `json:"kind"`
+	Namespace   string `json:"namespace,omitempty"`
+	Name        string `json:"name,omitempty"`
+	Node        string `json:"node,omitempty"`
+	Network     string `json:"network,omitempty"`
+	LogicalPort string `json:"logical_port,omitempty"`
+	MAC         string `json:"mac,omitempty"`
+	IP          string `json:"ip"`
+}
+
+// ZoneTrace is the trace of a packet in the database of the zone of a node.
+type ZoneTrace struct {
+	Node      string `json:"node"`
+	Datapath  string `json:"datapath"`
+	Microflow string `json:"microflow"`
+	Output    string `json:"output"`
+}
+
+// PodTraceResult contains the traces of a packet from a pod, in the zone of the
+// source node and, with interconnect, in the zone of the destination node.
+type PodTraceResult struct {
+	Source      TraceEndpoint `json:"source"`
+	Destination TraceEndpoint `json:"destination"`
+	Protocol    string        `json:"protocol"`
+	Port        int           `json:"port,omitempty"`
+	Traces      []ZoneTrace   `json:"traces"`
+}
+
 // GetParams are the parameters for querying records from an OVN table.
 // This is a flexible command that supports:
 // - Listing all records (when Record is empty)
 // - Getting a specific record (when Record is set)
 // - Getting specific columns (when Columns is set)
 type GetParams struct {
-	k8stypes.NamespacedNameParams
+	k8stypes.ClusterParams
+	k8stypes.PodTargetParams
 	Database Database `json:"database"`
 	Table    string   `json:"table"`
 	Record   string   `json:"record,omitempty"`  // Optional: if empty, lists all records
 	Columns  string   `json:"columns,omitempty"` // Optional: comma-separated columns to retrieve
 	Filter   string   `json:"filter,omitempty"`
-	MaxLines int      `json:"max_lines,omitempty"`
+	pagination.Params
 }
 
-// GetResult contains the output of ovn-nbctl/ovn-sbctl query.
+// GetResult contains a page of the output lines of ovn-nbctl/ovn-sbctl query.
 type GetResult struct {
 	Database Database `json:"database"`
 	Table    string   `json:"table"`
 	Record   string   `json:"record,omitempty"`
 	Output   string   `json:"output"`
+	pagination.Result
 }
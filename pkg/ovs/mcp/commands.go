@@ -7,20 +7,30 @@ import (
 	"strings"
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/ovn-kubernetes/ovn-kubernetes-mcp/pkg/artifacts"
 	k8stypes "github.com/ovn-kubernetes/ovn-kubernetes-mcp/pkg/kubernetes/types"
+	"github.com/ovn-kubernetes/ovn-kubernetes-mcp/pkg/ovsdb"
 )
 
 const defaultMaxLines = 100
 
-func (s *MCPServer) runCommand(ctx context.Context, req *mcp.CallToolRequest, namespacedName k8stypes.NamespacedNameParams,
+// runCommand runs an OVS command in the pod of the target, or in the pod of the
+// node of the target with the OVS sockets, and in the container of the target or
+// the container with the command and the OVS database socket.
+func (s *MCPServer) runCommand(ctx context.Context, req *mcp.CallToolRequest, target k8stypes.PodTargetParams,
 	commands []string) ([]string, error) {
-	_, result, err := s.k8sMcpServer.ExecPod(ctx, req, k8stypes.ExecPodParams{NamespacedNameParams: namespacedName, Command: commands})
+	exec, err := s.pods.Resolve(ctx, req, target, commands[0])
+	if err != nil {
+		return nil, err
+	}
+	exec.Command = commands
+	_, result, err := s.executor.ExecPod(ctx, req, exec)
 	if err != nil {
 		return nil, err
 	}
 	if result.Stderr != "" {
-		return nil, fmt.Errorf("error occurred while running command %v on pod %s/%s: %s", commands, namespacedName.Namespace,
-			namespacedName.Name, result.Stderr)
+		return nil, fmt.Errorf("error occurred while running command %v on pod %s/%s: %s", commands, exec.Namespace,
+			exec.Name, result.Stderr)
 	}
 	output := []string{} // Initialize with empty slice to ensure valid JSON when there's no output
 	for _, line := range strings.Split(result.Stdout, "\n") {
@@ -32,6 +42,18 @@ func (s *MCPServer) runCommand(ctx context.Context, req *mcp.CallToolRequest, na
 	return output, nil
 }
 
+// ovsdbClient returns a client of an OVN database, running ovsdb-client in the pod
+// and container of the target that run the ovn-nbctl or ovn-sbctl commands of the
+// database. They are resolved once for all the commands of the client.
+func (s *MCPServer) ovsdbClient(ctx context.Context, req *mcp.CallToolRequest, target k8stypes.PodTargetParams,
+	database ovsdb.Database) (*ovsdb.Client, error) {
+	exec, err := s.pods.Resolve(ctx, req, target, database.Command)
+	if err != nil {
+		return nil, err
+	}
+	return ovsdb.NewPodClient(s.executor, req, exec, database), nil
+}
+
 // filterLines filters lines using a regex pattern.
 func filterLines(lines []string, pattern string) ([]string, error) {
 	if pattern == "" {
@@ -52,12 +74,14 @@ func filterLines(lines []string, pattern string) ([]string, error) {
 	return filtered, nil
 }
 
-// limitLines limits the number of lines returned.
-func limitLines(lines []string, maxLines int) []string {
+// limitLines limits the number of lines returned. The complete lines are saved as
+// an artifact of the tool call when they are truncated.
+func limitLines(ctx context.Context, lines []string, maxLines int) []string {
 	if maxLines <= 0 {
 		maxLines = defaultMaxLines
 	}
 	if len(lines) > maxLines {
+		artifacts.Save(ctx, strings.Join(lines, "\n"))
 		return lines[:maxLines]
 	}
 	return lines
@@ -1,6 +1,7 @@
 package mcp
 
 import (
+	"context"
 	"testing"
 )
 
@@ -150,7 +151,7 @@ func TestLimitLines(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got := limitLines(tt.lines, tt.maxLines)
+			got := limitLines(context.Background(), tt.lines, tt.maxLines)
 			if len(got) != len(tt.want) {
 				t.Errorf("limitLines() got %d lines, want %d lines", len(got), len(tt.want))
 				return
@@ -0,0 +1,20 @@
+package mcp
+
+import (
+	"context"
+
+	"github.com/ovn-kubernetes/ovn-kubernetes-mcp/pkg/auth"
+	"github.com/ovn-kubernetes/ovn-kubernetes-mcp/pkg/completion"
+)
+
+// AddCompletions adds the completion of the bridge argument with the bridges of
+// the pod of the namespace and pod arguments, or of the node argument.
+func (s *MCPServer) AddCompletions(completer *completion.Completer) {
+	completer.Add(completion.ArgumentBridge, auth.FamilyOVS, func(ctx context.Context, arguments map[string]string) ([]string, error) {
+		pod := completion.PodTarget(arguments)
+		if pod.Name == "" && pod.Node == "" {
+			return nil, nil
+		}
+		return s.runCommand(ctx, nil, pod, []string{"ovs-vsctl", "list-br"})
+	})
+}
@@ -0,0 +1,303 @@
+package mcp
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"maps"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	k8stypes "github.com/ovn-kubernetes/ovn-kubernetes-mcp/pkg/kubernetes/types"
+	ovntypes "github.com/ovn-kubernetes/ovn-kubernetes-mcp/pkg/ovn/types"
+	ovstypes "github.com/ovn-kubernetes/ovn-kubernetes-mcp/pkg/ovs/types"
+	"github.com/ovn-kubernetes/ovn-kubernetes-mcp/pkg/ovsdb"
+)
+
+// tracePattern matches the OpenFlow flows of an ofproto/trace output, after their
+// table.
+var tracePattern = regexp.MustCompile(`^(\d+)\. (.+)$`)
+
+// cookiePattern matches the cookie of an OpenFlow flow.
+var cookiePattern = regexp.MustCompile(`\bcookie[ =](0x[0-9a-fA-F]+)`)
+
+// metadataPattern matches the tunnel key of the logical datapath of an OpenFlow
+// flow.
+var metadataPattern = regexp.MustCompile(`\bmetadata=(0x[0-9a-fA-F]+)`)
+
+// cookieTables are the Southbound tables whose UUIDs are the cookies of the
+// OpenFlow flows: the logical flows, and the records of the physical flows.
+var cookieTables = []string{"Logical_Flow", "Port_Binding", "Multicast_Group", "MAC_Binding"}
+
+// ownerTables are the Northbound tables of the stage hints of the logical flows.
+var ownerTables = []string{"ACL", "Load_Balancer", "NAT"}
+
+// parseTraceSteps returns the OpenFlow flows of an ofproto/trace output, with
+// their cookies.
+func parseTraceSteps(lines []string) []ovstypes.OfprotoTraceStep {
+	steps := []ovstypes.OfprotoTraceStep{}
+	for _, line := range lines {
+		match := tracePattern.FindStringSubmatch(line)
+		if match == nil {
+			continue
+		}
+		table, err := strconv.Atoi(match[1])
+		if err != nil {
+			continue
+		}
+		step := ovstypes.OfprotoTraceStep{Table: table, Flow: match[2]}
+		if cookie := cookiePattern.FindStringSubmatch(match[2]); cookie != nil {
+			step.Cookie = cookie[1]
+		}
+		steps = append(steps, step)
+	}
+	return steps
+}
+
+// cookiePrefix returns the prefix of the UUID of the OVN record of a cookie: its
+// first 32 bits in hexadecimal. The flows without OVN record have no cookie.
+func cookiePrefix(cookie string) (string, bool) {
+	value, err := strconv.ParseUint(strings.TrimPrefix(cookie, "0x"), 16, 64)
+	if err != nil || value == 0 {
+		return "", false
+	}
+	return fmt.Sprintf("%08x", value&0xffffffff), true
+}
+
+// ovnDatabase is an OVN database, with the UUIDs of its tables loaded once.
+type ovnDatabase struct {
+	client *ovsdb.Client
+	schema *ovsdb.Schema
+	uuids  map[string][]string
+}
+
+// find returns the row of a table whose UUID has the prefix, nil if there is none.
+func (d *ovnDatabase) find(ctx context.Context, table, prefix string) (ovsdb.Row, error) {
+	uuids, found := d.uuids[table]
+	if !found {
+		rows, err := d.client.Select(ctx, d.schema, ovsdb.Query{Table: table, Columns: []string{"_uuid"}, KeepUUIDs: true})
+		if err != nil {
+			return nil, err
+		}
+		for _, row := range rows {
+			uuids = append(uuids, row.String("_uuid"))
+		}
+		d.uuids[table] = uuids
+	}
+	for _, uuid := range uuids {
+		if !strings.HasPrefix(uuid, prefix) {
+			continue
+		}
+		rows, err := d.client.Select(ctx, d.schema, ovsdb.Query{
+			Table: table,
+			Where: []ovsdb.Condition{{Column: "_uuid", Function: "==", Value: uuid}},
+		})
+		if err != nil || len(rows) == 0 {
+			return nil, err
+		}
+		return rows[0], nil
+	}
+	return nil, nil
+}
+
+// detracer correlates the OpenFlow flows of a trace to the records of the OVN
+// databases, like ovn-detrace.
+type detracer struct {
+	sb, nb *ovnDatabase
+	// datapaths are the names of the logical datapaths by UUID, and their UUIDs
+	// by tunnel key.
+	datapaths  map[string]string
+	tunnelKeys map[int64]string
+}
+
+// newDetracer returns a detracer of the OVN databases of the target.
+func (s *MCPServer) newDetracer(ctx context.Context, req *mcp.CallToolRequest,
+	target k8stypes.PodTargetParams) (*detracer, error) {
+	d := &detracer{datapaths: map[string]string{}, tunnelKeys: map[int64]string{}}
+	var err error
+	if d.sb, err = s.ovnDatabase(ctx, req, target, ovsdb.Southbound); err != nil {
+		return nil, err
+	}
+	if d.nb, err = s.ovnDatabase(ctx, req, target, ovsdb.Northbound); err != nil {
+		return nil, err
+	}
+	datapaths, err := d.sb.client.Select(ctx, d.sb.schema, ovsdb.Query{
+		Table:     "Datapath_Binding",
+		Columns:   []string{"tunnel_key", "external_ids"},
+		KeepUUIDs: true,
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, datapath := range datapaths {
+		uuid := datapath.String("_uuid")
+		d.datapaths[uuid] = datapath.Map("external_ids")["name"]
+		if d.datapaths[uuid] == "" {
+			d.datapaths[uuid] = uuid
+		}
+		d.tunnelKeys[datapath.Int("tunnel_key")] = uuid
+	}
+	return d, nil
+}
+
+// ovnDatabase returns an OVN database of the target.
+func (s *MCPServer) ovnDatabase(ctx context.Context, req *mcp.CallToolRequest, target k8stypes.PodTargetParams,
+	database ovsdb.Database) (*ovnDatabase, error) {
+	client, err := s.ovsdbClient(ctx, req, target, database)
+	if err != nil {
+		return nil, err
+	}
+	schema, err := client.Schema(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &ovnDatabase{client: client, schema: schema, uuids: map[string][]string{}}, nil
+}
+
+// cookieRecord is the Southbound record of a cookie, with the Northbound record of
+// the stage hint of the logical flows.
+type cookieRecord struct {
+	table string
+	row   ovsdb.Row
+	owner *ovstypes.OVNRecord
+}
+
+// annotate adds the OVN records of the cookies to the steps. The records are
+// looked up once per cookie.
+func (d *detracer) annotate(ctx context.Context, steps []ovstypes.OfprotoTraceStep) error {
+	records := map[string]*cookieRecord{}
+	for i := range steps {
+		prefix, ok := cookiePrefix(steps[i].Cookie)
+		if !ok {
+			continue
+		}
+		record, found := records[prefix]
+		if !found {
+			var err error
+			if record, err = d.lookup(ctx, prefix); err != nil {
+				return fmt.Errorf("failed to look up cookie %s: %w", steps[i].Cookie, err)
+			}
+			records[prefix] = record
+		}
+		switch {
+		case record == nil:
+		case record.table == "Logical_Flow":
+			steps[i].LogicalFlow = d.logicalFlow(record.row, steps[i].Flow)
+			steps[i].Owner = record.owner
+		default:
+			steps[i].Record = d.describe(record.table, record.row)
+		}
+	}
+	return nil
+}
+
+// lookup returns the Southbound record of the UUID prefix of a cookie, nil if
+// there is none.
+func (d *detracer) lookup(ctx context.Context, prefix string) (*cookieRecord, error) {
+	for _, table := range cookieTables {
+		row, err := d.sb.find(ctx, table, prefix)
+		if err != nil {
+			return nil, err
+		}
+		if row == nil {
+			continue
+		}
+		record := &cookieRecord{table: table, row: row}
+		hint := row.Map("external_ids")["stage-hint"]
+		if table != "Logical_Flow" || hint == "" {
+			return record, nil
+		}
+		for _, ownerTable := range ownerTables {
+			owner, err := d.nb.find(ctx, ownerTable, hint)
+			if err != nil {
+				return nil, err
+			}
+			if owner != nil {
+				record.owner = d.describe(ownerTable, owner)
+				break
+			}
+		}
+		return record, nil
+	}
+	return nil, nil
+}
+
+// logicalFlow returns a logical flow, on its datapath, or on the datapath of the
+// OpenFlow flow for the flows of datapath groups.
+func (d *detracer) logicalFlow(row ovsdb.Row, openFlow string) *ovntypes.LogicalFlow {
+	datapath := d.datapaths[row.String("logical_datapath")]
+	if row.String("logical_datapath") == "" {
+		datapath = d.flowDatapath(openFlow)
+	}
+	flow := ovntypes.ParseLogicalFlow(row, datapath)
+	return &flow
+}
+
+// flowDatapath returns the logical datapath of the metadata of an OpenFlow flow.
+func (d *detracer) flowDatapath(openFlow string) string {
+	match := metadataPattern.FindStringSubmatch(openFlow)
+	if match == nil {
+		return ""
+	}
+	key, err := strconv.ParseInt(strings.TrimPrefix(match[1], "0x"), 16, 64)
+	if err != nil {
+		return ""
+	}
+	return d.datapaths[d.tunnelKeys[key]]
+}
+
+// describe returns a record of a table, described in one line.
+func (d *detracer) describe(table string, row ovsdb.Row) *ovstypes.OVNRecord {
+	record := &ovstypes.OVNRecord{Table: table, UUID: row.String("_uuid"), Name: row.String("name")}
+	datapath := d.datapaths[row.String("datapath")]
+	switch table {
+	case "Port_Binding":
+		record.Name = row.String("logical_port")
+		record.Description = fmt.Sprintf("logical port %s of type %s on datapath %s", record.Name,
+			cmp.Or(row.String("type"), "vif"), datapath)
+		if chassis := row.String("chassis"); chassis != "" {
+			record.Description += ", bound to chassis " + chassis
+		}
+	case "Multicast_Group":
+		record.Description = fmt.Sprintf("multicast group %s on datapath %s", record.Name, datapath)
+	case "MAC_Binding":
+		record.Description = fmt.Sprintf("MAC binding of %s to %s on logical port %s", row.String("ip"),
+			row.String("mac"), row.String("logical_port"))
+	case "ACL":
+		record.Description = fmt.Sprintf("%s ACL, priority %d, match (%s), action %s", row.String("direction"),
+			row.Int("priority"), row.String("match"), row.String("action"))
+	case "Load_Balancer":
+		vips := row.Map("vips")
+		var backends []string
+		for _, vip := range slices.Sorted(maps.Keys(vips)) {
+			backends = append(backends, vip+" -> "+vips[vip])
+		}
+		record.Description = fmt.Sprintf("%s load balancer, VIPs: %s", cmp.Or(row.String("protocol"), "tcp"),
+			strings.Join(backends, "; "))
+	case "NAT":
+		record.Description = fmt.Sprintf("%s of %s to %s", row.String("type"), row.String("logical_ip"),
+			row.String("external_ip"))
+	}
+	return record
+}
+
+// detrace returns the steps of a trace correlated to the records of the OVN
+// databases of the target.
+func (s *MCPServer) detrace(ctx context.Context, req *mcp.CallToolRequest, target k8stypes.PodTargetParams,
+	lines []string) ([]ovstypes.OfprotoTraceStep, error) {
+	steps := parseTraceSteps(lines)
+	if !slices.ContainsFunc(steps, func(step ovstypes.OfprotoTraceStep) bool { return step.Cookie != "" }) {
+		return steps, nil
+	}
+	d, err := s.newDetracer(ctx, req, target)
+	if err != nil {
+		return nil, err
+	}
+	if err := d.annotate(ctx, steps); err != nil {
+		return nil, err
+	}
+	return steps, nil
+}
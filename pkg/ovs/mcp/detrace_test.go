@@ -0,0 +1,257 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	ovntypes "github.com/ovn-kubernetes/ovn-kubernetes-mcp/pkg/ovn/types"
+	ovstypes "github.com/ovn-kubernetes/ovn-kubernetes-mcp/pkg/ovs/types"
+	"github.com/ovn-kubernetes/ovn-kubernetes-mcp/pkg/ovsdb"
+)
+
+// testSouthboundSchema is a subset of the OVN Southbound schema.
+const testSouthboundSchema = `{
+  "name": "OVN_Southbound",
+  "version": "20.37.0",
+  "tables": {
+    "Logical_Flow": {"columns": {
+      "logical_datapath": {"type": {"key": {"type": "uuid", "refTable": "Datapath_Binding"}, "min": 0, "max": 1}},
+      "logical_dp_group": {"type": {"key": {"type": "uuid", "refTable": "Logical_DP_Group"}, "min": 0, "max": 1}},
+      "pipeline": {"type": {"key": {"type": "string", "enum": ["set", ["ingress", "egress"]]}}},
+      "table_id": {"type": {"key": {"type": "integer", "minInteger": 0, "maxInteger": 32}}},
+      "priority": {"type": {"key": {"type": "integer", "minInteger": 0, "maxInteger": 65535}}},
+      "match": {"type": "string"},
+      "actions": {"type": "string"},
+      "external_ids": {"type": {"key": "string", "value": "string", "min": 0, "max": "unlimited"}}
+    }},
+    "Logical_DP_Group": {"columns": {
+      "datapaths": {"type": {"key": {"type": "uuid", "refTable": "Datapath_Binding", "refType": "weak"}, "min": 0, "max": "unlimited"}}
+    }},
+    "Datapath_Binding": {"columns": {
+      "tunnel_key": {"type": {"key": {"type": "integer", "minInteger": 1, "maxInteger": 16777215}}},
+      "external_ids": {"type": {"key": "string", "value": "string", "min": 0, "max": "unlimited"}}
+    }},
+    "Port_Binding": {"columns": {
+      "logical_port": {"type": "string"},
+      "type": {"type": "string"},
+      "datapath": {"type": {"key": {"type": "uuid", "refTable": "Datapath_Binding"}}},
+      "chassis": {"type": {"key": {"type": "uuid", "refTable": "Chassis", "refType": "weak"}, "min": 0, "max": 1}}
+    }, "indexes": [["logical_port"]]},
+    "Chassis": {"columns": {"name": {"type": "string"}}, "indexes": [["name"]]},
+    "Multicast_Group": {"columns": {
+      "name": {"type": "string"},
+      "datapath": {"type": {"key": {"type": "uuid", "refTable": "Datapath_Binding"}}}
+    }},
+    "MAC_Binding": {"columns": {
+      "logical_port": {"type": "string"},
+      "ip": {"type": "string"},
+      "mac": {"type": "string"},
+      "datapath": {"type": {"key": {"type": "uuid", "refTable": "Datapath_Binding"}}}
+    }}
+  }
+}`
+
+// testNorthboundSchema is a subset of the OVN Northbound schema.
+const testNorthboundSchema = `{
+  "name": "OVN_Northbound",
+  "version": "7.3.0",
+  "tables": {
+    "ACL": {"columns": {
+      "name": {"type": {"key": "string", "min": 0, "max": 1}},
+      "direction": {"type": {"key": {"type": "string", "enum": ["set", ["from-lport", "to-lport"]]}}},
+      "priority": {"type": {"key": {"type": "integer", "minInteger": 0, "maxInteger": 32767}}},
+      "match": {"type": "string"},
+      "action": {"type": "string"}
+    }},
+    "Load_Balancer": {"columns": {
+      "name": {"type": "string"},
+      "protocol": {"type": {"key": {"type": "string", "enum": ["set", ["tcp", "udp", "sctp"]]}, "min": 0, "max": 1}},
+      "vips": {"type": {"key": "string", "value": "string", "min": 0, "max": "unlimited"}}
+    }},
+    "NAT": {"columns": {
+      "type": {"type": "string"},
+      "logical_ip": {"type": "string"},
+      "external_ip": {"type": "string"}
+    }}
+  }
+}`
+
+const (
+	workerUUID    = "d0000001-0000-4000-8000-000000000001"
+	joinUUID      = "d0000002-0000-4000-8000-000000000002"
+	chassisUUID   = "c0000001-0000-4000-8000-000000000001"
+	portUUID      = "1a2b3c4d-0000-4000-8000-000000000001"
+	groupFlowUUID = "5e6f7a8b-0000-4000-8000-000000000001"
+	aclFlowUUID   = "9c8d7e6f-0000-4000-8000-000000000001"
+	aclUUID       = "aaaa1111-0000-4000-8000-000000000001"
+)
+
+// fakeDatabase answers the get-schema and transact commands of ovsdb-client with
+// the rows of its tables, in the wire format. The select operations only support
+// the conditions on _uuid, and return all the columns.
+type fakeDatabase struct {
+	schema string
+	tables map[string][]string
+}
+
+func (f *fakeDatabase) run(ctx context.Context, args ...string) (string, error) {
+	switch args[0] {
+	case "get-schema":
+		return f.schema, nil
+	case "transact":
+		var transaction []json.RawMessage
+		if err := json.Unmarshal([]byte(args[2]), &transaction); err != nil {
+			return "", err
+		}
+		var results []string
+		for _, operation := range transaction[1:] {
+			var op struct {
+				Table string  `json:"table"`
+				Where [][]any `json:"where"`
+			}
+			if err := json.Unmarshal(operation, &op); err != nil {
+				return "", err
+			}
+			var rows []string
+			for _, row := range f.tables[op.Table] {
+				if len(op.Where) == 0 || strings.Contains(row, `"_uuid":["uuid","`+op.Where[0][2].([]any)[1].(string)+`"]`) {
+					rows = append(rows, row)
+				}
+			}
+			results = append(results, `{"rows":[`+strings.Join(rows, ",")+`]}`)
+		}
+		return "[" + strings.Join(results, ",") + "]", nil
+	}
+	return "", errors.New("unknown command")
+}
+
+func newTestDetracer(t *testing.T) *detracer {
+	sb := &fakeDatabase{schema: testSouthboundSchema, tables: map[string][]string{
+		"Datapath_Binding": {
+			`{"_uuid":["uuid","` + workerUUID + `"],"tunnel_key":2,"external_ids":["map",[["name","ovn-worker"]]]}`,
+			`{"_uuid":["uuid","` + joinUUID + `"],"tunnel_key":1,"external_ids":["map",[["name","join"]]]}`,
+		},
+		"Chassis": {`{"_uuid":["uuid","` + chassisUUID + `"],"name":"ovn-worker"}`},
+		"Port_Binding": {
+			`{"_uuid":["uuid","` + portUUID + `"],"logical_port":"default_client","type":"","datapath":["uuid","` + workerUUID + `"],` +
+				`"chassis":["uuid","` + chassisUUID + `"]}`,
+		},
+		"Logical_Flow": {
+			`{"_uuid":["uuid","` + groupFlowUUID + `"],"logical_dp_group":["uuid","e0000001-0000-4000-8000-000000000001"],` +
+				`"logical_datapath":["set",[]],"pipeline":"ingress","table_id":0,"priority":50,"match":"inport == @pg","actions":"next;",` +
+				`"external_ids":["map",[["source","northd.c:5883"],["stage-name","ls_in_check_port_sec"]]]}`,
+			`{"_uuid":["uuid","` + aclFlowUUID + `"],"logical_datapath":["uuid","` + workerUUID + `"],"logical_dp_group":["set",[]],` +
+				`"pipeline":"egress","table_id":4,"priority":2002,"match":"outport == @a123 && ip4","actions":"drop;",` +
+				`"external_ids":["map",[["stage-hint","aaaa1111"],["stage-name","ls_out_acl_eval"]]]}`,
+		},
+	}}
+	nb := &fakeDatabase{schema: testNorthboundSchema, tables: map[string][]string{
+		"ACL": {`{"_uuid":["uuid","` + aclUUID + `"],"name":["set",[]],"direction":"to-lport","priority":1001,` +
+			`"match":"outport == @a123 && ip4","action":"drop"}`},
+	}}
+	d := &detracer{
+		datapaths:  map[string]string{workerUUID: "ovn-worker", joinUUID: "join"},
+		tunnelKeys: map[int64]string{2: workerUUID, 1: joinUUID},
+	}
+	for _, database := range []struct {
+		db     **ovnDatabase
+		fake   *fakeDatabase
+		server string
+		name   string
+	}{
+		{&d.sb, sb, "unix:/var/run/ovn/ovnsb_db.sock", "OVN_Southbound"},
+		{&d.nb, nb, "unix:/var/run/ovn/ovnnb_db.sock", "OVN_Northbound"},
+	} {
+		client := ovsdb.NewClient(database.fake.run, database.server, database.name)
+		schema, err := client.Schema(context.Background())
+		if err != nil {
+			t.Fatalf("Unexpected schema error: %v", err)
+		}
+		*database.db = &ovnDatabase{client: client, schema: schema, uuids: map[string][]string{}}
+	}
+	return d
+}
+
+func TestParseTraceSteps(t *testing.T) {
+	lines := []string{
+		"Flow: tcp,in_port=5,nw_src=10.244.1.5,nw_dst=10.244.2.7,tp_dst=8080",
+		`bridge("br-int")`,
+		"----------------",
+		"0. in_port=5, priority 100, cookie 0x1a2b3c4d",
+		"set_field:0x2->metadata",
+		"8. reg14=0x3,metadata=0x2, priority 50, cookie 0x5e6f7a8b",
+		"10. metadata=0x2, priority 0",
+		"Final flow: unchanged",
+	}
+	want := []ovstypes.OfprotoTraceStep{
+		{Table: 0, Flow: "in_port=5, priority 100, cookie 0x1a2b3c4d", Cookie: "0x1a2b3c4d"},
+		{Table: 8, Flow: "reg14=0x3,metadata=0x2, priority 50, cookie 0x5e6f7a8b", Cookie: "0x5e6f7a8b"},
+		{Table: 10, Flow: "metadata=0x2, priority 0"},
+	}
+	if steps := parseTraceSteps(lines); !reflect.DeepEqual(steps, want) {
+		t.Fatalf("Expected steps %+v, got %+v", want, steps)
+	}
+}
+
+func TestCookiePrefix(t *testing.T) {
+	tests := []struct {
+		cookie     string
+		wantPrefix string
+		wantOK     bool
+	}{
+		{cookie: "0x1a2b3c4d", wantPrefix: "1a2b3c4d", wantOK: true},
+		{cookie: "0xc4d", wantPrefix: "00000c4d", wantOK: true},
+		{cookie: "0x0"},
+		{cookie: ""},
+	}
+	for _, test := range tests {
+		t.Run(test.cookie, func(t *testing.T) {
+			prefix, ok := cookiePrefix(test.cookie)
+			if prefix != test.wantPrefix || ok != test.wantOK {
+				t.Fatalf("Expected prefix %q (%v), got %q (%v)", test.wantPrefix, test.wantOK, prefix, ok)
+			}
+		})
+	}
+}
+
+func TestAnnotate(t *testing.T) {
+	steps := parseTraceSteps([]string{
+		"0. in_port=5, priority 100, cookie 0x1a2b3c4d",
+		"8. reg14=0x3,metadata=0x2, priority 50, cookie 0x5e6f7a8b",
+		"8. reg14=0x1,metadata=0x1, priority 50, cookie 0x5e6f7a8b",
+		"44. ct_state=+new-est+trk,ip,reg15=0x3,metadata=0x2, priority 2002, cookie 0x9c8d7e6f",
+		"65. reg15=0x3,metadata=0x2, priority 100, cookie 0x12345678",
+	})
+	if err := newTestDetracer(t).annotate(context.Background(), steps); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	groupFlow := func(datapath string) *ovntypes.LogicalFlow {
+		return &ovntypes.LogicalFlow{UUID: groupFlowUUID, Datapath: datapath, Pipeline: "ingress", Table: 0,
+			Stage: "ls_in_check_port_sec", Priority: 50, Match: "inport == @pg", Actions: "next;",
+			ExternalIDs: map[string]string{"source": "northd.c:5883", "stage-name": "ls_in_check_port_sec"}}
+	}
+	want := []ovstypes.OfprotoTraceStep{
+		{Table: 0, Flow: "in_port=5, priority 100, cookie 0x1a2b3c4d", Cookie: "0x1a2b3c4d", Record: &ovstypes.OVNRecord{
+			Table: "Port_Binding", UUID: portUUID, Name: "default_client",
+			Description: "logical port default_client of type vif on datapath ovn-worker, bound to chassis ovn-worker"}},
+		{Table: 8, Flow: "reg14=0x3,metadata=0x2, priority 50, cookie 0x5e6f7a8b", Cookie: "0x5e6f7a8b", LogicalFlow: groupFlow("ovn-worker")},
+		{Table: 8, Flow: "reg14=0x1,metadata=0x1, priority 50, cookie 0x5e6f7a8b", Cookie: "0x5e6f7a8b", LogicalFlow: groupFlow("join")},
+		{Table: 44, Flow: "ct_state=+new-est+trk,ip,reg15=0x3,metadata=0x2, priority 2002, cookie 0x9c8d7e6f", Cookie: "0x9c8d7e6f",
+			LogicalFlow: &ovntypes.LogicalFlow{UUID: aclFlowUUID, Datapath: "ovn-worker", Pipeline: "egress", Table: 4,
+				Stage: "ls_out_acl_eval", Priority: 2002, Match: "outport == @a123 && ip4", Actions: "drop;",
+				ExternalIDs: map[string]string{"stage-hint": "aaaa1111", "stage-name": "ls_out_acl_eval"}},
+			Owner: &ovstypes.OVNRecord{Table: "ACL", UUID: aclUUID,
+				Description: "to-lport ACL, priority 1001, match (outport == @a123 && ip4), action drop"}},
+		{Table: 65, Flow: "reg15=0x3,metadata=0x2, priority 100, cookie 0x12345678", Cookie: "0x12345678"},
+	}
+	for i := range want {
+		if !reflect.DeepEqual(steps[i], want[i]) {
+			t.Fatalf("Expected step %d:\n%+v\ngot:\n%+v", i, want[i], steps[i])
+		}
+	}
+}
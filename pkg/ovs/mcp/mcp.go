@@ -6,20 +6,24 @@ import (
 	"strings"
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
-	kubernetesmcp "github.com/ovn-kubernetes/ovn-kubernetes-mcp/pkg/kubernetes/mcp"
-	k8stypes "github.com/ovn-kubernetes/ovn-kubernetes-mcp/pkg/kubernetes/types"
+	"github.com/ovn-kubernetes/ovn-kubernetes-mcp/pkg/executor"
 	ovstypes "github.com/ovn-kubernetes/ovn-kubernetes-mcp/pkg/ovs/types"
+	"github.com/ovn-kubernetes/ovn-kubernetes-mcp/pkg/pagination"
+	"github.com/ovn-kubernetes/ovn-kubernetes-mcp/pkg/topology"
 )
 
 // MCPServer provides OVS layer analysis tools
 type MCPServer struct {
-	k8sMcpServer *kubernetesmcp.MCPServer
+	executor executor.Executor
+	pods     topology.PodResolver
 }
 
-// NewMCPServer creates a new OVS MCP server
-func NewMCPServer(k8sMcpServer *kubernetesmcp.MCPServer) *MCPServer {
+// NewMCPServer creates a new OVS MCP server. The commands run in the pods
+// resolved by pods.
+func NewMCPServer(executor executor.Executor, pods topology.PodResolver) *MCPServer {
 	return &MCPServer{
-		k8sMcpServer: k8sMcpServer,
+		executor: executor,
+		pods:     pods,
 	}
 }
 
@@ -33,8 +37,11 @@ func (s *MCPServer) AddTools(server *mcp.Server) {
 Runs 'ovs-vsctl list-br' command and returns the names of all configured bridges.
 
 Parameters:
-- namespace: Kubernetes namespace of the OVS pod
+- cluster: Cluster of the pod, from cluster-list (optional, defaults to the default cluster)
+- namespace: Kubernetes namespace of the OVS pod, detected with node
 - name: Name of the pod running OVS
+- node: Node to run the command on instead of the pod, from ovnk-topology
+- container (optional): Container of the pod to run the command in, selected automatically if not set
 
 Example output:
 {
@@ -54,8 +61,11 @@ Example output:
 Runs 'ovs-vsctl list-ports' command and returns the names of all ports attached to the specified bridge.
 
 Parameters:
-- namespace: Kubernetes namespace of the OVS pod
+- cluster: Cluster of the pod, from cluster-list (optional, defaults to the default cluster)
+- namespace: Kubernetes namespace of the OVS pod, detected with node
 - name: Name of the pod running OVS
+- node: Node to run the command on instead of the pod, from ovnk-topology
+- container (optional): Container of the pod to run the command in, selected automatically if not set
 - bridge: Name of the OVS bridge (e.g., "br-int")
 
 Example output:
@@ -76,8 +86,11 @@ Example output:
 Runs 'ovs-vsctl list-ifaces' command and returns the names of all interfaces attached to the specified bridge.
 
 Parameters:
-- namespace: Kubernetes namespace of the OVS pod
+- cluster: Cluster of the pod, from cluster-list (optional, defaults to the default cluster)
+- namespace: Kubernetes namespace of the OVS pod, detected with node
 - name: Name of the pod running OVS
+- node: Node to run the command on instead of the pod, from ovnk-topology
+- container (optional): Container of the pod to run the command in, selected automatically if not set
 - bridge: Name of the OVS bridge (e.g., "br-int")
 
 Example output:
@@ -106,8 +119,11 @@ This command is useful for getting a complete view of the OVS switch configurati
 - Port configurations and tags
 
 Parameters:
-- namespace: Kubernetes namespace of the OVS pod
+- cluster: Cluster of the pod, from cluster-list (optional, defaults to the default cluster)
+- namespace: Kubernetes namespace of the OVS pod, detected with node
 - name: Name of the pod running OVS
+- node: Node to run the command on instead of the pod, from ovnk-topology
+- container (optional): Container of the pod to run the command in, selected automatically if not set
 - max_lines (optional): Limit the number of output lines returned
 
 Example output:
@@ -124,11 +140,15 @@ Example output:
 Runs 'ovs-ofctl dump-flows' command on the specified bridge and returns the flow entries.
 
 Parameters:
-- namespace: Kubernetes namespace of the OVS pod
+- cluster: Cluster of the pod, from cluster-list (optional, defaults to the default cluster)
+- namespace: Kubernetes namespace of the OVS pod, detected with node
 - name: Name of the pod running OVS
+- node: Node to run the command on instead of the pod, from ovnk-topology
+- container (optional): Container of the pod to run the command in, selected automatically if not set
 - bridge: Name of the OVS bridge (e.g., "br-int")
 - filter (optional): Regex pattern to filter flows
-- max_lines (optional): Limit the number of flows returned
+- page_size (optional): Number of flows per page (default: 100, max: 1000)
+- cursor (optional): next_cursor of the previous page, to get the next page
 
 Example output:
 {
@@ -136,7 +156,9 @@ Example output:
   "flows": [
     "cookie=0x0, duration=123.456s, table=0, n_packets=100, n_bytes=10000, priority=100,in_port=1 actions=output:2",
     "cookie=0x0, duration=123.456s, table=0, n_packets=50, n_bytes=5000, priority=90,in_port=2 actions=output:1"
-  ]
+  ],
+  "next_cursor": "eyJvIjoxMDAsInEiOiIxZzZ5In0",
+  "total": 5342
 }`,
 		}, s.DumpFlows)
 
@@ -151,8 +173,11 @@ Connection tracking (conntrack) maintains state for stateful firewall rules and
 Each entry shows source/destination IPs, ports, protocol, connection state, and more.
 
 Parameters:
-- namespace: Kubernetes namespace of the OVS pod
+- cluster: Cluster of the pod, from cluster-list (optional, defaults to the default cluster)
+- namespace: Kubernetes namespace of the OVS pod, detected with node
 - name: Name of the pod running OVS
+- node: Node to run the command on instead of the pod, from ovnk-topology
+- container (optional): Container of the pod to run the command in, selected automatically if not set
 - filter (optional): Regex pattern to filter conntrack entries
 - max_lines (optional): Limit the number of entries returned
 - additional_params (optional): Additional parameters to pass to dpctl/dump-conntrack command (e.g., ["zone=5"])
@@ -178,12 +203,20 @@ The trace output is essential for debugging flow rules, understanding packet for
 and troubleshooting connectivity issues.
 
 Parameters:
-- namespace: Kubernetes namespace of the OVS pod
+- cluster: Cluster of the pod, from cluster-list (optional, defaults to the default cluster)
+- namespace: Kubernetes namespace of the OVS pod, detected with node
 - name: Name of the pod running OVS
+- node: Node to run the command on instead of the pod, from ovnk-topology
+- container (optional): Container of the pod to run the command in, selected automatically if not set
 - bridge: Name of the OVS bridge (e.g., "br-int")
 - flow: Flow specification describing the packet to trace (e.g., "in_port=1,ip,nw_src=10.244.0.5,nw_dst=10.96.0.1")
 - filter (optional): Regex pattern to filter trace output lines
 - max_lines (optional): Limit the number of output lines returned
+- detrace (optional): Correlate the OpenFlow flows to OVN, like ovn-detrace: the steps of the trace have the
+  Southbound logical flow of their cookie (datapath, pipeline, table, stage, priority, match and actions) and
+  the Northbound ACL, load balancer or NAT it was generated for, or the port binding, multicast group or MAC
+  binding of the physical flows. The OVN databases are read in the pod running the databases of the node,
+  or in the named pod
 
 Flow specification examples:
 - "in_port=1,icmp"
@@ -195,21 +228,32 @@ Example output:
   "bridge": "br-int",
   "flow": "in_port=1,ip,nw_src=10.244.0.5,nw_dst=10.96.0.1",
   "output": "Flow: ip,in_port=1,nw_src=10.244.0.5,nw_dst=10.96.0.1\n\nbridge(\"br-int\")\n-------------\n 0. priority 100\n    resubmit(,10)\n10. ip,nw_dst=10.96.0.1, priority 200\n    load:0x1->NXM_NX_REG0[]\n    resubmit(,20)\n...\nFinal flow: ...\nDatapath actions: ..."
+}
+
+Example step with detrace:
+{
+  "table": 44,
+  "flow": "ct_state=+new-est+trk,ip,reg15=0x3,metadata=0x2,nw_src=10.244.0.5, priority 2002, cookie 0x8b1cd3f2",
+  "cookie": "0x8b1cd3f2",
+  "logical_flow": {"uuid": "8b1cd3f2-...", "datapath": "ovn-worker", "pipeline": "egress", "table": 4,
+    "stage": "ls_out_acl_eval", "priority": 2002, "match": "...", "actions": "drop;",
+    "external_ids": {"stage-hint": "5e4f1a2b", "stage-name": "ls_out_acl_eval"}},
+  "owner": {"table": "ACL", "uuid": "5e4f1a2b-...", "description": "to-lport ACL, priority 1001, match (...), action drop"}
 }`,
 		}, s.DumpOfprotoTrace)
 }
 
 func (s *MCPServer) ListBridges(ctx context.Context, req *mcp.CallToolRequest,
-	in k8stypes.NamespacedNameParams) (*mcp.CallToolResult, ovstypes.BridgeResult, error) {
+	in ovstypes.ListBridgesParams) (*mcp.CallToolResult, ovstypes.BridgeResult, error) {
 	result := ovstypes.BridgeResult{
 		Bridges: []string{}, // Initialize with empty slice to ensure valid JSON even on error
 	}
 
 	// Run ovs-vsctl list-br command
-	bridgeNames, err := s.runCommand(ctx, req, in, []string{"ovs-vsctl", "list-br"})
+	bridgeNames, err := s.runCommand(ctx, req, in.PodTargetParams, []string{"ovs-vsctl", "list-br"})
 	if err != nil {
-		return nil, result, fmt.Errorf("failed to retrieve ovs bridge from pod %s/%s: %w",
-			in.Namespace, in.Name, err)
+		return nil, result, fmt.Errorf("failed to retrieve ovs bridge from %s: %w",
+			in.Target(), err)
 	}
 	result.Bridges = append(result.Bridges, bridgeNames...)
 	return nil, result, nil
@@ -221,14 +265,14 @@ func (s *MCPServer) Show(ctx context.Context, req *mcp.CallToolRequest,
 	result := ovstypes.ShowResult{}
 
 	// Run ovs-vsctl show command
-	lines, err := s.runCommand(ctx, req, in.NamespacedNameParams, []string{"ovs-vsctl", "show"})
+	lines, err := s.runCommand(ctx, req, in.PodTargetParams, []string{"ovs-vsctl", "show"})
 	if err != nil {
-		return nil, result, fmt.Errorf("failed to retrieve ovs configuration from pod %s/%s: %w",
-			in.Namespace, in.Name, err)
+		return nil, result, fmt.Errorf("failed to retrieve ovs configuration from %s: %w",
+			in.Target(), err)
 	}
 
 	// Limit to MaxLines if specified
-	lines = limitLines(lines, in.MaxLines)
+	lines = limitLines(ctx, lines, in.MaxLines)
 
 	// Join all lines into a single output string
 	result.Output = strings.Join(lines, "\n")
@@ -247,10 +291,10 @@ func (s *MCPServer) ListPorts(ctx context.Context, req *mcp.CallToolRequest,
 	}
 
 	// Run ovs-vsctl list-ports command
-	ports, err := s.runCommand(ctx, req, in.NamespacedNameParams, []string{"ovs-vsctl", "list-ports", in.Bridge})
+	ports, err := s.runCommand(ctx, req, in.PodTargetParams, []string{"ovs-vsctl", "list-ports", in.Bridge})
 	if err != nil {
-		return nil, result, fmt.Errorf("failed to retrieve ports for bridge %s from pod %s/%s: %w",
-			in.Bridge, in.Namespace, in.Name, err)
+		return nil, result, fmt.Errorf("failed to retrieve ports for bridge %s from %s: %w",
+			in.Bridge, in.Target(), err)
 	}
 	result.Ports = append(result.Ports, ports...)
 	return nil, result, nil
@@ -268,10 +312,10 @@ func (s *MCPServer) ListInterfaces(ctx context.Context, req *mcp.CallToolRequest
 	}
 
 	// Run ovs-vsctl list-ifaces command
-	ports, err := s.runCommand(ctx, req, in.NamespacedNameParams, []string{"ovs-vsctl", "list-ifaces", in.Bridge})
+	ports, err := s.runCommand(ctx, req, in.PodTargetParams, []string{"ovs-vsctl", "list-ifaces", in.Bridge})
 	if err != nil {
-		return nil, result, fmt.Errorf("failed to retrieve interfaces for bridge %s from pod %s/%s: %w",
-			in.Bridge, in.Namespace, in.Name, err)
+		return nil, result, fmt.Errorf("failed to retrieve interfaces for bridge %s from %s: %w",
+			in.Bridge, in.Target(), err)
 	}
 	result.Interfaces = append(result.Interfaces, ports...)
 	return nil, result, nil
@@ -279,7 +323,7 @@ func (s *MCPServer) ListInterfaces(ctx context.Context, req *mcp.CallToolRequest
 
 // DumpFlows dumps flows from a specific OVS bridge.
 func (s *MCPServer) DumpFlows(ctx context.Context, req *mcp.CallToolRequest,
-	in ovstypes.GetOVSCommandParams) (*mcp.CallToolResult, ovstypes.FlowsResult, error) {
+	in ovstypes.DumpFlowsParams) (*mcp.CallToolResult, ovstypes.FlowsResult, error) {
 	result := ovstypes.FlowsResult{
 		Bridge: in.Bridge,
 		Flows:  []string{}, // Initialize with empty slice to ensure valid JSON even on error
@@ -291,10 +335,10 @@ func (s *MCPServer) DumpFlows(ctx context.Context, req *mcp.CallToolRequest,
 	}
 
 	// Run ovs-ofctl dump-flows command
-	flows, err := s.runCommand(ctx, req, in.NamespacedNameParams, []string{"ovs-ofctl", "dump-flows", in.Bridge})
+	flows, err := s.runCommand(ctx, req, in.PodTargetParams, []string{"ovs-ofctl", "dump-flows", in.Bridge})
 	if err != nil {
-		return nil, result, fmt.Errorf("failed to dump flows for bridge %s on pod %s/%s: %w",
-			in.Bridge, in.Namespace, in.Name, err)
+		return nil, result, fmt.Errorf("failed to dump flows for bridge %s on %s: %w",
+			in.Bridge, in.Target(), err)
 	}
 
 	// Filter flows by pattern if provided
@@ -303,8 +347,10 @@ func (s *MCPServer) DumpFlows(ctx context.Context, req *mcp.CallToolRequest,
 		return nil, result, fmt.Errorf("invalid filter pattern: %w", err)
 	}
 
-	// Limit to MaxLines if specified
-	flows = limitLines(flows, in.MaxLines)
+	flows, result.Result, err = pagination.Paginate(flows, in.Params, in.Cluster, in.Namespace, in.Name, in.Node, in.Bridge, in.Filter)
+	if err != nil {
+		return nil, result, err
+	}
 
 	result.Flows = flows
 	return nil, result, nil
@@ -331,10 +377,10 @@ func (s *MCPServer) DumpConntrack(ctx context.Context, req *mcp.CallToolRequest,
 	}
 
 	// Run ovs-appctl dpctl/dump-conntrack command
-	entries, err := s.runCommand(ctx, req, in.NamespacedNameParams, cmd)
+	entries, err := s.runCommand(ctx, req, in.PodTargetParams, cmd)
 	if err != nil {
-		return nil, result, fmt.Errorf("failed to dump conntrack on pod %s/%s: %w",
-			in.Namespace, in.Name, err)
+		return nil, result, fmt.Errorf("failed to dump conntrack on %s: %w",
+			in.Target(), err)
 	}
 
 	// Filter entries by pattern if provided
@@ -344,7 +390,7 @@ func (s *MCPServer) DumpConntrack(ctx context.Context, req *mcp.CallToolRequest,
 	}
 
 	// Limit to MaxLines if specified
-	entries = limitLines(entries, in.MaxLines)
+	entries = limitLines(ctx, entries, in.MaxLines)
 
 	result.Entries = entries
 	return nil, result, nil
@@ -372,10 +418,19 @@ func (s *MCPServer) DumpOfprotoTrace(ctx context.Context, req *mcp.CallToolReque
 	cmd := []string{"ovs-appctl", "ofproto/trace", in.Bridge, in.Flow}
 
 	// Run ovs-appctl ofproto/trace command
-	lines, err := s.runCommand(ctx, req, in.NamespacedNameParams, cmd)
+	lines, err := s.runCommand(ctx, req, in.PodTargetParams, cmd)
 	if err != nil {
-		return nil, result, fmt.Errorf("failed to trace flow on bridge %s, pod %s/%s: %w",
-			in.Bridge, in.Namespace, in.Name, err)
+		return nil, result, fmt.Errorf("failed to trace flow on bridge %s, %s: %w",
+			in.Bridge, in.Target(), err)
+	}
+
+	// Correlate the complete trace to OVN before it is filtered
+	if in.Detrace {
+		result.Steps, err = s.detrace(ctx, req, in.PodTargetParams, lines)
+		if err != nil {
+			return nil, result, fmt.Errorf("failed to correlate the trace with the OVN databases of %s: %w",
+				in.Target(), err)
+		}
 	}
 
 	// Filter lines by pattern if provided
@@ -385,7 +440,7 @@ func (s *MCPServer) DumpOfprotoTrace(ctx context.Context, req *mcp.CallToolReque
 	}
 
 	// Limit to MaxLines if specified
-	lines = limitLines(lines, in.MaxLines)
+	lines = limitLines(ctx, lines, in.MaxLines)
 
 	// Join all lines into a single output string
 	result.Output = strings.Join(lines, "\n")
@@ -2,6 +2,8 @@ package types
 
 import (
 	k8stypes "github.com/ovn-kubernetes/ovn-kubernetes-mcp/pkg/kubernetes/types"
+	ovntypes "github.com/ovn-kubernetes/ovn-kubernetes-mcp/pkg/ovn/types"
+	"github.com/ovn-kubernetes/ovn-kubernetes-mcp/pkg/pagination"
 )
 
 // BridgeResult contains the list of OVS bridges found on a node.
@@ -10,8 +12,14 @@ type BridgeResult struct {
 }
 
 // ShowParams are the parameters for ovs-vsctl show command.
+type ListBridgesParams struct {
+	k8stypes.ClusterParams
+	k8stypes.PodTargetParams
+}
+
 type ShowParams struct {
-	k8stypes.NamespacedNameParams
+	k8stypes.ClusterParams
+	k8stypes.PodTargetParams
 	MaxLines int `json:"max_lines,omitempty"`
 }
 
@@ -30,10 +38,11 @@ type InterfaceResult struct {
 	Interfaces []string `json:"interfaces"`
 }
 
-// FlowsResult contains the OpenFlow flows from a specific OVS bridge.
+// FlowsResult contains a page of the OpenFlow flows from a specific OVS bridge.
 type FlowsResult struct {
 	Flows  []string `json:"flows"`
 	Bridge string   `json:"bridge"`
+	pagination.Result
 }
 
 // ConntrackResult contains connection tracking entries from the OVS datapath.
@@ -43,15 +52,22 @@ type ConntrackResult struct {
 
 // GetOVSCommandParams are the parameters for OVS related commands.
 type GetOVSCommandParams struct {
-	k8stypes.NamespacedNameParams
-	Bridge   string `json:"bridge"`
-	Filter   string `json:"filter,omitempty"`
-	MaxLines int    `json:"max_lines,omitempty"`
+	k8stypes.ClusterParams
+	k8stypes.PodTargetParams
+	Bridge string `json:"bridge"`
+}
+
+// DumpFlowsParams are the parameters for dump-flows command.
+type DumpFlowsParams struct {
+	GetOVSCommandParams
+	Filter string `json:"filter,omitempty"`
+	pagination.Params
 }
 
 // DumpConntrackParams are the parameters for dump-conntrack command.
 type DumpConntrackParams struct {
-	k8stypes.NamespacedNameParams
+	k8stypes.ClusterParams
+	k8stypes.PodTargetParams
 	Filter           string   `json:"filter,omitempty"`
 	MaxLines         int      `json:"max_lines,omitempty"`
 	AdditionalParams []string `json:"additional_params,omitempty"`
@@ -59,16 +75,42 @@ type DumpConntrackParams struct {
 
 // OfprotoTraceParams are the parameters for ofproto/trace command.
 type OfprotoTraceParams struct {
-	k8stypes.NamespacedNameParams
+	k8stypes.ClusterParams
+	k8stypes.PodTargetParams
 	Bridge   string `json:"bridge"`
 	Flow     string `json:"flow"`
 	Filter   string `json:"filter,omitempty"`
 	MaxLines int    `json:"max_lines,omitempty"`
+	// Detrace correlates the OpenFlow flows of the trace to the OVN logical flows,
+	// like ovn-detrace.
+	Detrace bool `json:"detrace,omitempty"`
 }
 
-// OfprotoTraceResult returns the complete trace output.
+// OfprotoTraceResult returns the complete trace output, and its steps correlated
+// to OVN with detrace.
 type OfprotoTraceResult struct {
-	Bridge string `json:"bridge"`
-	Flow   string `json:"flow"`
-	Output string `json:"output"`
+	Bridge string             `json:"bridge"`
+	Flow   string             `json:"flow"`
+	Output string             `json:"output"`
+	Steps  []OfprotoTraceStep `json:"steps,omitempty"`
+}
+
+// OfprotoTraceStep is an OpenFlow flow matched by the packet of a trace, with the
+// OVN records of its cookie: the Southbound logical flow and the Northbound
+// record it was generated for, or the Southbound record of the physical flows.
+type OfprotoTraceStep struct {
+	Table       int                   `json:"table"`
+	Flow        string                `json:"flow"`
+	Cookie      string                `json:"cookie,omitempty"`
+	LogicalFlow *ovntypes.LogicalFlow `json:"logical_flow,omitempty"`
+	Record      *OVNRecord            `json:"record,omitempty"`
+	Owner       *OVNRecord            `json:"owner,omitempty"`
+}
+
+// OVNRecord is a record of an OVN database table, described in one line.
+type OVNRecord struct {
+	Table       string `json:"table"`
+	UUID        string `json:"uuid"`
+	Name        string `json:"name,omitempty"`
+	Description string `json:"description"`
 }
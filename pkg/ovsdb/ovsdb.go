@@ -0,0 +1,351 @@
+// Package ovsdb queries the OVSDB databases, like the OVN Northbound and Southbound
+// databases, with the select operation of the JSON-RPC transact method. The tables,
+// columns and conditions are validated against the schema of the database, the
+// rows are returned as JSON objects, and the UUIDs referencing other tables are
+// resolved to the names of their rows.
+package ovsdb
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+)
+
+// Functions of the conditions.
+var functions = []string{"==", "!=", "<", "<=", ">", ">=", "includes", "excludes"}
+
+// orderFunctions are the functions that only apply to the required integer and
+// real columns.
+var orderFunctions = []string{"<", "<=", ">", ">="}
+
+// Condition is a condition of the rows of a select: the column, the function and
+// the value it is compared with.
+type Condition struct {
+	Column   string `json:"column"`
+	Function string `json:"function"`
+	Value    any    `json:"value"`
+}
+
+// Query is a select of the rows of a table matching all the conditions.
+type Query struct {
+	Table string
+	// Columns are the columns of the rows, all of them if empty. The rows always
+	// have their _uuid.
+	Columns []string
+	Where   []Condition
+	// KeepUUIDs keeps the UUIDs referencing the rows of other tables, rather than
+	// resolving them to the names of the rows.
+	KeepUUIDs bool
+}
+
+// Row is a row of a table, with the values of its columns.
+type Row map[string]any
+
+// String returns the value of a string or UUID column, empty if it is not set.
+func (r Row) String(column string) string {
+	s, _ := r[column].(string)
+	return s
+}
+
+// Strings returns the strings of a set column, or of an optional string column.
+func (r Row) Strings(column string) []string {
+	var values []string
+	switch value := r[column].(type) {
+	case []any:
+		for _, element := range value {
+			if s, ok := element.(string); ok {
+				values = append(values, s)
+			}
+		}
+	case string:
+		values = append(values, value)
+	}
+	return values
+}
+
+// Int returns the value of an integer column, 0 if it is not set.
+func (r Row) Int(column string) int64 {
+	i, _ := r[column].(int64)
+	return i
+}
+
+// Map returns the values of a map column as strings.
+func (r Row) Map(column string) map[string]string {
+	values := map[string]string{}
+	if value, ok := r[column].(map[string]any); ok {
+		for key, element := range value {
+			values[key] = fmt.Sprint(element)
+		}
+	}
+	return values
+}
+
+// Runner runs an ovsdb-client command with its arguments, and returns its
+// output.
+type Runner func(ctx context.Context, args ...string) (string, error)
+
+// Client queries a database of an OVSDB server with ovsdb-client.
+type Client struct {
+	run      Runner
+	server   string
+	database string
+}
+
+// NewClient creates a client of the database of the server, for example
+// "unix:/var/run/ovn/ovnnb_db.sock" and "OVN_Northbound", running ovsdb-client
+// with run.
+func NewClient(run Runner, server, database string) *Client {
+	return &Client{run: run, server: server, database: database}
+}
+
+// operation is a select operation of a transaction.
+type operation struct {
+	Op      string   `json:"op"`
+	Table   string   `json:"table"`
+	Where   [][]any  `json:"where"`
+	Columns []string `json:"columns,omitempty"`
+}
+
+// result is the result of an operation of a transaction.
+type result struct {
+	Rows    []map[string]any `json:"rows"`
+	Error   string           `json:"error,omitempty"`
+	Details string           `json:"details,omitempty"`
+}
+
+// Schema returns the schema of the database.
+func (c *Client) Schema(ctx context.Context) (*Schema, error) {
+	output, err := c.run(ctx, "get-schema", c.server, c.database)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the schema of %s: %w", c.database, err)
+	}
+	return ParseSchema([]byte(output))
+}
+
+// Select returns the rows of the query, sorted by name if the table has names and
+// by UUID otherwise. The query is validated against the schema.
+func (c *Client) Select(ctx context.Context, schema *Schema, query Query) ([]Row, error) {
+	table, err := schema.Table(query.Table)
+	if err != nil {
+		return nil, err
+	}
+	columns := []string{}
+	if len(query.Columns) > 0 {
+		columns = append(columns, "_uuid")
+		for _, column := range query.Columns {
+			if _, err := table.Column(column); err != nil {
+				return nil, fmt.Errorf("invalid column of table %s: %w", query.Table, err)
+			}
+			if !slices.Contains(columns, column) {
+				columns = append(columns, column)
+			}
+		}
+	}
+	where, err := encodeConditions(table, query.Where)
+	if err != nil {
+		return nil, fmt.Errorf("invalid condition of table %s: %w", query.Table, err)
+	}
+
+	results, err := c.transact(ctx, operation{Op: "select", Table: query.Table, Where: where, Columns: columns})
+	if err != nil {
+		return nil, err
+	}
+	rows := make([]Row, 0, len(results[0].Rows))
+	for _, wireRow := range results[0].Rows {
+		row := Row{}
+		for column, wire := range wireRow {
+			schema, err := table.Column(column)
+			if err != nil {
+				return nil, fmt.Errorf("invalid row of table %s: %w", query.Table, err)
+			}
+			if row[column], err = decodeValue(schema.Type, wire); err != nil {
+				return nil, fmt.Errorf("invalid value of column %s of table %s: %w", column, query.Table, err)
+			}
+		}
+		rows = append(rows, row)
+	}
+
+	names := map[Reference]string{}
+	if !query.KeepUUIDs {
+		if names, err = c.names(ctx, schema, rows); err != nil {
+			return nil, err
+		}
+	}
+	for _, row := range rows {
+		for column, value := range row {
+			row[column] = resolve(value, names)
+		}
+	}
+
+	sortColumn := table.NameColumn()
+	slices.SortStableFunc(rows, func(a, b Row) int {
+		if sortColumn != "" {
+			if c := strings.Compare(fmt.Sprint(a[sortColumn]), fmt.Sprint(b[sortColumn])); c != 0 {
+				return c
+			}
+		}
+		return strings.Compare(fmt.Sprint(a["_uuid"]), fmt.Sprint(b["_uuid"]))
+	})
+	return rows, nil
+}
+
+// encodeConditions converts the conditions to the wire format, after checking their
+// columns, functions and values against the schema of the table.
+func encodeConditions(table *TableSchema, conditions []Condition) ([][]any, error) {
+	where := [][]any{}
+	for _, condition := range conditions {
+		column, err := table.Column(condition.Column)
+		if err != nil {
+			return nil, err
+		}
+		if !slices.Contains(functions, condition.Function) {
+			return nil, fmt.Errorf("unknown function %q of column %s, functions: %s", condition.Function, condition.Column,
+				strings.Join(functions, ", "))
+		}
+		if slices.Contains(orderFunctions, condition.Function) &&
+			(!column.Type.IsScalar() || column.Type.Min != 1 || (column.Type.Key.Type != TypeInteger && column.Type.Key.Type != TypeReal)) {
+			return nil, fmt.Errorf("function %s only applies to required integer and real columns, not to column %s", condition.Function, condition.Column)
+		}
+		value, err := encodeValue(column.Type, condition.Value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid value of column %s: %w", condition.Column, err)
+		}
+		where = append(where, []any{condition.Column, condition.Function, value})
+	}
+	return where, nil
+}
+
+// names returns the names of the rows referenced by the rows, for the tables that
+// have names. The names of every referenced table are selected in a single
+// transaction.
+func (c *Client) names(ctx context.Context, schema *Schema, rows []Row) (map[Reference]string, error) {
+	referenced := map[string]string{}
+	for _, row := range rows {
+		for _, value := range row {
+			for _, reference := range references(value) {
+				if _, found := referenced[reference.Table]; found {
+					continue
+				}
+				if table, found := schema.Tables[reference.Table]; found {
+					referenced[reference.Table] = table.NameColumn()
+				}
+			}
+		}
+	}
+	var tables []string
+	var operations []operation
+	for _, table := range slices.Sorted(maps.Keys(referenced)) {
+		if referenced[table] == "" {
+			continue
+		}
+		tables = append(tables, table)
+		operations = append(operations, operation{Op: "select", Table: table, Where: [][]any{}, Columns: []string{"_uuid", referenced[table]}})
+	}
+	names := map[Reference]string{}
+	if len(operations) == 0 {
+		return names, nil
+	}
+
+	results, err := c.transact(ctx, operations...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve the references: %w", err)
+	}
+	for i, result := range results[:len(operations)] {
+		for _, row := range result.Rows {
+			uuid, err := decodeAtom(BaseType{Type: TypeUUID}, row["_uuid"])
+			if err != nil {
+				return nil, err
+			}
+			if name, ok := row[referenced[tables[i]]].(string); ok && name != "" {
+				names[Reference{Table: tables[i], UUID: uuid.(string)}] = name
+			}
+		}
+	}
+	return names, nil
+}
+
+// references returns the references of a value.
+func references(value any) []Reference {
+	switch v := value.(type) {
+	case Reference:
+		return []Reference{v}
+	case []any:
+		var refs []Reference
+		for _, element := range v {
+			refs = append(refs, references(element)...)
+		}
+		return refs
+	case map[string]any:
+		var refs []Reference
+		for _, element := range v {
+			refs = append(refs, references(element)...)
+		}
+		return refs
+	default:
+		return nil
+	}
+}
+
+// resolve replaces the references of a value by the names of their rows, or by
+// their UUIDs if the rows have no names.
+func resolve(value any, names map[Reference]string) any {
+	switch v := value.(type) {
+	case Reference:
+		if name, found := names[v]; found {
+			return name
+		}
+		return v.UUID
+	case []any:
+		for i, element := range v {
+			v[i] = resolve(element, names)
+		}
+		return v
+	case map[string]any:
+		for key, element := range v {
+			v[key] = resolve(element, names)
+		}
+		return v
+	default:
+		return v
+	}
+}
+
+// transact runs the operations in a transaction, and returns their results. It
+// returns an error if an operation failed.
+func (c *Client) transact(ctx context.Context, operations ...operation) ([]result, error) {
+	transaction := []any{c.database}
+	for _, operation := range operations {
+		transaction = append(transaction, operation)
+	}
+	// The transaction is a command argument, recorded in the audit log: the
+	// functions are not escaped.
+	var data strings.Builder
+	encoder := json.NewEncoder(&data)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(transaction); err != nil {
+		return nil, err
+	}
+	output, err := c.run(ctx, "transact", c.server, strings.TrimSuffix(data.String(), "\n"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query %s: %w", c.database, err)
+	}
+
+	decoder := json.NewDecoder(strings.NewReader(output))
+	decoder.UseNumber()
+	var results []result
+	if err := decoder.Decode(&results); err != nil {
+		return nil, fmt.Errorf("failed to parse the result of the query of %s: %w", c.database, err)
+	}
+	if len(results) < len(operations) {
+		return nil, fmt.Errorf("invalid result of the query of %s: %d results for %d operations", c.database, len(results), len(operations))
+	}
+	for _, result := range results {
+		if result.Error != "" {
+			return nil, fmt.Errorf("query of %s failed: %s: %s", c.database, result.Error, result.Details)
+		}
+	}
+	return results, nil
+}
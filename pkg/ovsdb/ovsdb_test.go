@@ -0,0 +1,222 @@
+package ovsdb
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// testSchema is a subset of the OVN Northbound schema.
+const testSchema = `{
+  "name": "OVN_Northbound",
+  "version": "7.3.0",
+  "tables": {
+    "Logical_Switch": {
+      "columns": {
+        "name": {"type": "string"},
+        "ports": {"type": {"key": {"type": "uuid", "refTable": "Logical_Switch_Port", "refType": "strong"}, "min": 0, "max": "unlimited"}},
+        "acls": {"type": {"key": {"type": "uuid", "refTable": "ACL", "refType": "strong"}, "min": 0, "max": "unlimited"}},
+        "other_config": {"type": {"key": "string", "value": "string", "min": 0, "max": "unlimited"}}
+      },
+      "isRoot": true
+    },
+    "Logical_Switch_Port": {
+      "columns": {
+        "name": {"type": "string"},
+        "addresses": {"type": {"key": "string", "min": 0, "max": "unlimited"}},
+        "enabled": {"type": {"key": "boolean", "min": 0, "max": 1}},
+        "tag": {"type": {"key": {"type": "integer", "minInteger": 1, "maxInteger": 4095}, "min": 0, "max": 1}}
+      },
+      "indexes": [["name"]]
+    },
+    "ACL": {
+      "columns": {
+        "priority": {"type": {"key": {"type": "integer", "minInteger": 0, "maxInteger": 32767}}},
+        "match": {"type": "string"},
+        "action": {"type": {"key": {"type": "string", "enum": ["set", ["allow", "drop"]]}}}
+      }
+    }
+  }
+}`
+
+const (
+	switchUUID = "4c4a0a35-348c-41cc-8417-53a618e0c383"
+	port1UUID  = "11111111-348c-41cc-8417-53a618e0c383"
+	port2UUID  = "22222222-348c-41cc-8417-53a618e0c383"
+	aclUUID    = "33333333-348c-41cc-8417-53a618e0c383"
+)
+
+// fakeServer answers the get-schema and transact commands, and records the
+// transactions.
+type fakeServer struct {
+	results      map[string]string
+	transactions []string
+}
+
+func (f *fakeServer) run(ctx context.Context, args ...string) (string, error) {
+	switch args[0] {
+	case "get-schema":
+		return testSchema, nil
+	case "transact":
+		f.transactions = append(f.transactions, args[2])
+		var transaction []json.RawMessage
+		if err := json.Unmarshal([]byte(args[2]), &transaction); err != nil {
+			return "", err
+		}
+		var results []string
+		for _, operation := range transaction[1:] {
+			var op struct {
+				Table string `json:"table"`
+			}
+			if err := json.Unmarshal(operation, &op); err != nil {
+				return "", err
+			}
+			results = append(results, f.results[op.Table])
+		}
+		return "[" + strings.Join(results, ",") + "]", nil
+	}
+	return "", errors.New("unknown command")
+}
+
+func newFakeServer() *fakeServer {
+	return &fakeServer{results: map[string]string{
+		"Logical_Switch": `{"rows":[{"_uuid":["uuid","` + switchUUID + `"],"name":"worker-0",` +
+			`"ports":["set",[["uuid","` + port1UUID + `"],["uuid","` + port2UUID + `"]]],"acls":["uuid","` + aclUUID + `"],` +
+			`"other_config":["map",[["subnet","10.244.0.0/24"]]]}]}`,
+		"Logical_Switch_Port": `{"rows":[{"_uuid":["uuid","` + port2UUID + `"],"name":"default_server"},` +
+			`{"_uuid":["uuid","` + port1UUID + `"],"name":"default_client","addresses":"0a:58:0a:f4:00:05 10.244.0.5",` +
+			`"enabled":["set",[]],"tag":12}]}`,
+		"ACL": `{"rows":[{"_uuid":["uuid","` + aclUUID + `"],"priority":1001,"match":"ip4","action":"allow"}]}`,
+	}}
+}
+
+func TestSelect(t *testing.T) {
+	tests := []struct {
+		name            string
+		query           Query
+		wantRows        []Row
+		wantTransaction string
+		wantErr         string
+	}{
+		{
+			name:  "references resolved",
+			query: Query{Table: "Logical_Switch", Where: []Condition{{Column: "name", Function: "==", Value: "worker-0"}}},
+			wantRows: []Row{{
+				"_uuid": switchUUID, "name": "worker-0", "ports": []any{"default_client", "default_server"},
+				"acls": []any{aclUUID}, "other_config": map[string]any{"subnet": "10.244.0.0/24"},
+			}},
+			wantTransaction: `["OVN_Northbound",{"op":"select","table":"Logical_Switch","where":[["name","==","worker-0"]]}]`,
+		},
+		{
+			name:  "references kept",
+			query: Query{Table: "Logical_Switch", Columns: []string{"ports"}, KeepUUIDs: true},
+			wantRows: []Row{{
+				"_uuid": switchUUID, "name": "worker-0", "ports": []any{port1UUID, port2UUID},
+				"acls": []any{aclUUID}, "other_config": map[string]any{"subnet": "10.244.0.0/24"},
+			}},
+			wantTransaction: `["OVN_Northbound",{"op":"select","table":"Logical_Switch","where":[],"columns":["_uuid","ports"]}]`,
+		},
+		{
+			name: "typed values sorted by name",
+			query: Query{Table: "Logical_Switch_Port", Where: []Condition{
+				{Column: "addresses", Function: "includes", Value: "0a:58:0a:f4:00:05 10.244.0.5"},
+				{Column: "enabled", Function: "!=", Value: false},
+			}},
+			wantRows: []Row{
+				{"_uuid": port1UUID, "name": "default_client", "addresses": []any{"0a:58:0a:f4:00:05 10.244.0.5"}, "enabled": nil, "tag": int64(12)},
+				{"_uuid": port2UUID, "name": "default_server"},
+			},
+			wantTransaction: `["OVN_Northbound",{"op":"select","table":"Logical_Switch_Port","where":[` +
+				`["addresses","includes",["set",["0a:58:0a:f4:00:05 10.244.0.5"]]],["enabled","!=",["set",[false]]]]}]`,
+		},
+		{
+			name:            "map and uuid conditions",
+			query:           Query{Table: "Logical_Switch", Where: []Condition{{Column: "other_config", Function: "includes", Value: map[string]any{"subnet": "10.244.0.0/24"}}, {Column: "_uuid", Function: "==", Value: switchUUID}}, KeepUUIDs: true},
+			wantRows:        []Row{{"_uuid": switchUUID, "name": "worker-0", "ports": []any{port1UUID, port2UUID}, "acls": []any{aclUUID}, "other_config": map[string]any{"subnet": "10.244.0.0/24"}}},
+			wantTransaction: `["OVN_Northbound",{"op":"select","table":"Logical_Switch","where":[["other_config","includes",["map",[["subnet","10.244.0.0/24"]]]],["_uuid","==",["uuid","` + switchUUID + `"]]]}]`,
+		},
+		{
+			name:            "order function",
+			query:           Query{Table: "ACL", Columns: []string{"priority", "action"}, Where: []Condition{{Column: "priority", Function: ">=", Value: float64(1000)}}},
+			wantRows:        []Row{{"_uuid": aclUUID, "priority": int64(1001), "match": "ip4", "action": "allow"}},
+			wantTransaction: `["OVN_Northbound",{"op":"select","table":"ACL","where":[["priority",">=",1000]],"columns":["_uuid","priority","action"]}]`,
+		},
+		{name: "unknown table", query: Query{Table: "Logical_Swich"}, wantErr: `unknown table "Logical_Swich" of database OVN_Northbound, tables: ACL, Logical_Switch, Logical_Switch_Port`},
+		{name: "unknown column", query: Query{Table: "ACL", Columns: []string{"prio"}}, wantErr: `invalid column of table ACL: unknown column "prio", columns: action, match, priority`},
+		{name: "unknown function", query: Query{Table: "ACL", Where: []Condition{{Column: "priority", Function: "=", Value: 1}}}, wantErr: `unknown function "="`},
+		{name: "order function of string", query: Query{Table: "ACL", Where: []Condition{{Column: "match", Function: ">", Value: "ip4"}}}, wantErr: "function > only applies to required integer and real columns"},
+		{name: "order function of optional", query: Query{Table: "Logical_Switch_Port", Where: []Condition{{Column: "tag", Function: "<", Value: float64(100)}}}, wantErr: "not to column tag"},
+		{name: "invalid integer", query: Query{Table: "ACL", Where: []Condition{{Column: "priority", Function: "==", Value: 1.5}}}, wantErr: "invalid value of column priority: value 1.5 is not a valid integer"},
+		{name: "invalid uuid", query: Query{Table: "Logical_Switch", Where: []Condition{{Column: "ports", Function: "includes", Value: "default_client"}}}, wantErr: "value default_client is not a valid uuid"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			server := newFakeServer()
+			client := NewClient(server.run, "unix:/var/run/ovn/ovnnb_db.sock", "OVN_Northbound")
+			schema, err := client.Schema(context.Background())
+			if err != nil {
+				t.Fatalf("Unexpected schema error: %v", err)
+			}
+			rows, err := client.Select(context.Background(), schema, test.query)
+			if test.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), test.wantErr) {
+					t.Fatalf("Expected error %q, got %v", test.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(rows, test.wantRows) {
+				t.Fatalf("Expected rows %#v, got %#v", test.wantRows, rows)
+			}
+			if server.transactions[0] != test.wantTransaction {
+				t.Fatalf("Expected transaction %s, got %s", test.wantTransaction, server.transactions[0])
+			}
+		})
+	}
+}
+
+func TestSelectError(t *testing.T) {
+	server := newFakeServer()
+	server.results["ACL"] = `{"error":"permission error","details":"no access"}`
+	client := NewClient(server.run, "unix:/var/run/ovn/ovnnb_db.sock", "OVN_Northbound")
+	schema, err := client.Schema(context.Background())
+	if err != nil {
+		t.Fatalf("Unexpected schema error: %v", err)
+	}
+	_, err = client.Select(context.Background(), schema, Query{Table: "ACL"})
+	if err == nil || err.Error() != "query of OVN_Northbound failed: permission error: no access" {
+		t.Fatalf("Unexpected error %v", err)
+	}
+}
+
+func TestRow(t *testing.T) {
+	row := Row{
+		"_uuid": switchUUID, "name": "worker-0", "ports": []any{"default_client", "default_server"}, "tag": int64(12),
+		"external_ids": map[string]any{"network": "default", "k8s.ovn.org/id": int64(3)},
+	}
+	if name := row.String("name"); name != "worker-0" {
+		t.Fatalf("Expected name worker-0, got %q", name)
+	}
+	if ports := row.Strings("ports"); !reflect.DeepEqual(ports, []string{"default_client", "default_server"}) {
+		t.Fatalf("Unexpected ports %v", ports)
+	}
+	if names := row.Strings("name"); !reflect.DeepEqual(names, []string{"worker-0"}) {
+		t.Fatalf("Expected the optional string as a set, got %v", names)
+	}
+	if tag := row.Int("tag"); tag != 12 {
+		t.Fatalf("Expected tag 12, got %d", tag)
+	}
+	want := map[string]string{"network": "default", "k8s.ovn.org/id": "3"}
+	if ids := row.Map("external_ids"); !reflect.DeepEqual(ids, want) {
+		t.Fatalf("Expected external IDs %v, got %v", want, ids)
+	}
+	// Unset columns have zero values.
+	if row.String("acls") != "" || row.Strings("acls") != nil || row.Int("acls") != 0 || len(row.Map("acls")) != 0 {
+		t.Fatal("Expected zero values for an unset column")
+	}
+}
@@ -0,0 +1,48 @@
+package ovsdb
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/ovn-kubernetes/ovn-kubernetes-mcp/pkg/executor"
+	k8stypes "github.com/ovn-kubernetes/ovn-kubernetes-mcp/pkg/kubernetes/types"
+)
+
+// Database is an OVN database, with the socket of its server and the command
+// connecting to it.
+type Database struct {
+	// Name is the name of the database in its schema.
+	Name string
+	// Server is the socket of the server of the database.
+	Server string
+	// Command is the command connecting to the database, which runs in the
+	// containers that can reach the socket.
+	Command string
+}
+
+var (
+	// Northbound is the OVN Northbound database.
+	Northbound = Database{Name: "OVN_Northbound", Server: "unix:/var/run/ovn/ovnnb_db.sock", Command: "ovn-nbctl"}
+	// Southbound is the OVN Southbound database.
+	Southbound = Database{Name: "OVN_Southbound", Server: "unix:/var/run/ovn/ovnsb_db.sock", Command: "ovn-sbctl"}
+)
+
+// NewPodClient creates a client of the database running ovsdb-client in the pod
+// and container of exec, usually the ones running the command of the database, as
+// part of the tool call req.
+func NewPodClient(e executor.Executor, req *mcp.CallToolRequest, exec k8stypes.ExecPodParams, database Database) *Client {
+	run := func(ctx context.Context, args ...string) (string, error) {
+		exec.Command = append([]string{"ovsdb-client"}, args...)
+		_, result, err := e.ExecPod(ctx, req, exec)
+		if err != nil {
+			return "", err
+		}
+		if result.Stderr != "" {
+			return "", fmt.Errorf("error occurred while running command %v on pod %s/%s: %s", exec.Command, exec.Namespace,
+				exec.Name, result.Stderr)
+		}
+		return result.Stdout, nil
+	}
+	return NewClient(run, database.Server, database.Name)
+}
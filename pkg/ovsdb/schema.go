@@ -0,0 +1,176 @@
+package ovsdb
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+)
+
+// Atomic types of the OVSDB columns.
+const (
+	TypeInteger = "integer"
+	TypeReal    = "real"
+	TypeBoolean = "boolean"
+	TypeString  = "string"
+	TypeUUID    = "uuid"
+)
+
+// Schema is the schema of an OVSDB database, as returned by get-schema.
+type Schema struct {
+	Name    string                 `json:"name"`
+	Version string                 `json:"version"`
+	Tables  map[string]TableSchema `json:"tables"`
+}
+
+// TableSchema is the schema of a table.
+type TableSchema struct {
+	Columns map[string]ColumnSchema `json:"columns"`
+	Indexes [][]string              `json:"indexes,omitempty"`
+	IsRoot  bool                    `json:"isRoot,omitempty"`
+}
+
+// ColumnSchema is the schema of a column.
+type ColumnSchema struct {
+	Type ColumnType `json:"type"`
+}
+
+// ColumnType is the type of a column: an atom, an optional atom, a set of atoms
+// or a map, of min to max elements.
+type ColumnType struct {
+	Key   BaseType
+	Value *BaseType
+	Min   int
+	// Max is the maximum number of elements, -1 if unlimited.
+	Max int
+}
+
+// BaseType is the type of the atoms of a column.
+type BaseType struct {
+	Type string
+	// RefTable is the table referenced by a UUID.
+	RefTable string
+}
+
+// uuidColumns are the columns every table has.
+var uuidColumns = map[string]ColumnSchema{
+	"_uuid":    {Type: ColumnType{Key: BaseType{Type: TypeUUID}, Min: 1, Max: 1}},
+	"_version": {Type: ColumnType{Key: BaseType{Type: TypeUUID}, Min: 1, Max: 1}},
+}
+
+// UnmarshalJSON parses a column type, either the name of an atomic type or an
+// object with the key and value types and the number of elements.
+func (t *ColumnType) UnmarshalJSON(data []byte) error {
+	var atomic string
+	if err := json.Unmarshal(data, &atomic); err == nil {
+		*t = ColumnType{Key: BaseType{Type: atomic}, Min: 1, Max: 1}
+		return nil
+	}
+	var object struct {
+		Key   BaseType        `json:"key"`
+		Value *BaseType       `json:"value"`
+		Min   *int            `json:"min"`
+		Max   json.RawMessage `json:"max"`
+	}
+	if err := json.Unmarshal(data, &object); err != nil {
+		return fmt.Errorf("invalid column type %s: %w", data, err)
+	}
+	*t = ColumnType{Key: object.Key, Value: object.Value, Min: 1, Max: 1}
+	if object.Min != nil {
+		t.Min = *object.Min
+	}
+	switch max := string(object.Max); max {
+	case "":
+	case `"unlimited"`:
+		t.Max = -1
+	default:
+		if err := json.Unmarshal(object.Max, &t.Max); err != nil {
+			return fmt.Errorf("invalid max %s of column type: %w", max, err)
+		}
+	}
+	return nil
+}
+
+// UnmarshalJSON parses a base type, either the name of an atomic type or an object
+// with the type and its constraints.
+func (b *BaseType) UnmarshalJSON(data []byte) error {
+	var atomic string
+	if err := json.Unmarshal(data, &atomic); err == nil {
+		*b = BaseType{Type: atomic}
+		return nil
+	}
+	var object struct {
+		Type     string `json:"type"`
+		RefTable string `json:"refTable"`
+	}
+	if err := json.Unmarshal(data, &object); err != nil {
+		return fmt.Errorf("invalid base type %s: %w", data, err)
+	}
+	*b = BaseType{Type: object.Type, RefTable: object.RefTable}
+	return nil
+}
+
+// IsMap returns whether the column is a map.
+func (t ColumnType) IsMap() bool {
+	return t.Value != nil
+}
+
+// IsScalar returns whether the column holds at most one atom, which is returned
+// as is rather than in a set.
+func (t ColumnType) IsScalar() bool {
+	return !t.IsMap() && t.Max == 1
+}
+
+// ParseSchema parses the JSON schema of a database.
+func ParseSchema(data []byte) (*Schema, error) {
+	var schema Schema
+	if err := json.Unmarshal(data, &schema); err != nil {
+		return nil, fmt.Errorf("failed to parse the database schema: %w", err)
+	}
+	if schema.Name == "" || len(schema.Tables) == 0 {
+		return nil, fmt.Errorf("invalid database schema: no name or tables")
+	}
+	return &schema, nil
+}
+
+// Table returns the schema of a table, or an error listing the tables of the
+// database if it does not exist.
+func (s *Schema) Table(name string) (*TableSchema, error) {
+	table, found := s.Tables[name]
+	if !found {
+		return nil, fmt.Errorf("unknown table %q of database %s, tables: %s", name, s.Name, strings.Join(slices.Sorted(maps.Keys(s.Tables)), ", "))
+	}
+	return &table, nil
+}
+
+// Column returns the schema of a column of the table, or an error listing the
+// columns of the table if it does not exist.
+func (t *TableSchema) Column(name string) (ColumnSchema, error) {
+	if column, found := uuidColumns[name]; found {
+		return column, nil
+	}
+	column, found := t.Columns[name]
+	if !found {
+		return ColumnSchema{}, fmt.Errorf("unknown column %q, columns: %s", name, strings.Join(slices.Sorted(maps.Keys(t.Columns)), ", "))
+	}
+	return column, nil
+}
+
+// NameColumn returns the column naming the rows of the table: its name column, or
+// its first index of a single string column. It returns an empty string if the
+// rows have no name.
+func (t *TableSchema) NameColumn() string {
+	if column, found := t.Columns["name"]; found && column.Type.IsScalar() && column.Type.Key.Type == TypeString {
+		return "name"
+	}
+	for _, index := range t.Indexes {
+		if len(index) != 1 {
+			continue
+		}
+		if column, found := t.Columns[index[0]]; found && column.Type.IsScalar() && column.Type.Key.Type == TypeString {
+			return index[0]
+		}
+	}
+	return ""
+}
@@ -0,0 +1,211 @@
+package ovsdb
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"math"
+	"regexp"
+	"slices"
+	"strconv"
+)
+
+// uuidPattern matches the UUIDs of the rows.
+var uuidPattern = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+// Reference is a UUID referencing a row of another table, resolved to the name of
+// the row if the table has names.
+type Reference struct {
+	Table string
+	UUID  string
+}
+
+// decodeValue converts a value of the OVSDB wire format to a JSON value: an atom,
+// or null if it is not set, for the scalar columns, an array for the sets and an
+// object for the maps. The UUIDs are strings, or references if they reference a
+// table.
+func decodeValue(t ColumnType, wire any) (any, error) {
+	kind, elements, err := wireElements(wire)
+	if err != nil {
+		return nil, err
+	}
+	if t.IsMap() {
+		if kind != "map" {
+			return nil, fmt.Errorf("invalid map %v", wire)
+		}
+		value := map[string]any{}
+		for _, element := range elements {
+			pair, ok := element.([]any)
+			if !ok || len(pair) != 2 {
+				return nil, fmt.Errorf("invalid map pair %v", element)
+			}
+			key, err := decodeAtom(t.Key, pair[0])
+			if err != nil {
+				return nil, err
+			}
+			if value[fmt.Sprint(key)], err = decodeAtom(*t.Value, pair[1]); err != nil {
+				return nil, err
+			}
+		}
+		return value, nil
+	}
+
+	atoms := make([]any, 0, len(elements))
+	for _, element := range elements {
+		atom, err := decodeAtom(t.Key, element)
+		if err != nil {
+			return nil, err
+		}
+		atoms = append(atoms, atom)
+	}
+	if !t.IsScalar() {
+		return atoms, nil
+	}
+	if len(atoms) == 0 {
+		return nil, nil
+	}
+	return atoms[0], nil
+}
+
+// wireElements returns the kind of a value of the wire format, set or map, and its
+// elements. An atom is a set of one element.
+func wireElements(wire any) (string, []any, error) {
+	array, ok := wire.([]any)
+	if !ok || len(array) != 2 {
+		return "set", []any{wire}, nil
+	}
+	switch array[0] {
+	case "set", "map":
+		elements, ok := array[1].([]any)
+		if !ok {
+			return "", nil, fmt.Errorf("invalid %s %v", array[0], wire)
+		}
+		return array[0].(string), elements, nil
+	default:
+		return "set", []any{wire}, nil
+	}
+}
+
+// decodeAtom converts an atom of the wire format to a JSON value.
+func decodeAtom(b BaseType, wire any) (any, error) {
+	switch b.Type {
+	case TypeUUID:
+		array, ok := wire.([]any)
+		if !ok || len(array) != 2 || (array[0] != "uuid" && array[0] != "named-uuid") {
+			return nil, fmt.Errorf("invalid uuid %v", wire)
+		}
+		uuid, ok := array[1].(string)
+		if !ok {
+			return nil, fmt.Errorf("invalid uuid %v", wire)
+		}
+		if b.RefTable != "" {
+			return Reference{Table: b.RefTable, UUID: uuid}, nil
+		}
+		return uuid, nil
+	case TypeInteger:
+		number, ok := wire.(json.Number)
+		if !ok {
+			return nil, fmt.Errorf("invalid integer %v", wire)
+		}
+		return number.Int64()
+	case TypeReal:
+		number, ok := wire.(json.Number)
+		if !ok {
+			return nil, fmt.Errorf("invalid real %v", wire)
+		}
+		return number.Float64()
+	default:
+		return wire, nil
+	}
+}
+
+// encodeValue converts a JSON value of a condition to the wire format of a column:
+// an atom, an array of atoms for a set, or an object for a map.
+func encodeValue(t ColumnType, value any) (any, error) {
+	if t.IsMap() {
+		object, ok := value.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("value %v must be an object", value)
+		}
+		pairs := []any{}
+		for _, key := range slices.Sorted(maps.Keys(object)) {
+			element := object[key]
+			encodedKey, err := encodeAtom(t.Key, key)
+			if err != nil {
+				return nil, err
+			}
+			encodedValue, err := encodeAtom(*t.Value, element)
+			if err != nil {
+				return nil, err
+			}
+			pairs = append(pairs, []any{encodedKey, encodedValue})
+		}
+		return []any{"map", pairs}, nil
+	}
+
+	elements, isArray := value.([]any)
+	if !isArray {
+		if t.IsScalar() && t.Min == 1 {
+			return encodeAtom(t.Key, value)
+		}
+		elements = []any{value}
+		if value == nil {
+			elements = []any{}
+		}
+	}
+	if t.Max != -1 && len(elements) > t.Max {
+		return nil, fmt.Errorf("value %v has more than %d elements", value, t.Max)
+	}
+	atoms := make([]any, 0, len(elements))
+	for _, element := range elements {
+		atom, err := encodeAtom(t.Key, element)
+		if err != nil {
+			return nil, err
+		}
+		atoms = append(atoms, atom)
+	}
+	return []any{"set", atoms}, nil
+}
+
+// encodeAtom converts a JSON value to an atom of the wire format. The strings of
+// the numbers and booleans are accepted too.
+func encodeAtom(b BaseType, value any) (any, error) {
+	switch b.Type {
+	case TypeString:
+		if s, ok := value.(string); ok {
+			return s, nil
+		}
+	case TypeInteger:
+		switch v := value.(type) {
+		case float64:
+			if v == math.Trunc(v) {
+				return int64(v), nil
+			}
+		case json.Number:
+			return v.Int64()
+		case string:
+			return strconv.ParseInt(v, 10, 64)
+		}
+	case TypeReal:
+		switch v := value.(type) {
+		case float64:
+			return v, nil
+		case json.Number:
+			return v.Float64()
+		case string:
+			return strconv.ParseFloat(v, 64)
+		}
+	case TypeBoolean:
+		switch v := value.(type) {
+		case bool:
+			return v, nil
+		case string:
+			return strconv.ParseBool(v)
+		}
+	case TypeUUID:
+		if s, ok := value.(string); ok && uuidPattern.MatchString(s) {
+			return []any{"uuid", s}, nil
+		}
+	}
+	return nil, fmt.Errorf("value %v is not a valid %s", value, b.Type)
+}
@@ -0,0 +1,116 @@
+// Package pagination implements the cursor pagination of the list tools: a client
+// sets page_size, and passes the next_cursor of a page as the cursor of the next
+// call to get the next page, until next_cursor is empty.
+package pagination
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"strconv"
+)
+
+const (
+	// DefaultPageSize and MaxPageSize are the default and maximum number of items
+	// of a page.
+	DefaultPageSize = 100
+	MaxPageSize     = 1000
+)
+
+// Params are the pagination parameters of a list tool.
+type Params struct {
+	PageSize int    `json:"page_size,omitempty"`
+	Cursor   string `json:"cursor,omitempty"`
+}
+
+// Result is the pagination of a list tool result.
+type Result struct {
+	// NextCursor is the cursor of the next page, empty on the last page.
+	NextCursor string `json:"next_cursor,omitempty"`
+	// Total is the number of items of all the pages, unset if unknown.
+	Total *int `json:"total,omitempty"`
+}
+
+// Cursor is the position of a page in a list.
+type Cursor struct {
+	// Offset is the number of items of the previous pages.
+	Offset int `json:"o,omitempty"`
+	// Continue is the continue token of a Kubernetes list.
+	Continue string `json:"c,omitempty"`
+	// Query identifies the parameters of the list, so that a cursor cannot be
+	// used with another list.
+	Query string `json:"q"`
+}
+
+// Size returns the page size, or an error if it is out of range.
+func (p Params) Size() (int, error) {
+	switch {
+	case p.PageSize == 0:
+		return DefaultPageSize, nil
+	case p.PageSize < 0 || p.PageSize > MaxPageSize:
+		return 0, fmt.Errorf("invalid page_size %d: must be between 1 and %d", p.PageSize, MaxPageSize)
+	default:
+		return p.PageSize, nil
+	}
+}
+
+// Decode returns the cursor of the page, the first page if no cursor is set. The
+// query identifies the list parameters, see Encode.
+func (p Params) Decode(query ...any) (Cursor, error) {
+	if p.Cursor == "" {
+		return Cursor{}, nil
+	}
+	var c Cursor
+	data, err := base64.RawURLEncoding.DecodeString(p.Cursor)
+	if err == nil {
+		err = json.Unmarshal(data, &c)
+	}
+	if err != nil || c.Offset < 0 {
+		return Cursor{}, fmt.Errorf("invalid cursor: pass the next_cursor of the previous page unchanged")
+	}
+	if c.Query != hashQuery(query) {
+		return Cursor{}, fmt.Errorf("invalid cursor: it belongs to a list with other parameters, call the tool with the same parameters as the previous page")
+	}
+	return c, nil
+}
+
+// Encode returns the opaque cursor of a page. The query contains the parameters of
+// the list other than the pagination, which must not change between pages.
+func Encode(c Cursor, query ...any) string {
+	c.Query = hashQuery(query)
+	data, err := json.Marshal(c)
+	if err != nil {
+		// A cursor only contains strings and numbers.
+		panic(err)
+	}
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// hashQuery returns a short hash of the list parameters.
+func hashQuery(query []any) string {
+	h := fnv.New64a()
+	fmt.Fprintf(h, "%#v", query)
+	return strconv.FormatUint(h.Sum64(), 36)
+}
+
+// Paginate returns the page of items selected by the pagination parameters, and
+// the pagination of the result.
+func Paginate[T any](items []T, p Params, query ...any) ([]T, Result, error) {
+	size, err := p.Size()
+	if err != nil {
+		return nil, Result{}, err
+	}
+	c, err := p.Decode(query...)
+	if err != nil {
+		return nil, Result{}, err
+	}
+	total := len(items)
+	result := Result{Total: &total}
+	start := min(c.Offset, total)
+	end := min(start+size, total)
+	if end < total {
+		result.NextCursor = Encode(Cursor{Offset: end}, query...)
+	}
+	return items[start:end], result, nil
+}
@@ -0,0 +1,64 @@
+package pagination
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestPaginate(t *testing.T) {
+	items := []int{1, 2, 3, 4, 5}
+	var pages [][]int
+	p := Params{PageSize: 2}
+	for {
+		page, result, err := Paginate(items, p, "br-int", "table=0")
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if result.Total == nil || *result.Total != len(items) {
+			t.Fatalf("Expected a total of %d, got %v", len(items), result.Total)
+		}
+		pages = append(pages, page)
+		if result.NextCursor == "" {
+			break
+		}
+		p.Cursor = result.NextCursor
+	}
+	if want := [][]int{{1, 2}, {3, 4}, {5}}; !reflect.DeepEqual(pages, want) {
+		t.Fatalf("Unexpected pages %v, want %v", pages, want)
+	}
+}
+
+func TestPaginateErrors(t *testing.T) {
+	cursor := Encode(Cursor{Offset: 2}, "br-int", "")
+	tests := []struct {
+		name   string
+		params Params
+	}{
+		{"negative page size", Params{PageSize: -1}},
+		{"page size too large", Params{PageSize: MaxPageSize + 1}},
+		{"malformed cursor", Params{Cursor: "not a cursor"}},
+		{"cursor of another query", Params{Cursor: cursor}},
+		{"negative offset", Params{Cursor: Encode(Cursor{Offset: -1}, "br-int", "table=0")}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if _, _, err := Paginate([]int{1, 2, 3}, test.params, "br-int", "table=0"); err == nil {
+				t.Fatal("Expected an error")
+			}
+		})
+	}
+}
+
+func TestDecode(t *testing.T) {
+	want := Cursor{Offset: 500, Continue: "eyJ2IjoibWV0YS5rOHMuaW8vdjEifQ"}
+	c, err := Params{Cursor: Encode(want, "v1", "Pod")}.Decode("v1", "Pod")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if c.Offset != want.Offset || c.Continue != want.Continue {
+		t.Fatalf("Unexpected cursor %+v, want %+v", c, want)
+	}
+	if c, err := (Params{}).Decode("v1", "Pod"); err != nil || c.Offset != 0 || c.Continue != "" {
+		t.Fatalf("Expected the first page without cursor, got %+v, %v", c, err)
+	}
+}
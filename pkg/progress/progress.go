@@ -0,0 +1,179 @@
+// Package progress sends MCP progress notifications for the tool call of a context,
+// when the client asked for them with a progress token.
+package progress
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// minOutputInterval is the minimum interval between two notifications counting
+// command output, so that fast captures do not flood the client.
+const minOutputInterval = 500 * time.Millisecond
+
+// Notifier sends a progress notification to the client.
+type Notifier interface {
+	NotifyProgress(ctx context.Context, params *mcp.ProgressNotificationParams) error
+}
+
+// reporter sends the progress notifications of a tool call. The progress increases
+// by one on every stage, and by the number of counted lines while an output is
+// counted.
+type reporter struct {
+	notifier Notifier
+	token    any
+
+	mu       sync.Mutex
+	progress float64
+	message  string
+}
+
+type reporterKey struct{}
+
+// WithReporter returns a context sending the progress of the tool call to the
+// client with the progress token. Without a token, the context is returned unchanged
+// and progress reports are dropped.
+func WithReporter(ctx context.Context, notifier Notifier, token any) context.Context {
+	if notifier == nil || token == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, reporterKey{}, &reporter{notifier: notifier, token: token})
+}
+
+// Enabled returns true if the client asked for the progress of the tool call of the
+// context, so that callers can skip collecting information only used for progress.
+func Enabled(ctx context.Context) bool {
+	_, ok := ctx.Value(reporterKey{}).(*reporter)
+	return ok
+}
+
+// Report sends a progress notification for a new stage of the tool call of the
+// context. Consecutive reports with the same message are sent once.
+func Report(ctx context.Context, format string, args ...any) {
+	r, ok := ctx.Value(reporterKey{}).(*reporter)
+	if !ok {
+		return
+	}
+	message := fmt.Sprintf(format, args...)
+	r.mu.Lock()
+	if message == r.message {
+		r.mu.Unlock()
+		return
+	}
+	r.progress++
+	r.message = message
+	progress := r.progress
+	r.mu.Unlock()
+	r.notify(ctx, progress, message)
+}
+
+// notify sends a notification, ignoring the errors: a client that went away
+// cancels the tool call anyway.
+func (r *reporter) notify(ctx context.Context, progress float64, message string) {
+	_ = r.notifier.NotifyProgress(ctx, &mcp.ProgressNotificationParams{
+		ProgressToken: r.token,
+		Progress:      progress,
+		Message:       message,
+	})
+}
+
+type lineCounterKey struct{}
+
+// lineCounter describes how to count the lines of a command output.
+type lineCounter struct {
+	unit  string
+	match func(line []byte) bool
+}
+
+// WithLineCounter returns a context in which the stdout of the commands wrapped with
+// LineWriter is counted, one for each line accepted by match (all lines if nil),
+// and reported as "<count> <unit>", for example "12 packets captured".
+func WithLineCounter(ctx context.Context, unit string, match func(line []byte) bool) context.Context {
+	return context.WithValue(ctx, lineCounterKey{}, &lineCounter{unit: unit, match: match})
+}
+
+// LineWriter returns a writer forwarding to w and reporting the number of counted
+// lines written to it, if the context has a progress reporter and a line counter,
+// and a function reporting the final count once the command is done. Otherwise w
+// is returned unchanged.
+func LineWriter(ctx context.Context, w io.Writer) (io.Writer, func()) {
+	r, ok := ctx.Value(reporterKey{}).(*reporter)
+	if !ok {
+		return w, func() {}
+	}
+	counter, ok := ctx.Value(lineCounterKey{}).(*lineCounter)
+	if !ok {
+		return w, func() {}
+	}
+	r.mu.Lock()
+	base := r.progress
+	r.mu.Unlock()
+	lw := &lineWriter{ctx: ctx, w: w, reporter: r, counter: counter, base: base}
+	return lw, func() {
+		lw.mu.Lock()
+		defer lw.mu.Unlock()
+		lw.report()
+	}
+}
+
+// lineWriter counts the lines written to it and reports the count periodically.
+type lineWriter struct {
+	ctx      context.Context
+	w        io.Writer
+	reporter *reporter
+	counter  *lineCounter
+	base     float64
+
+	mu         sync.Mutex
+	partial    []byte
+	count      int
+	lastReport time.Time
+}
+
+func (lw *lineWriter) Write(p []byte) (int, error) {
+	lw.mu.Lock()
+	defer lw.mu.Unlock()
+	n, err := lw.w.Write(p)
+	counted := lw.count
+	data := p[:n]
+	for {
+		i := bytes.IndexByte(data, '\n')
+		if i < 0 {
+			lw.partial = append(lw.partial, data...)
+			break
+		}
+		line := append(lw.partial, data[:i]...)
+		if lw.counter.match == nil || lw.counter.match(line) {
+			lw.count++
+		}
+		lw.partial = lw.partial[:0]
+		data = data[i+1:]
+	}
+	if lw.count != counted && time.Since(lw.lastReport) >= minOutputInterval {
+		lw.lastReport = time.Now()
+		lw.report()
+	}
+	return n, err
+}
+
+// report sends the current count, if it was not reported yet.
+func (lw *lineWriter) report() {
+	r := lw.reporter
+	message := fmt.Sprintf("%d %s", lw.count, lw.counter.unit)
+	r.mu.Lock()
+	progress := lw.base + float64(lw.count)
+	if progress <= r.progress {
+		r.mu.Unlock()
+		return
+	}
+	r.progress = progress
+	r.message = message
+	r.mu.Unlock()
+	r.notify(lw.ctx, progress, message)
+}
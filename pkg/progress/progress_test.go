@@ -0,0 +1,92 @@
+package progress
+
+import (
+	"bytes"
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+type fakeNotifier struct {
+	mu            sync.Mutex
+	notifications []*mcp.ProgressNotificationParams
+}
+
+func (n *fakeNotifier) NotifyProgress(_ context.Context, params *mcp.ProgressNotificationParams) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.notifications = append(n.notifications, params)
+	return nil
+}
+
+func TestReport(t *testing.T) {
+	t.Run("without progress token", func(t *testing.T) {
+		notifier := &fakeNotifier{}
+		ctx := WithReporter(context.Background(), notifier, nil)
+		Report(ctx, "Creating debug pod on node %s", "worker-0")
+		if Enabled(ctx) || len(notifier.notifications) != 0 {
+			t.Fatalf("Expected no notification, got %d", len(notifier.notifications))
+		}
+	})
+
+	t.Run("stages", func(t *testing.T) {
+		notifier := &fakeNotifier{}
+		ctx := WithReporter(context.Background(), notifier, "token")
+		Report(ctx, "Creating debug pod on node %s", "worker-0")
+		Report(ctx, "Waiting for debug pod %s to start", "debug-1")
+		Report(ctx, "Waiting for debug pod %s to start", "debug-1")
+		Report(ctx, "Debug pod %s is running", "debug-1")
+		if len(notifier.notifications) != 3 {
+			t.Fatalf("Expected 3 notifications, got %d", len(notifier.notifications))
+		}
+		for i, notification := range notifier.notifications {
+			if notification.ProgressToken != "token" || notification.Progress != float64(i+1) {
+				t.Fatalf("Unexpected notification %d: %+v", i, notification)
+			}
+		}
+		if notifier.notifications[2].Message != "Debug pod debug-1 is running" {
+			t.Fatalf("Unexpected message %q", notifier.notifications[2].Message)
+		}
+	})
+}
+
+func TestLineWriter(t *testing.T) {
+	notifier := &fakeNotifier{}
+	ctx := WithReporter(context.Background(), notifier, 1)
+	Report(ctx, "Running command")
+	ctx = WithLineCounter(ctx, "packets captured", func(line []byte) bool {
+		return len(line) > 0 && line[0] != ' '
+	})
+
+	var output bytes.Buffer
+	w, done := LineWriter(ctx, &output)
+	for _, chunk := range []string{"packet 1\n    details\npack", "et 2\n", "packet 3\n"} {
+		if _, err := w.Write([]byte(chunk)); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+	done()
+
+	if output.String() != "packet 1\n    details\npacket 2\npacket 3\n" {
+		t.Fatalf("Output not forwarded, got %q", output.String())
+	}
+	// The first packet is reported immediately, the others are throttled until done.
+	if len(notifier.notifications) != 3 {
+		t.Fatalf("Expected 3 notifications, got %d", len(notifier.notifications))
+	}
+	last := notifier.notifications[2]
+	if last.Message != "3 packets captured" || last.Progress != 4 {
+		t.Fatalf("Unexpected last notification: %+v", last)
+	}
+
+	t.Run("without line counter", func(t *testing.T) {
+		var output bytes.Buffer
+		w, done := LineWriter(WithReporter(context.Background(), notifier, 1), &output)
+		done()
+		if w != &output {
+			t.Fatal("Expected the writer to be returned unchanged")
+		}
+	})
+}
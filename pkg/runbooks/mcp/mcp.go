@@ -0,0 +1,196 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// MCPServer provides the troubleshooting prompts, expanding the standard
+// OVN-Kubernetes runbooks into plans of tool calls.
+type MCPServer struct {
+	live    bool
+	offline bool
+	tools   map[string]bool
+}
+
+// NewMCPServer creates a new runbook MCP server. The plans use the live-cluster
+// tools if live is set, and the must-gather and sosreport tools if offline is set.
+// The steps of the tools that are not in tools, like the Kubernetes tools with the
+// local executor, are left out of the plans.
+func NewMCPServer(live, offline bool, tools []string) *MCPServer {
+	s := &MCPServer{live: live, offline: offline, tools: map[string]bool{}}
+	for _, tool := range tools {
+		s.tools[tool] = true
+	}
+	return s
+}
+
+// AddPrompts registers the troubleshooting prompts with the MCP server.
+func (s *MCPServer) AddPrompts(server *mcp.Server) {
+	for _, rb := range runbooks {
+		server.AddPrompt(&mcp.Prompt{
+			Name:        rb.name,
+			Title:       rb.title,
+			Description: rb.description,
+			Arguments:   s.arguments(rb),
+		}, s.handler(rb))
+	}
+}
+
+// arguments returns the arguments of the prompt of a runbook: its own ones, and
+// the ones of the tools of the modes of the server.
+func (s *MCPServer) arguments(rb runbook) []*mcp.PromptArgument {
+	arguments := slices.Clone(rb.arguments)
+	if s.live {
+		arguments = append(arguments, &mcp.PromptArgument{Name: "cluster", Description: "Cluster to troubleshoot, from cluster-list (optional, defaults to the default cluster)"})
+	}
+	arguments = append(arguments, &mcp.PromptArgument{Name: "ovn_namespace", Description: "Namespace of the OVN-Kubernetes pods (optional, detected by ovnk-topology)"})
+	if s.offline {
+		arguments = append(arguments, &mcp.PromptArgument{Name: "must_gather_path", Description: "Absolute path of an extracted must-gather of the cluster (optional)"})
+		arguments = append(arguments, rb.offlineArguments...)
+	}
+	return arguments
+}
+
+// handler returns the handler of the prompt of a runbook.
+func (s *MCPServer) handler(rb runbook) mcp.PromptHandler {
+	return func(ctx context.Context, req *mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+		arguments := map[string]string{}
+		if req.Params != nil {
+			for name, value := range req.Params.Arguments {
+				arguments[name] = strings.TrimSpace(value)
+			}
+		}
+		for _, argument := range rb.arguments {
+			if argument.Required && arguments[argument.Name] == "" {
+				return nil, fmt.Errorf("argument %s is required", argument.Name)
+			}
+		}
+		plan, err := s.plan(rb, arguments)
+		if err != nil {
+			return nil, err
+		}
+		return &mcp.GetPromptResult{
+			Description: rb.description,
+			Messages:    []*mcp.PromptMessage{{Role: "user", Content: &mcp.TextContent{Text: plan}}},
+		}, nil
+	}
+}
+
+// plan expands a runbook into the plan of tool calls of the modes of the server.
+func (s *MCPServer) plan(rb runbook, arguments map[string]string) (string, error) {
+	replacer := placeholderReplacer(arguments)
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s\n\n", replacer.Replace(rb.goal))
+	b.WriteString("Run the following steps in order with the tools of this server. Replace the <...> values with the ones found by the previous steps, and skip the steps that are not needed once the cause is found.\n")
+
+	sections := []struct {
+		enabled bool
+		title   string
+		steps   []step
+		live    bool
+	}{
+		{s.live, "Live cluster", rb.live, true},
+		{s.offline, "Must-gather and sosreport", rb.offline, false},
+	}
+	for _, section := range sections {
+		if !section.enabled {
+			continue
+		}
+		steps := slices.DeleteFunc(slices.Clone(section.steps), func(step step) bool { return !s.tools[step.tool] })
+		if len(steps) == 0 {
+			continue
+		}
+		fmt.Fprintf(&b, "\n## %s\n\n", section.title)
+		for i, step := range steps {
+			var extra map[string]any
+			if section.live {
+				extra = liveParams(step, arguments)
+			}
+			params, err := stepParams(step, extra, replacer)
+			if err != nil {
+				return "", err
+			}
+			fmt.Fprintf(&b, "%d. %s.\n   Tool `%s` with %s\n", i+1, replacer.Replace(step.purpose), step.tool, params)
+		}
+	}
+	if s.live && s.offline {
+		b.WriteString("\nUse the live cluster steps while the problem is happening, and the must-gather and sosreport steps to analyze a collected archive.\n")
+	}
+	fmt.Fprintf(&b, "\n%s\n", rb.conclusion)
+	return b.String(), nil
+}
+
+// liveParams returns the parameters of the tool call of a live-cluster step set by
+// the arguments: the cluster, and the namespace of the OVN-Kubernetes pods for the
+// tools detecting it.
+func liveParams(step step, arguments map[string]string) map[string]any {
+	params := map[string]any{"cluster": arguments["cluster"]}
+	_, node := step.params["node"]
+	if step.tool == "ovnk-topology" || node && (strings.HasPrefix(step.tool, "ovn-") || strings.HasPrefix(step.tool, "ovs-")) {
+		params["namespace"] = arguments["ovn_namespace"]
+	}
+	return params
+}
+
+// stepParams returns the JSON parameters of the tool call of a step, with its
+// placeholders replaced and the extra parameters that are set.
+func stepParams(step step, extra map[string]any, replacer *strings.Replacer) (string, error) {
+	params := map[string]any{}
+	for name, value := range step.params {
+		switch value := value.(type) {
+		case string:
+			params[name] = replacer.Replace(value)
+		case []string:
+			values := make([]string, 0, len(value))
+			for _, v := range value {
+				values = append(values, replacer.Replace(v))
+			}
+			params[name] = values
+		default:
+			params[name] = value
+		}
+	}
+	for name, value := range extra {
+		if value != "" {
+			params[name] = value
+		}
+	}
+	// Keep the <...> placeholders and the && of the microflows readable.
+	var b strings.Builder
+	encoder := json.NewEncoder(&b)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(params); err != nil {
+		return "", fmt.Errorf("failed to encode the parameters of tool %s: %w", step.tool, err)
+	}
+	return strings.TrimSuffix(b.String(), "\n"), nil
+}
+
+// placeholderReplacer returns a replacer of the {argument} placeholders with the
+// values of the arguments, or with <argument> if they are not set.
+func placeholderReplacer(arguments map[string]string) *strings.Replacer {
+	names := map[string]bool{}
+	for _, rb := range runbooks {
+		for _, argument := range slices.Concat(rb.arguments, rb.offlineArguments) {
+			names[argument.Name] = true
+		}
+	}
+	for _, name := range []string{"cluster", "ovn_namespace", "must_gather_path"} {
+		names[name] = true
+	}
+	var oldnew []string
+	for _, name := range slices.Sorted(maps.Keys(names)) {
+		value := arguments[name]
+		if value == "" {
+			value = "<" + name + ">"
+		}
+		oldnew = append(oldnew, "{"+name+"}", value)
+	}
+	return strings.NewReplacer(oldnew...)
+}
@@ -0,0 +1,171 @@
+package mcp
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// allTools returns the tools of the steps of all the runbooks.
+func allTools() []string {
+	var tools []string
+	for _, rb := range runbooks {
+		for _, step := range slices.Concat(rb.live, rb.offline) {
+			tools = append(tools, step.tool)
+		}
+	}
+	return tools
+}
+
+func getPrompt(t *testing.T, s *MCPServer, name string, arguments map[string]string) (string, error) {
+	t.Helper()
+	for _, rb := range runbooks {
+		if rb.name != name {
+			continue
+		}
+		result, err := s.handler(rb)(context.Background(), &mcp.GetPromptRequest{Params: &mcp.GetPromptParams{Name: name, Arguments: arguments}})
+		if err != nil {
+			return "", err
+		}
+		return result.Messages[0].Content.(*mcp.TextContent).Text, nil
+	}
+	t.Fatalf("Unknown runbook %s", name)
+	return "", nil
+}
+
+func TestRunbooks(t *testing.T) {
+	names := map[string]bool{}
+	for _, rb := range runbooks {
+		if names[rb.name] {
+			t.Fatalf("Duplicate runbook %s", rb.name)
+		}
+		names[rb.name] = true
+		if len(rb.live) == 0 || len(rb.offline) == 0 {
+			t.Fatalf("Runbook %s has no live or offline steps", rb.name)
+		}
+	}
+}
+
+func TestPrompt(t *testing.T) {
+	podToPod := map[string]string{
+		"source_namespace":      "default",
+		"source_pod":            "client",
+		"destination_namespace": "web",
+		"destination_pod":       "server",
+	}
+	tests := []struct {
+		name        string
+		server      *MCPServer
+		runbook     string
+		arguments   map[string]string
+		wantErr     string
+		wantText    []string
+		notWantText []string
+	}{
+		{
+			name:      "live",
+			server:    NewMCPServer(true, false, allTools()),
+			runbook:   "troubleshoot-pod-to-pod",
+			arguments: podToPod,
+			wantText: []string{
+				"Pod default/client cannot reach pod web/server on port <port>.",
+				"## Live cluster",
+				`Tool ` + "`resource-get`" + ` with {"kind":"Pod","name":"client","namespace":"default","output_type":"yaml","version":"v1"}`,
+				"Tool `ovnk-topology` with {}",
+				`Tool ` + "`ovn-get`" + ` with {"database":"nbdb","node":"<node of the source pod>","record":"default_client","table":"Logical_Switch_Port"}`,
+				`"name":"<ovnkube-node pod on the node of the source pod>","namespace":"<ovn_namespace>"`,
+				`inport==\"default_client\" && eth.src==<source pod MAC>`,
+			},
+			notWantText: []string{"## Must-gather and sosreport", "must-gather-", `"cluster"`},
+		},
+		{
+			name:    "live with cluster and port",
+			server:  NewMCPServer(true, false, allTools()),
+			runbook: "troubleshoot-pod-to-pod",
+			arguments: map[string]string{
+				"source_namespace": "default", "source_pod": "client", "destination_namespace": "web", "destination_pod": "server",
+				"port": "8080", "cluster": "east", "ovn_namespace": "openshift-ovn-kubernetes",
+			},
+			wantText: []string{
+				"on port 8080.", "tcp.dst==8080",
+				"Tool `ovnk-topology` with {\"cluster\":\"east\",\"namespace\":\"openshift-ovn-kubernetes\"}",
+				`{"cluster":"east","database":"nbdb","namespace":"openshift-ovn-kubernetes","node":"<node of the source pod>"`,
+				`{"cluster":"east","kind":"Pod","name":"client","namespace":"default"`,
+			},
+		},
+		{
+			name:        "offline",
+			server:      NewMCPServer(false, true, allTools()),
+			runbook:     "troubleshoot-pod-to-pod",
+			arguments:   map[string]string{"source_namespace": "default", "source_pod": "client", "destination_namespace": "web", "destination_pod": "server", "must_gather_path": "/tmp/mg"},
+			wantText:    []string{"## Must-gather and sosreport", `"must_gather_path":"/tmp/mg"`},
+			notWantText: []string{"## Live cluster", "ovn-trace", "Use the live cluster steps"},
+		},
+		{
+			name:      "dual",
+			server:    NewMCPServer(true, true, allTools()),
+			runbook:   "troubleshoot-pod-to-pod",
+			arguments: podToPod,
+			wantText:  []string{"## Live cluster", "## Must-gather and sosreport", `"must_gather_path":"<must_gather_path>"`, "Use the live cluster steps"},
+		},
+		{
+			name:        "unregistered tools",
+			server:      NewMCPServer(true, false, []string{"ovn-get", "ovn-trace"}),
+			runbook:     "troubleshoot-pod-to-pod",
+			arguments:   podToPod,
+			wantText:    []string{"1. Check the logical switch port of the source pod", "Tool `ovn-trace`"},
+			notWantText: []string{"resource-get", "tcpdump"},
+		},
+		{
+			name:      "missing required argument",
+			server:    NewMCPServer(true, false, allTools()),
+			runbook:   "troubleshoot-pod-to-pod",
+			arguments: map[string]string{"source_namespace": "default", "source_pod": " "},
+			wantErr:   "argument source_pod is required",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			text, err := getPrompt(t, test.server, test.runbook, test.arguments)
+			if test.wantErr != "" {
+				if err == nil || err.Error() != test.wantErr {
+					t.Fatalf("Expected error %q, got %v", test.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			for _, want := range test.wantText {
+				if !strings.Contains(text, want) {
+					t.Fatalf("Expected %q in the plan:\n%s", want, text)
+				}
+			}
+			for _, notWant := range test.notWantText {
+				if strings.Contains(text, notWant) {
+					t.Fatalf("Unexpected %q in the plan:\n%s", notWant, text)
+				}
+			}
+		})
+	}
+}
+
+func TestPromptArguments(t *testing.T) {
+	for _, rb := range runbooks {
+		for _, server := range []*MCPServer{NewMCPServer(true, false, nil), NewMCPServer(false, true, nil), NewMCPServer(true, true, nil)} {
+			names := map[string]bool{}
+			for _, argument := range server.arguments(rb) {
+				if names[argument.Name] {
+					t.Fatalf("Duplicate argument %s of runbook %s", argument.Name, rb.name)
+				}
+				names[argument.Name] = true
+			}
+			if names["cluster"] != server.live || names["must_gather_path"] != server.offline {
+				t.Fatalf("Unexpected arguments %v of runbook %s", names, rb.name)
+			}
+		}
+	}
+}
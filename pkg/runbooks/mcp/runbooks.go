@@ -0,0 +1,506 @@
+package mcp
+
+import "github.com/modelcontextprotocol/go-sdk/mcp"
+
+// runbook is a standard OVN-Kubernetes troubleshooting workflow, expanded by its
+// prompt into a plan of tool calls.
+type runbook struct {
+	name        string
+	title       string
+	description string
+	arguments   []*mcp.PromptArgument
+	// offlineArguments are the arguments of the offline steps only.
+	offlineArguments []*mcp.PromptArgument
+	// goal is the first paragraph of the plan.
+	goal string
+	// live and offline are the steps of the plan with the live-cluster tools, and
+	// with the must-gather and sosreport tools.
+	live    []step
+	offline []step
+	// conclusion is the last paragraph of the plan.
+	conclusion string
+}
+
+// step is a tool call of a runbook. The {argument} placeholders of the parameters
+// are replaced with the prompt arguments, and the <...> placeholders are values
+// found by the previous steps.
+type step struct {
+	purpose string
+	tool    string
+	params  map[string]any
+}
+
+// Placeholders of the values found by the previous steps.
+const (
+	sourceOVNPod     = "<ovnkube-node pod on the node of the source pod>"
+	destinationNode  = "<node of the destination pod>"
+	sourceNode       = "<node of the source pod>"
+	podNode          = "<node of the pod>"
+	nodeOVNPod       = "<ovnkube-node pod on the node>"
+	sourceNBDatabase = "<Northbound database of the node of the source pod>"
+	podNBDatabase    = "<Northbound database of the node of the pod>"
+	nodeSBDatabase   = "<Southbound database of the node>"
+)
+
+// ovnkTopology is the step discovering the OVN-Kubernetes deployment, whose
+// namespace and ovnkube-node pods are used by the next steps.
+var ovnkTopology = step{
+	purpose: "Discover the OVN-Kubernetes deployment: its namespace, its mode and the ovnkube-node pod of every node",
+	tool:    "ovnk-topology",
+	params:  map[string]any{},
+}
+
+var runbooks = []runbook{
+	{
+		name:        "troubleshoot-pod-to-pod",
+		title:       "Pod-to-pod connectivity",
+		description: "Diagnose why a pod cannot reach another pod, following the packet through the OVN logical network, OVS and the destination pod.",
+		arguments: []*mcp.PromptArgument{
+			{Name: "source_namespace", Description: "Namespace of the source pod", Required: true},
+			{Name: "source_pod", Description: "Name of the source pod", Required: true},
+			{Name: "destination_namespace", Description: "Namespace of the destination pod", Required: true},
+			{Name: "destination_pod", Description: "Name of the destination pod", Required: true},
+			{Name: "port", Description: "Destination TCP port (optional)"},
+		},
+		goal: "Pod {source_namespace}/{source_pod} cannot reach pod {destination_namespace}/{destination_pod} on port {port}. Find where the traffic is lost.",
+		live: []step{
+			{
+				purpose: "Check the source pod is running, and note its IP, MAC, node and the k8s.ovn.org/pod-networks annotation",
+				tool:    "resource-get",
+				params:  map[string]any{"version": "v1", "kind": "Pod", "namespace": "{source_namespace}", "name": "{source_pod}", "output_type": "yaml"},
+			},
+			{
+				purpose: "Check the destination pod is running and ready, and note its IP and node",
+				tool:    "resource-get",
+				params:  map[string]any{"version": "v1", "kind": "Pod", "namespace": "{destination_namespace}", "name": "{destination_pod}", "output_type": "yaml"},
+			},
+			ovnkTopology,
+			{
+				purpose: "Check the logical switch port of the source pod exists, with the pod addresses, and is up",
+				tool:    "ovn-get",
+				params:  map[string]any{"node": sourceNode, "database": "nbdb", "table": "Logical_Switch_Port", "record": "{source_namespace}_{source_pod}"},
+			},
+			{
+				purpose: "Check the logical switch port of the destination pod the same way",
+				tool:    "ovn-get",
+				params:  map[string]any{"node": destinationNode, "database": "nbdb", "table": "Logical_Switch_Port", "record": "{destination_namespace}_{destination_pod}"},
+			},
+			{
+				purpose: "Trace the packet through the logical network from the node switch of the source pod; look for drops by ACLs or missing routes",
+				tool:    "ovn-trace",
+				params: map[string]any{"node": sourceNode, "datapath": sourceNode,
+					"microflow": `inport=="{source_namespace}_{source_pod}" && eth.src==<source pod MAC> && eth.dst==<router port MAC> && ip4.src==<source pod IP> && ip4.dst==<destination pod IP> && ip.ttl==64 && tcp && tcp.dst=={port}`},
+			},
+			{
+				purpose: "If the logical trace passes, trace the packet through the OpenFlow pipeline of the source node; the steps show the logical flow and ACL of each OpenFlow flow",
+				tool:    "ovs-appctl-ofproto-trace",
+				params:  map[string]any{"node": sourceNode, "bridge": "br-int", "flow": "in_port=<OpenFlow port of the source pod>,tcp,nw_src=<source pod IP>,nw_dst=<destination pod IP>,tp_dst={port}", "detrace": true},
+			},
+			{
+				purpose: "Look for connection tracking entries of the connection on the destination node",
+				tool:    "ovs-appctl-dump-conntrack",
+				params:  map[string]any{"node": destinationNode, "filter": "<destination pod IP>"},
+			},
+			{
+				purpose: "Capture the traffic in the destination pod to check whether the packets arrive and are answered",
+				tool:    "tcpdump",
+				params:  map[string]any{"target_type": "pod", "pod_namespace": "{destination_namespace}", "pod_name": "{destination_pod}", "bpf_filter": "host <source pod IP>", "packet_count": 20},
+			},
+			{
+				purpose: "Look for errors about the pods in the logs of ovnkube-controller on both nodes",
+				tool:    "pod-logs",
+				params:  map[string]any{"namespace": "{ovn_namespace}", "name": sourceOVNPod, "container": "ovnkube-controller", "pattern": "{source_pod}|{destination_pod}|error"},
+			},
+		},
+		offline: []step{
+			{
+				purpose: "Check the status, IPs and nodes of both pods in the must-gather",
+				tool:    "must-gather-get-resource",
+				params:  map[string]any{"must_gather_path": "{must_gather_path}", "kind": "Pod", "namespace": "{source_namespace}", "name": "{source_pod}", "output_type": "yaml"},
+			},
+			{
+				purpose: "Find the Northbound database of the node of the source pod",
+				tool:    "must-gather-list-northbound-databases",
+				params:  map[string]any{"must_gather_path": "{must_gather_path}"},
+			},
+			{
+				purpose: "Check the logical switch port of the source pod",
+				tool:    "must-gather-query-database",
+				params: map[string]any{"must_gather_path": "{must_gather_path}", "database_name": sourceNBDatabase, "table": "Logical_Switch_Port",
+					"conditions": []string{`["name","==","{source_namespace}_{source_pod}"]`}},
+			},
+			{
+				purpose: "List the network policies of the destination namespace that may drop the traffic",
+				tool:    "must-gather-list-resources",
+				params:  map[string]any{"must_gather_path": "{must_gather_path}", "kind": "NetworkPolicy", "namespace": "{destination_namespace}"},
+			},
+		},
+		conclusion: "Report the first step where the traffic is lost, the evidence, and the likely cause.",
+	},
+	{
+		name:        "troubleshoot-pod-to-service",
+		title:       "Pod-to-service connectivity",
+		description: "Diagnose why a pod cannot reach a service, checking the endpoints and the OVN load balancer of the service.",
+		arguments: []*mcp.PromptArgument{
+			{Name: "namespace", Description: "Namespace of the client pod", Required: true},
+			{Name: "pod", Description: "Name of the client pod", Required: true},
+			{Name: "service_namespace", Description: "Namespace of the service", Required: true},
+			{Name: "service", Description: "Name of the service", Required: true},
+		},
+		goal: "Pod {namespace}/{pod} cannot reach service {service_namespace}/{service}. Check the service, its endpoints and its OVN load balancer.",
+		live: []step{
+			{
+				purpose: "Note the cluster IP, ports and selector of the service",
+				tool:    "resource-get",
+				params:  map[string]any{"version": "v1", "kind": "Service", "namespace": "{service_namespace}", "name": "{service}", "output_type": "yaml"},
+			},
+			{
+				purpose: "Check the service has ready endpoints",
+				tool:    "resource-list",
+				params:  map[string]any{"group": "discovery.k8s.io", "version": "v1", "kind": "EndpointSlice", "namespace": "{service_namespace}", "label_selector": "kubernetes.io/service-name={service}", "output_type": "yaml"},
+			},
+			{
+				purpose: "Note the IP and node of the client pod",
+				tool:    "resource-get",
+				params:  map[string]any{"version": "v1", "kind": "Pod", "namespace": "{namespace}", "name": "{pod}", "output_type": "wide"},
+			},
+			ovnkTopology,
+			{
+				purpose: "Check the OVN load balancer of the service has the endpoints as backends of the cluster IP",
+				tool:    "ovn-get",
+				params:  map[string]any{"node": podNode, "database": "nbdb", "table": "Load_Balancer", "columns": "name,vips,protocol", "filter": "{service_namespace}/{service}|vips"},
+			},
+			{
+				purpose: "Trace a packet from the client pod to the cluster IP; check it is load balanced to an endpoint and not dropped",
+				tool:    "ovn-trace",
+				params: map[string]any{"node": podNode, "datapath": podNode,
+					"microflow": `inport=="{namespace}_{pod}" && eth.src==<client pod MAC> && eth.dst==<router port MAC> && ip4.src==<client pod IP> && ip4.dst==<cluster IP> && ip.ttl==64 && tcp && tcp.dst==<service port>`},
+			},
+			{
+				purpose: "Look for the connection to the cluster IP in connection tracking on the node of the client",
+				tool:    "ovs-appctl-dump-conntrack",
+				params:  map[string]any{"node": podNode, "filter": "<cluster IP>"},
+			},
+		},
+		offline: []step{
+			{
+				purpose: "Check the service and its endpoints in the must-gather",
+				tool:    "must-gather-get-resource",
+				params:  map[string]any{"must_gather_path": "{must_gather_path}", "kind": "Service", "namespace": "{service_namespace}", "name": "{service}", "output_type": "yaml"},
+			},
+			{
+				purpose: "List the endpoint slices of the service",
+				tool:    "must-gather-list-resources",
+				params:  map[string]any{"must_gather_path": "{must_gather_path}", "kind": "EndpointSlice", "namespace": "{service_namespace}", "label_selector": "kubernetes.io/service-name={service}"},
+			},
+			{
+				purpose: "Find the Northbound database of the node of the client pod",
+				tool:    "must-gather-list-northbound-databases",
+				params:  map[string]any{"must_gather_path": "{must_gather_path}"},
+			},
+			{
+				purpose: "Check the backends of the load balancer of the service",
+				tool:    "must-gather-query-database",
+				params:  map[string]any{"must_gather_path": "{must_gather_path}", "database_name": podNBDatabase, "table": "Load_Balancer", "columns": []string{"name", "vips", "protocol"}},
+			},
+		},
+		conclusion: "Report whether the service, its endpoints or its load balancer is wrong, with the evidence.",
+	},
+	{
+		name:        "troubleshoot-egress",
+		title:       "Egress to an external destination",
+		description: "Diagnose why a pod cannot reach a destination outside of the cluster, following the traffic through the gateway router and the node.",
+		arguments: []*mcp.PromptArgument{
+			{Name: "namespace", Description: "Namespace of the pod", Required: true},
+			{Name: "pod", Description: "Name of the pod", Required: true},
+			{Name: "destination", Description: "External destination IP", Required: true},
+		},
+		goal: "Pod {namespace}/{pod} cannot reach {destination}, outside of the cluster. Follow the traffic from the pod to the node uplink.",
+		live: []step{
+			{
+				purpose: "Note the IP and node of the pod",
+				tool:    "resource-get",
+				params:  map[string]any{"version": "v1", "kind": "Pod", "namespace": "{namespace}", "name": "{pod}", "output_type": "wide"},
+			},
+			ovnkTopology,
+			{
+				purpose: "Trace the packet from the pod to the destination; check it reaches the gateway router GR_<node> and is SNATed",
+				tool:    "ovn-trace",
+				params: map[string]any{"node": podNode, "datapath": podNode,
+					"microflow": `inport=="{namespace}_{pod}" && eth.src==<pod MAC> && eth.dst==<router port MAC> && ip4.src==<pod IP> && ip4.dst=={destination} && ip.ttl==64 && icmp`},
+			},
+			{
+				purpose: "Check the SNAT entries of the gateway router of the node",
+				tool:    "ovn-get",
+				params:  map[string]any{"node": podNode, "database": "nbdb", "table": "NAT", "columns": "type,logical_ip,external_ip", "filter": "snat|logical_ip|external_ip"},
+			},
+			{
+				purpose: "Check the routes of the node towards the destination",
+				tool:    "get-ip",
+				params:  map[string]any{"node": podNode, "command": "route"},
+			},
+			{
+				purpose: "Check the OVN-Kubernetes nftables rules of the node",
+				tool:    "get-nft",
+				params:  map[string]any{"node": podNode, "command": "list ruleset", "address_families": "inet"},
+			},
+			{
+				purpose: "Look for the connection in the kernel connection tracking of the node",
+				tool:    "get-conntrack",
+				params:  map[string]any{"node": podNode, "command": "-L", "filter_parameters": "-d {destination}"},
+			},
+			{
+				purpose: "Capture on the node to check the packets leave the node SNATed to the node IP and are answered",
+				tool:    "tcpdump",
+				params:  map[string]any{"target_type": "node", "node_name": podNode, "interface": "any", "bpf_filter": "host {destination}", "packet_count": 20},
+			},
+		},
+		offline: []step{
+			{
+				purpose: "Note the IP and node of the pod in the must-gather",
+				tool:    "must-gather-get-resource",
+				params:  map[string]any{"must_gather_path": "{must_gather_path}", "kind": "Pod", "namespace": "{namespace}", "name": "{pod}", "output_type": "wide"},
+			},
+			{
+				purpose: "Check the gateway configuration of the nodes",
+				tool:    "must-gather-ovnk-info",
+				params:  map[string]any{"must_gather_path": "{must_gather_path}", "info_type": "hostnetinfo"},
+			},
+			{
+				purpose: "List the egress firewalls of the namespace that may drop the traffic",
+				tool:    "must-gather-list-resources",
+				params:  map[string]any{"must_gather_path": "{must_gather_path}", "kind": "EgressFirewall", "namespace": "{namespace}"},
+			},
+		},
+		conclusion: "Report where the traffic leaves the expected path (logical network, SNAT, node routing or firewall), with the evidence.",
+	},
+	{
+		name:        "troubleshoot-network-policy",
+		title:       "NetworkPolicy unexpectedly blocking",
+		description: "Diagnose why traffic to a pod is dropped although the network policies should allow it, comparing the policies with their OVN ACLs.",
+		arguments: []*mcp.PromptArgument{
+			{Name: "namespace", Description: "Namespace of the pod receiving the traffic", Required: true},
+			{Name: "pod", Description: "Name of the pod receiving the traffic", Required: true},
+			{Name: "source_namespace", Description: "Namespace of the pod sending the traffic (optional)"},
+			{Name: "source_pod", Description: "Name of the pod sending the traffic (optional)"},
+		},
+		goal: "Traffic from {source_namespace}/{source_pod} to pod {namespace}/{pod} is dropped, although the network policies should allow it. Find the policy or ACL dropping it.",
+		live: []step{
+			{
+				purpose: "List the network policies of the namespace, and find the ones whose podSelector matches the labels of the pod",
+				tool:    "resource-list",
+				params:  map[string]any{"group": "networking.k8s.io", "version": "v1", "kind": "NetworkPolicy", "namespace": "{namespace}", "output_type": "yaml"},
+			},
+			{
+				purpose: "Note the labels, IP and node of the pod",
+				tool:    "resource-get",
+				params:  map[string]any{"version": "v1", "kind": "Pod", "namespace": "{namespace}", "name": "{pod}", "output_type": "wide"},
+			},
+			{
+				purpose: "Check the labels of the source namespace match the namespaceSelector of the policies",
+				tool:    "resource-get",
+				params:  map[string]any{"version": "v1", "kind": "Namespace", "name": "{source_namespace}", "output_type": "wide"},
+			},
+			ovnkTopology,
+			{
+				purpose: "List the ACLs of the policies of the namespace",
+				tool:    "ovn-get",
+				params:  map[string]any{"node": podNode, "database": "nbdb", "table": "ACL", "columns": "action,direction,match,priority,external_ids", "filter": "{namespace}"},
+			},
+			{
+				purpose: "Check the port groups of the policies contain the logical switch port {namespace}_{pod}",
+				tool:    "ovn-get",
+				params:  map[string]any{"node": podNode, "database": "nbdb", "table": "Port_Group", "columns": "name,ports,external_ids", "filter": "{namespace}"},
+			},
+			{
+				purpose: "Trace the traffic to the pod and find the ACL stage dropping it",
+				tool:    "ovn-trace",
+				params: map[string]any{"node": podNode, "datapath": podNode,
+					"microflow": `inport=="{source_namespace}_{source_pod}" && eth.src==<source pod MAC> && eth.dst==<router port MAC> && ip4.src==<source pod IP> && ip4.dst==<pod IP> && ip.ttl==64 && tcp && tcp.dst==<port>`},
+			},
+			{
+				purpose: "Check the address sets of the policy peers contain the source pod IP",
+				tool:    "ovn-get",
+				params:  map[string]any{"node": podNode, "database": "nbdb", "table": "Address_Set", "columns": "name,addresses,external_ids", "filter": "{source_namespace}"},
+			},
+		},
+		offline: []step{
+			{
+				purpose: "List the network policies of the namespace in the must-gather",
+				tool:    "must-gather-list-resources",
+				params:  map[string]any{"must_gather_path": "{must_gather_path}", "kind": "NetworkPolicy", "namespace": "{namespace}", "output_type": "yaml"},
+			},
+			{
+				purpose: "Find the Northbound database of the node of the pod",
+				tool:    "must-gather-list-northbound-databases",
+				params:  map[string]any{"must_gather_path": "{must_gather_path}"},
+			},
+			{
+				purpose: "List the ACLs and check they match the policies",
+				tool:    "must-gather-query-database",
+				params:  map[string]any{"must_gather_path": "{must_gather_path}", "database_name": podNBDatabase, "table": "ACL", "columns": []string{"action", "direction", "match", "priority", "external_ids"}},
+			},
+		},
+		conclusion: "Report the policy or ACL dropping the traffic, and whether the policy or its translation to OVN is wrong.",
+	},
+	{
+		name:        "troubleshoot-egress-ip",
+		title:       "EgressIP not working",
+		description: "Diagnose why the traffic of a pod does not leave the cluster with its EgressIP, checking the EgressIP assignment, the reroute policies and the SNAT.",
+		arguments: []*mcp.PromptArgument{
+			{Name: "egress_ip", Description: "Name of the EgressIP object", Required: true},
+			{Name: "namespace", Description: "Namespace of a pod whose traffic should use the EgressIP", Required: true},
+			{Name: "pod", Description: "Name of a pod whose traffic should use the EgressIP", Required: true},
+		},
+		goal: "The traffic of pod {namespace}/{pod} does not leave the cluster with the IPs of EgressIP {egress_ip}. Check the assignment and the OVN configuration of the EgressIP.",
+		live: []step{
+			{
+				purpose: "Check the selectors of the EgressIP match the pod and its namespace, and note the node each egress IP is assigned to in its status",
+				tool:    "resource-get",
+				params:  map[string]any{"group": "k8s.ovn.org", "version": "v1", "kind": "EgressIP", "name": "{egress_ip}", "output_type": "yaml"},
+			},
+			{
+				purpose: "Check the egress nodes are labelled k8s.ovn.org/egress-assignable and Ready",
+				tool:    "resource-list",
+				params:  map[string]any{"version": "v1", "kind": "Node", "label_selector": "k8s.ovn.org/egress-assignable", "output_type": "wide"},
+			},
+			{
+				purpose: "Note the labels, IP and node of the pod",
+				tool:    "resource-get",
+				params:  map[string]any{"version": "v1", "kind": "Pod", "namespace": "{namespace}", "name": "{pod}", "output_type": "wide"},
+			},
+			ovnkTopology,
+			{
+				purpose: "Check the reroute policy of the pod IP to the egress node on ovn_cluster_router",
+				tool:    "ovn-get",
+				params:  map[string]any{"node": podNode, "database": "nbdb", "table": "Logical_Router_Policy", "columns": "priority,match,action,nexthops,external_ids", "filter": "<pod IP>|{egress_ip}"},
+			},
+			{
+				purpose: "Check the SNAT of the pod IP to the egress IP on the gateway router of the egress node",
+				tool:    "ovn-get",
+				params:  map[string]any{"node": "<egress node>", "database": "nbdb", "table": "NAT", "columns": "type,logical_ip,external_ip,external_ids", "filter": "{egress_ip}|logical_ip|external_ip"},
+			},
+			{
+				purpose: "Check the egress IP is configured on an interface of the egress node",
+				tool:    "get-ip",
+				params:  map[string]any{"node": "<egress node>", "command": "addr"},
+			},
+			{
+				purpose: "Capture on the egress node to check the traffic of the pod leaves with the egress IP",
+				tool:    "tcpdump",
+				params:  map[string]any{"target_type": "node", "node_name": "<egress node>", "interface": "any", "bpf_filter": "host <egress IP address>", "packet_count": 20},
+			},
+			{
+				purpose: "Look for EgressIP errors in the logs of ovnkube-controller on the egress node",
+				tool:    "pod-logs",
+				params:  map[string]any{"namespace": "{ovn_namespace}", "name": "<ovnkube-node pod on the egress node>", "container": "ovnkube-controller", "pattern": "(?i)egress ?ip"},
+			},
+		},
+		offline: []step{
+			{
+				purpose: "Check the EgressIP and its status in the must-gather",
+				tool:    "must-gather-get-resource",
+				params:  map[string]any{"must_gather_path": "{must_gather_path}", "kind": "EgressIP", "name": "{egress_ip}", "output_type": "yaml"},
+			},
+			{
+				purpose: "Check the egress-assignable nodes",
+				tool:    "must-gather-list-resources",
+				params:  map[string]any{"must_gather_path": "{must_gather_path}", "kind": "Node", "label_selector": "k8s.ovn.org/egress-assignable", "output_type": "wide"},
+			},
+			{
+				purpose: "Find the Northbound database of the node of the pod",
+				tool:    "must-gather-list-northbound-databases",
+				params:  map[string]any{"must_gather_path": "{must_gather_path}"},
+			},
+			{
+				purpose: "Check the reroute policies of the EgressIP",
+				tool:    "must-gather-query-database",
+				params:  map[string]any{"must_gather_path": "{must_gather_path}", "database_name": podNBDatabase, "table": "Logical_Router_Policy", "columns": []string{"priority", "match", "action", "nexthops"}},
+			},
+		},
+		conclusion: "Report whether the EgressIP is not assigned, not matching the pod, or not programmed in OVN or on the egress node, with the evidence.",
+	},
+	{
+		name:        "troubleshoot-node-not-ready",
+		title:       "Node NotReady networking",
+		description: "Diagnose a node NotReady because of its networking, checking the OVN-Kubernetes pods, OVS and the node network configuration.",
+		arguments: []*mcp.PromptArgument{
+			{Name: "node", Description: "Name of the NotReady node", Required: true},
+		},
+		offlineArguments: []*mcp.PromptArgument{
+			{Name: "sosreport_path", Description: "Path of an extracted sosreport of the node (optional)"},
+		},
+		goal: "Node {node} is NotReady, or its pods have no networking. Check the OVN-Kubernetes components of the node.",
+		live: []step{
+			{
+				purpose: "Check the conditions of the node, and the message of the Ready condition (e.g. no CNI configuration)",
+				tool:    "resource-get",
+				params:  map[string]any{"version": "v1", "kind": "Node", "name": "{node}", "output_type": "yaml"},
+			},
+			ovnkTopology,
+			{
+				purpose: "Look for errors of ovnkube-controller on the node",
+				tool:    "pod-logs",
+				params:  map[string]any{"namespace": "{ovn_namespace}", "name": nodeOVNPod, "container": "ovnkube-controller", "pattern": "(?i)error|fail", "tail": 100},
+			},
+			{
+				purpose: "Look for errors of ovn-controller, like a lost connection to the Southbound database",
+				tool:    "pod-logs",
+				params:  map[string]any{"namespace": "{ovn_namespace}", "name": nodeOVNPod, "container": "ovn-controller", "pattern": "(?i)error|fail|connection", "tail": 100},
+			},
+			{
+				purpose: "Check the chassis of the node is registered in the Southbound database",
+				tool:    "ovn-show",
+				params:  map[string]any{"node": "{node}", "database": "sbdb"},
+			},
+			{
+				purpose: "Check br-int and the gateway bridge of the node exist in OVS, with their ports and no interface errors",
+				tool:    "ovs-vsctl-show",
+				params:  map[string]any{"node": "{node}"},
+			},
+			{
+				purpose: "Check the addresses of the node and of its management port ovn-k8s-mp0",
+				tool:    "get-ip",
+				params:  map[string]any{"node": "{node}", "command": "addr"},
+			},
+			{
+				purpose: "Check the routes of the node to the cluster and service networks",
+				tool:    "get-ip",
+				params:  map[string]any{"node": "{node}", "command": "route"},
+			},
+		},
+		offline: []step{
+			{
+				purpose: "Check the conditions of the node in the must-gather",
+				tool:    "must-gather-get-resource",
+				params:  map[string]any{"must_gather_path": "{must_gather_path}", "kind": "Node", "name": "{node}", "output_type": "yaml"},
+			},
+			{
+				purpose: "Find the ovnkube-node pod of the node",
+				tool:    "must-gather-list-resources",
+				params:  map[string]any{"must_gather_path": "{must_gather_path}", "kind": "Pod", "namespace": "{ovn_namespace}", "label_selector": "app=ovnkube-node", "output_type": "wide"},
+			},
+			{
+				purpose: "Look for errors of ovnkube-controller on the node",
+				tool:    "must-gather-pod-logs",
+				params:  map[string]any{"must_gather_path": "{must_gather_path}", "namespace": "{ovn_namespace}", "name": nodeOVNPod, "container": "ovnkube-controller", "pattern": "(?i)error|fail"},
+			},
+			{
+				purpose: "Find the Southbound database of the node",
+				tool:    "must-gather-list-southbound-databases",
+				params:  map[string]any{"must_gather_path": "{must_gather_path}"},
+			},
+			{
+				purpose: "Check the chassis of the node is registered",
+				tool:    "must-gather-query-database",
+				params:  map[string]any{"must_gather_path": "{must_gather_path}", "database_name": nodeSBDatabase, "table": "Chassis", "conditions": []string{`["hostname","==","{node}"]`}},
+			},
+			{
+				purpose: "With a sosreport of the node, find the collected OVS and routing commands",
+				tool:    "sos-search-commands",
+				params:  map[string]any{"sosreport_path": "{sosreport_path}", "pattern": "ovs-vsctl show|ip route|ip address"},
+			},
+		},
+		conclusion: "Report the component of the node that is failing and the evidence.",
+	},
+}
@@ -1,12 +1,16 @@
 package sosreport
 
 import (
+	"context"
 	"fmt"
 	"log"
+	"maps"
 	"os"
 	"path/filepath"
 	"regexp"
+	"slices"
 
+	"github.com/ovn-kubernetes/ovn-kubernetes-mcp/pkg/pagination"
 	"github.com/ovn-kubernetes/ovn-kubernetes-mcp/pkg/sosreport/types"
 )
 
@@ -16,7 +20,7 @@ const (
 )
 
 // getCommandOutput reads a command output file by filepath from manifest
-func getCommandOutput(sosreportPath, relativeFilepath, pattern string, maxLines int) (string, error) {
+func getCommandOutput(ctx context.Context, sosreportPath, relativeFilepath, pattern string, maxLines int) (string, error) {
 	if err := validateSosreportPath(sosreportPath); err != nil {
 		return "", err
 	}
@@ -53,7 +57,7 @@ func getCommandOutput(sosreportPath, relativeFilepath, pattern string, maxLines
 		maxLines = defaultResultLimit
 	}
 
-	output, err := readWithLimit(file, searchPattern, maxLines)
+	output, err := readWithLimit(ctx, file, searchPattern, maxLines)
 	if err != nil {
 		return "", err
 	}
@@ -118,7 +122,7 @@ func listCommands(sosreportPath, pluginName string) (types.ListCommandsResult, e
 }
 
 // searchCommands searches for commands matching a pattern across all plugins
-func searchCommands(sosreportPath, pattern string, maxResults int) (types.SearchCommandsResult, error) {
+func searchCommands(sosreportPath, pattern string, page pagination.Params) (types.SearchCommandsResult, error) {
 	manifest, err := loadManifest(sosreportPath)
 	if err != nil {
 		return types.SearchCommandsResult{}, err
@@ -129,30 +133,24 @@ func searchCommands(sosreportPath, pattern string, maxResults int) (types.Search
 		return types.SearchCommandsResult{}, fmt.Errorf("invalid search pattern: %w", err)
 	}
 
-	result := types.SearchCommandsResult{
-		Matches: []types.CommandMatch{},
-	}
-	if maxResults <= 0 {
-		maxResults = defaultResultLimit
-	}
-
-	for pluginName, plugin := range manifest.Components.Report.Plugins {
-		for _, cmd := range plugin.Commands {
+	// Search the plugins in a stable order so that the pages do not overlap.
+	matches := []types.CommandMatch{}
+	for _, pluginName := range slices.Sorted(maps.Keys(manifest.Components.Report.Plugins)) {
+		for _, cmd := range manifest.Components.Report.Plugins[pluginName].Commands {
 			if searchPattern.MatchString(cmd.Exec) || searchPattern.MatchString(cmd.Filepath) {
-				result.Matches = append(result.Matches, types.CommandMatch{
+				matches = append(matches, types.CommandMatch{
 					Plugin:   pluginName,
 					Exec:     cmd.Exec,
 					Filepath: cmd.Filepath,
 				})
-
-				if len(result.Matches) >= maxResults {
-					result.Total = len(result.Matches)
-					return result, nil
-				}
 			}
 		}
 	}
 
-	result.Total = len(result.Matches)
+	result := types.SearchCommandsResult{}
+	result.Matches, result.Result, err = pagination.Paginate(matches, page, sosreportPath, pattern)
+	if err != nil {
+		return types.SearchCommandsResult{}, err
+	}
 	return result, nil
 }
@@ -1,8 +1,13 @@
 package sosreport
 
 import (
+	"context"
+	"reflect"
 	"strings"
 	"testing"
+
+	"github.com/ovn-kubernetes/ovn-kubernetes-mcp/pkg/pagination"
+	"github.com/ovn-kubernetes/ovn-kubernetes-mcp/pkg/sosreport/types"
 )
 
 const sosreportTestData = "testdata/sosreport"
@@ -101,7 +106,7 @@ func TestGetCommandOutput(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			output, err := getCommandOutput(tt.sosreport, tt.filepath, tt.pattern, tt.maxLines)
+			output, err := getCommandOutput(context.Background(), tt.sosreport, tt.filepath, tt.pattern, tt.maxLines)
 			if tt.wantError {
 				if err == nil {
 					t.Errorf("getCommandOutput() expected error but got nil")
@@ -286,7 +291,7 @@ func TestSearchCommands(t *testing.T) {
 		name           string
 		path           string
 		pattern        string
-		maxResults     int
+		pageSize       int
 		wantError      bool
 		wantMinMatches int
 		wantMaxMatches int
@@ -295,7 +300,7 @@ func TestSearchCommands(t *testing.T) {
 			name:           "search for ovs commands",
 			path:           sosreportTestData,
 			pattern:        "ovs",
-			maxResults:     100,
+			pageSize:       100,
 			wantError:      false,
 			wantMinMatches: 2, // At least the 2 ovs commands
 			wantMaxMatches: 100,
@@ -304,7 +309,7 @@ func TestSearchCommands(t *testing.T) {
 			name:           "search for ip command",
 			path:           sosreportTestData,
 			pattern:        "ip.*show",
-			maxResults:     100,
+			pageSize:       100,
 			wantError:      false,
 			wantMinMatches: 1, // ip addr show
 			wantMaxMatches: 100,
@@ -313,23 +318,23 @@ func TestSearchCommands(t *testing.T) {
 			name:           "search with no matches",
 			path:           sosreportTestData,
 			pattern:        "nonexistent-pattern-xyz",
-			maxResults:     100,
+			pageSize:       100,
 			wantError:      false,
 			wantMinMatches: 0,
 			wantMaxMatches: 0,
 		},
 		{
-			name:       "invalid regex pattern",
-			path:       sosreportTestData,
-			pattern:    "[invalid(",
-			maxResults: 100,
-			wantError:  true,
+			name:      "invalid regex pattern",
+			path:      sosreportTestData,
+			pattern:   "[invalid(",
+			pageSize:  100,
+			wantError: true,
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			result, err := searchCommands(tt.path, tt.pattern, tt.maxResults)
+			result, err := searchCommands(tt.path, tt.pattern, pagination.Params{PageSize: tt.pageSize})
 			if tt.wantError {
 				if err == nil {
 					t.Errorf("searchCommands() expected error but got nil")
@@ -341,16 +346,21 @@ func TestSearchCommands(t *testing.T) {
 				return
 			}
 
-			if result.Total < tt.wantMinMatches {
-				t.Errorf("searchCommands() got %d matches, want at least %d", result.Total, tt.wantMinMatches)
+			if result.Total == nil {
+				t.Fatalf("searchCommands() expected the total number of matches")
+			}
+			total := *result.Total
+
+			if total < tt.wantMinMatches {
+				t.Errorf("searchCommands() got %d matches, want at least %d", total, tt.wantMinMatches)
 			}
 
-			if result.Total > tt.wantMaxMatches {
-				t.Errorf("searchCommands() got %d matches, want at most %d", result.Total, tt.wantMaxMatches)
+			if total > tt.wantMaxMatches {
+				t.Errorf("searchCommands() got %d matches, want at most %d", total, tt.wantMaxMatches)
 			}
 
-			if len(result.Matches) != result.Total {
-				t.Errorf("searchCommands() matches slice length %d doesn't match Total %d", len(result.Matches), result.Total)
+			if len(result.Matches) != total {
+				t.Errorf("searchCommands() matches slice length %d doesn't match Total %d", len(result.Matches), total)
 			}
 
 			// Verify match structure
@@ -368,3 +378,38 @@ func TestSearchCommands(t *testing.T) {
 		})
 	}
 }
+
+func TestSearchCommandsPagination(t *testing.T) {
+	all, err := searchCommands(sosreportTestData, ".", pagination.Params{})
+	if err != nil {
+		t.Fatalf("searchCommands() unexpected error = %v", err)
+	}
+	if all.NextCursor != "" || len(all.Matches) < 2 {
+		t.Fatalf("searchCommands() expected a single page with at least 2 matches, got %d", len(all.Matches))
+	}
+
+	var paged []types.CommandMatch
+	page := pagination.Params{PageSize: 1}
+	for {
+		result, err := searchCommands(sosreportTestData, ".", page)
+		if err != nil {
+			t.Fatalf("searchCommands() unexpected error = %v", err)
+		}
+		if result.Total == nil || *result.Total != len(all.Matches) {
+			t.Fatalf("searchCommands() got total %v, want %d", result.Total, len(all.Matches))
+		}
+		paged = append(paged, result.Matches...)
+		if result.NextCursor == "" {
+			break
+		}
+		page.Cursor = result.NextCursor
+	}
+	if !reflect.DeepEqual(paged, all.Matches) {
+		t.Errorf("searchCommands() pages = %v, want %v", paged, all.Matches)
+	}
+
+	// A cursor cannot be used with another pattern.
+	if _, err := searchCommands(sosreportTestData, "ovs", pagination.Params{Cursor: page.Cursor}); err == nil {
+		t.Errorf("searchCommands() expected an error for a cursor of another search")
+	}
+}
@@ -2,6 +2,7 @@ package sosreport
 
 import (
 	"compress/gzip"
+	"context"
 	"fmt"
 	"io"
 	"log"
@@ -12,7 +13,7 @@ import (
 )
 
 // searchPodLogs searches pod logs using the manifest to find log files
-func searchPodLogs(sosreportPath, pattern, podFilter string, maxResults int) (string, error) {
+func searchPodLogs(ctx context.Context, sosreportPath, pattern, podFilter string, maxResults int) (string, error) {
 	searchPattern, err := regexp.Compile(pattern)
 	if err != nil {
 		return "", fmt.Errorf("invalid search pattern: %w", err)
@@ -46,7 +47,7 @@ func searchPodLogs(sosreportPath, pattern, podFilter string, maxResults int) (st
 
 			fullPath := filepath.Join(sosreportPath, logPath)
 
-			matches, err := searchInFile(fullPath, searchPattern, maxResults-totalMatches)
+			matches, err := searchInFile(ctx, fullPath, searchPattern, maxResults-totalMatches)
 			if err != nil {
 				return "", err
 			}
@@ -72,7 +73,7 @@ func searchPodLogs(sosreportPath, pattern, podFilter string, maxResults int) (st
 
 // searchInFile searches in a file (handles both regular and gzip compressed files)
 // Returns the matches and an error
-func searchInFile(filePath string, pattern *regexp.Regexp, maxLines int) (string, error) {
+func searchInFile(ctx context.Context, filePath string, pattern *regexp.Regexp, maxLines int) (string, error) {
 	file, err := os.Open(filePath)
 	if err != nil {
 		return "", err
@@ -101,5 +102,5 @@ func searchInFile(filePath string, pattern *regexp.Regexp, maxLines int) (string
 	}
 
 	// Read with limit directly from the reader
-	return readWithLimit(reader, pattern, maxLines)
+	return readWithLimit(ctx, reader, pattern, maxLines)
 }
@@ -1,6 +1,7 @@
 package sosreport
 
 import (
+	"context"
 	"strings"
 	"testing"
 )
@@ -90,7 +91,7 @@ func TestSearchPodLogs(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			result, err := searchPodLogs(tt.sosreport, tt.pattern, tt.podFilter, tt.maxResults)
+			result, err := searchPodLogs(context.Background(), tt.sosreport, tt.pattern, tt.podFilter, tt.maxResults)
 			if tt.wantError {
 				if err == nil {
 					t.Errorf("searchPodLogs() expected error but got nil")
@@ -5,6 +5,7 @@ import (
 	"fmt"
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/ovn-kubernetes/ovn-kubernetes-mcp/pkg/pagination"
 	"github.com/ovn-kubernetes/ovn-kubernetes-mcp/pkg/sosreport/types"
 )
 
@@ -69,15 +70,17 @@ Example:
 Parameters:
 - sosreport_path (required): Path to extracted sosreport directory
 - pattern (required): Regex pattern to search in command exec and filepath
-- max_results (optional): Maximum results to return (default: %d)
+- page_size (optional): Number of matches per page (default: %d, max: %d)
+- cursor (optional): next_cursor of the previous page, to get the next page
 
-Searches command names and filepaths across all plugins. Returns matching commands
-with their plugin, exec string, and filepath. Does NOT return file contents.
+Searches command names and filepaths across all plugins. Returns a page of the matching
+commands with their plugin, exec string, and filepath, the total number of matches, and
+a next_cursor until the last page. Does NOT return file contents.
 
 Examples:
 - pattern='iptables' finds all iptables-related commands
 - pattern='ovn.*show' finds OVN show commands
-- pattern='journalctl.*kubelet' finds kubelet journal logs`, defaultResultLimit),
+- pattern='journalctl.*kubelet' finds kubelet journal logs`, pagination.DefaultPageSize, pagination.MaxPageSize),
 		}, s.SearchCommands)
 
 	// Get command output
@@ -142,7 +145,7 @@ func (s *MCPServer) ListCommands(ctx context.Context, req *mcp.CallToolRequest,
 
 // SearchCommands searches for commands across all plugins
 func (s *MCPServer) SearchCommands(ctx context.Context, req *mcp.CallToolRequest, in types.SearchCommandsParams) (*mcp.CallToolResult, types.SearchCommandsResult, error) {
-	result, err := searchCommands(in.SosreportPath, in.Pattern, in.MaxResults)
+	result, err := searchCommands(in.SosreportPath, in.Pattern, in.Params)
 	if err != nil {
 		return nil, types.SearchCommandsResult{}, err
 	}
@@ -151,7 +154,7 @@ func (s *MCPServer) SearchCommands(ctx context.Context, req *mcp.CallToolRequest
 
 // GetCommand retrieves command output by filepath
 func (s *MCPServer) GetCommand(ctx context.Context, req *mcp.CallToolRequest, in types.GetCommandParams) (*mcp.CallToolResult, types.GetCommandResult, error) {
-	output, err := getCommandOutput(in.SosreportPath, in.Filepath, in.Pattern, in.MaxLines)
+	output, err := getCommandOutput(ctx, in.SosreportPath, in.Filepath, in.Pattern, in.MaxLines)
 	if err != nil {
 		return nil, types.GetCommandResult{}, err
 	}
@@ -160,7 +163,7 @@ func (s *MCPServer) GetCommand(ctx context.Context, req *mcp.CallToolRequest, in
 
 // SearchPodLogs searches pod logs using the manifest
 func (s *MCPServer) SearchPodLogs(ctx context.Context, req *mcp.CallToolRequest, in types.SearchPodLogsParams) (*mcp.CallToolResult, types.SearchPodLogsResult, error) {
-	output, err := searchPodLogs(in.SosreportPath, in.Pattern, in.PodFilter, in.MaxResults)
+	output, err := searchPodLogs(ctx, in.SosreportPath, in.Pattern, in.PodFilter, in.MaxResults)
 	if err != nil {
 		return nil, types.SearchPodLogsResult{}, err
 	}
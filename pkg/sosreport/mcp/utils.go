@@ -2,6 +2,7 @@ package sosreport
 
 import (
 	"bufio"
+	"context"
 	"errors"
 	"fmt"
 	"io"
@@ -9,6 +10,8 @@ import (
 	"path/filepath"
 	"regexp"
 	"strings"
+
+	"github.com/ovn-kubernetes/ovn-kubernetes-mcp/pkg/artifacts"
 )
 
 // validateSosreportPath validates that the path looks like a sosreport directory
@@ -40,8 +43,10 @@ func validateRelativePath(relPath string) error {
 	return nil
 }
 
-// readWithLimit reads from a reader with a line limit
-func readWithLimit(reader io.Reader, pattern *regexp.Regexp, maxLines int) (string, error) {
+// readWithLimit reads from a reader with a line limit. When the limit is reached,
+// the remaining matching lines are still read to save all of them as an artifact of
+// the tool call, if the context has an artifact store.
+func readWithLimit(ctx context.Context, reader io.Reader, pattern *regexp.Regexp, maxLines int) (string, error) {
 	var result strings.Builder
 	scanner := bufio.NewScanner(reader)
 
@@ -52,23 +57,51 @@ func readWithLimit(reader io.Reader, pattern *regexp.Regexp, maxLines int) (stri
 	scanner.Buffer(buf, 1024*1024)
 
 	lineCount := 0
+	truncated := false
+	var artifact *artifacts.Writer
 	for scanner.Scan() {
-		if pattern == nil || pattern.MatchString(scanner.Text()) {
-			result.WriteString(scanner.Text())
-			result.WriteString("\n")
-			lineCount++
+		if pattern != nil && !pattern.MatchString(scanner.Text()) {
+			continue
+		}
+		if truncated {
+			if artifact == nil {
+				// Only save an artifact if there are more lines than returned.
+				if artifact = artifacts.Create(ctx); artifact == nil {
+					break
+				}
+				_, _ = artifact.Write([]byte(result.String()))
+			}
+			_, _ = artifact.Write(append(scanner.Bytes(), '\n'))
+			continue
 		}
+		result.WriteString(scanner.Text())
+		result.WriteString("\n")
+		lineCount++
 
 		if maxLines > 0 && lineCount >= maxLines {
-			fmt.Fprintf(&result, "\n... (output truncated at %d lines)\n", maxLines)
-			break
+			truncated = true
+			if !artifacts.Enabled(ctx) {
+				break
+			}
 		}
-
 	}
 
 	if err := scanner.Err(); err != nil {
+		if artifact != nil {
+			artifact.Discard()
+		}
 		return "", err
 	}
 
+	if truncated {
+		fmt.Fprintf(&result, "\n... (output truncated at %d lines", maxLines)
+		if artifact != nil {
+			if saved := artifacts.Commit(artifact); saved != nil {
+				fmt.Fprintf(&result, ", all %d matching lines available at %s", saved.Lines, saved.URI())
+			}
+		}
+		result.WriteString(")\n")
+	}
+
 	return result.String(), nil
 }
@@ -1,10 +1,13 @@
 package sosreport
 
 import (
+	"context"
 	"os"
 	"regexp"
 	"strings"
 	"testing"
+
+	"github.com/ovn-kubernetes/ovn-kubernetes-mcp/pkg/artifacts"
 )
 
 func TestReadWithLimit(t *testing.T) {
@@ -106,7 +109,7 @@ line 10: DEBUG more
 				searchPattern = regexp.MustCompile(tt.pattern)
 			}
 
-			result, err := readWithLimit(file, searchPattern, tt.maxLines)
+			result, err := readWithLimit(context.Background(), file, searchPattern, tt.maxLines)
 			if err != nil {
 				t.Errorf("readWithLimit() unexpected error = %v", err)
 				return
@@ -152,6 +155,36 @@ line 10: DEBUG more
 	}
 }
 
+func TestReadWithLimitArtifact(t *testing.T) {
+	store, err := artifacts.NewStore(artifacts.Config{Dir: t.TempDir()})
+	if err != nil {
+		t.Fatalf("failed to create artifact store: %v", err)
+	}
+	defer store.Close()
+	ctx, saved := artifacts.WithCollector(context.Background(), store, "sos-get-command", "")
+
+	input := "ERROR 1\nINFO 2\nERROR 3\nERROR 4\nERROR 5\n"
+	result, err := readWithLimit(ctx, strings.NewReader(input), regexp.MustCompile("ERROR"), 2)
+	if err != nil {
+		t.Fatalf("readWithLimit() unexpected error = %v", err)
+	}
+	if len(saved()) != 1 {
+		t.Fatalf("readWithLimit() expected one artifact, got %d", len(saved()))
+	}
+	artifact := saved()[0]
+	if artifact.Lines != 4 || !strings.Contains(result, artifact.URI()) {
+		t.Errorf("readWithLimit() unexpected artifact %+v for result:\n%s", artifact, result)
+	}
+
+	// No artifact is saved when the limit is reached by the last matching line.
+	if _, err := readWithLimit(ctx, strings.NewReader(input), regexp.MustCompile("INFO"), 1); err != nil {
+		t.Fatalf("readWithLimit() unexpected error = %v", err)
+	}
+	if len(saved()) != 1 {
+		t.Errorf("readWithLimit() expected no new artifact, got %d artifacts", len(saved()))
+	}
+}
+
 func TestValidateRelativePath(t *testing.T) {
 	tests := []struct {
 		name    string
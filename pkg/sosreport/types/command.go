@@ -1,5 +1,9 @@
 package types
 
+import (
+	"github.com/ovn-kubernetes/ovn-kubernetes-mcp/pkg/pagination"
+)
+
 // ListCommandsParams are the parameters for sos-list-commands
 type ListCommandsParams struct {
 	SosreportPath string `json:"sosreport_path"`
@@ -23,13 +27,13 @@ type CommandSummary struct {
 type SearchCommandsParams struct {
 	SosreportPath string `json:"sosreport_path"`
 	Pattern       string `json:"pattern"`
-	MaxResults    int    `json:"max_results,omitempty"`
+	pagination.Params
 }
 
-// SearchCommandsResult returns commands matching the pattern
+// SearchCommandsResult returns a page of the commands matching the pattern
 type SearchCommandsResult struct {
 	Matches []CommandMatch `json:"matches"`
-	Total   int            `json:"total"`
+	pagination.Result
 }
 
 // CommandMatch represents a command that matches the search
@@ -0,0 +1,167 @@
+package topology
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	k8stypes "github.com/ovn-kubernetes/ovn-kubernetes-mcp/pkg/kubernetes/types"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// commandSockets are the sockets the commands of the OVN and OVS tools connect to.
+// The OVS commands not listed connect to the OVS database.
+var commandSockets = map[string]string{
+	"ovn-nbctl": "/var/run/ovn/ovnnb_db.sock",
+	"ovn-sbctl": "/var/run/ovn/ovnsb_db.sock",
+	"ovn-trace": "/var/run/ovn/ovnsb_db.sock",
+}
+
+// ovsSocket is the socket of the OVS database.
+const ovsSocket = "/var/run/openvswitch/db.sock"
+
+// fallbackRoles are the components whose containers usually have the command of
+// a component and its socket too, when the pod has no container running it.
+var fallbackRoles = map[Role][]Role{
+	RoleNorthboundDB: {RoleNorthd},
+	RoleSouthboundDB: {RoleNorthd, RoleOVNController},
+	RoleOVS:          {RoleOVNController},
+}
+
+// probeScript exits with 2 if the command of its first argument is not found, and
+// with 3 if the socket of its second argument does not exist.
+const probeScript = `command -v "$1" >/dev/null || exit 2; [ -S "$2" ] || exit 3`
+
+// cachedContainer is a container selected at a time.
+type cachedContainer struct {
+	container string
+	expiry    time.Time
+}
+
+// commandRole returns the component a command connects to.
+func commandRole(command string) Role {
+	switch {
+	case command == "ovn-nbctl":
+		return RoleNorthboundDB
+	case strings.HasPrefix(command, "ovs-"):
+		return RoleOVS
+	default:
+		return RoleSouthboundDB
+	}
+}
+
+// commandSocket returns the socket a command connects to.
+func commandSocket(command string) string {
+	if socket, found := commandSockets[command]; found {
+		return socket
+	}
+	return ovsSocket
+}
+
+// selectContainer returns the container of the pod with the command and its
+// socket. The containers of the pod are probed in turn, those running the
+// component of the command first, unless the pod has a single container. The
+// selected container is cached like the topology.
+func (d *Discoverer) selectContainer(ctx context.Context, req *mcp.CallToolRequest, namespacedName k8stypes.NamespacedNameParams,
+	pod *Pod, command string, role Role) (string, error) {
+	key := cacheKey(ctx, namespacedName.Namespace, namespacedName.Name, command)
+	d.mu.Lock()
+	cached, found := d.containers[key]
+	d.mu.Unlock()
+	if found && d.now().Before(cached.expiry) {
+		return cached.container, nil
+	}
+
+	if pod == nil {
+		var err error
+		if pod, err = d.getPod(ctx, namespacedName); err != nil {
+			return "", err
+		}
+	}
+	candidates := candidateContainers(pod.Containers, role)
+	if len(candidates) == 1 {
+		return candidates[0].Name, nil
+	}
+
+	socket := commandSocket(command)
+	var probed []string
+	for _, container := range candidates {
+		_, _, err := d.cluster.ExecPod(ctx, req, k8stypes.ExecPodParams{
+			NamespacedNameParams: namespacedName,
+			Container:            container.Name,
+			Command:              []string{"sh", "-c", probeScript, "probe", command, socket},
+		})
+		if err == nil {
+			d.cacheContainer(key, container.Name)
+			return container.Name, nil
+		}
+		probed = append(probed, fmt.Sprintf("%s (%s)", container.Name, probeError(err, command, socket)))
+	}
+	return "", fmt.Errorf("no container of pod %s/%s has %s and socket %s, probed containers: %s",
+		namespacedName.Namespace, namespacedName.Name, command, socket, strings.Join(probed, ", "))
+}
+
+// cacheContainer caches the container selected for a key, and drops the expired
+// ones.
+func (d *Discoverer) cacheContainer(key, container string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	now := d.now()
+	for key, cached := range d.containers {
+		if !now.Before(cached.expiry) {
+			delete(d.containers, key)
+		}
+	}
+	d.containers[key] = cachedContainer{container: container, expiry: now.Add(d.ttl)}
+}
+
+// getPod returns the containers of a pod that is not looked up in the topology.
+func (d *Discoverer) getPod(ctx context.Context, namespacedName k8stypes.NamespacedNameParams) (*Pod, error) {
+	item, err := d.cluster.Get(ctx, "", "v1", "Pod", namespacedName.Namespace, namespacedName.Name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get pod %s/%s: %w", namespacedName.Namespace, namespacedName.Name, err)
+	}
+	pods, err := fromUnstructured[corev1.Pod]([]unstructured.Unstructured{*item})
+	if err != nil {
+		return nil, err
+	}
+	pod := &Pod{Name: pods[0].Name, Node: pods[0].Spec.NodeName, Phase: string(pods[0].Status.Phase)}
+	for _, container := range pods[0].Spec.Containers {
+		pod.Containers = append(pod.Containers, Container{Name: container.Name, Role: containerRoles[container.Name]})
+	}
+	return pod, nil
+}
+
+// candidateContainers returns the containers in the order they are probed: those
+// running the component, then those running its fallback components, then the
+// others in the order of the pod.
+func candidateContainers(containers []Container, role Role) []Container {
+	rank := func(container Container) int {
+		if container.Role == role {
+			return 0
+		}
+		if index := slices.Index(fallbackRoles[role], container.Role); index >= 0 {
+			return index + 1
+		}
+		return len(fallbackRoles[role]) + 1
+	}
+	candidates := slices.Clone(containers)
+	slices.SortStableFunc(candidates, func(a, b Container) int { return rank(a) - rank(b) })
+	return candidates
+}
+
+// probeError returns why a container was not selected.
+func probeError(err error, command, socket string) string {
+	switch {
+	case strings.HasSuffix(err.Error(), "exit code 2"):
+		return command + " not found"
+	case strings.HasSuffix(err.Error(), "exit code 3"):
+		return "socket " + socket + " not found"
+	default:
+		return err.Error()
+	}
+}
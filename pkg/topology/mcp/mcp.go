@@ -0,0 +1,86 @@
+package mcp
+
+import (
+	"context"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/ovn-kubernetes/ovn-kubernetes-mcp/pkg/topology"
+	"github.com/ovn-kubernetes/ovn-kubernetes-mcp/pkg/topology/types"
+)
+
+// MCPServer provides the tool describing the OVN-Kubernetes topology of the
+// clusters.
+type MCPServer struct {
+	discoverer *topology.Discoverer
+}
+
+// NewMCPServer creates a new MCP server describing the topology discovered by the
+// discoverer.
+func NewMCPServer(discoverer *topology.Discoverer) *MCPServer {
+	return &MCPServer{discoverer: discoverer}
+}
+
+// AddTools registers the topology tools with the MCP server.
+func (s *MCPServer) AddTools(server *mcp.Server) {
+	mcp.AddTool(server,
+		&mcp.Tool{
+			Name: "ovnk-topology",
+			Description: `Discover the OVN-Kubernetes deployment of the cluster, and the pods and containers of every node.
+
+Detects the namespace of the OVN-Kubernetes pods ("ovn-kubernetes" or "openshift-ovn-kubernetes"),
+the platform (upstream or openshift), the mode (interconnect, with the OVN databases and northd of
+every zone in the ovnkube-node pods, or central, with the OVN databases in the control plane pods),
+and the gateway mode (shared or local) of the nodes.
+
+For every node, returns its zone, gateway mode and OVN-Kubernetes pods with the component of every
+container (nbdb, sbdb, northd, ovn-controller, ovs or ovnkube). In central mode, also returns the
+control plane pods and the raft role (leader, follower or standalone) of their database servers.
+
+The OVN and OVS tools take a node parameter instead of the namespace and name of a pod, and run
+their commands in the pod of the node resolved from this topology. The topology is cached for 30
+seconds.
+
+Parameters:
+- cluster: Cluster to discover, from cluster-list (optional, defaults to the default cluster)
+- namespace (optional): Namespace of the OVN-Kubernetes pods, detected if not set
+- refresh (optional): Discover the topology again rather than returning the cached one
+
+Example output:
+{
+  "namespace": "ovn-kubernetes",
+  "platform": "upstream",
+  "mode": "interconnect",
+  "gateway_mode": "shared",
+  "nodes": [
+    {
+      "name": "ovn-worker",
+      "zone": "ovn-worker",
+      "gateway_mode": "shared",
+      "pods": [
+        {
+          "name": "ovnkube-node-x7k2m",
+          "node": "ovn-worker",
+          "phase": "Running",
+          "containers": [
+            {"name": "nb-ovsdb", "role": "nbdb"},
+            {"name": "sb-ovsdb", "role": "sbdb"},
+            {"name": "ovn-northd", "role": "northd"},
+            {"name": "ovnkube-controller", "role": "ovnkube"},
+            {"name": "ovn-controller", "role": "ovn-controller"}
+          ]
+        }
+      ]
+    }
+  ]
+}`,
+		}, s.Topology)
+}
+
+// Topology returns the OVN-Kubernetes topology of the cluster.
+func (s *MCPServer) Topology(ctx context.Context, req *mcp.CallToolRequest, in types.TopologyParams) (*mcp.CallToolResult, types.TopologyResult, error) {
+	topology, err := s.discoverer.Discover(ctx, req, in.Namespace, in.Refresh)
+	if err != nil {
+		return nil, types.TopologyResult{}, err
+	}
+	return nil, types.TopologyResult{Topology: *topology}, nil
+}
@@ -0,0 +1,510 @@
+// Package topology discovers the OVN-Kubernetes deployment of a cluster: its
+// namespace and flavor, the ovnkube pods of every node and the components their
+// containers run, the leaders of the OVN databases and the gateway mode. The OVN
+// and OVS tools use it to run their commands on a node rather than in a pod.
+package topology
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/ovn-kubernetes/ovn-kubernetes-mcp/pkg/clusters"
+	"github.com/ovn-kubernetes/ovn-kubernetes-mcp/pkg/kubernetes/client"
+	k8stypes "github.com/ovn-kubernetes/ovn-kubernetes-mcp/pkg/kubernetes/types"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// DefaultTTL is how long a discovered topology is cached.
+const DefaultTTL = 30 * time.Second
+
+// DefaultNamespaces are the namespaces of the upstream and OpenShift deployments,
+// where the OVN-Kubernetes pods are looked up.
+var DefaultNamespaces = []string{"ovn-kubernetes", "openshift-ovn-kubernetes"}
+
+// openshiftNamespace is the namespace of the OVN-Kubernetes pods on OpenShift.
+const openshiftNamespace = "openshift-ovn-kubernetes"
+
+// componentSelector selects the pods of the OVN-Kubernetes components in the
+// upstream and OpenShift deployments.
+const componentSelector = "app in (ovnkube-node,ovnkube-master,ovnkube-db,ovnkube-control-plane,ovs-node)"
+
+// Node annotations set by OVN-Kubernetes.
+const (
+	zoneAnnotation          = "k8s.ovn.org/zone-name"
+	gatewayConfigAnnotation = "k8s.ovn.org/l3-gateway-config"
+)
+
+// Role is the OVN-Kubernetes component a container runs.
+type Role string
+
+const (
+	RoleNorthboundDB  Role = "nbdb"
+	RoleSouthboundDB  Role = "sbdb"
+	RoleNorthd        Role = "northd"
+	RoleOVNController Role = "ovn-controller"
+	RoleOVS           Role = "ovs"
+	RoleOVNKube       Role = "ovnkube"
+)
+
+// containerRoles maps the names of the containers of the upstream and OpenShift
+// deployments to the component they run.
+var containerRoles = map[string]Role{
+	"nbdb":                    RoleNorthboundDB,
+	"nb-ovsdb":                RoleNorthboundDB,
+	"sbdb":                    RoleSouthboundDB,
+	"sb-ovsdb":                RoleSouthboundDB,
+	"northd":                  RoleNorthd,
+	"ovn-northd":              RoleNorthd,
+	"ovn-controller":          RoleOVNController,
+	"ovs-daemons":             RoleOVS,
+	"ovnkube-controller":      RoleOVNKube,
+	"ovnkube-node":            RoleOVNKube,
+	"ovnkube-master":          RoleOVNKube,
+	"ovnkube-cluster-manager": RoleOVNKube,
+	"ovnkube-control-plane":   RoleOVNKube,
+}
+
+// databaseControls are the control sockets and names of the OVN databases, to get
+// their cluster status.
+var databaseControls = map[Role][2]string{
+	RoleNorthboundDB: {"/var/run/ovn/ovnnb_db.ctl", "OVN_Northbound"},
+	RoleSouthboundDB: {"/var/run/ovn/ovnsb_db.ctl", "OVN_Southbound"},
+}
+
+// Platform is the distribution OVN-Kubernetes is deployed with.
+type Platform string
+
+const (
+	PlatformUpstream  Platform = "upstream"
+	PlatformOpenShift Platform = "openshift"
+)
+
+// Mode is how the OVN databases are deployed.
+type Mode string
+
+const (
+	// ModeInterconnect runs the OVN databases and northd of every zone, usually
+	// a node, in the ovnkube-node pods.
+	ModeInterconnect Mode = "interconnect"
+	// ModeCentral runs the OVN databases of the cluster in the control plane pods.
+	ModeCentral Mode = "central"
+)
+
+// Topology is the OVN-Kubernetes deployment of a cluster.
+type Topology struct {
+	Namespace string   `json:"namespace"`
+	Platform  Platform `json:"platform"`
+	Mode      Mode     `json:"mode"`
+	// GatewayMode is the gateway mode of the nodes, shared or local, or mixed if
+	// they do not have the same one.
+	GatewayMode  string     `json:"gateway_mode,omitempty"`
+	Nodes        []Node     `json:"nodes"`
+	ControlPlane []Pod      `json:"control_plane,omitempty"`
+	Databases    []Database `json:"databases,omitempty"`
+}
+
+// Node is a node of the cluster and the OVN-Kubernetes pods running on it.
+type Node struct {
+	Name        string `json:"name"`
+	Zone        string `json:"zone,omitempty"`
+	GatewayMode string `json:"gateway_mode,omitempty"`
+	Pods        []Pod  `json:"pods"`
+}
+
+// Pod is an OVN-Kubernetes pod.
+type Pod struct {
+	Name       string      `json:"name"`
+	Node       string      `json:"node,omitempty"`
+	Phase      string      `json:"phase"`
+	Containers []Container `json:"containers"`
+}
+
+// Container is a container of an OVN-Kubernetes pod and the component it runs.
+type Container struct {
+	Name string `json:"name"`
+	Role Role   `json:"role,omitempty"`
+}
+
+// Database is the cluster status of an OVN database server of the control plane.
+type Database struct {
+	Database  Role   `json:"database"`
+	Pod       string `json:"pod"`
+	Container string `json:"container"`
+	// Role is the role of the server in the raft cluster, leader, follower or
+	// candidate, or standalone if the database is not clustered.
+	Role  string `json:"role,omitempty"`
+	Error string `json:"error,omitempty"`
+}
+
+// container returns the container of the pod running a component, if any.
+func (p *Pod) container(role Role) (Container, bool) {
+	for _, container := range p.Containers {
+		if container.Role == role {
+			return container, true
+		}
+	}
+	return Container{}, false
+}
+
+// Cluster gets the resources and runs the commands the topology is discovered
+// with, as the user of the tool call.
+type Cluster interface {
+	Get(ctx context.Context, group, version, kind, namespace, name string) (*unstructured.Unstructured, error)
+	List(ctx context.Context, group, version, kind, namespace, labelSelector string) ([]unstructured.Unstructured, error)
+	ExecPod(ctx context.Context, req *mcp.CallToolRequest, in k8stypes.ExecPodParams) (*mcp.CallToolResult, k8stypes.ExecPodResult, error)
+}
+
+// PodResolver resolves the pod and container the OVN and OVS tools run their
+// commands in.
+type PodResolver interface {
+	// Resolve returns the pod and container of the target to run the command in:
+	// the pod of the target if it is named, the OVN-Kubernetes pod of its node
+	// running the component the command connects to otherwise, and the container
+	// of the target if it is set, the container of the pod with the command and
+	// its socket otherwise. The command of the result is not set.
+	Resolve(ctx context.Context, req *mcp.CallToolRequest, target k8stypes.PodTargetParams, command string) (k8stypes.ExecPodParams, error)
+}
+
+// Discoverer discovers the topology of the clusters, and caches it.
+type Discoverer struct {
+	cluster    Cluster
+	namespaces []string
+	ttl        time.Duration
+	now        func() time.Time
+
+	mu         sync.Mutex
+	cache      map[string]cachedTopology
+	containers map[string]cachedContainer
+}
+
+var _ PodResolver = &Discoverer{}
+
+// cachedTopology is a topology discovered at a time.
+type cachedTopology struct {
+	topology *Topology
+	expiry   time.Time
+}
+
+// NewDiscoverer creates a discoverer looking up the OVN-Kubernetes pods in the
+// first of namespaces that has some, and caching the topology for ttl.
+func NewDiscoverer(cluster Cluster, namespaces []string, ttl time.Duration) *Discoverer {
+	return &Discoverer{
+		cluster:    cluster,
+		namespaces: namespaces,
+		ttl:        ttl,
+		now:        time.Now,
+		cache:      map[string]cachedTopology{},
+		containers: map[string]cachedContainer{},
+	}
+}
+
+// Discover returns the topology of the cluster of the tool call, looking up the
+// OVN-Kubernetes pods in namespace if set. The topology is discovered again if
+// refresh is set or if it was discovered more than ttl ago. It is cached per
+// cluster and impersonated user, who may not see the same pods.
+func (d *Discoverer) Discover(ctx context.Context, req *mcp.CallToolRequest, namespace string, refresh bool) (*Topology, error) {
+	key := cacheKey(ctx, namespace)
+	d.mu.Lock()
+	cached, found := d.cache[key]
+	d.mu.Unlock()
+	if found && !refresh && d.now().Before(cached.expiry) {
+		return cached.topology, nil
+	}
+
+	topology, err := d.discover(ctx, req, namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	now := d.now()
+	for key, cached := range d.cache {
+		if !now.Before(cached.expiry) {
+			delete(d.cache, key)
+		}
+	}
+	d.cache[key] = cachedTopology{topology: topology, expiry: now.Add(d.ttl)}
+	return topology, nil
+}
+
+// cacheKey returns the key of the cluster of the tool call, the impersonated user
+// and the names in the caches.
+func cacheKey(ctx context.Context, names ...string) string {
+	key := strings.Join(append([]string{clusters.Name(ctx)}, names...), "/")
+	if impersonation := client.ImpersonationFrom(ctx); impersonation != nil {
+		key += "/" + impersonation.UserName
+	}
+	return key
+}
+
+// discover discovers the topology of the cluster of the tool call.
+func (d *Discoverer) discover(ctx context.Context, req *mcp.CallToolRequest, namespace string) (*Topology, error) {
+	namespaces := d.namespaces
+	if namespace != "" {
+		namespaces = []string{namespace}
+	}
+	var pods []corev1.Pod
+	var errs []error
+	for _, candidate := range namespaces {
+		items, err := d.cluster.List(ctx, "", "v1", "Pod", candidate, componentSelector)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to list the OVN-Kubernetes pods of namespace %s: %w", candidate, err))
+			continue
+		}
+		if pods, err = fromUnstructured[corev1.Pod](items); err != nil {
+			return nil, err
+		}
+		if len(pods) > 0 {
+			namespace = candidate
+			break
+		}
+	}
+	if len(pods) == 0 {
+		errs = append(errs, fmt.Errorf("no OVN-Kubernetes pods found in namespaces %s", strings.Join(namespaces, ", ")))
+		return nil, errors.Join(errs...)
+	}
+
+	items, err := d.cluster.List(ctx, "", "v1", "Node", "", "")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list the nodes: %w", err)
+	}
+	nodes, err := fromUnstructured[corev1.Node](items)
+	if err != nil {
+		return nil, err
+	}
+
+	topology := build(namespace, pods, nodes)
+	if topology.Mode == ModeCentral {
+		topology.Databases = d.databases(ctx, req, topology)
+	}
+	return topology, nil
+}
+
+// build builds the topology of the OVN-Kubernetes pods of a namespace and of the
+// nodes of the cluster.
+func build(namespace string, pods []corev1.Pod, nodes []corev1.Node) *Topology {
+	topology := &Topology{Namespace: namespace, Platform: PlatformUpstream, Mode: ModeCentral, Nodes: []Node{}}
+	if namespace == openshiftNamespace {
+		topology.Platform = PlatformOpenShift
+	}
+
+	nodePods := map[string][]Pod{}
+	for _, pod := range pods {
+		p := Pod{Name: pod.Name, Node: pod.Spec.NodeName, Phase: string(pod.Status.Phase), Containers: []Container{}}
+		for _, container := range pod.Spec.Containers {
+			p.Containers = append(p.Containers, Container{Name: container.Name, Role: containerRoles[container.Name]})
+		}
+		switch pod.Labels["app"] {
+		case "ovnkube-node", "ovs-node":
+			nodePods[pod.Spec.NodeName] = append(nodePods[pod.Spec.NodeName], p)
+			// The ovnkube-node pods run the databases of their zone with
+			// interconnect.
+			if _, found := p.container(RoleNorthboundDB); found {
+				topology.Mode = ModeInterconnect
+			}
+		default:
+			topology.ControlPlane = append(topology.ControlPlane, p)
+		}
+	}
+
+	gatewayModes := map[string]bool{}
+	for _, node := range nodes {
+		n := Node{
+			Name:        node.Name,
+			Zone:        node.Annotations[zoneAnnotation],
+			GatewayMode: gatewayMode(node.Annotations[gatewayConfigAnnotation]),
+			Pods:        nodePods[node.Name],
+		}
+		if n.Pods == nil {
+			n.Pods = []Pod{}
+		}
+		// Every node has its own zone with interconnect, unless the nodes are
+		// grouped in multi-node zones.
+		if n.Zone != "" && n.Zone != "global" {
+			topology.Mode = ModeInterconnect
+		}
+		if n.GatewayMode != "" {
+			gatewayModes[n.GatewayMode] = true
+		}
+		topology.Nodes = append(topology.Nodes, n)
+	}
+	switch len(gatewayModes) {
+	case 0:
+	case 1:
+		for mode := range gatewayModes {
+			topology.GatewayMode = mode
+		}
+	default:
+		topology.GatewayMode = "mixed"
+	}
+	slices.SortFunc(topology.Nodes, func(a, b Node) int { return strings.Compare(a.Name, b.Name) })
+	slices.SortFunc(topology.ControlPlane, func(a, b Pod) int { return strings.Compare(a.Name, b.Name) })
+	return topology
+}
+
+// gatewayMode returns the gateway mode of the l3-gateway-config annotation of a
+// node, empty if it is not set.
+func gatewayMode(annotation string) string {
+	if annotation == "" {
+		return ""
+	}
+	var config map[string]struct {
+		Mode string `json:"mode"`
+	}
+	if err := json.Unmarshal([]byte(annotation), &config); err != nil {
+		return ""
+	}
+	return config["default"].Mode
+}
+
+// databases returns the cluster status of the database servers of the control
+// plane.
+func (d *Discoverer) databases(ctx context.Context, req *mcp.CallToolRequest, topology *Topology) []Database {
+	var databases []Database
+	for _, pod := range topology.ControlPlane {
+		for _, role := range []Role{RoleNorthboundDB, RoleSouthboundDB} {
+			container, found := pod.container(role)
+			if !found || pod.Phase != string(corev1.PodRunning) {
+				continue
+			}
+			database := Database{Database: role, Pod: pod.Name, Container: container.Name}
+			control := databaseControls[role]
+			_, result, err := d.cluster.ExecPod(ctx, req, k8stypes.ExecPodParams{
+				NamespacedNameParams: k8stypes.NamespacedNameParams{Namespace: topology.Namespace, Name: pod.Name},
+				Container:            container.Name,
+				Command:              []string{"ovn-appctl", "-t", control[0], "cluster/status", control[1]},
+			})
+			switch {
+			case err != nil:
+				database.Error = err.Error()
+			case strings.Contains(result.Stderr, "not clustered") || strings.Contains(result.Stderr, "not a clustered"):
+				database.Role = "standalone"
+			case result.Stderr != "":
+				database.Error = strings.TrimSpace(result.Stderr)
+			default:
+				database.Role = raftRole(result.Stdout)
+			}
+			databases = append(databases, database)
+		}
+	}
+	return databases
+}
+
+// raftRole returns the role of a database server in the output of cluster/status.
+func raftRole(status string) string {
+	for _, line := range strings.Split(status, "\n") {
+		if role, found := strings.CutPrefix(strings.TrimSpace(line), "Role:"); found {
+			return strings.TrimSpace(role)
+		}
+	}
+	return ""
+}
+
+// Resolve returns the pod and container of the target to run the command in. The
+// databases and northd of a node are the ones of its zone with interconnect, and
+// the ones of the control plane otherwise, on the leader if it is known. The OVS
+// commands run in the ovs-node pod of the node if any, or in its ovnkube-node pod,
+// which has the OVS sockets of the host.
+func (d *Discoverer) Resolve(ctx context.Context, req *mcp.CallToolRequest, target k8stypes.PodTargetParams,
+	command string) (k8stypes.ExecPodParams, error) {
+	role := commandRole(command)
+	var pod *Pod
+	var namespace string
+	switch {
+	case target.Name != "" && target.Node != "":
+		return k8stypes.ExecPodParams{}, errors.New("name and node cannot be set together")
+	case target.Name != "":
+		namespace = target.Namespace
+	case target.Node != "":
+		topology, err := d.Discover(ctx, req, target.Namespace, false)
+		if err != nil {
+			return k8stypes.ExecPodParams{}, err
+		}
+		if pod, err = topology.podOf(target.Node, role); err != nil {
+			return k8stypes.ExecPodParams{}, err
+		}
+		namespace = topology.Namespace
+	default:
+		return k8stypes.ExecPodParams{}, errors.New("name or node is required")
+	}
+
+	exec := k8stypes.ExecPodParams{
+		NamespacedNameParams: k8stypes.NamespacedNameParams{Namespace: namespace, Name: target.Name},
+		Container:            target.Container,
+	}
+	if pod != nil {
+		exec.Name = pod.Name
+	}
+	if exec.Container == "" {
+		var err error
+		if exec.Container, err = d.selectContainer(ctx, req, exec.NamespacedNameParams, pod, command, role); err != nil {
+			return k8stypes.ExecPodParams{}, err
+		}
+	}
+	return exec, nil
+}
+
+// podOf returns the pod running a component for a node.
+func (t *Topology) podOf(nodeName string, role Role) (*Pod, error) {
+	index := slices.IndexFunc(t.Nodes, func(node Node) bool { return node.Name == nodeName })
+	if index < 0 {
+		return nil, fmt.Errorf("node %s not found", nodeName)
+	}
+	node := t.Nodes[index]
+
+	if t.Mode == ModeCentral && role != RoleOVNController && role != RoleOVS {
+		var leader string
+		for _, database := range t.Databases {
+			if database.Database == role && database.Role == "leader" {
+				leader = database.Pod
+			}
+		}
+		for i, pod := range t.ControlPlane {
+			if pod.Name == leader {
+				return &t.ControlPlane[i], nil
+			}
+		}
+		for i, pod := range t.ControlPlane {
+			if _, found := pod.container(role); found && pod.Phase == string(corev1.PodRunning) {
+				return &t.ControlPlane[i], nil
+			}
+		}
+		return nil, fmt.Errorf("no running %s pod found in the control plane of namespace %s", role, t.Namespace)
+	}
+
+	roles := []Role{role}
+	if role == RoleOVS {
+		roles = append(roles, RoleOVNController)
+	}
+	for _, role := range roles {
+		for i, pod := range node.Pods {
+			if _, found := pod.container(role); found {
+				return &node.Pods[i], nil
+			}
+		}
+	}
+	return nil, fmt.Errorf("no %s pod found on node %s in namespace %s", role, nodeName, t.Namespace)
+}
+
+// fromUnstructured converts unstructured resources to their type.
+func fromUnstructured[T any](items []unstructured.Unstructured) ([]T, error) {
+	objects := make([]T, 0, len(items))
+	for _, item := range items {
+		var object T
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(item.UnstructuredContent(), &object); err != nil {
+			return nil, fmt.Errorf("failed to convert %s %s: %w", item.GetKind(), item.GetName(), err)
+		}
+		objects = append(objects, object)
+	}
+	return objects, nil
+}
@@ -0,0 +1,305 @@
+package topology
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	k8stypes "github.com/ovn-kubernetes/ovn-kubernetes-mcp/pkg/kubernetes/types"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// fakeCluster lists its pods and nodes, answers the cluster/status commands with
+// the status of the pods, and the probes of the containers with their exit codes,
+// 2 if the container has no exit code.
+type fakeCluster struct {
+	pods   []corev1.Pod
+	nodes  []corev1.Node
+	status map[string]k8stypes.ExecPodResult
+	probes map[string]int
+	lists  int
+	probed []string
+}
+
+func (c *fakeCluster) Get(ctx context.Context, group, version, kind, namespace, name string) (*unstructured.Unstructured, error) {
+	for _, pod := range c.pods {
+		if pod.Namespace == namespace && pod.Name == name {
+			content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(&pod)
+			if err != nil {
+				return nil, err
+			}
+			return &unstructured.Unstructured{Object: content}, nil
+		}
+	}
+	return nil, errors.New("not found")
+}
+
+func (c *fakeCluster) List(ctx context.Context, group, version, kind, namespace, labelSelector string) ([]unstructured.Unstructured, error) {
+	c.lists++
+	var objects []any
+	switch kind {
+	case "Pod":
+		for _, pod := range c.pods {
+			if pod.Namespace == namespace {
+				objects = append(objects, &pod)
+			}
+		}
+	case "Node":
+		for _, node := range c.nodes {
+			objects = append(objects, &node)
+		}
+	}
+	var items []unstructured.Unstructured
+	for _, object := range objects {
+		content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(object)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, unstructured.Unstructured{Object: content})
+	}
+	return items, nil
+}
+
+func (c *fakeCluster) ExecPod(ctx context.Context, req *mcp.CallToolRequest, in k8stypes.ExecPodParams) (*mcp.CallToolResult, k8stypes.ExecPodResult, error) {
+	if in.Command[0] == "sh" {
+		c.probed = append(c.probed, in.Container)
+		if code := c.probes[in.Name+"/"+in.Container]; code != 0 {
+			return nil, k8stypes.ExecPodResult{}, fmt.Errorf("command terminated with exit code %d", code)
+		}
+		if _, found := c.probes[in.Name+"/"+in.Container]; !found {
+			return nil, k8stypes.ExecPodResult{}, errors.New("command terminated with exit code 2")
+		}
+		return nil, k8stypes.ExecPodResult{}, nil
+	}
+	result, found := c.status[in.Name+"/"+in.Container]
+	if !found {
+		return nil, k8stypes.ExecPodResult{}, errors.New("container not found")
+	}
+	return nil, result, nil
+}
+
+func newPod(namespace, name, app, node string, containers ...string) corev1.Pod {
+	pod := corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name, Labels: map[string]string{"app": app}},
+		Spec:       corev1.PodSpec{NodeName: node},
+		Status:     corev1.PodStatus{Phase: corev1.PodRunning},
+	}
+	for _, container := range containers {
+		pod.Spec.Containers = append(pod.Spec.Containers, corev1.Container{Name: container})
+	}
+	return pod
+}
+
+func newNode(name, zone, gatewayMode string) corev1.Node {
+	node := corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: name, Annotations: map[string]string{}}}
+	if zone != "" {
+		node.Annotations[zoneAnnotation] = zone
+	}
+	if gatewayMode != "" {
+		node.Annotations[gatewayConfigAnnotation] = `{"default":{"mode":"` + gatewayMode + `","interface-id":"br-ex_` + name + `"}}`
+	}
+	return node
+}
+
+// interconnectCluster is an OpenShift cluster with interconnect.
+func interconnectCluster() *fakeCluster {
+	containers := []string{"ovn-controller", "ovn-acl-logging", "kube-rbac-proxy-node", "northd", "nbdb", "sbdb", "ovnkube-controller"}
+	return &fakeCluster{
+		pods: []corev1.Pod{
+			newPod(openshiftNamespace, "ovnkube-node-b", "ovnkube-node", "worker-b", containers...),
+			newPod(openshiftNamespace, "ovnkube-node-a", "ovnkube-node", "worker-a", containers...),
+			newPod(openshiftNamespace, "ovnkube-control-plane-0", "ovnkube-control-plane", "master-0", "kube-rbac-proxy", "ovnkube-cluster-manager"),
+		},
+		nodes: []corev1.Node{newNode("worker-a", "worker-a", "shared"), newNode("worker-b", "worker-b", "local"), newNode("master-0", "master-0", "shared")},
+		probes: map[string]int{
+			"ovnkube-node-a/ovn-controller": 0, "ovnkube-node-a/northd": 3, "ovnkube-node-a/nbdb": 0, "ovnkube-node-a/sbdb": 0,
+			"ovnkube-node-b/ovn-controller": 0, "ovnkube-node-b/northd": 3, "ovnkube-node-b/nbdb": 0, "ovnkube-node-b/sbdb": 0,
+		},
+	}
+}
+
+// centralCluster is an upstream cluster with clustered databases.
+func centralCluster() *fakeCluster {
+	return &fakeCluster{
+		pods: []corev1.Pod{
+			newPod("ovn-kubernetes", "ovnkube-db-0", "ovnkube-db", "master-0", "nb-ovsdb", "sb-ovsdb"),
+			newPod("ovn-kubernetes", "ovnkube-db-1", "ovnkube-db", "master-1", "nb-ovsdb", "sb-ovsdb"),
+			newPod("ovn-kubernetes", "ovnkube-master-0", "ovnkube-master", "master-0", "ovn-northd", "ovnkube-master"),
+			newPod("ovn-kubernetes", "ovnkube-node-a", "ovnkube-node", "worker-a", "ovnkube-node", "ovn-controller"),
+			newPod("ovn-kubernetes", "ovs-node-a", "ovs-node", "worker-a", "ovs-daemons"),
+		},
+		nodes: []corev1.Node{newNode("worker-a", "", "shared"), newNode("master-0", "", "shared"), newNode("master-1", "", "shared")},
+		status: map[string]k8stypes.ExecPodResult{
+			"ovnkube-db-0/nb-ovsdb": {Stdout: "Name: OVN_Northbound\nStatus: cluster member\nRole: follower\nLeader: 5c1e\n"},
+			"ovnkube-db-1/nb-ovsdb": {Stdout: "Name: OVN_Northbound\nStatus: cluster member\nRole: leader\nLeader: self\n"},
+			"ovnkube-db-0/sb-ovsdb": {Stdout: "Name: OVN_Southbound\nRole: leader\n"},
+			"ovnkube-db-1/sb-ovsdb": {Stderr: "cluster/status: OVN_Southbound is not a clustered database"},
+		},
+		probes: map[string]int{"ovnkube-db-1/nb-ovsdb": 0, "ovnkube-node-a/ovn-controller": 3},
+	}
+}
+
+func TestDiscover(t *testing.T) {
+	t.Run("interconnect", func(t *testing.T) {
+		d := NewDiscoverer(interconnectCluster(), DefaultNamespaces, DefaultTTL)
+		topology, err := d.Discover(context.Background(), nil, "", false)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if topology.Namespace != openshiftNamespace || topology.Platform != PlatformOpenShift || topology.Mode != ModeInterconnect {
+			t.Fatalf("Unexpected deployment %s %s %s", topology.Namespace, topology.Platform, topology.Mode)
+		}
+		if topology.GatewayMode != "mixed" {
+			t.Fatalf("Expected a mixed gateway mode, got %q", topology.GatewayMode)
+		}
+		var nodes []string
+		for _, node := range topology.Nodes {
+			nodes = append(nodes, node.Name)
+		}
+		if !reflect.DeepEqual(nodes, []string{"master-0", "worker-a", "worker-b"}) {
+			t.Fatalf("Unexpected nodes %v", nodes)
+		}
+		workerA := topology.Nodes[1]
+		if workerA.Zone != "worker-a" || workerA.GatewayMode != "shared" || len(workerA.Pods) != 1 || workerA.Pods[0].Name != "ovnkube-node-a" {
+			t.Fatalf("Unexpected node %+v", workerA)
+		}
+		if container, _ := workerA.Pods[0].container(RoleNorthboundDB); container.Name != "nbdb" {
+			t.Fatalf("Expected the nbdb container, got %+v", workerA.Pods[0].Containers)
+		}
+		if len(topology.ControlPlane) != 1 || len(topology.Databases) != 0 {
+			t.Fatalf("Unexpected control plane %+v and databases %+v", topology.ControlPlane, topology.Databases)
+		}
+	})
+
+	t.Run("central", func(t *testing.T) {
+		d := NewDiscoverer(centralCluster(), DefaultNamespaces, DefaultTTL)
+		topology, err := d.Discover(context.Background(), nil, "", false)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if topology.Namespace != "ovn-kubernetes" || topology.Platform != PlatformUpstream || topology.Mode != ModeCentral {
+			t.Fatalf("Unexpected deployment %s %s %s", topology.Namespace, topology.Platform, topology.Mode)
+		}
+		want := []Database{
+			{Database: RoleNorthboundDB, Pod: "ovnkube-db-0", Container: "nb-ovsdb", Role: "follower"},
+			{Database: RoleSouthboundDB, Pod: "ovnkube-db-0", Container: "sb-ovsdb", Role: "leader"},
+			{Database: RoleNorthboundDB, Pod: "ovnkube-db-1", Container: "nb-ovsdb", Role: "leader"},
+			{Database: RoleSouthboundDB, Pod: "ovnkube-db-1", Container: "sb-ovsdb", Role: "standalone"},
+		}
+		if !reflect.DeepEqual(topology.Databases, want) {
+			t.Fatalf("Expected databases %+v, got %+v", want, topology.Databases)
+		}
+	})
+
+	t.Run("namespace not found", func(t *testing.T) {
+		d := NewDiscoverer(centralCluster(), DefaultNamespaces, DefaultTTL)
+		_, err := d.Discover(context.Background(), nil, "kube-system", false)
+		if err == nil || !strings.Contains(err.Error(), "no OVN-Kubernetes pods found in namespaces kube-system") {
+			t.Fatalf("Expected a not found error, got %v", err)
+		}
+	})
+
+	t.Run("cache", func(t *testing.T) {
+		cluster := centralCluster()
+		d := NewDiscoverer(cluster, DefaultNamespaces, time.Minute)
+		now := time.Now()
+		d.now = func() time.Time { return now }
+		discover := func(refresh bool) {
+			t.Helper()
+			if _, err := d.Discover(context.Background(), nil, "", refresh); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+		}
+		discover(false)
+		lists := cluster.lists
+		discover(false)
+		if cluster.lists != lists {
+			t.Fatal("Expected the topology to be cached")
+		}
+		discover(true)
+		if cluster.lists != 2*lists {
+			t.Fatal("Expected the topology to be discovered again on refresh")
+		}
+		now = now.Add(2 * time.Minute)
+		discover(false)
+		if cluster.lists != 3*lists {
+			t.Fatal("Expected the expired topology to be discovered again")
+		}
+	})
+}
+
+func TestResolve(t *testing.T) {
+	tests := []struct {
+		name          string
+		cluster       *fakeCluster
+		target        k8stypes.PodTargetParams
+		command       string
+		wantPod       string
+		wantContainer string
+		wantProbed    []string
+		wantErr       string
+	}{
+		{name: "named pod and container", cluster: centralCluster(), target: k8stypes.PodTargetParams{Namespace: "ns", Name: "pod", Container: "c"}, command: "ovs-vsctl", wantPod: "pod", wantContainer: "c"},
+		{name: "named pod", cluster: interconnectCluster(), target: k8stypes.PodTargetParams{Namespace: openshiftNamespace, Name: "ovnkube-node-a"}, command: "ovn-sbctl",
+			wantPod: "ovnkube-node-a", wantContainer: "sbdb", wantProbed: []string{"sbdb"}},
+		{name: "unknown named pod", cluster: centralCluster(), target: k8stypes.PodTargetParams{Namespace: "ns", Name: "pod"}, command: "ovs-vsctl", wantErr: "failed to get pod ns/pod"},
+		{name: "name and node", cluster: centralCluster(), target: k8stypes.PodTargetParams{Name: "pod", Node: "worker-a"}, wantErr: "cannot be set together"},
+		{name: "no target", cluster: centralCluster(), wantErr: "name or node is required"},
+		{name: "interconnect database", cluster: interconnectCluster(), target: k8stypes.PodTargetParams{Node: "worker-b"}, command: "ovn-nbctl",
+			wantPod: "ovnkube-node-b", wantContainer: "nbdb", wantProbed: []string{"nbdb"}},
+		{name: "interconnect trace", cluster: interconnectCluster(), target: k8stypes.PodTargetParams{Node: "worker-b"}, command: "ovn-trace",
+			wantPod: "ovnkube-node-b", wantContainer: "sbdb", wantProbed: []string{"sbdb"}},
+		{name: "interconnect ovs", cluster: interconnectCluster(), target: k8stypes.PodTargetParams{Node: "worker-a"}, command: "ovs-ofctl",
+			wantPod: "ovnkube-node-a", wantContainer: "ovn-controller", wantProbed: []string{"ovn-controller"}},
+		{name: "interconnect control plane node", cluster: interconnectCluster(), target: k8stypes.PodTargetParams{Node: "master-0"}, command: "ovn-sbctl", wantErr: "no sbdb pod found on node master-0"},
+		{name: "central leader", cluster: centralCluster(), target: k8stypes.PodTargetParams{Node: "worker-a"}, command: "ovn-nbctl",
+			wantPod: "ovnkube-db-1", wantContainer: "nb-ovsdb", wantProbed: []string{"nb-ovsdb"}},
+		{name: "central ovs single container", cluster: centralCluster(), target: k8stypes.PodTargetParams{Node: "worker-a"}, command: "ovs-vsctl", wantPod: "ovs-node-a", wantContainer: "ovs-daemons"},
+		{name: "no container", cluster: centralCluster(), target: k8stypes.PodTargetParams{Namespace: "ovn-kubernetes", Name: "ovnkube-node-a"}, command: "ovs-appctl",
+			wantErr: "no container of pod ovn-kubernetes/ovnkube-node-a has ovs-appctl and socket /var/run/openvswitch/db.sock, probed containers: " +
+				"ovn-controller (socket /var/run/openvswitch/db.sock not found), ovnkube-node (ovs-appctl not found)"},
+		{name: "unknown node", cluster: centralCluster(), target: k8stypes.PodTargetParams{Node: "worker-z"}, command: "ovs-vsctl", wantErr: "node worker-z not found"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			d := NewDiscoverer(test.cluster, DefaultNamespaces, DefaultTTL)
+			exec, err := d.Resolve(context.Background(), nil, test.target, test.command)
+			if test.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), test.wantErr) {
+					t.Fatalf("Expected error %q, got %v", test.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if exec.Name != test.wantPod || exec.Container != test.wantContainer {
+				t.Fatalf("Expected container %s/%s, got %s/%s", test.wantPod, test.wantContainer, exec.Name, exec.Container)
+			}
+			if !reflect.DeepEqual(test.cluster.probed, test.wantProbed) {
+				t.Fatalf("Expected probed containers %v, got %v", test.wantProbed, test.cluster.probed)
+			}
+		})
+	}
+}
+
+func TestResolveCache(t *testing.T) {
+	cluster := interconnectCluster()
+	d := NewDiscoverer(cluster, DefaultNamespaces, DefaultTTL)
+	for range 2 {
+		if _, err := d.Resolve(context.Background(), nil, k8stypes.PodTargetParams{Node: "worker-a"}, "ovn-nbctl"); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+	if len(cluster.probed) != 1 {
+		t.Fatalf("Expected the selected container to be cached, probed %v", cluster.probed)
+	}
+}
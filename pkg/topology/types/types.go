@@ -0,0 +1,21 @@
+package types
+
+import (
+	k8stypes "github.com/ovn-kubernetes/ovn-kubernetes-mcp/pkg/kubernetes/types"
+	"github.com/ovn-kubernetes/ovn-kubernetes-mcp/pkg/topology"
+)
+
+// TopologyParams contains the parameters for discovering the OVN-Kubernetes
+// topology of a cluster.
+type TopologyParams struct {
+	k8stypes.ClusterParams
+	// Namespace is the namespace of the OVN-Kubernetes pods, detected if empty.
+	Namespace string `json:"namespace,omitempty"`
+	// Refresh discovers the topology again rather than returning the cached one.
+	Refresh bool `json:"refresh,omitempty"`
+}
+
+// TopologyResult contains the OVN-Kubernetes topology of a cluster.
+type TopologyResult struct {
+	topology.Topology
+}